@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"go-ecommerce/internal/adapters/alerts"
 	"go-ecommerce/internal/adapters/api/http/handlers"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
 	"go-ecommerce/internal/adapters/api/http/routes"
 	"go-ecommerce/internal/adapters/config"
+	"go-ecommerce/internal/adapters/imaging"
 	"go-ecommerce/internal/adapters/logger"
 	"go-ecommerce/internal/adapters/mercadopago"
+	"go-ecommerce/internal/adapters/notifications"
+	"go-ecommerce/internal/adapters/scheduler"
 	"go-ecommerce/internal/adapters/security"
+	"go-ecommerce/internal/adapters/storage/blob/local"
 	"go-ecommerce/internal/adapters/storage/cache/redis"
 	"go-ecommerce/internal/adapters/storage/database/postgres"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
 	"go-ecommerce/internal/core/services"
 	"net/http"
+	"net/mail"
 	"time"
 
 	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
@@ -59,7 +68,15 @@ func main() {
 	defer cache.Close()
 	slog.Info("Successfully connected to the cache server")
 
+	// the carts used to be saved only in cache
+	err = postgres.ImportCachedCarts(ctx, db, cache)
+	if err != nil {
+		slog.Error("Error importing the cached carts", "error", err)
+		os.Exit(1)
+	}
+
 	hasher := &security.Hasher{}
+	alerter := alerts.NewLogAlerter()
 	httpClient := &http.Client{
 		Timeout: time.Second * 2,
 	}
@@ -68,8 +85,29 @@ func main() {
 
 	// users
 	userRepo := repository.NewUserRepo(db)
-	userSrv := services.NewUserService(userRepo, cache, hasher)
-	userHandler := handlers.NewUserHandler(userSrv)
+
+	// notifications are queued in the database and sent by the dispatch job
+	var sender ports.Notifier
+	switch config.Notifications.Driver {
+	case "smtp":
+		templates, err := notifications.NewTemplates(config.Notifications.DefaultLocale)
+		if err != nil {
+			slog.Error("Error parsing notification templates", "error", err)
+			os.Exit(1)
+		}
+		smtpConfig := config.Notifications.SMTP
+		from := mail.Address{Name: smtpConfig.FromName, Address: smtpConfig.From}
+		sender = notifications.NewSMTPNotifier(smtpConfig.Host, smtpConfig.Port, smtpConfig.Username, smtpConfig.Password, from, templates)
+	default:
+		sender = notifications.NewLogNotifier()
+	}
+	notificationSrv := services.NewNotificationService(repository.NewNotificationRepo(db), userRepo, sender, config.Notifications.MaxAttempts)
+
+	userSrv := services.NewUserService(userRepo, cache, hasher, notificationSrv)
+
+	// access tokens of the users, the protected routes require them as bearer tokens
+	authSrv := services.NewAuthService(userRepo, userSrv, hasher, security.NewSigner(config.Auth.Secret), config.Auth.TokenTTL)
+	authenticate := middlewares.Authenticate(authSrv)
 
 	// categories
 	catRepo := repository.NewCategoryRepo(db)
@@ -81,51 +119,202 @@ func main() {
 	prodSrv := services.NewProductService(prodRepo, cache)
 	prodHandler := handlers.NewProductHandler(prodSrv)
 
-	// cart
-	cartSrv := services.NewCartService(cache, prodSrv)
+	// price history and scheduled price changes
+	priceRepo := repository.NewPriceRepo(db)
+	priceSrv := services.NewPriceService(priceRepo, prodRepo, cache)
+	priceHandler := handlers.NewPriceHandler(priceSrv)
+
+	// low stock and back in stock alerts
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(db), prodRepo, userRepo, notificationSrv)
+	stockAlertHandler := handlers.NewStockAlertHandler(stockAlertSrv)
+
+	// warehouses and the stock ledger
+	warehouseRepo := repository.NewWarehouseRepo(db)
+	warehouseSrv := services.NewWarehouseService(warehouseRepo)
+	warehouseHandler := handlers.NewWarehouseHandler(warehouseSrv)
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(db), warehouseRepo, prodRepo, stockAlertSrv, cache)
+	inventoryHandler := handlers.NewInventoryHandler(inventorySrv)
+
+	// bulk import and export of the catalogue
+	catalogSrv := services.NewCatalogService(prodRepo, cache)
+	catalogHandler := handlers.NewCatalogHandler(catalogSrv)
+
+	// product images
+	blobStorage := local.NewStorage(config.Storage.LocalDir, config.Storage.PublicURL)
+	imageSrv := services.NewProductImageService(prodRepo, blobStorage, imaging.NewThumbnailer(), cache)
+	imageHandler := handlers.NewProductImageHandler(imageSrv)
+
+	// cart, saved in the database and written through to the cache
+	cartRepo := repository.NewCartRepo(db)
+	cartSrv := services.NewCartService(cartRepo, cache, prodSrv)
 	cartHandler := handlers.NewCartHandler(cartSrv)
 
+	// carts of the visitors, merged into the cart of the user when the guest registers or logs in
+	guestCartSrv := services.NewGuestCartService(cartRepo, cache, prodSrv, security.NewSigner(config.GuestCart.Secret))
+	guestCartHandler := handlers.NewGuestCartHandler(guestCartSrv)
+	userHandler := handlers.NewUserHandler(userSrv, guestCartSrv)
+	authHandler := handlers.NewAuthHandler(authSrv, guestCartSrv)
+
 	// order-products
 	opRepo := repository.NewOrderProductRepo(db)
 	opSrv := services.NewOrderProductService(opRepo)
 
-	// orders
-	orderRepo := repository.NewOrderRepo(opSrv, db)
-	orderSrv := services.NewOrderService(orderRepo, opSrv, cartSrv, cache)
-	orderHandler := handlers.NewOrderHandler(orderSrv)
-
 	paymentProv := mercadopago.NewPaymentProvider(
 		httpClient,
 		config.HTTP.Domain,
 		config.PaymentProvider.MercadoPago.AccessToken,
+		config.PaymentProvider.MercadoPago.AutoCapture,
+	)
+
+	// orders
+	orderRepo := repository.NewOrderRepo(opSrv, db)
+	attemptRepo := repository.NewPaymentAttemptRepo(db)
+	// reminders of the abandoned carts
+	cartRecoverySrv := services.NewCartRecoveryService(
+		repository.NewCartRecoveryRepo(db),
+		cartSrv,
+		notificationSrv,
+		security.NewSigner(config.CartRecovery.Secret),
+		config.CartRecovery.IdleAfter,
+		config.CartRecovery.LinkTTL,
+		config.CartRecovery.LinkURL,
 	)
+	cartRecoveryHandler := handlers.NewCartRecoveryHandler(cartRecoverySrv)
+
+	// disputes
+	disputeRepo := repository.NewDisputeRepo(db)
+	disputeSrv := services.NewDisputeService(disputeRepo, orderRepo, alerter, cache)
+	disputeHandler := handlers.NewDisputeHandler(disputeSrv)
 
 	paymentSrv := services.NewPaymentService(
 		userRepo,
 		orderRepo,
+		attemptRepo,
 		prodRepo,
 		paymentProv,
+		disputeSrv,
+		inventorySrv,
+		alerter,
+		notificationSrv,
+		config.PaymentProvider.AmountTolerance,
 	)
 	paymentHandler := handlers.NewPaymentHandler(paymentSrv)
 
+	orderSrv := services.NewOrderService(orderRepo, attemptRepo, opSrv, cartSrv, prodSrv, inventorySrv, paymentProv, paymentSrv, notificationSrv, cartRecoverySrv, cache)
+	orderHandler := handlers.NewOrderHandler(orderSrv)
+
+	// purge of the archived products, categories and users
+	archiveSrv := services.NewArchiveService(prodRepo, catRepo, userRepo, blobStorage)
+
+	// reports
+	reportRepo := repository.NewReportRepo(db)
+	reportSrv := services.NewReportService(reportRepo)
+	reportHandler := handlers.NewReportHandler(reportSrv)
+
 	// root router
 	router := chi.NewRouter()
 
 	router.Use(cors.Handler(cors.Options{
 		AllowedOrigins: config.HTTP.AllowedOrigins,
 		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", handlers.CartTokenHeader},
+		ExposedHeaders: []string{handlers.CartTokenHeader},
 		MaxAge:         300,
 	}))
 
 	// load all routes
+	routes.LoadAuthRoutes(router, authHandler)
 	routes.LoadUserRoutes(router, userHandler)
 	routes.LoadCategoryRoutes(router, catHandler)
-	routes.LoadProductRoutes(router, prodHandler)
-	routes.LoadOrderRoutes(router, orderHandler)
+	routes.LoadProductRoutes(router.With(middlewares.IdentifyUser(authSrv)), prodHandler)
+	routes.LoadProductImageRoutes(router, imageHandler, authenticate, middlewares.RequireRole(domain.Admin, domain.Seller))
+	routes.LoadUploadRoutes(router, config.Storage.LocalDir)
+	routes.LoadStockAlertRoutes(router, stockAlertHandler, authenticate)
+	routes.LoadOrderRoutes(router, orderHandler, authenticate)
 	routes.LoadCartRoutes(router, cartHandler)
+	routes.LoadCartRecoveryRoutes(router, cartRecoveryHandler)
+	routes.LoadGuestCartRoutes(router, guestCartHandler, authenticate)
 	routes.LoadPaymentRoutes(router, paymentHandler)
 
+	// admin routes, require an authenticated user with admin role
+	router.Group(func(r chi.Router) {
+		r.Use(authenticate, middlewares.RequireRole(domain.Admin))
+		routes.LoadReportRoutes(r, reportHandler)
+		routes.LoadAdminOrderRoutes(r, orderHandler, paymentHandler)
+		routes.LoadAdminArchiveRoutes(r, prodHandler, catHandler, userHandler)
+		routes.LoadAdminInventoryRoutes(r, inventoryHandler)
+		routes.LoadWarehouseRoutes(r, warehouseHandler)
+	})
+
+	// chargebacks, the bulk catalogue, the pricing and the stock are handled by sellers or admins
+	router.Group(func(r chi.Router) {
+		r.Use(authenticate, middlewares.RequireRole(domain.Admin, domain.Seller))
+		routes.LoadDisputeRoutes(r, disputeHandler)
+		routes.LoadCatalogRoutes(r, catalogHandler)
+		routes.LoadPriceRoutes(r, priceHandler)
+		routes.LoadInventoryRoutes(r, inventoryHandler)
+	})
+
+	// background jobs
+	jobs := scheduler.New()
+	jobs.Every("authorization-expiry-alerts", time.Hour, func(ctx context.Context) error {
+		_, err := paymentSrv.AlertExpiringAuthorizations(ctx, 24*time.Hour)
+		return err
+	})
+	jobs.Every("payment-reconciliation", config.Reconciliation.Interval, func(ctx context.Context) error {
+		report, err := paymentSrv.Reconcile(ctx, config.Reconciliation.MaxAge)
+		if err != nil {
+			return err
+		}
+		slog.Info("Payments reconciled", "checked", report.Checked, "updated", len(report.Updated), "mismatches", len(report.Mismatches), "errors", len(report.Errors))
+		return nil
+	})
+	jobs.Every("archive-purge", config.Archive.Interval, func(ctx context.Context) error {
+		report, err := archiveSrv.Purge(ctx, config.Archive.Retention)
+		if err != nil {
+			return err
+		}
+		slog.Info("Archived rows purged", "products", report.Products, "categories", report.Categories, "users", report.Users, "errors", len(report.Errors))
+		return nil
+	})
+	jobs.Every("price-schedules", time.Minute, func(ctx context.Context) error {
+		applied, err := priceSrv.ApplyDueSchedules(ctx, time.Now())
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			slog.Info("Scheduled price changes applied", "applied", applied)
+		}
+		return nil
+	})
+	// the stock of the products created by the catalogue import doesn't go through the inventory
+	jobs.Every("stock-alerts", time.Minute, func(ctx context.Context) error {
+		return stockAlertSrv.CheckAllStock(ctx)
+	})
+	jobs.Every("notifications", config.Notifications.DispatchInterval, func(ctx context.Context) error {
+		sent, err := notificationSrv.Dispatch(ctx, time.Now())
+		if sent > 0 {
+			slog.Info("Notifications sent", "sent", sent)
+		}
+		return err
+	})
+	jobs.Every("cart-recovery", config.CartRecovery.Interval, func(ctx context.Context) error {
+		sent, err := cartRecoverySrv.SendReminders(ctx, time.Now())
+		if sent > 0 {
+			slog.Info("Abandoned cart reminders sent", "sent", sent)
+		}
+		return err
+	})
+	jobs.Every("guest-cart-purge", time.Hour, func(ctx context.Context) error {
+		purged, err := guestCartSrv.PurgeExpired(ctx, time.Now())
+		if purged > 0 {
+			slog.Info("Expired guest carts purged", "carts", purged)
+		}
+		return err
+	})
+	jobs.Start(ctx)
+	defer jobs.Stop()
+
 	// Configurar servidor HTTP
 	s := &http.Server{
 		Handler:      router,
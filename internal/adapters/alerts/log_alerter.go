@@ -0,0 +1,34 @@
+package alerts
+
+import (
+	"context"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"sort"
+)
+
+// LogAlerter writes alerts in the application logs, so they can be collected by the log pipeline
+type LogAlerter struct{}
+
+func NewLogAlerter() ports.Alerter {
+	return &LogAlerter{}
+}
+
+// Alert implements ports.Alerter.
+func (a *LogAlerter) Alert(ctx context.Context, subject string, attrs map[string]any) error {
+	// sort keys to keep the same output for the same alert
+	keys := make([]string, 0, len(attrs))
+	for k := range attrs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]any, 0, len(attrs)*2+2)
+	args = append(args, "alert", subject)
+	for _, k := range keys {
+		args = append(args, k, attrs[k])
+	}
+
+	slog.WarnContext(ctx, "Alert raised", args...)
+	return nil
+}
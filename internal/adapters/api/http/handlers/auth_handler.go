@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type AuthHandler struct {
+	srv        ports.AuthService
+	guestCarts ports.GuestCartService
+}
+
+func NewAuthHandler(srv ports.AuthService, guestCarts ports.GuestCartService) *AuthHandler {
+	return &AuthHandler{srv: srv, guestCarts: guestCarts}
+}
+
+// Login returns the access token of the user, it's sent in the Authorization header as a bearer token
+func (ah *AuthHandler) Login(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodPost {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	type parameters struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	if params.Email == "" || params.Password == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Email and password are required")
+		return
+	}
+
+	token, user, err := ah.srv.Login(r.Context(), params.Email, params.Password)
+	if err != nil {
+		if err == domain.ErrInvalidCredentials {
+			httpdtos.RespondError(w, http.StatusUnauthorized, err.Error())
+			return
+		}
+		slog.Error("Error logging in user", "error", err)
+		httpdtos.RespondError(w, http.StatusInternalServerError, "Error logging in")
+		return
+	}
+
+	// the cart of the guest that logs in is merged into the cart of the user
+	if cartToken := guestCartToken(r); cartToken != "" && ah.guestCarts != nil {
+		if _, err := ah.guestCarts.Merge(r.Context(), cartToken, user.ID); err != nil {
+			slog.Warn("error merging guest cart on login", "user_id", user.ID, "error", err)
+		} else {
+			clearGuestCartToken(w)
+		}
+	}
+
+	type response struct {
+		Token  string          `json:"token"`
+		UserID uuid.UUID       `json:"user_id"`
+		Role   domain.UserRole `json:"role"`
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "User successfully logged in", response{Token: token, UserID: user.ID, Role: user.Role})
+}
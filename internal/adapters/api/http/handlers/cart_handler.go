@@ -30,7 +30,8 @@ func (ch *CartHandler) AddProductToCart(r *http.Request, w http.ResponseWriter)
 	}
 
 	type parameters struct {
-		Quantity *int16 `json:"quantity"`
+		Quantity  *int16     `json:"quantity"`
+		VariantID *uuid.UUID `json:"variant_id"` // required for products with variants
 	}
 
 	params, err := utils.ParseRequestBody[parameters](r)
@@ -71,9 +72,18 @@ func (ch *CartHandler) AddProductToCart(r *http.Request, w http.ResponseWriter)
 	}
 
 	// Call service to add a product
-	err = ch.srv.AddItemToCart(r.Context(), parsedUserId, parsedProductId, *params.Quantity)
+	err = ch.srv.AddItemToCart(r.Context(), parsedUserId, parsedProductId, params.VariantID, *params.Quantity)
 	if err != nil {
-		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		switch err {
+		case domain.ErrProductNotFound, domain.ErrVariantNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		case domain.ErrVariantIsRequire, domain.ErrNegativeQuantityNonExistProductCart, domain.ErrCartQuantityIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		case domain.ErrCartInsufficientStock, domain.ErrCartLineLimitExceeded, domain.ErrConcurrentUpdate:
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		}
 		return
 	}
 
@@ -100,8 +110,8 @@ func (ch *CartHandler) GetProductsFromCart(r *http.Request, w http.ResponseWrite
 		return
 	}
 
-	// Call service to add a product
-	cart, err := ch.srv.GetCart(r.Context(), parsedUserId)
+	// the lines are priced with the current products
+	cart, err := ch.srv.GetCartView(r.Context(), parsedUserId)
 	if err != nil {
 		slog.Error("Error retrieving cart", "user_id", userId, "error", err)
 		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving cart: %s", err.Error()))
@@ -139,6 +149,11 @@ func (ch *CartHandler) ClearCart(r *http.Request, w http.ResponseWriter) {
 			return
 		}
 
+		if err == domain.ErrConcurrentUpdate {
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error clearing cart: %s", err.Error()))
+			return
+		}
+
 		slog.Error("Error clearing cart", "user_id", userId, "error", err)
 		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error clearing cart: %s", err.Error()))
 		return
@@ -179,8 +194,19 @@ func (ch *CartHandler) RemoveItemFromCart(r *http.Request, w http.ResponseWriter
 		return
 	}
 
+	// the variant of the line is sent as query param, e.g. ?variant_id=
+	var variantId *uuid.UUID
+	if raw := r.URL.Query().Get("variant_id"); raw != "" {
+		parsedVariantId, err := uuid.Parse(raw)
+		if err != nil {
+			httpdtos.RespondError(w, http.StatusBadRequest, "VariantID must be a valid UUID")
+			return
+		}
+		variantId = &parsedVariantId
+	}
+
 	// Call service to add a product
-	err = ch.srv.RemoveItem(r.Context(), parsedUserId, parsedProductId)
+	err = ch.srv.RemoveItem(r.Context(), parsedUserId, parsedProductId, variantId)
 	if err != nil {
 		if err == domain.ErrAlreadyEmptyCart {
 			httpdtos.RespondError(w, http.StatusNoContent, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
@@ -192,6 +218,11 @@ func (ch *CartHandler) RemoveItemFromCart(r *http.Request, w http.ResponseWriter
 			return
 		}
 
+		if err == domain.ErrConcurrentUpdate {
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
+			return
+		}
+
 		slog.Error("Error remove item from cart", "user_id", userId, "product_id", productId, "error", err)
 		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
 		return
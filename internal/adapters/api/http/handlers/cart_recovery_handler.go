@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"net/http"
+)
+
+type CartRecoveryHandler struct {
+	srv ports.CartRecoveryService
+}
+
+func NewCartRecoveryHandler(srv ports.CartRecoveryService) *CartRecoveryHandler {
+	return &CartRecoveryHandler{srv: srv}
+}
+
+// RestoreCart is the link of the reminders, the signed token identifies the cart so it doesn't require a session
+func (ch *CartRecoveryHandler) RestoreCart(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Token is required")
+		return
+	}
+
+	cart, err := ch.srv.Restore(r.Context(), token)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidToken:
+			httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Error restoring cart: %s", err.Error()))
+		case domain.ErrTokenExpired:
+			httpdtos.RespondError(w, http.StatusGone, fmt.Sprintf("Error restoring cart: %s", err.Error()))
+		case domain.ErrCartRecoveryNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, fmt.Sprintf("Error restoring cart: %s", err.Error()))
+		default:
+			slog.Error("Error restoring cart", "error", err)
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error restoring cart: %s", err.Error()))
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Cart successfully restored", cart)
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+)
+
+// maxImportSize is the max size of the CSV files accepted by the import
+const maxImportSize = 50 << 20
+
+type CatalogHandler struct {
+	srv ports.CatalogService
+}
+
+func NewCatalogHandler(srv ports.CatalogService) *CatalogHandler {
+	return &CatalogHandler{srv: srv}
+}
+
+// ImportProducts accepts the CSV as the "file" field of a multipart form or as the raw body (text/csv)
+func (ch *CatalogHandler) ImportProducts(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodPost {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	var file io.Reader = r.Body
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		formFile, _, err := r.FormFile("file")
+		if err != nil {
+			httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("the \"file\" field is required: %s", err))
+			return
+		}
+		defer formFile.Close()
+		file = formFile
+	}
+
+	job, err := ch.srv.StartImport(r.Context(), file)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		var parseErr *csv.ParseError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			httpdtos.RespondError(w, http.StatusRequestEntityTooLarge, "the file exceeds the max size of 50MB")
+		case err == domain.ErrImportEmptyFile, err == domain.ErrImportMissingSKUColumn, errors.As(err, &parseErr):
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusAccepted, "import started", job)
+}
+
+func (ch *CatalogHandler) GetImportJob(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	jobId, err := parseUUIDParam(r, "job_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := ch.srv.GetImportJob(r.Context(), jobId)
+	if err != nil {
+		if err == domain.ErrImportJobNotFound {
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "import job successfully retrieved", job)
+}
+
+// ExportProducts streams the products that match the filters of the storefront (?format=csv|json)
+func (ch *CatalogHandler) ExportProducts(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query, err := parseProductQuery(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "json" {
+		httpdtos.RespondError(w, http.StatusBadRequest, domain.ErrInvalidExportFormat.Error())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=products.%s", format))
+
+	// once the first product is written the status can't change, later errors are only logged
+	started := false
+	var write func(*domain.Product) error
+	var finish func() error
+
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		writer := csv.NewWriter(w)
+		write = func(p *domain.Product) error {
+			if !started {
+				started = true
+				if err := writer.Write(domain.ProductCSVHeader); err != nil {
+					return err
+				}
+			}
+			return writer.Write(domain.ProductCSVRecord(p))
+		}
+		finish = func() error {
+			if !started {
+				if err := writer.Write(domain.ProductCSVHeader); err != nil {
+					return err
+				}
+			}
+			writer.Flush()
+			return writer.Error()
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		encoder := json.NewEncoder(w)
+		write = func(p *domain.Product) error {
+			separator := ","
+			if !started {
+				started = true
+				separator = "["
+			}
+			if _, err := io.WriteString(w, separator); err != nil {
+				return err
+			}
+			return encoder.Encode(p)
+		}
+		finish = func() error {
+			closing := "]"
+			if !started {
+				closing = "[]"
+			}
+			_, err := io.WriteString(w, closing)
+			return err
+		}
+	}
+
+	err = ch.srv.ExportProducts(r.Context(), query, write)
+	if err != nil {
+		if !started {
+			switch err {
+			case domain.ErrInvalidProductSort, domain.ErrInvalidPriceRange:
+				httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+			default:
+				httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+			}
+			return
+		}
+		slog.Error("error exporting products", "error", err)
+		return
+	}
+
+	if err := finish(); err != nil {
+		slog.Error("error finishing export of products", "error", err)
+	}
+}
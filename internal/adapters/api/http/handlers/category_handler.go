@@ -22,7 +22,8 @@ func NewCategoryHandler(srv ports.CategoryService) *CategoryHandler {
 
 func (ch *CategoryHandler) SaveCategory(r *http.Request, w http.ResponseWriter) {
 	type parameters struct {
-		Name *string `json:"name"`
+		Name     *string `json:"name"`
+		ParentID *uint64 `json:"parent_id"`
 	}
 
 	// verify http methods
@@ -56,9 +57,19 @@ func (ch *CategoryHandler) SaveCategory(r *http.Request, w http.ResponseWriter)
 		return
 	}
 
-	category, err := ch.srv.SaveCategory(r.Context(), uintId, *params.Name)
+	// without parent_id the category keeps its parent, parent_id 0 moves it to the root
+	category, err := ch.srv.SaveCategory(r.Context(), uintId, *params.Name, params.ParentID)
 	if err != nil {
-		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case domain.ErrCategoryNotFound, domain.ErrCategoryParentNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrCategoryCycle:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrCategoryNameIsRequire, domain.ErrMinLenghtCategoryNameIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -126,6 +137,21 @@ func (ch *CategoryHandler) ListCategories(r *http.Request, w http.ResponseWriter
 	httpdtos.RespondJSON(w, http.StatusOK, "categories successfully retrieved", categs)
 }
 
+func (ch *CategoryHandler) CategoryTree(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	tree, err := ch.srv.CategoryTree(r.Context())
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "category tree successfully retrieved", tree)
+}
+
 func (ch *CategoryHandler) DeleteCategory(r *http.Request, w http.ResponseWriter) {
 	if r.Method != http.MethodDelete {
 		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
@@ -146,15 +172,46 @@ func (ch *CategoryHandler) DeleteCategory(r *http.Request, w http.ResponseWriter
 		return
 	}
 
-	err = ch.srv.DeleteCategory(r.Context(), uintId)
+	// restrict is used by default, so children and products are never deleted by mistake
+	strategy := domain.CategoryDeleteStrategy(r.URL.Query().Get("strategy"))
+
+	err = ch.srv.DeleteCategory(r.Context(), uintId, strategy)
 	if err != nil {
-		if err == domain.ErrCategoryNotFound {
+		switch err {
+		case domain.ErrCategoryNotFound:
 			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
-			return
+		case domain.ErrCategoryHasChildren, domain.ErrCategoryHasProducts:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrInvalidCategoryDeleteStrategy:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
 		}
-		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	httpdtos.RespondJSON(w, http.StatusOK, "category successfully deleted", nil)
 }
+
+func (ch *CategoryHandler) RestoreCategory(r *http.Request, w http.ResponseWriter) {
+	categoryId, err := strconv.ParseUint(chi.URLParam(r, "category_id"), 10, 64)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, "category id must be a positive integer")
+		return
+	}
+
+	category, err := ch.srv.RestoreCategory(r.Context(), categoryId)
+	if err != nil {
+		switch err {
+		case domain.ErrCategoryNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrCategoryNotArchived, domain.ErrCategoryParentArchived:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "category successfully restored", category)
+}
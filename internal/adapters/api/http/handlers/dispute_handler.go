@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+type DisputeHandler struct {
+	srv ports.DisputeService
+}
+
+func NewDisputeHandler(disputeService ports.DisputeService) *DisputeHandler {
+	return &DisputeHandler{srv: disputeService}
+}
+
+// helper func
+func respondDisputeError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrDisputeNotFound:
+		httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+	case domain.ErrDisputeAlreadyResolved, domain.ErrDisputeEvidenceDeadline:
+		httpdtos.RespondError(w, http.StatusConflict, err.Error())
+	case domain.ErrDisputeEvidenceIsRequire, domain.ErrDisputeInvalidOutcome:
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error processing dispute: %s", err))
+	}
+}
+
+func (dh *DisputeHandler) ListDisputes(r *http.Request, w http.ResponseWriter) {
+	// Verify HTTP method
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// optional filter by status
+	var status *domain.DisputeStatus
+	if s := r.URL.Query().Get("status"); s != "" {
+		parsed := domain.DisputeStatus(s)
+		status = &parsed
+	}
+
+	disputes, err := dh.srv.ListDisputes(r.Context(), status)
+	if err != nil {
+		respondDisputeError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Disputes successfully obtained", disputes)
+}
+
+func (dh *DisputeHandler) GetDisputeById(r *http.Request, w http.ResponseWriter) {
+	// Verify HTTP method
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	disputeId, err := uuid.Parse(chi.URLParam(r, "dispute_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Dispute id must be a valid uuid: %s", err))
+		return
+	}
+
+	dispute, err := dh.srv.GetDisputeById(r.Context(), disputeId)
+	if err != nil {
+		respondDisputeError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Dispute successfully obtained", dispute)
+}
+
+func (dh *DisputeHandler) AddEvidence(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		Note string `json:"note"`
+	}
+
+	// Verify HTTP method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	disputeId, err := uuid.Parse(chi.URLParam(r, "dispute_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Dispute id must be a valid uuid: %s", err))
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	dispute, err := dh.srv.AddEvidence(r.Context(), disputeId, actor, params.Note)
+	if err != nil {
+		respondDisputeError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusCreated, "Evidence successfully attached", dispute)
+}
+
+func (dh *DisputeHandler) Resolve(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		Outcome string `json:"outcome"`
+	}
+
+	// Verify HTTP method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	actor, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	disputeId, err := uuid.Parse(chi.URLParam(r, "dispute_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Dispute id must be a valid uuid: %s", err))
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	dispute, err := dh.srv.Resolve(r.Context(), disputeId, actor, domain.DisputeStatus(params.Outcome))
+	if err != nil {
+		respondDisputeError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Dispute successfully resolved", dispute)
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	cachettl "go-ecommerce/internal/adapters/storage/cache/cache_ttl"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// The cart token of a guest is sent in the header or in the cookie, the responses set both
+const (
+	CartTokenHeader = "X-Cart-Token"
+	CartTokenCookie = "cart_token"
+)
+
+type GuestCartHandler struct {
+	srv ports.GuestCartService
+}
+
+func NewGuestCartHandler(srv ports.GuestCartService) *GuestCartHandler {
+	return &GuestCartHandler{srv: srv}
+}
+
+// helper func, returns the cart token of the request, empty if it doesn't have one
+func guestCartToken(r *http.Request) string {
+	if token := r.Header.Get(CartTokenHeader); token != "" {
+		return token
+	}
+	if cookie, err := r.Cookie(CartTokenCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// helper func
+func setGuestCartToken(w http.ResponseWriter, token string) {
+	w.Header().Set(CartTokenHeader, token)
+	http.SetCookie(w, &http.Cookie{
+		Name:     CartTokenCookie,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(cachettl.GuestCart.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// helper func, the cookie is removed once the cart was merged
+func clearGuestCartToken(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     CartTokenCookie,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// helper func, responds the errors of an invalid cart token
+func respondGuestCartTokenError(w http.ResponseWriter, err error) bool {
+	switch err {
+	case domain.ErrInvalidToken:
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid cart token: %s", err.Error()))
+	case domain.ErrTokenExpired:
+		httpdtos.RespondError(w, http.StatusGone, fmt.Sprintf("Invalid cart token: %s", err.Error()))
+	default:
+		return false
+	}
+	return true
+}
+
+func (gh *GuestCartHandler) GetCart(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	// a visitor without token hasn't added items yet
+	token := guestCartToken(r)
+	if token == "" {
+		httpdtos.RespondJSON(w, http.StatusOK, "Cart successfully retrieved", domain.NewCartView(domain.NewCart(uuid.Nil), nil))
+		return
+	}
+
+	cart, err := gh.srv.GetCartView(r.Context(), token)
+	if err != nil {
+		if respondGuestCartTokenError(w, err) {
+			return
+		}
+		slog.Error("Error retrieving guest cart", "error", err)
+		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving cart: %s", err.Error()))
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Cart successfully retrieved", cart)
+}
+
+// AddProductToCart creates the guest cart when the request doesn't have a cart token
+func (gh *GuestCartHandler) AddProductToCart(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	type parameters struct {
+		Quantity  *int16     `json:"quantity"`
+		VariantID *uuid.UUID `json:"variant_id"` // required for products with variants
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	// Validate params
+	if params.Quantity == nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Quantity is required")
+		return
+	}
+
+	parsedProductId, err := uuid.Parse(chi.URLParam(r, "product_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, "ProductID must be a valid UUID")
+		return
+	}
+
+	token := guestCartToken(r)
+	if token == "" {
+		token = gh.srv.NewToken()
+	}
+
+	err = gh.srv.AddItemToCart(r.Context(), token, parsedProductId, params.VariantID, *params.Quantity)
+	if err != nil {
+		if respondGuestCartTokenError(w, err) {
+			return
+		}
+		switch err {
+		case domain.ErrProductNotFound, domain.ErrVariantNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		case domain.ErrVariantIsRequire, domain.ErrNegativeQuantityNonExistProductCart, domain.ErrCartQuantityIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		case domain.ErrCartInsufficientStock, domain.ErrCartLineLimitExceeded, domain.ErrConcurrentUpdate:
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error adding item to cart: %s", err.Error()))
+		}
+		return
+	}
+
+	setGuestCartToken(w, token)
+	httpdtos.RespondJSON(w, http.StatusOK, "Product added to cart", nil)
+}
+
+func (gh *GuestCartHandler) RemoveItemFromCart(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodDelete {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	token := guestCartToken(r)
+	if token == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Cart token is required")
+		return
+	}
+
+	parsedProductId, err := uuid.Parse(chi.URLParam(r, "product_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, "ProductID must be a valid UUID")
+		return
+	}
+
+	// the variant of the line is sent as query param, e.g. ?variant_id=
+	var variantId *uuid.UUID
+	if raw := r.URL.Query().Get("variant_id"); raw != "" {
+		parsedVariantId, err := uuid.Parse(raw)
+		if err != nil {
+			httpdtos.RespondError(w, http.StatusBadRequest, "VariantID must be a valid UUID")
+			return
+		}
+		variantId = &parsedVariantId
+	}
+
+	err = gh.srv.RemoveItem(r.Context(), token, parsedProductId, variantId)
+	if err != nil {
+		if respondGuestCartTokenError(w, err) {
+			return
+		}
+		switch err {
+		case domain.ErrAlreadyEmptyCart:
+			httpdtos.RespondError(w, http.StatusNoContent, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
+		case domain.ErrProductNotFoundCart:
+			httpdtos.RespondError(w, http.StatusNotFound, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
+		case domain.ErrConcurrentUpdate:
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
+		default:
+			slog.Error("Error remove item from guest cart", "product_id", parsedProductId, "error", err)
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Cart item successfully removed", nil)
+}
+
+func (gh *GuestCartHandler) ClearCart(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodDelete {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	token := guestCartToken(r)
+	if token == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Cart token is required")
+		return
+	}
+
+	err := gh.srv.Clear(r.Context(), token)
+	if err != nil {
+		if respondGuestCartTokenError(w, err) {
+			return
+		}
+		if err == domain.ErrAlreadyEmptyCart {
+			httpdtos.RespondError(w, http.StatusNoContent, fmt.Sprintf("Error deleting product in cart: %s", err.Error()))
+			return
+		}
+		if err == domain.ErrConcurrentUpdate {
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error clearing cart: %s", err.Error()))
+			return
+		}
+		slog.Error("Error clearing guest cart", "error", err)
+		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error clearing cart: %s", err.Error()))
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Cart successfully cleared", nil)
+}
+
+// MergeCart moves the guest cart to the cart of the authenticated user, called by the clients after the login
+func (gh *GuestCartHandler) MergeCart(r *http.Request, w http.ResponseWriter) {
+	// Validate http methods
+	if r.Method != http.MethodPost {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	user, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	token := guestCartToken(r)
+	if token == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Cart token is required")
+		return
+	}
+
+	cart, err := gh.srv.Merge(r.Context(), token, user.ID)
+	if err != nil {
+		if respondGuestCartTokenError(w, err) {
+			return
+		}
+		if err == domain.ErrConcurrentUpdate {
+			httpdtos.RespondError(w, http.StatusConflict, fmt.Sprintf("Error merging cart: %s", err.Error()))
+			return
+		}
+		slog.Error("Error merging guest cart", "user_id", user.ID, "error", err)
+		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error merging cart: %s", err.Error()))
+		return
+	}
+
+	clearGuestCartToken(w)
+	httpdtos.RespondJSON(w, http.StatusOK, "Cart successfully merged", cart)
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InventoryHandler struct {
+	srv ports.InventoryService
+}
+
+func NewInventoryHandler(srv ports.InventoryService) *InventoryHandler {
+	return &InventoryHandler{srv: srv}
+}
+
+func (ih *InventoryHandler) RecordMovement(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		VariantID   *uuid.UUID `json:"variant_id"`
+		WarehouseID *uuid.UUID `json:"warehouse_id"` // the default warehouse when it's empty
+		Type        string     `json:"type"`         // receipt, adjustment or return
+		Quantity    int64      `json:"quantity"`     // signed for adjustments
+		Reason      string     `json:"reason"`
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	inputs := ports_dtos.StockMovementInputs{
+		VariantID:   params.VariantID,
+		WarehouseID: params.WarehouseID,
+		Type:        params.Type,
+		Quantity:    params.Quantity,
+		Reason:      params.Reason,
+	}
+	if user, ok := middlewares.UserFromContext(r.Context()); ok {
+		inputs.ActorID = &user.ID
+	}
+
+	movement, err := ih.srv.RecordMovement(r.Context(), productId, inputs)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound, domain.ErrVariantNotFound, domain.ErrWarehouseNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrProductInsufficientStock, domain.ErrVariantInsufficientStock, domain.ErrNoDefaultWarehouse:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrInvalidStockMovementType, domain.ErrStockMovementNotManual, domain.ErrStockMovementQuantity,
+			domain.ErrStockAdjustmentIsZero, domain.ErrStockMovementReasonIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusCreated, "stock movement successfully recorded", movement)
+}
+
+func (ih *InventoryHandler) ListMovements(r *http.Request, w http.ResponseWriter) {
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	movements, err := ih.srv.ListMovements(r.Context(), productId)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "stock movements successfully retrieved", movements)
+}
+
+// StockAt reports the stock of the product and its variants, in total and by warehouse, at the time of the "at" query param (RFC 3339), now by default
+func (ih *InventoryHandler) StockAt(r *http.Request, w http.ResponseWriter) {
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	at := time.Now()
+	if v := r.URL.Query().Get("at"); v != "" {
+		at, err = time.Parse(time.RFC3339, v)
+		if err != nil {
+			httpdtos.RespondError(w, http.StatusBadRequest, "at must be a RFC 3339 date")
+			return
+		}
+	}
+
+	report, err := ih.srv.StockAt(r.Context(), productId, at)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "stock successfully retrieved", report)
+}
+
+func (ih *InventoryHandler) AuditStock(r *http.Request, w http.ResponseWriter) {
+	discrepancies, err := ih.srv.AuditStock(r.Context())
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "stock successfully audited", discrepancies)
+}
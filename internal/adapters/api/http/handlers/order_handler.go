@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
 	"go-ecommerce/internal/adapters/api/http/utils"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
@@ -30,6 +31,8 @@ func (oh *OrderHandler) SaveOrder(r *http.Request, w http.ResponseWriter) {
 		Paid              bool                    `json:"paid"`
 		PayStatus         *domain.PayStatus       `json:"pay_status"`
 		PayStatusDetail   *domain.PayStatusDetail `json:"pay_status_detail,omitempty"`
+		Allocation        domain.AllocationRule   `json:"allocation,omitempty"` // closest or most_stock, most_stock by default
+		ShipTo            *domain.GeoPoint        `json:"ship_to,omitempty"`    // required by the closest rule
 	}
 
 	params, err := utils.ParseRequestBody[parameters](r)
@@ -86,11 +89,20 @@ func (oh *OrderHandler) SaveOrder(r *http.Request, w http.ResponseWriter) {
 		Currency:          params.Currency,
 		PayStatus:         params.PayStatus,
 		PayStatusDetail:   params.PayStatusDetail,
+		Allocation:        params.Allocation,
+		ShipTo:            params.ShipTo,
 	}
 
 	result, err := oh.srv.SaveOrder(r.Context(), inputs)
 	if err != nil {
-		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error saving order: %s", err))
+		switch err {
+		case domain.ErrInvalidAllocationRule, domain.ErrAllocationLocationIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		case domain.ErrProductInsufficientStock, domain.ErrVariantInsufficientStock:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error saving order: %s", err))
+		}
 		return
 	}
 
@@ -152,3 +164,137 @@ func (oh *OrderHandler) GetAllOrders(r *http.Request, w http.ResponseWriter) {
 
 	httpdtos.RespondJSON(w, http.StatusOK, "Orders retrieved successfully", orders)
 }
+
+func (oh *OrderHandler) CancelOrder(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		Reason string `json:"reason"`
+	}
+
+	// Verify HTTP method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// the user that cancels the order is loaded by the authentication middleware
+	actor, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	orderID := chi.URLParam(r, "order_id")
+	parsedOrderId, err := uuid.Parse(orderID)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid OrderID: %s", err))
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	if params.Reason == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "Reason is required")
+		return
+	}
+
+	order, err := oh.srv.CancelOrder(r.Context(), parsedOrderId, actor, params.Reason)
+	if err != nil {
+		switch err {
+		case domain.ErrOrderNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrOrderCancelNotAllowed:
+			httpdtos.RespondError(w, http.StatusForbidden, err.Error())
+		case domain.ErrOrderCannotBeCancelled:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrCancelReasonIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error cancelling order: %s", err))
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Order successfully cancelled", order)
+}
+
+func (oh *OrderHandler) ShipOrder(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		Carrier        string  `json:"carrier"`
+		TrackingNumber *string `json:"tracking_number,omitempty"`
+	}
+
+	// Verify HTTP method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// the user that ships the order is loaded by the authentication middleware
+	actor, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	parsedOrderId, err := uuid.Parse(chi.URLParam(r, "order_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid OrderID: %s", err))
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	order, err := oh.srv.ShipOrder(r.Context(), parsedOrderId, actor, domain.ShipOrderInputs{
+		Carrier:        params.Carrier,
+		TrackingNumber: params.TrackingNumber,
+	})
+	if err != nil {
+		switch err {
+		case domain.ErrOrderNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrOrderNotPaid, domain.ErrOrderAlreadyShipped:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrCarrierIsRequire:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error shipping order: %s", err))
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Order successfully shipped", order)
+}
+
+func (oh *OrderHandler) ListPaymentAttempts(r *http.Request, w http.ResponseWriter) {
+	// Verify HTTP method
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	parsedOrderId, err := uuid.Parse(chi.URLParam(r, "order_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid OrderID: %s", err))
+		return
+	}
+
+	attempts, err := oh.srv.ListPaymentAttempts(r.Context(), parsedOrderId)
+	if err != nil {
+		if err == domain.ErrOrderNotFound {
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error retrieving payments of order: %s", err))
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Payments of order successfully obtained", attempts)
+}
@@ -4,9 +4,13 @@ import (
 	"fmt"
 	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
 	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 )
 
@@ -77,3 +81,61 @@ func (ph *PaymentHandler) NotificationWebhook(r *http.Request, w http.ResponseWr
 
 	httpdtos.RespondJSON(w, http.StatusOK, "Order successfully updated with payment data", nil)
 }
+
+func (ph *PaymentHandler) Capture(r *http.Request, w http.ResponseWriter) {
+	// Verify HTTP method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	orderId, err := uuid.Parse(chi.URLParam(r, "order_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Order id must be a valid uuid: %s", err))
+		return
+	}
+
+	order, err := ph.srv.Capture(r.Context(), orderId)
+	if err != nil {
+		switch err {
+		case domain.ErrOrderNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrOrderNotAuthorized, domain.ErrAuthorizationExpired:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusBadGateway, fmt.Sprintf("Error capturing payment: %s", err))
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Payment successfully captured", order)
+}
+
+// default age of the orders reconciled on demand
+const defaultReconcileDays = 7
+
+func (ph *PaymentHandler) Reconcile(r *http.Request, w http.ResponseWriter) {
+	// Verify HTTP method
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days := defaultReconcileDays
+	if d := r.URL.Query().Get("days"); d != "" {
+		parsed, err := strconv.Atoi(d)
+		if err != nil || parsed <= 0 {
+			httpdtos.RespondError(w, http.StatusBadRequest, "days must be a positive number")
+			return
+		}
+		days = parsed
+	}
+
+	report, err := ph.srv.Reconcile(r.Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error reconciling payments: %s", err))
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "Payments successfully reconciled", report)
+}
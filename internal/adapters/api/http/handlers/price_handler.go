@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"net/http"
+	"time"
+)
+
+type PriceHandler struct {
+	srv ports.PriceService
+}
+
+func NewPriceHandler(srv ports.PriceService) *PriceHandler {
+	return &PriceHandler{srv: srv}
+}
+
+// helper func, maps the errors of the price service to the status of the response
+func respondPriceError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrProductNotFound, domain.ErrPriceScheduleNotFound:
+		httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+	case domain.ErrPriceScheduleFinished:
+		httpdtos.RespondError(w, http.StatusConflict, err.Error())
+	case domain.ErrPriceScheduleIsEmpty, domain.ErrPriceScheduleInvalidDates, domain.ErrProductPriceIsRequire,
+		domain.ErrInvalidDiscount, domain.ErrInvalidDiscountType:
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (ph *PriceHandler) PriceHistory(r *http.Request, w http.ResponseWriter) {
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := ph.srv.PriceHistory(r.Context(), productId)
+	if err != nil {
+		respondPriceError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "price history successfully retrieved", history)
+}
+
+func (ph *PriceHandler) SchedulePrice(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		Price        *float64   `json:"price"`
+		Discount     *float64   `json:"discount"`
+		DiscountType *string    `json:"discount_type"`
+		StartsAt     *time.Time `json:"starts_at"`
+		EndsAt       *time.Time `json:"ends_at"` // optional, makes the schedule a time-boxed sale
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	if params.StartsAt == nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, "starts_at is required")
+		return
+	}
+
+	inputs := ports_dtos.SchedulePriceInputs{
+		Price:        params.Price,
+		Discount:     params.Discount,
+		DiscountType: params.DiscountType,
+		StartsAt:     *params.StartsAt,
+		EndsAt:       params.EndsAt,
+	}
+	if user, ok := middlewares.UserFromContext(r.Context()); ok {
+		inputs.CreatedBy = &user.ID
+	}
+
+	schedule, err := ph.srv.SchedulePrice(r.Context(), productId, inputs)
+	if err != nil {
+		respondPriceError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusCreated, "price change successfully scheduled", schedule)
+}
+
+func (ph *PriceHandler) ListSchedules(r *http.Request, w http.ResponseWriter) {
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	schedules, err := ph.srv.ListSchedules(r.Context(), productId)
+	if err != nil {
+		respondPriceError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "price schedules successfully retrieved", schedules)
+}
+
+func (ph *PriceHandler) CancelSchedule(r *http.Request, w http.ResponseWriter) {
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	scheduleId, err := parseUUIDParam(r, "schedule_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	schedule, err := ph.srv.CancelSchedule(r.Context(), productId, scheduleId)
+	if err != nil {
+		respondPriceError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "price schedule successfully cancelled", schedule)
+}
@@ -3,11 +3,15 @@ package handlers
 import (
 	"fmt"
 	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
 	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/adapters/shared"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
 	"go-ecommerce/internal/core/ports/ports_dtos"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -29,6 +33,11 @@ func (ph *ProductHandler) SaveProduct(r *http.Request, w http.ResponseWriter) {
 		Price      *float64 `json:"price"`
 		Stock      *int64   `json:"stock"`
 		CategoryID *uint64  `json:"category_id"`
+
+		Discount     *float64 `json:"discount"`
+		DiscountType *string  `json:"discount_type"`
+
+		LowStockThreshold *int64 `json:"low_stock_threshold"` // 0 disables the low stock alert
 	}
 
 	if r.Method != http.MethodPost && r.Method != http.MethodPut {
@@ -93,11 +102,36 @@ func (ph *ProductHandler) SaveProduct(r *http.Request, w http.ResponseWriter) {
 		Price:      params.Price,
 		Stock:      params.Stock,
 		CategoryID: params.CategoryID,
+
+		Discount:     params.Discount,
+		DiscountType: params.DiscountType,
+
+		LowStockThreshold: params.LowStockThreshold,
+	}
+
+	// the price history records who made the change when the user is known
+	if user, ok := middlewares.UserFromContext(r.Context()); ok {
+		inputs.UpdatedBy = &user.ID
 	}
 
 	product, err := ph.srv.SaveProduct(r.Context(), inputs)
 	if err != nil {
-		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		switch err {
+		case domain.ErrProductNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case shared.ErrConflictingData:
+			httpdtos.RespondError(w, http.StatusConflict, "a product with the same sku already exists")
+		case domain.ErrProductInsufficientStock, domain.ErrProductSKUArchived:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrProductNameIsRequire, domain.ErrProductMinLenghtName, domain.ErrProductSKUIsRequire,
+			domain.ErrProductMinLenghtSKU, domain.ErrProductPriceIsRequire, domain.ErrProductStockIsRequire,
+			domain.ErrProductImageIsRequire, domain.ErrProductCategoryIsRequire, domain.ErrInvalidDiscount,
+			domain.ErrInvalidDiscountType, domain.ErrDiscountTypeIsRequire, domain.ErrPercentageDiscountTooHigh,
+			domain.ErrFixedDiscountTooHigh, domain.ErrProductLowStockThreshold, domain.ErrProductStockNotEditable:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
 		return
 	}
 
@@ -146,16 +180,37 @@ func (ph *ProductHandler) FindProductById(r *http.Request, w http.ResponseWriter
 	httpdtos.RespondJSON(w, http.StatusOK, "product successfully retrieved", prod)
 }
 
-func (ph *ProductHandler) ListProducts(r *http.Request, w http.ResponseWriter) {
-	if r.Method != http.MethodGet {
-		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+// helper func, parses the storefront filters from the query string
+func (ph *ProductHandler) FindProductBySlug(r *http.Request, w http.ResponseWriter) {
+	slug := chi.URLParam(r, "slug")
+	if slug == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "slug is required")
+		return
+	}
+
+	prod, err := ph.srv.GetProductBySlug(r.Context(), slug)
+	if err != nil {
+		if err == domain.ErrProductNotFound {
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "product successfully retrieved", prod)
+}
+
+func (ph *ProductHandler) FindProductBySKU(r *http.Request, w http.ResponseWriter) {
+	sku := chi.URLParam(r, "sku")
+	if sku == "" {
+		httpdtos.RespondError(w, http.StatusBadRequest, "sku is required")
 		return
 	}
 
-	// find all category calling service
-	prods, err := ph.srv.ListProducts(r.Context())
+	prod, err := ph.srv.GetProductBySKU(r.Context(), sku)
 	if err != nil {
-		if err == domain.ErrProductNotFound || len(prods) <= 0 {
+		if err == domain.ErrProductNotFound {
 			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
 			return
 		}
@@ -163,7 +218,84 @@ func (ph *ProductHandler) ListProducts(r *http.Request, w http.ResponseWriter) {
 		return
 	}
 
-	httpdtos.RespondJSON(w, http.StatusOK, "products successfully retrieved", prods)
+	httpdtos.RespondJSON(w, http.StatusOK, "product successfully retrieved", prod)
+}
+
+func parseProductQuery(r *http.Request) (ports_dtos.ProductQuery, error) {
+	values := r.URL.Query()
+	query := ports_dtos.ProductQuery{
+		Q:      strings.TrimSpace(values.Get("q")),
+		Sort:   ports_dtos.ProductSort(values.Get("sort")),
+		Cursor: values.Get("cursor"),
+	}
+
+	if v := values.Get("category_id"); v != "" {
+		categoryId, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return query, fmt.Errorf("category_id must be a number")
+		}
+		query.CategoryID = &categoryId
+	}
+
+	if v := values.Get("min_price"); v != "" {
+		minPrice, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return query, fmt.Errorf("min_price must be a number")
+		}
+		query.MinPrice = &minPrice
+	}
+
+	if v := values.Get("max_price"); v != "" {
+		maxPrice, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return query, fmt.Errorf("max_price must be a number")
+		}
+		query.MaxPrice = &maxPrice
+	}
+
+	if v := values.Get("in_stock"); v != "" {
+		inStock, err := strconv.ParseBool(v)
+		if err != nil {
+			return query, fmt.Errorf("in_stock must be true or false")
+		}
+		query.InStock = &inStock
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return query, fmt.Errorf("limit must be a number")
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
+func (ph *ProductHandler) ListProducts(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	query, err := parseProductQuery(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := ph.srv.SearchProducts(r.Context(), query)
+	if err != nil {
+		switch err {
+		case domain.ErrInvalidProductSort, domain.ErrInvalidPriceRange, domain.ErrInvalidSearchCursor:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "products successfully retrieved", page)
 }
 
 func (ph *ProductHandler) DeleteProduct(r *http.Request, w http.ResponseWriter) {
@@ -198,3 +330,169 @@ func (ph *ProductHandler) DeleteProduct(r *http.Request, w http.ResponseWriter)
 
 	httpdtos.RespondJSON(w, http.StatusOK, "product successfully deleted", nil)
 }
+
+// helper func, parses an uuid url param
+func parseUUIDParam(r *http.Request, name string) (uuid.UUID, error) {
+	raw := chi.URLParam(r, name)
+	if raw == "" {
+		return uuid.Nil, fmt.Errorf("%s is required", name)
+	}
+
+	parsed, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%s must be a valid uuid", name)
+	}
+	return parsed, nil
+}
+
+func (ph *ProductHandler) SaveVariant(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		SKU     *string           `json:"sku"`
+		Options map[string]string `json:"options"`
+		Price   *float64          `json:"price"`
+		Stock   *int64            `json:"stock"`
+	}
+
+	if r.Method != http.MethodPost && r.Method != http.MethodPut {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the variant id only exists on updates
+	variantId := uuid.Nil
+	if chi.URLParam(r, "variant_id") != "" {
+		variantId, err = parseUUIDParam(r, "variant_id")
+		if err != nil {
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	inputs := ports_dtos.SaveVariantInputs{
+		ID:      variantId,
+		SKU:     params.SKU,
+		Options: params.Options,
+		Price:   params.Price,
+		Stock:   params.Stock,
+	}
+
+	// the stock ledger records who changed the stock when the user is known
+	if user, ok := middlewares.UserFromContext(r.Context()); ok {
+		inputs.UpdatedBy = &user.ID
+	}
+
+	variant, err := ph.srv.SaveVariant(r.Context(), productId, inputs)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound, domain.ErrVariantNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case shared.ErrConflictingData:
+			httpdtos.RespondError(w, http.StatusConflict, "a variant with the same sku already exists")
+		case domain.ErrVariantInsufficientStock:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		case domain.ErrVariantSKUIsRequire, domain.ErrVariantMinLenghtSKU, domain.ErrVariantOptionsIsRequire,
+			domain.ErrVariantInvalidPrice, domain.ErrVariantNegativeStock, domain.ErrVariantStockNotEditable:
+			httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	if variantId != uuid.Nil {
+		httpdtos.RespondJSON(w, http.StatusOK, "variant successfully updated", variant)
+		return
+	}
+	httpdtos.RespondJSON(w, http.StatusCreated, "variant successfully created", variant)
+}
+
+func (ph *ProductHandler) ListVariants(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variants, err := ph.srv.ListVariants(r.Context(), productId)
+	if err != nil {
+		if err == domain.ErrProductNotFound {
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "variants successfully retrieved", variants)
+}
+
+func (ph *ProductHandler) DeleteVariant(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodDelete {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	variantId, err := parseUUIDParam(r, "variant_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = ph.srv.DeleteVariant(r.Context(), productId, variantId)
+	if err != nil {
+		if err == domain.ErrProductNotFound || err == domain.ErrVariantNotFound {
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "variant successfully deleted", nil)
+}
+
+func (ph *ProductHandler) RestoreProduct(r *http.Request, w http.ResponseWriter) {
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	product, err := ph.srv.RestoreProduct(r.Context(), productId)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrProductNotArchived, domain.ErrProductCategoryArchived:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "product successfully restored", product)
+}
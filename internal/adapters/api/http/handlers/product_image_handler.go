@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// imageFormField is the multipart field that contains the uploaded file
+const imageFormField = "image"
+
+type ProductImageHandler struct {
+	srv ports.ProductImageService
+}
+
+func NewProductImageHandler(srv ports.ProductImageService) *ProductImageHandler {
+	return &ProductImageHandler{srv: srv}
+}
+
+// helper func, maps the errors of the gallery to http status
+func respondImageError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrProductNotFound, domain.ErrProductImageNotFound:
+		httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+	case domain.ErrProductImageEmpty, domain.ErrProductImageInvalidType, domain.ErrProductImageInvalidOrder:
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+	case domain.ErrProductImageTooLarge, domain.ErrProductImageTooManyPixels:
+		httpdtos.RespondError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case domain.ErrProductImageLimit:
+		httpdtos.RespondError(w, http.StatusConflict, err.Error())
+	default:
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (ih *ProductImageHandler) UploadImage(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodPost {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the body is limited to the max image size plus some room for the multipart headers
+	r.Body = http.MaxBytesReader(w, r.Body, domain.MaxProductImageSize+1<<20)
+	err = r.ParseMultipartForm(domain.MaxProductImageSize)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondImageError(w, domain.ErrProductImageTooLarge)
+			return
+		}
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("invalid multipart form: %s", err))
+		return
+	}
+
+	file, _, err := r.FormFile(imageFormField)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("the %q file is required", imageFormField))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, domain.MaxProductImageSize+1))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// the type is detected from the content, the header sent by the client isn't trusted
+	contentType := http.DetectContentType(data)
+
+	image, err := ih.srv.UploadImage(r.Context(), productId, data, contentType)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusCreated, "image successfully uploaded", image)
+}
+
+func (ih *ProductImageHandler) ListImages(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	images, err := ih.srv.ListImages(r.Context(), productId)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "images successfully retrieved", images)
+}
+
+func (ih *ProductImageHandler) DeleteImage(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodDelete {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	imageId, err := parseUUIDParam(r, "image_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = ih.srv.DeleteImage(r.Context(), productId, imageId)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "image successfully deleted", nil)
+}
+
+func (ih *ProductImageHandler) ReorderImages(r *http.Request, w http.ResponseWriter) {
+	type parameters struct {
+		ImageIDs []uuid.UUID `json:"image_ids"`
+	}
+
+	if r.Method != http.MethodPut {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params, err := utils.ParseRequestBody[parameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	images, err := ih.srv.ReorderImages(r.Context(), productId, params.ImageIDs)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "images successfully reordered", images)
+}
+
+func (ih *ProductImageHandler) SetPrimaryImage(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodPut {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	imageId, err := parseUUIDParam(r, "image_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	images, err := ih.srv.SetPrimaryImage(r.Context(), productId, imageId)
+	if err != nil {
+		respondImageError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "primary image successfully updated", images)
+}
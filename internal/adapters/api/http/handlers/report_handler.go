@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// format used by from and to query params
+const reportDateLayout = "2006-01-02"
+
+// amount of days covered by a report when the range is not sent
+const defaultReportDays = 30
+
+type ReportHandler struct {
+	srv ports.ReportService
+}
+
+func NewReportHandler(srv ports.ReportService) *ReportHandler {
+	return &ReportHandler{srv: srv}
+}
+
+// helper func, parses from and to query params. "to" is inclusive, so the range ends at the start of the next day
+func parseReportFilters(r *http.Request) (ports_dtos.ReportFilters, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	from := to.AddDate(0, 0, -defaultReportDays)
+
+	if fromStr := r.URL.Query().Get("from"); fromStr != "" {
+		parsed, err := time.Parse(reportDateLayout, fromStr)
+		if err != nil {
+			return ports_dtos.ReportFilters{}, fmt.Errorf("from must have the format YYYY-MM-DD: %w", err)
+		}
+		from = parsed
+	}
+
+	if toStr := r.URL.Query().Get("to"); toStr != "" {
+		parsed, err := time.Parse(reportDateLayout, toStr)
+		if err != nil {
+			return ports_dtos.ReportFilters{}, fmt.Errorf("to must have the format YYYY-MM-DD: %w", err)
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	return ports_dtos.ReportFilters{From: from, To: to}, nil
+}
+
+// helper func
+func wantsCSV(r *http.Request) bool {
+	return r.URL.Query().Get("format") == "csv"
+}
+
+// helper func
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// helper func
+func respondReportError(w http.ResponseWriter, err error) {
+	if err == domain.ErrInvalidReportPeriod || err == domain.ErrInvalidReportRange || err == domain.ErrInvalidReportRanking {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	httpdtos.RespondError(w, http.StatusInternalServerError, fmt.Sprintf("Error generating report: %s", err))
+}
+
+func (rh *ReportHandler) Revenue(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	period := domain.Daily
+	if p := r.URL.Query().Get("period"); p != "" {
+		period = domain.ReportPeriod(p)
+	}
+
+	buckets, err := rh.srv.Revenue(r.Context(), filters, period)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(buckets))
+		for i, b := range buckets {
+			rows[i] = []string{
+				b.Period.Format(reportDateLayout),
+				strconv.FormatInt(b.Orders, 10),
+				formatAmount(b.Gross),
+				formatAmount(b.Fees),
+				formatAmount(b.Net),
+			}
+		}
+		httpdtos.RespondCSV(w, http.StatusOK, "revenue.csv", []string{"period", "orders", "gross", "fees", "net"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "revenue report successfully generated", buckets)
+}
+
+func (rh *ReportHandler) FeesByPayMethod(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fees, err := rh.srv.FeesByPayMethod(r.Context(), filters)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(fees))
+		for i, f := range fees {
+			rows[i] = []string{
+				f.PayMethod,
+				strconv.FormatInt(f.Orders, 10),
+				formatAmount(f.Gross),
+				formatAmount(f.Fees),
+			}
+		}
+		httpdtos.RespondCSV(w, http.StatusOK, "fees.csv", []string{"pay_method", "orders", "gross", "fees"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "fees report successfully generated", fees)
+}
+
+func (rh *ReportHandler) AverageOrderValue(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	aov, err := rh.srv.AverageOrderValue(r.Context(), filters)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := [][]string{{
+			strconv.FormatInt(aov.Orders, 10),
+			formatAmount(aov.Gross),
+			formatAmount(aov.Average),
+		}}
+		httpdtos.RespondCSV(w, http.StatusOK, "average_order_value.csv", []string{"orders", "gross", "average"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "average order value successfully generated", aov)
+}
+
+func (rh *ReportHandler) TopProducts(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rankBy := domain.ByUnits
+	if by := r.URL.Query().Get("by"); by != "" {
+		rankBy = domain.TopProductsRanking(by)
+	}
+
+	limit := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	products, err := rh.srv.TopProducts(r.Context(), filters, rankBy, limit)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, len(products))
+		for i, p := range products {
+			rows[i] = []string{
+				p.ProductID.String(),
+				p.Name,
+				p.SKU,
+				strconv.FormatInt(p.Units, 10),
+				formatAmount(p.Revenue),
+			}
+		}
+		httpdtos.RespondCSV(w, http.StatusOK, "top_products.csv", []string{"product_id", "name", "sku", "units", "revenue"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "top products successfully generated", products)
+}
+
+func (rh *ReportHandler) Conversion(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	conversion, err := rh.srv.Conversion(r.Context(), filters)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := [][]string{{
+			strconv.FormatInt(conversion.Created, 10),
+			strconv.FormatInt(conversion.Pending, 10),
+			strconv.FormatInt(conversion.Approved, 10),
+			strconv.FormatFloat(conversion.Rate, 'f', 4, 64),
+		}}
+		httpdtos.RespondCSV(w, http.StatusOK, "conversion.csv", []string{"created", "pending", "approved", "rate"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "conversion successfully generated", conversion)
+}
+
+func (rh *ReportHandler) Chargebacks(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := rh.srv.Chargebacks(r.Context(), filters)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := [][]string{{
+			strconv.FormatInt(summary.Count, 10),
+			formatAmount(summary.Amount),
+			strconv.FormatInt(summary.Open, 10),
+			strconv.FormatInt(summary.Won, 10),
+			strconv.FormatInt(summary.Lost, 10),
+			formatAmount(summary.LostAmount),
+		}}
+		httpdtos.RespondCSV(w, http.StatusOK, "chargebacks.csv", []string{"count", "amount", "open", "won", "lost", "lost_amount"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "chargebacks successfully generated", summary)
+}
+
+func (rh *ReportHandler) CartRecovery(r *http.Request, w http.ResponseWriter) {
+	if r.Method != http.MethodGet {
+		httpdtos.RespondError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	filters, err := parseReportFilters(r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	summary, err := rh.srv.CartRecovery(r.Context(), filters)
+	if err != nil {
+		respondReportError(w, err)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := [][]string{{
+			strconv.FormatInt(summary.Reminded, 10),
+			strconv.FormatInt(summary.Restored, 10),
+			strconv.FormatInt(summary.Recovered, 10),
+			formatAmount(summary.RecoveredRevenue),
+			strconv.FormatFloat(summary.Rate, 'f', 4, 64),
+		}}
+		httpdtos.RespondCSV(w, http.StatusOK, "cart_recovery.csv", []string{"reminded", "restored", "recovered", "recovered_revenue", "rate"}, rows)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "cart recovery successfully generated", summary)
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/middlewares"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"net/http"
+)
+
+type StockAlertHandler struct {
+	srv ports.StockAlertService
+}
+
+func NewStockAlertHandler(srv ports.StockAlertService) *StockAlertHandler {
+	return &StockAlertHandler{srv: srv}
+}
+
+// Subscribe asks for a back in stock alert of the product for the authenticated user
+func (sh *StockAlertHandler) Subscribe(r *http.Request, w http.ResponseWriter) {
+	user, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	subscription, err := sh.srv.Subscribe(r.Context(), productId, user.ID)
+	if err != nil {
+		switch err {
+		case domain.ErrProductNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrStockAlertProductInStock:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusCreated, "back in stock alert successfully requested", subscription)
+}
+
+func (sh *StockAlertHandler) Unsubscribe(r *http.Request, w http.ResponseWriter) {
+	user, ok := middlewares.UserFromContext(r.Context())
+	if !ok {
+		httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	productId, err := parseUUIDParam(r, "product_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = sh.srv.Unsubscribe(r.Context(), productId, user.ID)
+	if err != nil {
+		switch err {
+		case domain.ErrStockAlertNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "back in stock alert successfully cancelled", nil)
+}
@@ -33,7 +33,7 @@ func Test_UserHandler_Register(t *testing.T) {
 	}
 
 	r := chi.NewRouter()
-	handler := handlers.NewUserHandler(mockUserService)
+	handler := handlers.NewUserHandler(mockUserService, nil)
 	routes.LoadUserRoutes(r, handler)
 
 	reqBody := `{"name":"John", "email":"john@mail.test", "password":"password"}`
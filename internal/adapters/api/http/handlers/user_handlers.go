@@ -6,6 +6,7 @@ import (
 	"go-ecommerce/internal/adapters/api/http/utils"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
+	"log/slog"
 	"net/http"
 	"strconv"
 
@@ -14,11 +15,12 @@ import (
 )
 
 type UserHandler struct {
-	srv ports.UserService
+	srv        ports.UserService
+	guestCarts ports.GuestCartService
 }
 
-func NewUserHandler(us ports.UserService) *UserHandler {
-	return &UserHandler{srv: us}
+func NewUserHandler(us ports.UserService, guestCarts ports.GuestCartService) *UserHandler {
+	return &UserHandler{srv: us, guestCarts: guestCarts}
 }
 
 func (uh *UserHandler) SaveUser(r *http.Request, w http.ResponseWriter) {
@@ -27,6 +29,7 @@ func (uh *UserHandler) SaveUser(r *http.Request, w http.ResponseWriter) {
 		Email    *string `json:"email"`
 		Password *string `json:"password"`
 		Role     *string `json:"role,omitempty"`
+		Locale   *string `json:"locale,omitempty"` // language of the notifications, es or en
 	}
 
 	if r.Method != http.MethodPost && r.Method != http.MethodPut {
@@ -88,6 +91,7 @@ func (uh *UserHandler) SaveUser(r *http.Request, w http.ResponseWriter) {
 		Email:    params.Email,
 		Password: params.Password,
 		Role:     &role,
+		Locale:   params.Locale,
 	}
 
 	user, err := uh.srv.SaveUser(r.Context(), inputs)
@@ -96,6 +100,15 @@ func (uh *UserHandler) SaveUser(r *http.Request, w http.ResponseWriter) {
 		return
 	}
 
+	// the cart of the guest that registers becomes the cart of the new user
+	if token := guestCartToken(r); id == uuid.Nil && token != "" {
+		if _, err := uh.guestCarts.Merge(r.Context(), token, user.ID); err != nil {
+			slog.Warn("error merging guest cart of new user", "user_id", user.ID, "error", err)
+		} else {
+			clearGuestCartToken(w)
+		}
+	}
+
 	httpdtos.RespondJSON(w, http.StatusCreated, "User successfully saved", user)
 }
 
@@ -234,3 +247,26 @@ func (uh *UserHandler) DeleteUser(r *http.Request, w http.ResponseWriter) {
 
 	httpdtos.RespondJSON(w, http.StatusOK, "user successfully deleted", nil)
 }
+
+func (uh *UserHandler) RestoreUser(r *http.Request, w http.ResponseWriter) {
+	userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("user id must be a valid uuid: %s", err))
+		return
+	}
+
+	user, err := uh.srv.RestoreUser(r.Context(), userId)
+	if err != nil {
+		switch err {
+		case domain.ErrUserNotFound:
+			httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+		case domain.ErrUserNotArchived:
+			httpdtos.RespondError(w, http.StatusConflict, err.Error())
+		default:
+			httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "user successfully restored", user)
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"fmt"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/adapters/api/http/utils"
+	"go-ecommerce/internal/adapters/shared"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"net/http"
+)
+
+type WarehouseHandler struct {
+	srv ports.WarehouseService
+}
+
+func NewWarehouseHandler(srv ports.WarehouseService) *WarehouseHandler {
+	return &WarehouseHandler{srv: srv}
+}
+
+type warehouseParameters struct {
+	Code      *string  `json:"code"`
+	Name      *string  `json:"name"`
+	Latitude  *float64 `json:"latitude"` // latitude and longitude are set together
+	Longitude *float64 `json:"longitude"`
+	IsDefault *bool    `json:"is_default"`
+	Active    *bool    `json:"active"`
+}
+
+// helper func, maps the errors of the warehouse service to the status of the response
+func respondWarehouseError(w http.ResponseWriter, err error) {
+	switch err {
+	case domain.ErrWarehouseNotFound:
+		httpdtos.RespondError(w, http.StatusNotFound, err.Error())
+	case shared.ErrConflictingData:
+		httpdtos.RespondError(w, http.StatusConflict, "a warehouse with this code already exists")
+	case domain.ErrWarehouseCodeIsRequire, domain.ErrWarehouseNameIsRequire, domain.ErrWarehouseInvalidLocation,
+		domain.ErrWarehouseDefaultInactive, domain.ErrWarehouseDefaultIsRequire:
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+	default:
+		httpdtos.RespondError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (wh *WarehouseHandler) SaveWarehouse(r *http.Request, w http.ResponseWriter) {
+	params, err := utils.ParseRequestBody[warehouseParameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	warehouse, err := wh.srv.SaveWarehouse(r.Context(), ports_dtos.SaveWarehouseInputs{
+		Code:      params.Code,
+		Name:      params.Name,
+		Latitude:  params.Latitude,
+		Longitude: params.Longitude,
+		IsDefault: params.IsDefault,
+		Active:    params.Active,
+	})
+	if err != nil {
+		respondWarehouseError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusCreated, "warehouse successfully created", warehouse)
+}
+
+func (wh *WarehouseHandler) UpdateWarehouse(r *http.Request, w http.ResponseWriter) {
+	warehouseId, err := parseUUIDParam(r, "warehouse_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params, err := utils.ParseRequestBody[warehouseParameters](r)
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, fmt.Sprintf("Invalid input: %s", err))
+		return
+	}
+
+	warehouse, err := wh.srv.SaveWarehouse(r.Context(), ports_dtos.SaveWarehouseInputs{
+		ID:        warehouseId,
+		Code:      params.Code,
+		Name:      params.Name,
+		Latitude:  params.Latitude,
+		Longitude: params.Longitude,
+		IsDefault: params.IsDefault,
+		Active:    params.Active,
+	})
+	if err != nil {
+		respondWarehouseError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "warehouse successfully updated", warehouse)
+}
+
+func (wh *WarehouseHandler) GetWarehouseById(r *http.Request, w http.ResponseWriter) {
+	warehouseId, err := parseUUIDParam(r, "warehouse_id")
+	if err != nil {
+		httpdtos.RespondError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	warehouse, err := wh.srv.GetWarehouseById(r.Context(), warehouseId)
+	if err != nil {
+		respondWarehouseError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "warehouse successfully retrieved", warehouse)
+}
+
+func (wh *WarehouseHandler) ListWarehouses(r *http.Request, w http.ResponseWriter) {
+	warehouses, err := wh.srv.ListWarehouses(r.Context())
+	if err != nil {
+		respondWarehouseError(w, err)
+		return
+	}
+
+	httpdtos.RespondJSON(w, http.StatusOK, "warehouses successfully retrieved", warehouses)
+}
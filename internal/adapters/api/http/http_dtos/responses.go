@@ -1,7 +1,9 @@
 package httpdtos
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
 )
@@ -64,3 +66,19 @@ func RespondError(w http.ResponseWriter, code int, msg string) {
 		slog.Error("Error writing JSON response", "error", err)
 	}
 }
+
+func RespondCSV(w http.ResponseWriter, code int, filename string, header []string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(code)
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		slog.Error("Error writing CSV header", "error", err)
+		return
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		slog.Error("Error writing CSV response", "error", err)
+	}
+}
@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"context"
+	httpdtos "go-ecommerce/internal/adapters/api/http/http_dtos"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+// helper func, returns the access token sent as "Authorization: Bearer <token>", empty if the request doesn't have one
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// Authenticate verifies the access token of the Authorization header and stores its user in the request context
+func Authenticate(auth ports.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r)
+			if token == "" {
+				httpdtos.RespondError(w, http.StatusUnauthorized, "a bearer token is required")
+				return
+			}
+
+			user, err := auth.Authenticate(r.Context(), token)
+			if err != nil {
+				switch err {
+				case domain.ErrInvalidToken, domain.ErrTokenExpired:
+					httpdtos.RespondError(w, http.StatusUnauthorized, err.Error())
+				default:
+					httpdtos.RespondError(w, http.StatusInternalServerError, "error authenticating user")
+				}
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// IdentifyUser stores the user of the access token in the request context when it's valid, unlike Authenticate
+// anonymous requests are allowed. Used by public routes that record who performs the changes
+func IdentifyUser(auth ports.AuthService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if token := bearerToken(r); token != "" {
+				if user, err := auth.Authenticate(r.Context(), token); err == nil {
+					r = r.WithContext(context.WithValue(r.Context(), userContextKey, user))
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRole allows the request only if the authenticated user has one of the given roles.
+// Must be used after Authenticate
+func RequireRole(roles ...domain.UserRole) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := UserFromContext(r.Context())
+			if !ok {
+				httpdtos.RespondError(w, http.StatusUnauthorized, "user not authenticated")
+				return
+			}
+
+			if !slices.Contains(roles, user.Role) {
+				httpdtos.RespondError(w, http.StatusForbidden, "user doesn't have permissions to perform this action")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// UserFromContext returns the user stored by Authenticate
+func UserFromContext(ctx context.Context) (*domain.User, bool) {
+	user, ok := ctx.Value(userContextKey).(*domain.User)
+	return user, ok && user != nil
+}
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LoadAdminArchiveRoutes loads the endpoints that restore archived (soft deleted) rows
+func LoadAdminArchiveRoutes(r chi.Router, ph *handlers.ProductHandler, ch *handlers.CategoryHandler, uh *handlers.UserHandler) {
+	r.Post("/admin/product/{product_id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		ph.RestoreProduct(r, w)
+	})
+	r.Post("/admin/category/{category_id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		ch.RestoreCategory(r, w)
+	})
+	r.Post("/admin/user/{user_id}/restore", func(w http.ResponseWriter, r *http.Request) {
+		uh.RestoreUser(r, w)
+	})
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadAdminOrderRoutes(r chi.Router, oh *handlers.OrderHandler, ph *handlers.PaymentHandler) {
+	r.Route("/admin/order", func(r chi.Router) {
+		r.Post("/reconcile", func(w http.ResponseWriter, r *http.Request) {
+			ph.Reconcile(r, w)
+		})
+		r.Post("/{order_id}/capture", func(w http.ResponseWriter, r *http.Request) {
+			ph.Capture(r, w)
+		})
+		// voiding an authorization cancels the order, the cancellation rules allow it only to admins
+		r.Post("/{order_id}/void", func(w http.ResponseWriter, r *http.Request) {
+			oh.CancelOrder(r, w)
+		})
+		r.Post("/{order_id}/ship", func(w http.ResponseWriter, r *http.Request) {
+			oh.ShipOrder(r, w)
+		})
+	})
+}
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadAuthRoutes(r chi.Router, h *handlers.AuthHandler) {
+	r.Route("/auth", func(r chi.Router) {
+		r.Post("/login", func(w http.ResponseWriter, r *http.Request) {
+			h.Login(r, w)
+		})
+	})
+}
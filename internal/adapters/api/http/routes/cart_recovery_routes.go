@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadCartRecoveryRoutes(r chi.Router, h *handlers.CartRecoveryHandler) {
+	r.Get("/cart/recover", func(w http.ResponseWriter, r *http.Request) {
+		h.RestoreCart(r, w)
+	})
+}
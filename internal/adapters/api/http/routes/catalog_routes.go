@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadCatalogRoutes(r chi.Router, h *handlers.CatalogHandler) {
+	r.Route("/product/import", func(r chi.Router) {
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			h.ImportProducts(r, w)
+		})
+		r.Get("/{job_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.GetImportJob(r, w)
+		})
+	})
+	r.Get("/product/export", func(w http.ResponseWriter, r *http.Request) {
+		h.ExportProducts(r, w)
+	})
+}
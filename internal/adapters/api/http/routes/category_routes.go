@@ -15,9 +15,15 @@ func LoadCategoryRoutes(r chi.Router, h *handlers.CategoryHandler) {
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			h.ListCategories(r, w)
 		})
+		r.Get("/tree", func(w http.ResponseWriter, r *http.Request) {
+			h.CategoryTree(r, w)
+		})
 		r.Get("/{category_id}", func(w http.ResponseWriter, r *http.Request) {
 			h.FindCategoryById(r, w)
 		})
+		r.Put("/{category_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.SaveCategory(r, w)
+		})
 		r.Delete("/{category_id}", func(w http.ResponseWriter, r *http.Request) {
 			h.DeleteCategory(r, w)
 		})
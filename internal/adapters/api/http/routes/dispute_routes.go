@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadDisputeRoutes(r chi.Router, h *handlers.DisputeHandler) {
+	r.Route("/admin/dispute", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			h.ListDisputes(r, w)
+		})
+		r.Get("/{dispute_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.GetDisputeById(r, w)
+		})
+		r.Post("/{dispute_id}/evidence", func(w http.ResponseWriter, r *http.Request) {
+			h.AddEvidence(r, w)
+		})
+		r.Post("/{dispute_id}/outcome", func(w http.ResponseWriter, r *http.Request) {
+			h.Resolve(r, w)
+		})
+	})
+}
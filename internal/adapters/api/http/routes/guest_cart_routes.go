@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadGuestCartRoutes(r chi.Router, h *handlers.GuestCartHandler, authenticate func(http.Handler) http.Handler) {
+	r.Route("/cart/guest", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			h.GetCart(r, w)
+		})
+		r.Post("/{product_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.AddProductToCart(r, w)
+		})
+		r.Put("/{product_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.AddProductToCart(r, w)
+		})
+		r.Delete("/{product_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.RemoveItemFromCart(r, w)
+		})
+		r.Delete("/", func(w http.ResponseWriter, r *http.Request) {
+			h.ClearCart(r, w)
+		})
+		r.With(authenticate).Post("/merge", func(w http.ResponseWriter, r *http.Request) {
+			h.MergeCart(r, w)
+		})
+	})
+}
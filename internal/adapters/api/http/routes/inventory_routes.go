@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadInventoryRoutes(r chi.Router, h *handlers.InventoryHandler) {
+	r.Route("/product/{product_id}/stock", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			h.StockAt(r, w)
+		})
+		r.Get("/movements", func(w http.ResponseWriter, r *http.Request) {
+			h.ListMovements(r, w)
+		})
+		r.Post("/movements", func(w http.ResponseWriter, r *http.Request) {
+			h.RecordMovement(r, w)
+		})
+	})
+}
+
+// LoadAdminInventoryRoutes loads the audit of the stock against its ledger
+func LoadAdminInventoryRoutes(r chi.Router, h *handlers.InventoryHandler) {
+	r.Get("/admin/inventory/audit", func(w http.ResponseWriter, r *http.Request) {
+		h.AuditStock(r, w)
+	})
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/go-chi/chi/v5"
 )
 
-func LoadOrderRoutes(r chi.Router, h *handlers.OrderHandler) {
+func LoadOrderRoutes(r chi.Router, h *handlers.OrderHandler, authenticate func(http.Handler) http.Handler) {
 	r.Route("/order", func(r chi.Router) {
 		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
 			h.SaveOrder(r, w)
@@ -21,5 +21,11 @@ func LoadOrderRoutes(r chi.Router, h *handlers.OrderHandler) {
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			h.GetAllOrders(r, w)
 		})
+		r.Get("/{order_id}/payments", func(w http.ResponseWriter, r *http.Request) {
+			h.ListPaymentAttempts(r, w)
+		})
+		r.With(authenticate).Post("/{order_id}/cancel", func(w http.ResponseWriter, r *http.Request) {
+			h.CancelOrder(r, w)
+		})
 	})
 }
@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadPriceRoutes(r chi.Router, h *handlers.PriceHandler) {
+	r.Get("/product/{product_id}/price-history", func(w http.ResponseWriter, r *http.Request) {
+		h.PriceHistory(r, w)
+	})
+	r.Route("/product/{product_id}/price-schedules", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			h.ListSchedules(r, w)
+		})
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			h.SchedulePrice(r, w)
+		})
+		r.Delete("/{schedule_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.CancelSchedule(r, w)
+		})
+	})
+}
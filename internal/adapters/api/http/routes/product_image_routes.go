@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LoadProductImageRoutes loads the gallery of the products, anyone can list the images and the changes go through the
+// authorize middlewares
+func LoadProductImageRoutes(r chi.Router, h *handlers.ProductImageHandler, authorize ...func(http.Handler) http.Handler) {
+	r.Route("/product/{product_id}/images", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			h.ListImages(r, w)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(authorize...)
+			r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+				h.UploadImage(r, w)
+			})
+			r.Put("/order", func(w http.ResponseWriter, r *http.Request) {
+				h.ReorderImages(r, w)
+			})
+			r.Put("/{image_id}/primary", func(w http.ResponseWriter, r *http.Request) {
+				h.SetPrimaryImage(r, w)
+			})
+			r.Delete("/{image_id}", func(w http.ResponseWriter, r *http.Request) {
+				h.DeleteImage(r, w)
+			})
+		})
+	})
+}
+
+// LoadUploadRoutes serves the files of the local blob storage
+func LoadUploadRoutes(r chi.Router, dir string) {
+	r.Handle("/uploads/*", http.StripPrefix("/uploads/", http.FileServer(http.Dir(dir))))
+}
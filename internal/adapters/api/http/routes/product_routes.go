@@ -12,6 +12,12 @@ func LoadProductRoutes(r chi.Router, h *handlers.ProductHandler) {
 		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 			h.ListProducts(r, w)
 		})
+		r.Get("/by-slug/{slug}", func(w http.ResponseWriter, r *http.Request) {
+			h.FindProductBySlug(r, w)
+		})
+		r.Get("/by-sku/{sku}", func(w http.ResponseWriter, r *http.Request) {
+			h.FindProductBySKU(r, w)
+		})
 		r.Get("/{product_id}", func(w http.ResponseWriter, r *http.Request) {
 			h.FindProductById(r, w)
 		})
@@ -24,5 +30,19 @@ func LoadProductRoutes(r chi.Router, h *handlers.ProductHandler) {
 		r.Delete("/{product_id}", func(w http.ResponseWriter, r *http.Request) {
 			h.DeleteProduct(r, w)
 		})
+
+		// variants of a product
+		r.Get("/{product_id}/variants", func(w http.ResponseWriter, r *http.Request) {
+			h.ListVariants(r, w)
+		})
+		r.Post("/{product_id}/variants", func(w http.ResponseWriter, r *http.Request) {
+			h.SaveVariant(r, w)
+		})
+		r.Put("/{product_id}/variants/{variant_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.SaveVariant(r, w)
+		})
+		r.Delete("/{product_id}/variants/{variant_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.DeleteVariant(r, w)
+		})
 	})
 }
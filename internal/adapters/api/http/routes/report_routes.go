@@ -0,0 +1,34 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadReportRoutes(r chi.Router, h *handlers.ReportHandler) {
+	r.Route("/admin/report", func(r chi.Router) {
+		r.Get("/revenue", func(w http.ResponseWriter, r *http.Request) {
+			h.Revenue(r, w)
+		})
+		r.Get("/fees", func(w http.ResponseWriter, r *http.Request) {
+			h.FeesByPayMethod(r, w)
+		})
+		r.Get("/average-order-value", func(w http.ResponseWriter, r *http.Request) {
+			h.AverageOrderValue(r, w)
+		})
+		r.Get("/top-products", func(w http.ResponseWriter, r *http.Request) {
+			h.TopProducts(r, w)
+		})
+		r.Get("/conversion", func(w http.ResponseWriter, r *http.Request) {
+			h.Conversion(r, w)
+		})
+		r.Get("/chargebacks", func(w http.ResponseWriter, r *http.Request) {
+			h.Chargebacks(r, w)
+		})
+		r.Get("/cart-recovery", func(w http.ResponseWriter, r *http.Request) {
+			h.CartRecovery(r, w)
+		})
+	})
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// LoadStockAlertRoutes loads the back in stock alerts of the customers, they require an authenticated user
+func LoadStockAlertRoutes(r chi.Router, h *handlers.StockAlertHandler, authenticate func(http.Handler) http.Handler) {
+	r.With(authenticate).Route("/product/{product_id}/stock-alerts", func(r chi.Router) {
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			h.Subscribe(r, w)
+		})
+		r.Delete("/", func(w http.ResponseWriter, r *http.Request) {
+			h.Unsubscribe(r, w)
+		})
+	})
+}
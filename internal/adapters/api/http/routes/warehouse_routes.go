@@ -0,0 +1,25 @@
+package routes
+
+import (
+	"go-ecommerce/internal/adapters/api/http/handlers"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func LoadWarehouseRoutes(r chi.Router, h *handlers.WarehouseHandler) {
+	r.Route("/admin/warehouses", func(r chi.Router) {
+		r.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			h.ListWarehouses(r, w)
+		})
+		r.Post("/", func(w http.ResponseWriter, r *http.Request) {
+			h.SaveWarehouse(r, w)
+		})
+		r.Get("/{warehouse_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.GetWarehouseById(r, w)
+		})
+		r.Put("/{warehouse_id}", func(w http.ResponseWriter, r *http.Request) {
+			h.UpdateWarehouse(r, w)
+		})
+	})
+}
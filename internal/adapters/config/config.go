@@ -1,9 +1,13 @@
 package config
 
 import (
+	"fmt"
 	"log/slog"
+	"math"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -15,6 +19,13 @@ type (
 		DB              *DB
 		HTTP            *HTTP
 		PaymentProvider *PaymentProvider
+		Reconciliation  *Reconciliation
+		Storage         *Storage
+		Archive         *Archive
+		Notifications   *Notifications
+		CartRecovery    *CartRecovery
+		GuestCart       *GuestCart
+		Auth            *Auth
 	}
 
 	App struct {
@@ -25,10 +36,68 @@ type (
 	MercadoPago struct {
 		PublicKey   string
 		AccessToken string
+		AutoCapture bool
 	}
 
 	PaymentProvider struct {
-		MercadoPago MercadoPago
+		MercadoPago     MercadoPago
+		AmountTolerance float64 // max difference accepted between a payment and the order total
+	}
+
+	// Reconciliation configures the job that compares pending orders with the payments in the provider
+	Reconciliation struct {
+		MaxAge   time.Duration
+		Interval time.Duration
+	}
+
+	// Archive configures the job that purges the archived (soft deleted) products, categories and users
+	Archive struct {
+		Retention time.Duration
+		Interval  time.Duration
+	}
+
+	// Notifications configures the delivery of the notifications, the driver is log or smtp
+	Notifications struct {
+		Driver           string
+		SMTP             SMTP
+		DefaultLocale    string
+		DispatchInterval time.Duration
+		MaxAttempts      int
+	}
+
+	SMTP struct {
+		Host     string
+		Port     int
+		Username string
+		Password string
+		From     string
+		FromName string
+	}
+
+	// CartRecovery configures the reminders of the idle carts, the link of the reminder is signed with the secret
+	CartRecovery struct {
+		IdleAfter time.Duration
+		Interval  time.Duration
+		LinkTTL   time.Duration
+		LinkURL   string
+		Secret    string
+	}
+
+	// GuestCart configures the carts of the visitors, their cart tokens are signed with the secret
+	GuestCart struct {
+		Secret string
+	}
+
+	// Auth configures the access tokens of the users, they're signed with the secret
+	Auth struct {
+		Secret   string
+		TokenTTL time.Duration
+	}
+
+	// Storage configures the local blob storage, files are served under PublicURL
+	Storage struct {
+		LocalDir  string
+		PublicURL string
 	}
 
 	Redis struct {
@@ -60,6 +129,27 @@ func getEnv(value string) string {
 	return env
 }
 
+// getEnvOrDefault returns the default value if the optional environment variable is not set
+func getEnvOrDefault(value, defaultValue string) string {
+	env := os.Getenv(value)
+	if env == "" {
+		return defaultValue
+	}
+	return env
+}
+
+// minSecretLength is the min length of the secrets used to sign tokens, a short secret can be guessed
+const minSecretLength = 32
+
+// getSecret returns the required secret, it fails if it isn't set or is shorter than minSecretLength
+func getSecret(value string) (string, error) {
+	secret := os.Getenv(value)
+	if len(secret) < minSecretLength {
+		return "", fmt.Errorf("environment variable %s must be set with at least %d characters", value, minSecretLength)
+	}
+	return secret, nil
+}
+
 const envFile string = "../../.env"
 
 func New() (*Container, error) {
@@ -81,11 +171,22 @@ func New() (*Container, error) {
 		DB:       0,
 	}
 
+	amountTolerance, err := strconv.ParseFloat(getEnvOrDefault("PAYMENT_AMOUNT_TOLERANCE", "0.01"), 64)
+	if err != nil {
+		return nil, err
+	}
+	// a negative tolerance would reject every payment and an infinite one would accept any amount
+	if amountTolerance < 0 || math.IsNaN(amountTolerance) || math.IsInf(amountTolerance, 0) {
+		return nil, fmt.Errorf("environment variable PAYMENT_AMOUNT_TOLERANCE must be a non negative amount, got %v", amountTolerance)
+	}
+
 	pp := &PaymentProvider{
 		MercadoPago: MercadoPago{
 			PublicKey:   getEnv("MERCADO_PAGO_PUBLIC_KEY"),
 			AccessToken: getEnv("MERCADO_PAGO_ACCESS_TOKEN"),
+			AutoCapture: getEnvOrDefault("MERCADO_PAGO_AUTO_CAPTURE", "true") == "true",
 		},
+		AmountTolerance: amountTolerance,
 	}
 
 	db := &DB{
@@ -95,6 +196,123 @@ func New() (*Container, error) {
 		MaxLifeTime:        getEnv("DB_MAX_LIFETIME"),
 	}
 
+	maxAgeDays, err := strconv.Atoi(getEnvOrDefault("PAYMENT_RECONCILE_MAX_AGE_DAYS", "7"))
+	if err != nil {
+		return nil, err
+	}
+
+	intervalMinutes, err := strconv.Atoi(getEnvOrDefault("PAYMENT_RECONCILE_INTERVAL_MINUTES", "30"))
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliation := &Reconciliation{
+		MaxAge:   time.Duration(maxAgeDays) * 24 * time.Hour,
+		Interval: time.Duration(intervalMinutes) * time.Minute,
+	}
+
+	storage := &Storage{
+		LocalDir:  getEnvOrDefault("BLOB_LOCAL_DIR", "uploads"),
+		PublicURL: getEnvOrDefault("BLOB_PUBLIC_URL", "/uploads"),
+	}
+
+	retentionDays, err := strconv.Atoi(getEnvOrDefault("ARCHIVE_RETENTION_DAYS", "90"))
+	if err != nil {
+		return nil, err
+	}
+
+	purgeIntervalHours, err := strconv.Atoi(getEnvOrDefault("ARCHIVE_PURGE_INTERVAL_HOURS", "24"))
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &Archive{
+		Retention: time.Duration(retentionDays) * 24 * time.Hour,
+		Interval:  time.Duration(purgeIntervalHours) * time.Hour,
+	}
+
+	smtpPort, err := strconv.Atoi(getEnvOrDefault("SMTP_PORT", "587"))
+	if err != nil {
+		return nil, err
+	}
+
+	dispatchIntervalSeconds, err := strconv.Atoi(getEnvOrDefault("NOTIFICATIONS_DISPATCH_INTERVAL_SECONDS", "30"))
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts, err := strconv.Atoi(getEnvOrDefault("NOTIFICATIONS_MAX_ATTEMPTS", "5"))
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := &Notifications{
+		Driver: getEnvOrDefault("NOTIFICATIONS_DRIVER", "log"),
+		SMTP: SMTP{
+			Host:     getEnvOrDefault("SMTP_HOST", "localhost"),
+			Port:     smtpPort,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnvOrDefault("SMTP_FROM", "no-reply@localhost"),
+			FromName: getEnvOrDefault("SMTP_FROM_NAME", "Go Ecommerce"),
+		},
+		DefaultLocale:    getEnvOrDefault("NOTIFICATIONS_DEFAULT_LOCALE", "es"),
+		DispatchInterval: time.Duration(dispatchIntervalSeconds) * time.Second,
+		MaxAttempts:      maxAttempts,
+	}
+
+	idleHours, err := strconv.Atoi(getEnvOrDefault("CART_RECOVERY_IDLE_HOURS", "24"))
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryIntervalMinutes, err := strconv.Atoi(getEnvOrDefault("CART_RECOVERY_INTERVAL_MINUTES", "60"))
+	if err != nil {
+		return nil, err
+	}
+
+	linkTTLDays, err := strconv.Atoi(getEnvOrDefault("CART_RECOVERY_LINK_TTL_DAYS", "7"))
+	if err != nil {
+		return nil, err
+	}
+
+	recoverySecret, err := getSecret("CART_RECOVERY_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
+	cartRecovery := &CartRecovery{
+		IdleAfter: time.Duration(idleHours) * time.Hour,
+		Interval:  time.Duration(recoveryIntervalMinutes) * time.Minute,
+		LinkTTL:   time.Duration(linkTTLDays) * 24 * time.Hour,
+		LinkURL:   getEnvOrDefault("CART_RECOVERY_URL", os.Getenv("APP_DOMAIN")+"/cart/recover"),
+		Secret:    recoverySecret,
+	}
+
+	guestCartSecret, err := getSecret("GUEST_CART_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
+	guestCart := &GuestCart{
+		Secret: guestCartSecret,
+	}
+
+	authSecret, err := getSecret("AUTH_SECRET")
+	if err != nil {
+		return nil, err
+	}
+
+	tokenTTLHours, err := strconv.Atoi(getEnvOrDefault("AUTH_TOKEN_TTL_HOURS", "24"))
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &Auth{
+		Secret:   authSecret,
+		TokenTTL: time.Duration(tokenTTLHours) * time.Hour,
+	}
+
 	allowedOriginsStr := getEnv("APP_ALLOWED_ORIGINS")
 	allowedOriginsOpts := strings.Split(allowedOriginsStr, ",")
 
@@ -111,6 +329,13 @@ func New() (*Container, error) {
 		db,
 		http,
 		pp,
+		reconciliation,
+		storage,
+		archive,
+		notifications,
+		cartRecovery,
+		guestCart,
+		auth,
 	}, nil
 
 }
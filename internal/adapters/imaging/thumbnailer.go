@@ -0,0 +1,95 @@
+package imaging
+
+import (
+	"bytes"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the gif decoder
+	"image/jpeg"
+	"image/png"
+)
+
+const thumbnailJPEGQuality = 85
+
+// Thumbnailer scales images with the standard library only, so no native dependency is required
+type Thumbnailer struct{}
+
+func NewThumbnailer() ports.ImageProcessor {
+	return &Thumbnailer{}
+}
+
+// Thumbnail implements ports.ImageProcessor.
+// Jpeg images keep the format, png and gif are encoded as png to keep the transparency. The size declared in the
+// header is checked before decoding, the decoder allocates the whole image
+func (t *Thumbnailer) Thumbnail(data []byte, maxSide int) ([]byte, string, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(config.Width)*int64(config.Height) > domain.MaxProductImagePixels {
+		return nil, "", domain.ErrProductImageTooManyPixels
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", err
+	}
+
+	dst := scaleDown(src, maxSide)
+
+	var buf bytes.Buffer
+	if format == "jpeg" {
+		err = jpeg.Encode(&buf, dst, &jpeg.Options{Quality: thumbnailJPEGQuality})
+		return buf.Bytes(), "image/jpeg", err
+	}
+
+	err = png.Encode(&buf, dst)
+	return buf.Bytes(), "image/png", err
+}
+
+// helper func, box filter: every pixel of the thumbnail is the average of the pixels it covers in the source
+func scaleDown(src image.Image, maxSide int) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// smaller images keep their size
+	dstW, dstH := w, h
+	if w >= h && w > maxSide {
+		dstW, dstH = maxSide, max(1, h*maxSide/w)
+	} else if h > w && h > maxSide {
+		dstW, dstH = max(1, w*maxSide/h), maxSide
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		y0 := bounds.Min.Y + y*h/dstH
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*h/dstH)
+
+		for x := 0; x < dstW; x++ {
+			x0 := bounds.Min.X + x*w/dstW
+			x1 := max(x0+1, bounds.Min.X+(x+1)*w/dstW)
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBA64Model.Convert(src.At(sx, sy)).(color.NRGBA64)
+					r += uint64(c.R)
+					g += uint64(c.G)
+					b += uint64(c.B)
+					a += uint64(c.A)
+					n++
+				}
+			}
+
+			dst.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+	return dst
+}
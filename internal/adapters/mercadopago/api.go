@@ -12,19 +12,23 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	neturl "net/url"
+	"strconv"
 )
 
 type PaymentProvider struct {
 	httpClient  *http.Client
 	domain      string
 	secretToken string
+	autoCapture bool // if false, payments are only authorized and must be captured later
 }
 
-func NewPaymentProvider(client *http.Client, domain, secretToken string) ports.PaymentProvider {
+func NewPaymentProvider(client *http.Client, domain, secretToken string, autoCapture bool) ports.PaymentProvider {
 	return &PaymentProvider{
 		httpClient:  client,
 		domain:      domain,
 		secretToken: secretToken,
+		autoCapture: autoCapture,
 	}
 }
 
@@ -40,7 +44,8 @@ func (ps *PaymentProvider) GeneratePreference(ctx context.Context, order *domain
 			Failure: fmt.Sprintf("%s/order/%s", ps.domain, order.SecureToken),
 			Pending: fmt.Sprintf("%s/order/%s", ps.domain, order.SecureToken),
 		},
-		Items: items,
+		Items:   items,
+		Capture: ps.autoCapture,
 		Payer: mp_dtos.MpPayer{
 			Name:  user.Name,
 			Email: user.Email,
@@ -84,12 +89,18 @@ func (ps *PaymentProvider) handlePayment(ctx context.Context, paymentId string)
 	}
 	defer resp.Body.Close()
 
-	// transform body in payment object
+	// transform body in payment object, the raw body is kept in the payment attempts ledger
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	payment := &mp_dtos.MpSimplifiedPayment{}
-	err = json.NewDecoder(resp.Body).Decode(payment)
+	err = json.Unmarshal(body, payment)
 	if err != nil {
 		return nil, fmt.Errorf("failed decoding payment: %w", err)
 	}
+	payment.Raw = body
 
 	return payment, nil
 }
@@ -200,3 +211,179 @@ func (ps *PaymentProvider) VerifyPayment(ctx context.Context, id, topic *string)
 
 	return nil, nil
 }
+
+// CancelPayment implements ports.PaymentProvider.
+// Cancels a pending or authorized payment, releasing the funds reserved in the buyer's card
+func (ps *PaymentProvider) CancelPayment(ctx context.Context, paymentId string) error {
+	url := fmt.Sprintf("https://api.mercadopago.com/v1/payments/%s", paymentId)
+
+	jsonBody, _ := json.Marshal(mp_dtos.MpPaymentStatusUpdate{Status: domain.Cancelled})
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ps.secretToken))
+
+	res, err := ps.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		slog.Error("Error in mercado pago response", "error", string(bodyBytes), "code", res.StatusCode)
+		return fmt.Errorf("couldn't cancel payment %s in MercadoPago", paymentId)
+	}
+
+	return nil
+}
+
+// CapturePayment implements ports.PaymentProvider.
+// Captures the funds of an authorized payment, amount can be lower than the authorized one
+func (ps *PaymentProvider) CapturePayment(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error) {
+	url := fmt.Sprintf("https://api.mercadopago.com/v1/payments/%s", paymentId)
+
+	jsonBody, _ := json.Marshal(mp_dtos.MpPaymentCapture{Capture: true, TransactionAmount: amount})
+	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ps.secretToken))
+
+	res, err := ps.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		slog.Error("Error in mercado pago response", "error", string(bodyBytes), "code", res.StatusCode)
+		return nil, fmt.Errorf("couldn't capture payment %s in MercadoPago", paymentId)
+	}
+
+	// the raw body is kept in the payment attempts ledger, like the notified payments
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	payment := &mp_dtos.MpSimplifiedPayment{}
+	err = json.Unmarshal(body, payment)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding payment: %w", err)
+	}
+	payment.Raw = body
+
+	return payment, nil
+}
+
+// GetPayment implements ports.PaymentProvider.
+func (ps *PaymentProvider) GetPayment(ctx context.Context, paymentId string) (*mp_dtos.MpSimplifiedPayment, error) {
+	return ps.handlePayment(ctx, paymentId)
+}
+
+// GetChargeback implements ports.PaymentProvider.
+// Returns the chargeback object, it contains the disputed payments and the deadline to submit documentation
+func (ps *PaymentProvider) GetChargeback(ctx context.Context, chargebackId string) (*mp_dtos.MpChargeback, error) {
+	url := fmt.Sprintf("https://api.mercadopago.com/v1/chargebacks/%s", chargebackId)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ps.secretToken))
+
+	res, err := ps.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		slog.Error("Error in mercado pago response", "error", string(bodyBytes), "code", res.StatusCode)
+		return nil, fmt.Errorf("couldn't get chargeback %s from MercadoPago", chargebackId)
+	}
+
+	chargeback := &mp_dtos.MpChargeback{}
+	err = json.NewDecoder(res.Body).Decode(chargeback)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding chargeback: %w", err)
+	}
+
+	return chargeback, nil
+}
+
+// the max number of payments returned by a page of the payments search
+const paymentSearchLimit = 100
+
+// SearchPayments implements ports.PaymentProvider.
+// Returns every payment created for the external reference (order id), used to recover lost notifications. The
+// pages of the search are read until its total is reached
+func (ps *PaymentProvider) SearchPayments(ctx context.Context, externalReference string) ([]*mp_dtos.MpSimplifiedPayment, error) {
+	payments := []*mp_dtos.MpSimplifiedPayment{}
+	for {
+		search, err := ps.searchPaymentsPage(ctx, externalReference, len(payments))
+		if err != nil {
+			return nil, err
+		}
+
+		// each result is decoded apart to keep its raw payload
+		for _, raw := range search.Results {
+			payment := &mp_dtos.MpSimplifiedPayment{}
+			if err := json.Unmarshal(raw, payment); err != nil {
+				return nil, fmt.Errorf("failed decoding payment: %w", err)
+			}
+			payment.Raw = raw
+			payments = append(payments, payment)
+		}
+
+		if len(search.Results) == 0 || len(payments) >= search.Paging.Total {
+			return payments, nil
+		}
+	}
+}
+
+// helper func, returns the page of the payments search that starts at offset, sorted by creation so the pages
+// don't skip or repeat payments
+func (ps *PaymentProvider) searchPaymentsPage(ctx context.Context, externalReference string, offset int) (*mp_dtos.MpPaymentSearch, error) {
+	query := neturl.Values{}
+	query.Set("external_reference", externalReference)
+	query.Set("sort", "date_created")
+	query.Set("criteria", "asc")
+	query.Set("limit", strconv.Itoa(paymentSearchLimit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	url := "https://api.mercadopago.com/v1/payments/search?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", ps.secretToken))
+
+	res, err := ps.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 400 {
+		bodyBytes, _ := io.ReadAll(res.Body)
+		slog.Error("Error in mercado pago response", "error", string(bodyBytes), "code", res.StatusCode)
+		return nil, fmt.Errorf("couldn't search payments of %s in MercadoPago", externalReference)
+	}
+
+	search := &mp_dtos.MpPaymentSearch{}
+	err = json.NewDecoder(res.Body).Decode(search)
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding payments search: %w", err)
+	}
+	return search, nil
+}
@@ -1,6 +1,10 @@
 package mp_dtos
 
-import "go-ecommerce/internal/core/domain"
+import (
+	"encoding/json"
+	"go-ecommerce/internal/core/domain"
+	"time"
+)
 
 // ? Preference request params
 type MpPreferenceRequest struct {
@@ -12,6 +16,7 @@ type MpPreferenceRequest struct {
 	Items               []MpItem       `json:"items"`
 	Payer               MpPayer        `json:"payer"`
 	PaymentMethods      PaymentMethods `json:"payment_methods"`
+	Capture             bool           `json:"capture"` // false only authorizes the payment, it must be captured later
 }
 
 type MpBackUrls struct {
@@ -86,6 +91,39 @@ type MpSimplifiedPayment struct {
 	Payer              MpPayer                `json:"payer"`
 	TransactionDetails TransactionDetails     `json:"transaction_details"`
 	Order              *Order                 `json:"order"`
+	Raw                json.RawMessage        `json:"-"` // body received from the api
+}
+
+type MpPaymentSearch struct {
+	Paging  MpPaging          `json:"paging"`
+	Results []json.RawMessage `json:"results"`
+}
+
+type MpPaging struct {
+	Total  int `json:"total"`
+	Limit  int `json:"limit"`
+	Offset int `json:"offset"`
+}
+
+// ? Payment updates
+type MpPaymentStatusUpdate struct {
+	Status domain.PayStatus `json:"status"`
+}
+
+type MpPaymentCapture struct {
+	Capture           bool    `json:"capture"`
+	TransactionAmount float64 `json:"transaction_amount"`
+}
+
+// ? Chargeback objects
+type MpChargeback struct {
+	ID                        string     `json:"id"`
+	Payments                  []int      `json:"payments"`
+	Currency                  string     `json:"currency"`
+	Amount                    float64    `json:"amount"`
+	Reason                    string     `json:"reason"`
+	DocumentationRequired     bool       `json:"documentation_required"`
+	DateDocumentationDeadline *time.Time `json:"date_documentation_deadline"`
 }
 
 // ? Merchant Order objects
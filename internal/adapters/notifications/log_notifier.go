@@ -0,0 +1,35 @@
+package notifications
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"sort"
+)
+
+// LogNotifier writes the notifications in the application logs instead of sending them
+type LogNotifier struct{}
+
+func NewLogNotifier() ports.Notifier {
+	return &LogNotifier{}
+}
+
+// Notify implements ports.Notifier.
+func (n *LogNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	// sort keys to keep the same output for the same notification
+	keys := make([]string, 0, len(notification.Data))
+	for k := range notification.Data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	args := make([]any, 0, len(notification.Data)*2+6)
+	args = append(args, "kind", notification.Kind, "user_id", notification.UserID, "to", notification.To)
+	for _, k := range keys {
+		args = append(args, k, notification.Data[k])
+	}
+
+	slog.InfoContext(ctx, "Notification sent", args...)
+	return nil
+}
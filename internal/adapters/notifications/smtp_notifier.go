@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"time"
+)
+
+// SMTPNotifier sends the notifications by email, rendered with the templates of their kind
+type SMTPNotifier struct {
+	addr      string
+	auth      smtp.Auth
+	from      mail.Address
+	templates *Templates
+}
+
+// NewSMTPNotifier creates a notifier that sends the emails through the server at host:port. The server is used
+// without authentication when the username is empty, e.g. a local relay
+func NewSMTPNotifier(host string, port int, username, password string, from mail.Address, templates *Templates) ports.Notifier {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPNotifier{
+		addr:      net.JoinHostPort(host, strconv.Itoa(port)),
+		auth:      auth,
+		from:      from,
+		templates: templates,
+	}
+}
+
+// Notify implements ports.Notifier.
+func (n *SMTPNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	message, err := n.templates.Render(notification)
+	if err != nil {
+		return err
+	}
+
+	to := mail.Address{Name: notification.Name, Address: notification.To}
+	body, err := n.buildEmail(to, message)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(n.addr, n.auth, n.from.Address, []string{to.Address}, body)
+}
+
+// helper func, builds a multipart/alternative email with the text and html bodies
+func (n *SMTPNotifier) buildEmail(to mail.Address, message *Message) ([]byte, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=UTF-8", message.Text},
+		{"text/html; charset=UTF-8", message.HTML},
+	}
+	for _, p := range parts {
+		part, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(p.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	var email bytes.Buffer
+	fmt.Fprintf(&email, "From: %s\r\n", n.from.String())
+	fmt.Fprintf(&email, "To: %s\r\n", to.String())
+	fmt.Fprintf(&email, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", message.Subject))
+	fmt.Fprintf(&email, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&email, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&email, "Content-Type: multipart/alternative; boundary=%q\r\n", writer.Boundary())
+	fmt.Fprintf(&email, "\r\n")
+	email.Write(body.Bytes())
+
+	return email.Bytes(), nil
+}
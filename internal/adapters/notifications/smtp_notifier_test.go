@@ -0,0 +1,187 @@
+package notifications_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/notifications"
+	"go-ecommerce/internal/core/domain"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type receivedEmail struct {
+	from string
+	to   []string
+	data []byte
+}
+
+// smtpStandIn is a local SMTP server that keeps the received emails
+type smtpStandIn struct {
+	host   string
+	port   int
+	emails chan receivedEmail
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+
+	s := &smtpStandIn{host: host, port: portNumber, emails: make(chan receivedEmail, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	tc := textproto.NewConn(conn)
+	tc.PrintfLine("220 localhost ESMTP")
+
+	var email receivedEmail
+	for {
+		line, err := tc.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			tc.PrintfLine("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			email.from = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			tc.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			email.to = append(email.to, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			tc.PrintfLine("250 OK")
+		case command == "DATA":
+			tc.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			email.data, err = tc.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			tc.PrintfLine("250 OK")
+			s.emails <- email
+			email = receivedEmail{}
+		case command == "QUIT":
+			tc.PrintfLine("221 bye")
+			return
+		default:
+			tc.PrintfLine("250 OK")
+		}
+	}
+}
+
+// helper func, returns the subject and the bodies by content type of the email
+func parseEmail(t *testing.T, data []byte) (string, map[string]string) {
+	t.Helper()
+
+	message, err := mail.ReadMessage(strings.NewReader(string(data)))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	require.NoError(t, err)
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	bodies := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		content, err := io.ReadAll(part)
+		require.NoError(t, err)
+		contentType, _, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		bodies[contentType] = string(content)
+	}
+	return subject, bodies
+}
+
+func Test_SMTPNotifier_Notify(t *testing.T) {
+	ctx := context.Background()
+	server := newSMTPStandIn(t)
+
+	templates, err := notifications.NewTemplates(domain.LocaleES)
+	require.NoError(t, err)
+
+	from := mail.Address{Name: "Go Ecommerce", Address: "shop@mail.test"}
+	notifier := notifications.NewSMTPNotifier(server.host, server.port, "", "", from, templates)
+
+	orderID := uuid.New()
+	notification := domain.Notification{
+		Kind:   domain.NotificationPaymentApproved,
+		UserID: uuid.New(),
+		To:     "john@mail.test",
+		Name:   "John",
+		Locale: domain.LocaleEN,
+		Data: map[string]any{
+			"order_id": orderID,
+			"total":    1500.5,
+			"currency": domain.ARS,
+		},
+	}
+
+	t.Run("sends the text and html bodies in the locale of the user", func(t *testing.T) {
+		require.NoError(t, notifier.Notify(ctx, notification))
+
+		email := <-server.emails
+		assert.Equal(t, "shop@mail.test", email.from)
+		assert.Equal(t, []string{"john@mail.test"}, email.to)
+
+		subject, bodies := parseEmail(t, email.data)
+		assert.Equal(t, "Payment approved", subject)
+		assert.Contains(t, bodies["text/plain"], "Hi John,")
+		assert.Contains(t, bodies["text/plain"], orderID.String()+" for 1500.50 ARS was approved")
+		assert.Contains(t, bodies["text/html"], `<html lang="en">`)
+		assert.Contains(t, bodies["text/html"], "<strong>1500.50 ARS</strong>")
+		// the text body isn't escaped as html
+		assert.Contains(t, bodies["text/plain"], "We'll let you know")
+	})
+
+	t.Run("uses the default locale when the user's one has no templates", func(t *testing.T) {
+		welcome := domain.Notification{Kind: domain.NotificationWelcome, To: "ana@mail.test", Name: "Ana <admin>", Locale: "fr"}
+		require.NoError(t, notifier.Notify(ctx, welcome))
+
+		subject, bodies := parseEmail(t, (<-server.emails).data)
+		assert.Equal(t, "¡Bienvenido/a, Ana <admin>!", subject)
+		assert.Contains(t, bodies["text/html"], `<html lang="es">`)
+		assert.Contains(t, bodies["text/html"], "Ana &lt;admin&gt;")
+	})
+
+	t.Run("fails for a kind without templates", func(t *testing.T) {
+		err := notifier.Notify(ctx, domain.Notification{Kind: "unknown", To: "john@mail.test"})
+		assert.ErrorIs(t, err, domain.ErrNotificationTemplateNotFound)
+	})
+}
@@ -0,0 +1,141 @@
+package notifications
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go-ecommerce/internal/core/domain"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates
+var templatesFS embed.FS
+
+// Message is a notification rendered for its recipient
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Templates renders the notifications with the templates of their kind and locale. Every kind has a text template
+// that defines the subject, and an html template rendered inside the layout. The text body is rendered with
+// text/template, html/template would escape it as html
+type Templates struct {
+	defaultLocale string
+	text          map[string]*texttemplate.Template
+	html          map[string]*htmltemplate.Template
+}
+
+// values available in the templates
+type templateData struct {
+	Name    string
+	Locale  string
+	Subject string
+	Data    map[string]any
+}
+
+var templateFuncs = map[string]any{
+	"money": func(amount any) string {
+		switch v := amount.(type) {
+		case float64:
+			return fmt.Sprintf("%.2f", v)
+		case int, int64:
+			return fmt.Sprintf("%d.00", v)
+		default:
+			return fmt.Sprint(v)
+		}
+	},
+}
+
+// NewTemplates parses the embedded templates, the notifications in an unknown locale use the default one
+func NewTemplates(defaultLocale string) (*Templates, error) {
+	t := &Templates{
+		defaultLocale: defaultLocale,
+		text:          map[string]*texttemplate.Template{},
+		html:          map[string]*htmltemplate.Template{},
+	}
+
+	layout, err := htmltemplate.New("layout.html").Funcs(templateFuncs).ParseFS(templatesFS, "templates/layout.html")
+	if err != nil {
+		return nil, err
+	}
+
+	textFiles, err := fs.Glob(templatesFS, "templates/*/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range textFiles {
+		locale := path.Base(path.Dir(file))
+		kind := strings.TrimSuffix(path.Base(file), ".txt")
+		key := templateKey(locale, domain.NotificationKind(kind))
+
+		text, err := texttemplate.New(path.Base(file)).Funcs(templateFuncs).ParseFS(templatesFS, file)
+		if err != nil {
+			return nil, err
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("template %s doesn't define the subject", file)
+		}
+
+		html, err := layout.Clone()
+		if err != nil {
+			return nil, err
+		}
+		html, err = html.ParseFS(templatesFS, strings.TrimSuffix(file, ".txt")+".html")
+		if err != nil {
+			return nil, err
+		}
+
+		t.text[key] = text
+		t.html[key] = html
+	}
+
+	return t, nil
+}
+
+func templateKey(locale string, kind domain.NotificationKind) string {
+	return locale + "/" + string(kind)
+}
+
+// Render renders the subject and the bodies of the notification
+func (t *Templates) Render(n domain.Notification) (*Message, error) {
+	locale := n.Locale
+	if _, ok := t.text[templateKey(locale, n.Kind)]; !ok {
+		locale = t.defaultLocale
+	}
+
+	key := templateKey(locale, n.Kind)
+	text, ok := t.text[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", domain.ErrNotificationTemplateNotFound, n.Kind)
+	}
+
+	data := templateData{Name: n.Name, Locale: locale, Data: n.Data}
+
+	var subject bytes.Buffer
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	data.Subject = strings.TrimSpace(subject.String())
+
+	var textBody bytes.Buffer
+	if err := text.Execute(&textBody, data); err != nil {
+		return nil, err
+	}
+
+	var htmlBody bytes.Buffer
+	if err := t.html[key].ExecuteTemplate(&htmlBody, "layout", data); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Subject: data.Subject,
+		Text:    strings.TrimSpace(textBody.String()) + "\n",
+		HTML:    htmlBody.String(),
+	}, nil
+}
@@ -0,0 +1,71 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// Scheduler runs background jobs periodically until it's stopped
+type Scheduler struct {
+	jobs   []job
+	wg     sync.WaitGroup
+	cancel context.CancelFunc
+}
+
+func New() *Scheduler {
+	return &Scheduler{jobs: make([]job, 0)}
+}
+
+// Every registers a job that will be executed each interval. Must be called before Start
+func (s *Scheduler) Every(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start launches a goroutine for each registered job
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					start := time.Now()
+					if err := j.run(ctx); err != nil {
+						slog.Error("error executing scheduled job", "job", j.name, "error", err)
+						continue
+					}
+					slog.Info("scheduled job executed", "job", j.name, "duration", time.Since(start))
+				}
+			}
+		}(j)
+	}
+
+	slog.Info("Scheduler started", "jobs", len(s.jobs))
+}
+
+// Stop cancels the running jobs and waits until all of them return
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	slog.Info("Scheduler stopped")
+}
@@ -0,0 +1,85 @@
+package local
+
+import (
+	"context"
+	"fmt"
+	"go-ecommerce/internal/core/ports"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Storage saves the blobs in a directory of the local filesystem, the files are served by the http server under the base url
+type Storage struct {
+	baseDir string
+	baseURL string
+}
+
+func NewStorage(baseDir, baseURL string) ports.BlobStorage {
+	return &Storage{
+		baseDir: baseDir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}
+}
+
+// helper func, resolves the key inside the base dir. Keys that escape it (e.g. "../") are rejected
+func (s *Storage) path(key string) (string, error) {
+	cleaned := filepath.Clean("/" + key)
+	if cleaned == "/" {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.baseDir, cleaned), nil
+}
+
+// Put implements ports.BlobStorage.
+func (s *Storage) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+
+	// write in a temp file and rename it, so readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+
+	return s.URL(key), nil
+}
+
+// Delete implements ports.BlobStorage.
+func (s *Storage) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// URL implements ports.BlobStorage.
+func (s *Storage) URL(key string) string {
+	return s.baseURL + "/" + strings.TrimLeft(key, "/")
+}
@@ -3,3 +3,23 @@ package cachekeys
 func Cart(id string) string {
 	return generateCacheKey("cart", id)
 }
+
+// CartPrefix matches every cached cart of the users
+func CartPrefix() string {
+	return generateCacheKey("cart", "")
+}
+
+// CartsImported marks that the carts saved only in cache, of the users and the guests, were imported to the database
+func CartsImported() string {
+	return generateCacheKey("migration", "carts_imported")
+}
+
+// GuestCart is the cart of a visitor, identified by the id signed in its cart token
+func GuestCart(id string) string {
+	return generateCacheKey("guest_cart", id)
+}
+
+// GuestCartPrefix matches every cached cart of the guests
+func GuestCartPrefix() string {
+	return generateCacheKey("guest_cart", "")
+}
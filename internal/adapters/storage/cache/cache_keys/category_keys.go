@@ -7,3 +7,7 @@ func Category(id uint64) string {
 func AllCategories() string {
 	return "categories:all"
 }
+
+func CategoryTree() string {
+	return "categories:tree"
+}
@@ -4,6 +4,36 @@ func Product(id string) string {
 	return generateCacheKey("product", id)
 }
 
+// ProductPrefix matches every cached product
+func ProductPrefix() string {
+	return generateCacheKey("product", "")
+}
+
+// ProductBySlug maps the slug of a product to its id
+func ProductBySlug(slug string) string {
+	return generateCacheKey("product:slug", slug)
+}
+
+// ProductBySKU maps the SKU of a product to its id
+func ProductBySKU(sku string) string {
+	return generateCacheKey("product:sku", sku)
+}
+
 func AllProducts() string {
 	return "products:all"
 }
+
+// ProductQuery is the key of a page of the product search, hash identifies the query
+func ProductQuery(hash string) string {
+	return ProductQueriesPrefix() + hash
+}
+
+// ProductQueriesPrefix matches every cached page of the product search
+func ProductQueriesPrefix() string {
+	return "products:query:"
+}
+
+// ProductImportJob is the key of the progress of an import of products
+func ProductImportJob(id string) string {
+	return generateCacheKey("product_import", id)
+}
@@ -3,9 +3,12 @@ package cachettl
 import "time"
 
 const (
-	User     = 40 * time.Minute
-	Product  = 30 * time.Minute
-	Category = 10 * time.Minute
-	Order    = 20 * time.Minute
-	Cart     = 0 // always is in cache
+	User         = 40 * time.Minute
+	Product      = 30 * time.Minute
+	ProductQuery = 5 * time.Minute // pages of the product search, stock changes often
+	Category     = 10 * time.Minute
+	Order        = 20 * time.Minute
+	Cart         = 24 * time.Hour      // saved in the database, the cache keeps the carts in use
+	GuestCart    = 30 * 24 * time.Hour // expires with the cart token of the guest
+	ImportJob    = 24 * time.Hour
 )
@@ -2,13 +2,18 @@ package redis
 
 import (
 	"context"
+	"errors"
 	"go-ecommerce/internal/adapters/config"
+	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
+// attempts of Update while the key is changed by other clients
+const maxUpdateAttempts = 10
+
 type Redis struct {
 	client *redis.Client
 }
@@ -54,7 +59,7 @@ func (r *Redis) DeleteByPrefix(ctx context.Context, prefix string) error {
 
 	for {
 		var err error
-		keys, cursor, err = r.client.Scan(ctx, cursor, prefix, 100).Result()
+		keys, cursor, err = r.client.Scan(ctx, cursor, prefix+"*", 100).Result()
 		if err != nil {
 			return err
 		}
@@ -74,6 +79,61 @@ func (r *Redis) DeleteByPrefix(ctx context.Context, prefix string) error {
 	return nil
 }
 
+// Keys returns the keys of the redis database with the given prefix, SCAN doesn't block the server like KEYS
+func (r *Redis) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var cursor uint64
+	var result []string
+
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, prefix+"*", 100).Result()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, keys...)
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// Update replaces the value in a WATCH/MULTI transaction, the transaction fails if another client changes the key
+// after it's read and fn runs again with the new value
+func (r *Redis) Update(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) ([]byte, error)) error {
+	update := func(tx *redis.Tx) error {
+		current, err := tx.Get(ctx, key).Bytes()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+
+		next, err := fn(current)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if next == nil {
+				pipe.Del(ctx, key)
+			} else {
+				pipe.Set(ctx, key, next, ttl)
+			}
+			return nil
+		})
+		return err
+	}
+
+	for range maxUpdateAttempts {
+		err := r.client.Watch(ctx, update, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return domain.ErrConcurrentUpdate
+}
+
 // Close closes the connection to the redis database
 func (r *Redis) Close() error {
 	return r.client.Close()
@@ -0,0 +1,51 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.Cart -> DB model
+func ConvertCartDomainToModel(c *domain.Cart) *models.CartModel {
+	items := make([]models.CartItemModel, 0, len(c.Items))
+	for i, item := range c.Items {
+		items = append(items, models.CartItemModel{
+			CartUserID:     c.UserID,
+			ProductID:      item.ProductID,
+			VariantID:      item.VariantID,
+			Quantity:       item.Quantity,
+			PriceWhenAdded: item.PriceWhenAdded,
+			Position:       i,
+		})
+	}
+
+	return &models.CartModel{
+		UserID:    c.UserID,
+		UpdatedAt: c.UpdatedAt,
+		Items:     items,
+	}
+}
+
+// DB model -> domain.Cart, the lines must be sorted by position
+func ConvertCartModelToDomain(c *models.CartModel) *domain.Cart {
+	cart := domain.NewCart(c.UserID)
+	cart.UpdatedAt = c.UpdatedAt
+	for _, item := range c.Items {
+		cart.Items = append(cart.Items, domain.CartItem{
+			ProductID:      item.ProductID,
+			VariantID:      item.VariantID,
+			Quantity:       item.Quantity,
+			PriceWhenAdded: item.PriceWhenAdded,
+		})
+	}
+	return cart
+}
+
+// DB models -> domain.Carts
+func ConvertCartModelsToDomains(carts []*models.CartModel) []*domain.Cart {
+	result := make([]*domain.Cart, 0, len(carts))
+	for _, c := range carts {
+		result = append(result, ConvertCartModelToDomain(c))
+	}
+	return result
+}
@@ -0,0 +1,45 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.CartRecovery -> DB model
+func ConvertCartRecoveryDomainToModel(r *domain.CartRecovery) *models.CartRecoveryModel {
+	return &models.CartRecoveryModel{
+		ID:             r.ID,
+		UserID:         r.UserID,
+		Items:          r.Items,
+		CartUpdatedAt:  r.CartUpdatedAt,
+		RemindedAt:     r.RemindedAt,
+		RestoredAt:     r.RestoredAt,
+		OrderID:        r.OrderID,
+		RecoveredAt:    r.RecoveredAt,
+		RecoveredTotal: r.RecoveredTotal,
+	}
+}
+
+// DB model -> domain.CartRecovery
+func ConvertCartRecoveryModelToDomain(r *models.CartRecoveryModel) *domain.CartRecovery {
+	return &domain.CartRecovery{
+		ID:             r.ID,
+		UserID:         r.UserID,
+		Items:          r.Items,
+		CartUpdatedAt:  r.CartUpdatedAt,
+		RemindedAt:     r.RemindedAt,
+		RestoredAt:     r.RestoredAt,
+		OrderID:        r.OrderID,
+		RecoveredAt:    r.RecoveredAt,
+		RecoveredTotal: r.RecoveredTotal,
+	}
+}
+
+// DB models -> domain.CartRecoveries
+func ConvertCartRecoveryModelsToDomains(recoveries []*models.CartRecoveryModel) []*domain.CartRecovery {
+	result := make([]*domain.CartRecovery, 0, len(recoveries))
+	for _, r := range recoveries {
+		result = append(result, ConvertCartRecoveryModelToDomain(r))
+	}
+	return result
+}
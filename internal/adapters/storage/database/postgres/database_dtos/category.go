@@ -9,7 +9,9 @@ import (
 func ConvertCategoryDomainToModel(category *domain.Category) *models.CategoryModel {
 	return &models.CategoryModel{
 		ID:        category.ID,
+		ParentID:  category.ParentID,
 		Name:      category.Name,
+		Slug:      category.Slug,
 		CreatedAt: category.CreatedAt,
 		UpdatedAt: category.UpdatedAt,
 	}
@@ -22,7 +24,9 @@ func ConvertCategoriesDomainToModels(categories []*domain.Category) []*models.Ca
 	for _, c := range categories {
 		categoriesModels = append(categoriesModels, &models.CategoryModel{
 			ID:        c.ID,
+			ParentID:  c.ParentID,
 			Name:      c.Name,
+			Slug:      c.Slug,
 			CreatedAt: c.CreatedAt,
 			UpdatedAt: c.UpdatedAt,
 		})
@@ -35,9 +39,12 @@ func ConvertCategoriesDomainToModels(categories []*domain.Category) []*models.Ca
 func ConvertCategoryModelToDomain(categoryModel *models.CategoryModel) *domain.Category {
 	return &domain.Category{
 		ID:        categoryModel.ID,
+		ParentID:  categoryModel.ParentID,
 		Name:      categoryModel.Name,
+		Slug:      categoryModel.Slug,
 		CreatedAt: categoryModel.CreatedAt,
 		UpdatedAt: categoryModel.UpdatedAt,
+		DeletedAt: deletedAt(categoryModel.DeletedAt),
 	}
 }
 
@@ -48,9 +55,12 @@ func ConvertCategoriesModelsToDomain(categoryModels []*models.CategoryModel) []*
 	for _, c := range categoryModels {
 		domainCategories = append(domainCategories, &domain.Category{
 			ID:        c.ID,
+			ParentID:  c.ParentID,
 			Name:      c.Name,
+			Slug:      c.Slug,
 			CreatedAt: c.CreatedAt,
 			UpdatedAt: c.UpdatedAt,
+			DeletedAt: deletedAt(c.DeletedAt),
 		})
 	}
 
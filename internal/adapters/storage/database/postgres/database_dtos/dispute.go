@@ -0,0 +1,81 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.Dispute -> DB model, evidence is saved apart
+func ConvertDisputeDomainToModel(d *domain.Dispute) *models.DisputeModel {
+	return &models.DisputeModel{
+		ID:            d.ID,
+		OrderID:       d.OrderID,
+		PaymentID:     d.PaymentID,
+		ChargebackID:  d.ChargebackID,
+		Amount:        d.Amount,
+		Currency:      d.Currency,
+		Reason:        d.Reason,
+		Status:        d.Status,
+		EvidenceDueAt: d.EvidenceDueAt,
+		ResolvedAt:    d.ResolvedAt,
+		ResolvedBy:    d.ResolvedBy,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+	}
+}
+
+// DB model -> domain.Dispute
+func ConvertDisputeModelToDomain(d *models.DisputeModel) *domain.Dispute {
+	evidence := make([]domain.DisputeEvidence, len(d.Evidence))
+	for i, e := range d.Evidence {
+		evidence[i] = *ConvertDisputeEvidenceModelToDomain(&e)
+	}
+
+	return &domain.Dispute{
+		ID:            d.ID,
+		OrderID:       d.OrderID,
+		PaymentID:     d.PaymentID,
+		ChargebackID:  d.ChargebackID,
+		Amount:        d.Amount,
+		Currency:      d.Currency,
+		Reason:        d.Reason,
+		Status:        d.Status,
+		EvidenceDueAt: d.EvidenceDueAt,
+		ResolvedAt:    d.ResolvedAt,
+		ResolvedBy:    d.ResolvedBy,
+		CreatedAt:     d.CreatedAt,
+		UpdatedAt:     d.UpdatedAt,
+		Evidence:      evidence,
+	}
+}
+
+// DB models -> domain.Disputes
+func ConvertDisputeModelsToDomains(disputes []*models.DisputeModel) []*domain.Dispute {
+	disputesDomain := make([]*domain.Dispute, len(disputes))
+	for i, d := range disputes {
+		disputesDomain[i] = ConvertDisputeModelToDomain(d)
+	}
+	return disputesDomain
+}
+
+// domain.DisputeEvidence -> DB model
+func ConvertDisputeEvidenceDomainToModel(e *domain.DisputeEvidence) *models.DisputeEvidenceModel {
+	return &models.DisputeEvidenceModel{
+		ID:        e.ID,
+		DisputeID: e.DisputeID,
+		AuthorID:  e.AuthorID,
+		Note:      e.Note,
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// DB model -> domain.DisputeEvidence
+func ConvertDisputeEvidenceModelToDomain(e *models.DisputeEvidenceModel) *domain.DisputeEvidence {
+	return &domain.DisputeEvidence{
+		ID:        e.ID,
+		DisputeID: e.DisputeID,
+		AuthorID:  e.AuthorID,
+		Note:      e.Note,
+		CreatedAt: e.CreatedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package database_dtos
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// helper func, soft deleted models -> nil if the row isn't archived
+func deletedAt(d gorm.DeletedAt) *time.Time {
+	if !d.Valid {
+		return nil
+	}
+	return &d.Time
+}
@@ -0,0 +1,57 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.QueuedNotification -> DB model
+func ConvertNotificationDomainToModel(n *domain.QueuedNotification) *models.NotificationModel {
+	return &models.NotificationModel{
+		ID:            n.ID,
+		Kind:          n.Notification.Kind,
+		UserID:        n.Notification.UserID,
+		To:            n.Notification.To,
+		Name:          n.Notification.Name,
+		Locale:        n.Notification.Locale,
+		Data:          n.Notification.Data,
+		Status:        n.Status,
+		Attempts:      n.Attempts,
+		NextAttemptAt: n.NextAttemptAt,
+		LastError:     n.LastError,
+		SentAt:        n.SentAt,
+		CreatedAt:     n.CreatedAt,
+		UpdatedAt:     n.UpdatedAt,
+	}
+}
+
+// DB model -> domain.QueuedNotification
+func ConvertNotificationModelToDomain(n *models.NotificationModel) *domain.QueuedNotification {
+	return &domain.QueuedNotification{
+		ID: n.ID,
+		Notification: domain.Notification{
+			Kind:   n.Kind,
+			UserID: n.UserID,
+			To:     n.To,
+			Name:   n.Name,
+			Locale: n.Locale,
+			Data:   n.Data,
+		},
+		Status:        n.Status,
+		Attempts:      n.Attempts,
+		NextAttemptAt: n.NextAttemptAt,
+		LastError:     n.LastError,
+		SentAt:        n.SentAt,
+		CreatedAt:     n.CreatedAt,
+		UpdatedAt:     n.UpdatedAt,
+	}
+}
+
+// DB models -> domain.QueuedNotifications
+func ConvertNotificationModelsToDomains(notifications []models.NotificationModel) []*domain.QueuedNotification {
+	result := make([]*domain.QueuedNotification, 0, len(notifications))
+	for i := range notifications {
+		result = append(result, ConvertNotificationModelToDomain(&notifications[i]))
+	}
+	return result
+}
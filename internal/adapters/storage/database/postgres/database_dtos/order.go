@@ -10,39 +10,53 @@ func ConvertOrderDomainToModel(o *domain.Order) *models.OrderModel {
 	items := make([]models.OrderProductModel, len(o.Items))
 	for i, item := range o.Items {
 		items[i] = models.OrderProductModel{
-			ID:        item.ID,
-			OrderID:   o.ID,
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			CreatedAt: item.CreatedAt,
-			UpdatedAt: item.UpdatedAt,
+			ID:           item.ID,
+			OrderID:      o.ID,
+			ProductID:    item.ProductID,
+			VariantID:    item.VariantID,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			UnitDiscount: item.UnitDiscount,
+			CreatedAt:    item.CreatedAt,
+			UpdatedAt:    item.UpdatedAt,
 		}
 	}
 
 	return &models.OrderModel{
-		ID:                o.ID,
-		Providers:         o.Providers,
-		UserID:            o.UserID,
-		PaymentID:         o.PaymentID,
-		SecureToken:       o.SecureToken,
-		ExternalReference: o.ExternalReference,
-		Currency:          o.Currency,
-		SubTotal:          o.SubTotal,
-		Discount:          o.Discount,
-		Total:             o.Total,
-		Paid:              o.Paid,
-		Fee:               o.Fee,
-		Installments:      o.Installments,
-		PayMethod:         o.PayMethod,
-		PayResource:       o.PayResource,
-		NetReceivedAmount: o.NetReceivedAmount,
-		PayStatus:         o.PayStatus,
-		PayStatusDetail:   o.PayStatusDetail,
-		CreatedAt:         o.CreatedAt,
-		UpdatedAt:         o.UpdatedAt,
-		ExpiresAt:         o.ExpiresAt,
-		PaidAt:            o.PaidAt,
-		Items:             items,
+		ID:                     o.ID,
+		Providers:              o.Providers,
+		UserID:                 o.UserID,
+		PaymentID:              o.PaymentID,
+		SecureToken:            o.SecureToken,
+		ExternalReference:      o.ExternalReference,
+		Currency:               o.Currency,
+		SubTotal:               o.SubTotal,
+		Discount:               o.Discount,
+		Total:                  o.Total,
+		Paid:                   o.Paid,
+		Fee:                    o.Fee,
+		Installments:           o.Installments,
+		PayMethod:              o.PayMethod,
+		PayResource:            o.PayResource,
+		NetReceivedAmount:      o.NetReceivedAmount,
+		PayStatus:              o.PayStatus,
+		PayStatusDetail:        o.PayStatusDetail,
+		CreatedAt:              o.CreatedAt,
+		UpdatedAt:              o.UpdatedAt,
+		ExpiresAt:              o.ExpiresAt,
+		PaidAt:                 o.PaidAt,
+		CancelledAt:            o.CancelledAt,
+		CancelledBy:            o.CancelledBy,
+		CancelReason:           o.CancelReason,
+		AuthorizedAt:           o.AuthorizedAt,
+		AuthorizationExpiresAt: o.AuthorizationExpiresAt,
+		AuthorizationAlertedAt: o.AuthorizationAlertedAt,
+		Disputed:               o.Disputed,
+		ShippedAt:              o.ShippedAt,
+		ShippedBy:              o.ShippedBy,
+		Carrier:                o.Carrier,
+		TrackingNumber:         o.TrackingNumber,
+		Items:                  items,
 	}
 }
 
@@ -55,41 +69,55 @@ func ConvertOrdersDomainToModels(orders []*domain.Order) []*models.OrderModel {
 		items = make([]models.OrderProductModel, len(o.Items))
 		for i, item := range o.Items {
 			items[i] = models.OrderProductModel{
-				ID:        item.ID,
-				OrderID:   o.ID,
-				ProductID: item.ProductID,
-				Quantity:  item.Quantity,
-				CreatedAt: item.CreatedAt,
-				UpdatedAt: item.UpdatedAt,
+				ID:           item.ID,
+				OrderID:      o.ID,
+				ProductID:    item.ProductID,
+				VariantID:    item.VariantID,
+				Quantity:     item.Quantity,
+				UnitPrice:    item.UnitPrice,
+				UnitDiscount: item.UnitDiscount,
+				CreatedAt:    item.CreatedAt,
+				UpdatedAt:    item.UpdatedAt,
 			}
 		}
 	}
 
 	for _, o := range orders {
 		ordersModels = append(ordersModels, &models.OrderModel{
-			ID:                o.ID,
-			Providers:         o.Providers,
-			UserID:            o.UserID,
-			PaymentID:         o.PaymentID,
-			SecureToken:       o.SecureToken,
-			ExternalReference: o.ExternalReference,
-			Currency:          o.Currency,
-			SubTotal:          o.SubTotal,
-			Discount:          o.Discount,
-			Total:             o.Total,
-			Paid:              o.Paid,
-			Fee:               o.Fee,
-			Installments:      o.Installments,
-			PayMethod:         o.PayMethod,
-			PayResource:       o.PayResource,
-			NetReceivedAmount: o.NetReceivedAmount,
-			PayStatus:         o.PayStatus,
-			PayStatusDetail:   o.PayStatusDetail,
-			CreatedAt:         o.CreatedAt,
-			UpdatedAt:         o.UpdatedAt,
-			ExpiresAt:         o.ExpiresAt,
-			PaidAt:            o.PaidAt,
-			Items:             items,
+			ID:                     o.ID,
+			Providers:              o.Providers,
+			UserID:                 o.UserID,
+			PaymentID:              o.PaymentID,
+			SecureToken:            o.SecureToken,
+			ExternalReference:      o.ExternalReference,
+			Currency:               o.Currency,
+			SubTotal:               o.SubTotal,
+			Discount:               o.Discount,
+			Total:                  o.Total,
+			Paid:                   o.Paid,
+			Fee:                    o.Fee,
+			Installments:           o.Installments,
+			PayMethod:              o.PayMethod,
+			PayResource:            o.PayResource,
+			NetReceivedAmount:      o.NetReceivedAmount,
+			PayStatus:              o.PayStatus,
+			PayStatusDetail:        o.PayStatusDetail,
+			CreatedAt:              o.CreatedAt,
+			UpdatedAt:              o.UpdatedAt,
+			ExpiresAt:              o.ExpiresAt,
+			PaidAt:                 o.PaidAt,
+			CancelledAt:            o.CancelledAt,
+			CancelledBy:            o.CancelledBy,
+			CancelReason:           o.CancelReason,
+			AuthorizedAt:           o.AuthorizedAt,
+			AuthorizationExpiresAt: o.AuthorizationExpiresAt,
+			AuthorizationAlertedAt: o.AuthorizationAlertedAt,
+			Disputed:               o.Disputed,
+			ShippedAt:              o.ShippedAt,
+			ShippedBy:              o.ShippedBy,
+			Carrier:                o.Carrier,
+			TrackingNumber:         o.TrackingNumber,
+			Items:                  items,
 		})
 	}
 
@@ -101,39 +129,53 @@ func ConvertOrderModelToDomain(o *models.OrderModel) *domain.Order {
 	items := make([]domain.OrderProduct, len(o.Items))
 	for i, item := range o.Items {
 		items[i] = domain.OrderProduct{
-			ID:        item.ID,
-			OrderID:   item.OrderID,
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-			CreatedAt: item.CreatedAt,
-			UpdatedAt: item.UpdatedAt,
+			ID:           item.ID,
+			OrderID:      item.OrderID,
+			ProductID:    item.ProductID,
+			VariantID:    item.VariantID,
+			Quantity:     item.Quantity,
+			UnitPrice:    item.UnitPrice,
+			UnitDiscount: item.UnitDiscount,
+			CreatedAt:    item.CreatedAt,
+			UpdatedAt:    item.UpdatedAt,
 		}
 	}
 
 	return &domain.Order{
-		ID:                o.ID,
-		Providers:         o.Providers,
-		UserID:            o.UserID,
-		PaymentID:         o.PaymentID,
-		SecureToken:       o.SecureToken,
-		ExternalReference: o.ExternalReference,
-		Currency:          o.Currency,
-		SubTotal:          o.SubTotal,
-		Discount:          o.Discount,
-		Total:             o.Total,
-		Paid:              o.Paid,
-		Fee:               o.Fee,
-		Installments:      o.Installments,
-		PayMethod:         o.PayMethod,
-		PayResource:       o.PayResource,
-		NetReceivedAmount: o.NetReceivedAmount,
-		PayStatus:         o.PayStatus,
-		PayStatusDetail:   o.PayStatusDetail,
-		CreatedAt:         o.CreatedAt,
-		UpdatedAt:         o.UpdatedAt,
-		ExpiresAt:         o.ExpiresAt,
-		PaidAt:            o.PaidAt,
-		Items:             items,
+		ID:                     o.ID,
+		Providers:              o.Providers,
+		UserID:                 o.UserID,
+		PaymentID:              o.PaymentID,
+		SecureToken:            o.SecureToken,
+		ExternalReference:      o.ExternalReference,
+		Currency:               o.Currency,
+		SubTotal:               o.SubTotal,
+		Discount:               o.Discount,
+		Total:                  o.Total,
+		Paid:                   o.Paid,
+		Fee:                    o.Fee,
+		Installments:           o.Installments,
+		PayMethod:              o.PayMethod,
+		PayResource:            o.PayResource,
+		NetReceivedAmount:      o.NetReceivedAmount,
+		PayStatus:              o.PayStatus,
+		PayStatusDetail:        o.PayStatusDetail,
+		CreatedAt:              o.CreatedAt,
+		UpdatedAt:              o.UpdatedAt,
+		ExpiresAt:              o.ExpiresAt,
+		PaidAt:                 o.PaidAt,
+		CancelledAt:            o.CancelledAt,
+		CancelledBy:            o.CancelledBy,
+		CancelReason:           o.CancelReason,
+		AuthorizedAt:           o.AuthorizedAt,
+		AuthorizationExpiresAt: o.AuthorizationExpiresAt,
+		AuthorizationAlertedAt: o.AuthorizationAlertedAt,
+		Disputed:               o.Disputed,
+		ShippedAt:              o.ShippedAt,
+		ShippedBy:              o.ShippedBy,
+		Carrier:                o.Carrier,
+		TrackingNumber:         o.TrackingNumber,
+		Items:                  items,
 	}
 }
 
@@ -146,41 +188,55 @@ func ConvertOrdersModelsToDomain(orders []*models.OrderModel) []*domain.Order {
 		items = make([]domain.OrderProduct, len(orders.Items))
 		for i, item := range orders.Items {
 			items[i] = domain.OrderProduct{
-				ID:        item.ID,
-				OrderID:   item.OrderID,
-				ProductID: item.ProductID,
-				Quantity:  item.Quantity,
-				CreatedAt: item.CreatedAt,
-				UpdatedAt: item.UpdatedAt,
+				ID:           item.ID,
+				OrderID:      item.OrderID,
+				ProductID:    item.ProductID,
+				VariantID:    item.VariantID,
+				Quantity:     item.Quantity,
+				UnitPrice:    item.UnitPrice,
+				UnitDiscount: item.UnitDiscount,
+				CreatedAt:    item.CreatedAt,
+				UpdatedAt:    item.UpdatedAt,
 			}
 		}
 	}
 
 	for _, o := range orders {
 		ordersDomain = append(ordersDomain, &domain.Order{
-			ID:                o.ID,
-			Providers:         o.Providers,
-			UserID:            o.UserID,
-			PaymentID:         o.PaymentID,
-			SecureToken:       o.SecureToken,
-			ExternalReference: o.ExternalReference,
-			Currency:          o.Currency,
-			SubTotal:          o.SubTotal,
-			Discount:          o.Discount,
-			Total:             o.Total,
-			Paid:              o.Paid,
-			Fee:               o.Fee,
-			Installments:      o.Installments,
-			PayMethod:         o.PayMethod,
-			PayResource:       o.PayResource,
-			NetReceivedAmount: o.NetReceivedAmount,
-			PayStatus:         o.PayStatus,
-			PayStatusDetail:   o.PayStatusDetail,
-			CreatedAt:         o.CreatedAt,
-			UpdatedAt:         o.UpdatedAt,
-			ExpiresAt:         o.ExpiresAt,
-			PaidAt:            o.PaidAt,
-			Items:             items,
+			ID:                     o.ID,
+			Providers:              o.Providers,
+			UserID:                 o.UserID,
+			PaymentID:              o.PaymentID,
+			SecureToken:            o.SecureToken,
+			ExternalReference:      o.ExternalReference,
+			Currency:               o.Currency,
+			SubTotal:               o.SubTotal,
+			Discount:               o.Discount,
+			Total:                  o.Total,
+			Paid:                   o.Paid,
+			Fee:                    o.Fee,
+			Installments:           o.Installments,
+			PayMethod:              o.PayMethod,
+			PayResource:            o.PayResource,
+			NetReceivedAmount:      o.NetReceivedAmount,
+			PayStatus:              o.PayStatus,
+			PayStatusDetail:        o.PayStatusDetail,
+			CreatedAt:              o.CreatedAt,
+			UpdatedAt:              o.UpdatedAt,
+			ExpiresAt:              o.ExpiresAt,
+			PaidAt:                 o.PaidAt,
+			CancelledAt:            o.CancelledAt,
+			CancelledBy:            o.CancelledBy,
+			CancelReason:           o.CancelReason,
+			AuthorizedAt:           o.AuthorizedAt,
+			AuthorizationExpiresAt: o.AuthorizationExpiresAt,
+			AuthorizationAlertedAt: o.AuthorizationAlertedAt,
+			Disputed:               o.Disputed,
+			ShippedAt:              o.ShippedAt,
+			ShippedBy:              o.ShippedBy,
+			Carrier:                o.Carrier,
+			TrackingNumber:         o.TrackingNumber,
+			Items:                  items,
 		})
 	}
 
@@ -8,12 +8,15 @@ import (
 // domain.OrderProduct -> DB model
 func ConvertOrderProductDomainToModel(op *domain.OrderProduct) *models.OrderProductModel {
 	return &models.OrderProductModel{
-		ID:        op.ID,
-		OrderID:   op.OrderID,
-		ProductID: op.ProductID,
-		Quantity:  op.Quantity,
-		CreatedAt: op.CreatedAt,
-		UpdatedAt: op.UpdatedAt,
+		ID:           op.ID,
+		OrderID:      op.OrderID,
+		ProductID:    op.ProductID,
+		VariantID:    op.VariantID,
+		Quantity:     op.Quantity,
+		UnitPrice:    op.UnitPrice,
+		UnitDiscount: op.UnitDiscount,
+		CreatedAt:    op.CreatedAt,
+		UpdatedAt:    op.UpdatedAt,
 	}
 }
 
@@ -23,12 +26,15 @@ func ConvertOrderProductsDomainToModels(orderProducts []*domain.OrderProduct) []
 
 	for _, op := range orderProducts {
 		orderProductsModels = append(orderProductsModels, &models.OrderProductModel{
-			ID:        op.ID,
-			OrderID:   op.OrderID,
-			ProductID: op.ProductID,
-			Quantity:  op.Quantity,
-			CreatedAt: op.CreatedAt,
-			UpdatedAt: op.UpdatedAt,
+			ID:           op.ID,
+			OrderID:      op.OrderID,
+			ProductID:    op.ProductID,
+			VariantID:    op.VariantID,
+			Quantity:     op.Quantity,
+			UnitPrice:    op.UnitPrice,
+			UnitDiscount: op.UnitDiscount,
+			CreatedAt:    op.CreatedAt,
+			UpdatedAt:    op.UpdatedAt,
 		})
 	}
 
@@ -38,12 +44,15 @@ func ConvertOrderProductsDomainToModels(orderProducts []*domain.OrderProduct) []
 // DB model -> domain.OrderProduct
 func ConvertOrderProductModelToDomain(op *models.OrderProductModel) *domain.OrderProduct {
 	return &domain.OrderProduct{
-		ID:        op.ID,
-		OrderID:   op.OrderID,
-		ProductID: op.ProductID,
-		Quantity:  op.Quantity,
-		CreatedAt: op.CreatedAt,
-		UpdatedAt: op.UpdatedAt,
+		ID:           op.ID,
+		OrderID:      op.OrderID,
+		ProductID:    op.ProductID,
+		VariantID:    op.VariantID,
+		Quantity:     op.Quantity,
+		UnitPrice:    op.UnitPrice,
+		UnitDiscount: op.UnitDiscount,
+		CreatedAt:    op.CreatedAt,
+		UpdatedAt:    op.UpdatedAt,
 	}
 }
 
@@ -53,12 +62,15 @@ func ConvertOrderProductModelsToDomains(orderProducts []*models.OrderProductMode
 
 	for _, op := range orderProducts {
 		orderProductsDomain = append(orderProductsDomain, &domain.OrderProduct{
-			ID:        op.ID,
-			OrderID:   op.OrderID,
-			ProductID: op.ProductID,
-			Quantity:  op.Quantity,
-			CreatedAt: op.CreatedAt,
-			UpdatedAt: op.UpdatedAt,
+			ID:           op.ID,
+			OrderID:      op.OrderID,
+			ProductID:    op.ProductID,
+			VariantID:    op.VariantID,
+			Quantity:     op.Quantity,
+			UnitPrice:    op.UnitPrice,
+			UnitDiscount: op.UnitDiscount,
+			CreatedAt:    op.CreatedAt,
+			UpdatedAt:    op.UpdatedAt,
 		})
 	}
 
@@ -0,0 +1,67 @@
+package database_dtos
+
+import (
+	"encoding/json"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.PaymentAttempt -> DB model
+func ConvertPaymentAttemptDomainToModel(a *domain.PaymentAttempt) *models.PaymentAttemptModel {
+	var raw *string
+	if len(a.RawPayload) > 0 {
+		payload := string(a.RawPayload)
+		raw = &payload
+	}
+
+	return &models.PaymentAttemptModel{
+		ID:                a.ID,
+		OrderID:           a.OrderID,
+		PaymentID:         a.PaymentID,
+		Status:            a.Status,
+		StatusDetail:      a.StatusDetail,
+		Amount:            a.Amount,
+		NetReceivedAmount: a.NetReceivedAmount,
+		Currency:          a.Currency,
+		PayMethod:         a.PayMethod,
+		PayResource:       a.PayResource,
+		Installments:      a.Installments,
+		RawPayload:        raw,
+		CreatedAt:         a.CreatedAt,
+		UpdatedAt:         a.UpdatedAt,
+	}
+}
+
+// DB model -> domain.PaymentAttempt
+func ConvertPaymentAttemptModelToDomain(a *models.PaymentAttemptModel) *domain.PaymentAttempt {
+	var raw json.RawMessage
+	if a.RawPayload != nil {
+		raw = json.RawMessage(*a.RawPayload)
+	}
+
+	return &domain.PaymentAttempt{
+		ID:                a.ID,
+		OrderID:           a.OrderID,
+		PaymentID:         a.PaymentID,
+		Status:            a.Status,
+		StatusDetail:      a.StatusDetail,
+		Amount:            a.Amount,
+		NetReceivedAmount: a.NetReceivedAmount,
+		Currency:          a.Currency,
+		PayMethod:         a.PayMethod,
+		PayResource:       a.PayResource,
+		Installments:      a.Installments,
+		RawPayload:        raw,
+		CreatedAt:         a.CreatedAt,
+		UpdatedAt:         a.UpdatedAt,
+	}
+}
+
+// DB models -> domain.PaymentAttempts
+func ConvertPaymentAttemptModelsToDomains(attempts []*models.PaymentAttemptModel) []*domain.PaymentAttempt {
+	attemptsDomain := make([]*domain.PaymentAttempt, len(attempts))
+	for i, a := range attempts {
+		attemptsDomain[i] = ConvertPaymentAttemptModelToDomain(a)
+	}
+	return attemptsDomain
+}
@@ -0,0 +1,114 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.PriceChange -> DB model
+func ConvertPriceChangeDomainToModel(c *domain.PriceChange) *models.ProductPriceHistoryModel {
+	return &models.ProductPriceHistoryModel{
+		ID:              c.ID,
+		ProductID:       c.ProductID,
+		OldPrice:        c.OldPrice,
+		NewPrice:        c.NewPrice,
+		OldDiscount:     c.OldDiscount,
+		NewDiscount:     c.NewDiscount,
+		OldDiscountType: discountType(c.OldDiscountType),
+		NewDiscountType: discountType(c.NewDiscountType),
+		ChangedBy:       c.ChangedBy,
+		ScheduleID:      c.ScheduleID,
+		CreatedAt:       c.CreatedAt,
+	}
+}
+
+// DB model -> domain.PriceChange
+func ConvertPriceChangeModelToDomain(c *models.ProductPriceHistoryModel) *domain.PriceChange {
+	return &domain.PriceChange{
+		ID:              c.ID,
+		ProductID:       c.ProductID,
+		OldPrice:        c.OldPrice,
+		NewPrice:        c.NewPrice,
+		OldDiscount:     c.OldDiscount,
+		NewDiscount:     c.NewDiscount,
+		OldDiscountType: fromDiscountType(c.OldDiscountType),
+		NewDiscountType: fromDiscountType(c.NewDiscountType),
+		ChangedBy:       c.ChangedBy,
+		ScheduleID:      c.ScheduleID,
+		CreatedAt:       c.CreatedAt,
+	}
+}
+
+// DB models -> domain.PriceChanges
+func ConvertPriceChangeModelsToDomains(changes []models.ProductPriceHistoryModel) []domain.PriceChange {
+	changesDomain := make([]domain.PriceChange, 0, len(changes))
+	for i := range changes {
+		changesDomain = append(changesDomain, *ConvertPriceChangeModelToDomain(&changes[i]))
+	}
+	return changesDomain
+}
+
+// domain.PriceSchedule -> DB model
+func ConvertPriceScheduleDomainToModel(s *domain.PriceSchedule) *models.PriceScheduleModel {
+	schedule := &models.PriceScheduleModel{
+		ID:           s.ID,
+		ProductID:    s.ProductID,
+		Price:        s.Price,
+		Discount:     s.Discount,
+		DiscountType: s.DiscountType,
+		StartsAt:     s.StartsAt,
+		EndsAt:       s.EndsAt,
+		Status:       s.Status,
+		CreatedBy:    s.CreatedBy,
+		AppliedAt:    s.AppliedAt,
+		EndedAt:      s.EndedAt,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+
+	if s.Previous != nil {
+		schedule.PreviousPrice = &s.Previous.Price
+		schedule.PreviousDiscount = &s.Previous.Discount
+		schedule.PreviousDiscountType = discountType(s.Previous.DiscountType)
+	}
+	return schedule
+}
+
+// DB model -> domain.PriceSchedule
+func ConvertPriceScheduleModelToDomain(s *models.PriceScheduleModel) *domain.PriceSchedule {
+	schedule := &domain.PriceSchedule{
+		ID:           s.ID,
+		ProductID:    s.ProductID,
+		Price:        s.Price,
+		Discount:     s.Discount,
+		DiscountType: s.DiscountType,
+		StartsAt:     s.StartsAt,
+		EndsAt:       s.EndsAt,
+		Status:       s.Status,
+		CreatedBy:    s.CreatedBy,
+		AppliedAt:    s.AppliedAt,
+		EndedAt:      s.EndedAt,
+		CreatedAt:    s.CreatedAt,
+		UpdatedAt:    s.UpdatedAt,
+	}
+
+	if s.PreviousPrice != nil {
+		schedule.Previous = &domain.Pricing{
+			Price:        *s.PreviousPrice,
+			DiscountType: fromDiscountType(s.PreviousDiscountType),
+		}
+		if s.PreviousDiscount != nil {
+			schedule.Previous.Discount = *s.PreviousDiscount
+		}
+	}
+	return schedule
+}
+
+// DB models -> domain.PriceSchedules
+func ConvertPriceScheduleModelsToDomains(schedules []models.PriceScheduleModel) []*domain.PriceSchedule {
+	schedulesDomain := make([]*domain.PriceSchedule, 0, len(schedules))
+	for i := range schedules {
+		schedulesDomain = append(schedulesDomain, ConvertPriceScheduleModelToDomain(&schedules[i]))
+	}
+	return schedulesDomain
+}
@@ -8,15 +8,21 @@ import (
 // domain.User -> DB model
 func ConvertProductDomainToModel(p *domain.Product) *models.ProductModel {
 	return &models.ProductModel{
-		ID:         p.ID,
-		Name:       p.Name,
-		SKU:        p.SKU,
-		Stock:      p.Stock,
-		Price:      p.Price,
-		Image:      p.Image,
-		CreatedAt:  p.CreatedAt,
-		UpdatedAt:  p.UpdatedAt,
-		CategoryID: p.CategoryID,
+		ID:           p.ID,
+		Name:         p.Name,
+		SKU:          p.SKU,
+		Slug:         p.Slug,
+		Stock:        p.Stock,
+		Price:        p.Price,
+		Discount:     p.Disscount,
+		DiscountType: discountType(p.DisscountType),
+		Image:        p.Image,
+		CreatedAt:    p.CreatedAt,
+		UpdatedAt:    p.UpdatedAt,
+		CategoryID:   p.CategoryID,
+
+		// the low stock alert is only set by the stock alerts
+		LowStockThreshold: p.LowStockThreshold,
 	}
 }
 
@@ -26,15 +32,20 @@ func ConvertProductsDomainToModels(products []*domain.Product) []*models.Product
 
 	for _, p := range products {
 		productsModels = append(productsModels, &models.ProductModel{
-			ID:         p.ID,
-			Name:       p.Name,
-			SKU:        p.SKU,
-			Stock:      p.Stock,
-			Price:      p.Price,
-			Image:      p.Image,
-			CreatedAt:  p.CreatedAt,
-			UpdatedAt:  p.UpdatedAt,
-			CategoryID: p.CategoryID,
+			ID:           p.ID,
+			Name:         p.Name,
+			SKU:          p.SKU,
+			Slug:         p.Slug,
+			Stock:        p.Stock,
+			Price:        p.Price,
+			Discount:     p.Disscount,
+			DiscountType: discountType(p.DisscountType),
+			Image:        p.Image,
+			CreatedAt:    p.CreatedAt,
+			UpdatedAt:    p.UpdatedAt,
+			CategoryID:   p.CategoryID,
+
+			LowStockThreshold: p.LowStockThreshold,
 		})
 	}
 
@@ -44,15 +55,24 @@ func ConvertProductsDomainToModels(products []*domain.Product) []*models.Product
 // DB model -> domain.User
 func ConvertProductModelToDomain(p *models.ProductModel) *domain.Product {
 	return &domain.Product{
-		ID:         p.ID,
-		Name:       p.Name,
-		SKU:        p.SKU,
-		Stock:      p.Stock,
-		Price:      p.Price,
-		Image:      p.Image,
-		CreatedAt:  p.CreatedAt,
-		UpdatedAt:  p.UpdatedAt,
-		CategoryID: p.CategoryID,
+		ID:            p.ID,
+		Name:          p.Name,
+		SKU:           p.SKU,
+		Slug:          p.Slug,
+		Stock:         p.Stock,
+		Price:         p.Price,
+		Disscount:     p.Discount,
+		DisscountType: fromDiscountType(p.DiscountType),
+		Image:         p.Image,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+		DeletedAt:     deletedAt(p.DeletedAt),
+		CategoryID:    p.CategoryID,
+		Variants:      ConvertVariantModelsToDomains(p.Variants),
+		Images:        ConvertProductImageModelsToDomains(p.Images),
+
+		LowStockThreshold: p.LowStockThreshold,
+		LowStockAlertedAt: p.LowStockAlertedAt,
 	}
 }
 
@@ -62,17 +82,41 @@ func ConvertProductsModelsToDomain(products []*models.ProductModel) []*domain.Pr
 
 	for _, p := range products {
 		productsDomain = append(productsDomain, &domain.Product{
-			ID:         p.ID,
-			Name:       p.Name,
-			SKU:        p.SKU,
-			Stock:      p.Stock,
-			Price:      p.Price,
-			Image:      p.Image,
-			CreatedAt:  p.CreatedAt,
-			UpdatedAt:  p.UpdatedAt,
-			CategoryID: p.CategoryID,
+			ID:            p.ID,
+			Name:          p.Name,
+			SKU:           p.SKU,
+			Slug:          p.Slug,
+			Stock:         p.Stock,
+			Price:         p.Price,
+			Disscount:     p.Discount,
+			DisscountType: fromDiscountType(p.DiscountType),
+			Image:         p.Image,
+			CreatedAt:     p.CreatedAt,
+			UpdatedAt:     p.UpdatedAt,
+			DeletedAt:     deletedAt(p.DeletedAt),
+			CategoryID:    p.CategoryID,
+			Variants:      ConvertVariantModelsToDomains(p.Variants),
+			Images:        ConvertProductImageModelsToDomains(p.Images),
+
+			LowStockThreshold: p.LowStockThreshold,
+			LowStockAlertedAt: p.LowStockAlertedAt,
 		})
 	}
 
 	return productsDomain
 }
+
+// helper func, products without discount keep a null type
+func discountType(t domain.DisscountTypes) *domain.DisscountTypes {
+	if t == "" {
+		return nil
+	}
+	return &t
+}
+
+func fromDiscountType(t *domain.DisscountTypes) domain.DisscountTypes {
+	if t == nil {
+		return ""
+	}
+	return *t
+}
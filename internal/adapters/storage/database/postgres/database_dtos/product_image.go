@@ -0,0 +1,46 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.ProductImage -> DB model
+func ConvertProductImageDomainToModel(pi *domain.ProductImage) *models.ProductImageModel {
+	return &models.ProductImageModel{
+		ID:           pi.ID,
+		ProductID:    pi.ProductID,
+		Key:          pi.Key,
+		URL:          pi.URL,
+		ThumbnailKey: pi.ThumbnailKey,
+		ThumbnailURL: pi.ThumbnailURL,
+		ContentType:  pi.ContentType,
+		Size:         pi.Size,
+		Position:     pi.Position,
+		IsPrimary:    pi.IsPrimary,
+		CreatedAt:    pi.CreatedAt,
+		UpdatedAt:    pi.UpdatedAt,
+	}
+}
+
+// DB models -> domain.ProductImages
+func ConvertProductImageModelsToDomains(images []models.ProductImageModel) []domain.ProductImage {
+	imagesDomain := make([]domain.ProductImage, 0, len(images))
+	for _, pi := range images {
+		imagesDomain = append(imagesDomain, domain.ProductImage{
+			ID:           pi.ID,
+			ProductID:    pi.ProductID,
+			Key:          pi.Key,
+			URL:          pi.URL,
+			ThumbnailKey: pi.ThumbnailKey,
+			ThumbnailURL: pi.ThumbnailURL,
+			ContentType:  pi.ContentType,
+			Size:         pi.Size,
+			Position:     pi.Position,
+			IsPrimary:    pi.IsPrimary,
+			CreatedAt:    pi.CreatedAt,
+			UpdatedAt:    pi.UpdatedAt,
+		})
+	}
+	return imagesDomain
+}
@@ -0,0 +1,35 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.StockAlertSubscription -> DB model
+func ConvertStockAlertSubscriptionDomainToModel(s *domain.StockAlertSubscription) *models.StockAlertSubscriptionModel {
+	return &models.StockAlertSubscriptionModel{
+		ID:        s.ID,
+		ProductID: s.ProductID,
+		UserID:    s.UserID,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+// DB model -> domain.StockAlertSubscription
+func ConvertStockAlertSubscriptionModelToDomain(s *models.StockAlertSubscriptionModel) *domain.StockAlertSubscription {
+	return &domain.StockAlertSubscription{
+		ID:        s.ID,
+		ProductID: s.ProductID,
+		UserID:    s.UserID,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+// DB models -> domain.StockAlertSubscriptions
+func ConvertStockAlertSubscriptionModelsToDomains(subscriptions []models.StockAlertSubscriptionModel) []domain.StockAlertSubscription {
+	result := make([]domain.StockAlertSubscription, 0, len(subscriptions))
+	for i := range subscriptions {
+		result = append(result, *ConvertStockAlertSubscriptionModelToDomain(&subscriptions[i]))
+	}
+	return result
+}
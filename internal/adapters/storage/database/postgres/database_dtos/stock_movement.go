@@ -0,0 +1,49 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.StockMovement -> DB model
+func ConvertStockMovementDomainToModel(m *domain.StockMovement) *models.StockMovementModel {
+	return &models.StockMovementModel{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		WarehouseID: m.WarehouseID,
+		Type:        m.Type,
+		Quantity:    m.Quantity,
+		Delta:       m.Delta,
+		Balance:     m.Balance,
+		Reason:      m.Reason,
+		ActorID:     m.ActorID,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// DB model -> domain.StockMovement
+func ConvertStockMovementModelToDomain(m *models.StockMovementModel) *domain.StockMovement {
+	return &domain.StockMovement{
+		ID:          m.ID,
+		ProductID:   m.ProductID,
+		VariantID:   m.VariantID,
+		WarehouseID: m.WarehouseID,
+		Type:        m.Type,
+		Quantity:    m.Quantity,
+		Delta:       m.Delta,
+		Balance:     m.Balance,
+		Reason:      m.Reason,
+		ActorID:     m.ActorID,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// []DB model -> []domain.StockMovement
+func ConvertStockMovementModelsToDomains(movements []models.StockMovementModel) []domain.StockMovement {
+	result := make([]domain.StockMovement, 0, len(movements))
+	for i := range movements {
+		result = append(result, *ConvertStockMovementModelToDomain(&movements[i]))
+	}
+	return result
+}
@@ -13,6 +13,7 @@ func CovertToDBUser(u *domain.User) *models.UserModel {
 		Email:     u.Email,
 		Password:  u.Password,
 		Role:      u.Role,
+		Locale:    u.Locale,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
 	}
@@ -29,6 +30,7 @@ func CovertToDBUsers(users []*domain.User) []*models.UserModel {
 			Email:     u.Email,
 			Password:  u.Password,
 			Role:      u.Role,
+			Locale:    u.Locale,
 			CreatedAt: u.CreatedAt,
 			UpdatedAt: u.UpdatedAt,
 		})
@@ -45,8 +47,10 @@ func CovertToDomainUser(u *models.UserModel) *domain.User {
 		Email:     u.Email,
 		Password:  u.Password,
 		Role:      u.Role,
+		Locale:    u.Locale,
 		CreatedAt: u.CreatedAt,
 		UpdatedAt: u.UpdatedAt,
+		DeletedAt: deletedAt(u.DeletedAt),
 	}
 }
 
@@ -61,8 +65,10 @@ func CovertToDomainUsers(users []*models.UserModel) []*domain.User {
 			Email:     u.Email,
 			Password:  u.Password,
 			Role:      u.Role,
+			Locale:    u.Locale,
 			CreatedAt: u.CreatedAt,
 			UpdatedAt: u.UpdatedAt,
+			DeletedAt: deletedAt(u.DeletedAt),
 		})
 	}
 
@@ -0,0 +1,43 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+)
+
+// domain.Variant -> DB model
+func ConvertVariantDomainToModel(v *domain.Variant) *models.VariantModel {
+	return &models.VariantModel{
+		ID:        v.ID,
+		ProductID: v.ProductID,
+		SKU:       v.SKU,
+		Options:   v.Options,
+		Price:     v.Price,
+		Stock:     v.Stock,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
+
+// DB model -> domain.Variant
+func ConvertVariantModelToDomain(v *models.VariantModel) *domain.Variant {
+	return &domain.Variant{
+		ID:        v.ID,
+		ProductID: v.ProductID,
+		SKU:       v.SKU,
+		Options:   v.Options,
+		Price:     v.Price,
+		Stock:     v.Stock,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+	}
+}
+
+// DB models -> domain.Variants
+func ConvertVariantModelsToDomains(variants []models.VariantModel) []domain.Variant {
+	variantsDomain := make([]domain.Variant, 0, len(variants))
+	for i := range variants {
+		variantsDomain = append(variantsDomain, *ConvertVariantModelToDomain(&variants[i]))
+	}
+	return variantsDomain
+}
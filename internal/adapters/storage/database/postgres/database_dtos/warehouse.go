@@ -0,0 +1,100 @@
+package database_dtos
+
+import (
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+// domain.Warehouse -> DB model
+func ConvertWarehouseDomainToModel(w *domain.Warehouse) *models.WarehouseModel {
+	return &models.WarehouseModel{
+		ID:        w.ID,
+		Code:      w.Code,
+		Name:      w.Name,
+		Latitude:  w.Latitude,
+		Longitude: w.Longitude,
+		IsDefault: w.IsDefault,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// DB model -> domain.Warehouse
+func ConvertWarehouseModelToDomain(w *models.WarehouseModel) *domain.Warehouse {
+	return &domain.Warehouse{
+		ID:        w.ID,
+		Code:      w.Code,
+		Name:      w.Name,
+		Latitude:  w.Latitude,
+		Longitude: w.Longitude,
+		IsDefault: w.IsDefault,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+}
+
+// []DB model -> []domain.Warehouse
+func ConvertWarehouseModelsToDomains(warehouses []models.WarehouseModel) []domain.Warehouse {
+	result := make([]domain.Warehouse, 0, len(warehouses))
+	for i := range warehouses {
+		result = append(result, *ConvertWarehouseModelToDomain(&warehouses[i]))
+	}
+	return result
+}
+
+// DB model -> domain.WarehouseStock, uuid.Nil is the stock of the product
+func ConvertWarehouseStockModelToDomain(ws *models.WarehouseStockModel) domain.WarehouseStock {
+	level := domain.WarehouseStock{
+		WarehouseID: ws.WarehouseID,
+		ProductID:   ws.ProductID,
+		Stock:       ws.Stock,
+	}
+	if ws.VariantID != uuid.Nil {
+		variantID := ws.VariantID
+		level.VariantID = &variantID
+	}
+	return level
+}
+
+// domain.StockAllocation -> DB model
+func ConvertStockAllocationDomainToModel(a *domain.StockAllocation) *models.StockAllocationModel {
+	return &models.StockAllocationModel{
+		ID:          a.ID,
+		OrderID:     a.OrderID,
+		WarehouseID: a.WarehouseID,
+		ProductID:   a.ProductID,
+		VariantID:   a.VariantID,
+		Quantity:    a.Quantity,
+		Status:      a.Status,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// DB model -> domain.StockAllocation
+func ConvertStockAllocationModelToDomain(a *models.StockAllocationModel) *domain.StockAllocation {
+	return &domain.StockAllocation{
+		ID:          a.ID,
+		OrderID:     a.OrderID,
+		WarehouseID: a.WarehouseID,
+		ProductID:   a.ProductID,
+		VariantID:   a.VariantID,
+		Quantity:    a.Quantity,
+		Status:      a.Status,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+	}
+}
+
+// []DB model -> []domain.StockAllocation
+func ConvertStockAllocationModelsToDomains(allocations []models.StockAllocationModel) []domain.StockAllocation {
+	result := make([]domain.StockAllocation, 0, len(allocations))
+	for i := range allocations {
+		result = append(result, *ConvertStockAllocationModelToDomain(&allocations[i]))
+	}
+	return result
+}
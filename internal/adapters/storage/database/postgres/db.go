@@ -2,14 +2,23 @@ package postgres
 
 import (
 	"context"
+	"encoding/json"
 	"go-ecommerce/internal/adapters/config"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	cachettl "go-ecommerce/internal/adapters/storage/cache/cache_ttl"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
 	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
 	"log/slog"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gorm_logger "gorm.io/gorm/logger"
 )
 
@@ -19,7 +28,8 @@ var DB *gorm.DB
 func New(ctx context.Context, config *config.DB) (*gorm.DB, error) {
 	// Connection to db using GORM
 	conn, err := gorm.Open(postgres.Open(config.DSN), &gorm.Config{
-		Logger: gorm_logger.Default.LogMode(gorm_logger.Info),
+		Logger:         gorm_logger.Default.LogMode(gorm_logger.Info),
+		TranslateError: true, // unique violations are returned as gorm.ErrDuplicatedKey
 	})
 	if err != nil {
 		slog.Error("error connecting database", "error", err)
@@ -83,19 +93,315 @@ func Close() {
 
 // execute migrations
 func Migrate(db *gorm.DB) error {
+	// the unique indexes of the SKUs can't be created while there are duplicates
+	for _, model := range []interface{}{&models.ProductModel{}, &models.VariantModel{}} {
+		if err := dedupeSKUs(db, model); err != nil {
+			slog.Error("Error deduplicating SKUs", "error", err)
+			return err
+		}
+	}
+
+	// the lines of the orders created before their prices were recorded are priced after the migration
+	pricedOrderProducts := db.Migrator().HasColumn(&models.OrderProductModel{}, "unit_price")
+
 	err := automigrateSchemas(db,
 		&models.UserModel{},
 		&models.CategoryModel{},
 		&models.ProductModel{},
+		&models.VariantModel{},
+		&models.ProductImageModel{},
+		&models.ProductPriceHistoryModel{},
+		&models.PriceScheduleModel{},
+		&models.WarehouseModel{},
+		&models.WarehouseStockModel{},
+		&models.StockAllocationModel{},
+		&models.StockMovementModel{},
+		&models.StockAlertSubscriptionModel{},
+		&models.NotificationModel{},
+		&models.CartRecoveryModel{},
+		&models.CartModel{},
+		&models.CartItemModel{},
 		&models.OrderModel{},
 		&models.OrderProductModel{},
+		&models.PaymentAttemptModel{},
+		&models.DisputeModel{},
+		&models.DisputeEvidenceModel{},
 	)
 	if err != nil {
 		return err
 	}
+
+	if !pricedOrderProducts {
+		if err := backfillOrderProductPrices(db); err != nil {
+			slog.Error("Error pricing the lines of the orders", "error", err)
+			return err
+		}
+	}
+
+	// automigrate doesn't update existing constraints, products used to be deleted in cascade with their category
+	var deleteRule string
+	err = db.Raw("SELECT delete_rule FROM information_schema.referential_constraints WHERE constraint_name = ?", "fk_product_models_category").Scan(&deleteRule).Error
+	if err != nil {
+		return err
+	}
+	if deleteRule == "CASCADE" {
+		err = db.Migrator().DropConstraint(&models.ProductModel{}, "Category")
+		if err == nil {
+			err = db.Migrator().CreateConstraint(&models.ProductModel{}, "Category")
+		}
+		if err != nil {
+			slog.Error("Error updating product category constraint", "error", err)
+			return err
+		}
+	}
+
+	// products created before the slugs existed
+	if err := backfillProductSlugs(db); err != nil {
+		slog.Error("Error generating product slugs", "error", err)
+		return err
+	}
+
+	// discounts used to be applied as a fixed amount without a type
+	err = db.Model(&models.ProductModel{}).Unscoped().Where("discount > 0 AND discount_type IS NULL").Update("discount_type", domain.Fixed).Error
+	if err != nil {
+		slog.Error("Error setting the type of the product discounts", "error", err)
+		return err
+	}
+
+	// the stock without a warehouse goes to the default one
+	if err := ensureDefaultWarehouse(db); err != nil {
+		slog.Error("Error creating the default warehouse", "error", err)
+		return err
+	}
+
+	// the stock of the products and variants created before the ledger existed
+	if err := backfillOpeningStock(db); err != nil {
+		slog.Error("Error recording the opening stock", "error", err)
+		return err
+	}
+
+	// the stock recorded before the warehouses existed
+	if err := backfillWarehouseStock(db); err != nil {
+		slog.Error("Error recording the stock of the warehouses", "error", err)
+		return err
+	}
+
+	// automigrate can't create expression indexes
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_product_models_search ON product_models USING GIN (" + models.ProductSearchVector + ")").Error
+	if err != nil {
+		slog.Error("Error creating product search index", "error", err)
+		return err
+	}
+	return nil
+}
+
+// helper func, the oldest row keeps its SKU and the rest get a suffix with the start of their id
+func dedupeSKUs(db *gorm.DB, model interface{}) error {
+	if !db.Migrator().HasTable(model) {
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	table := stmt.Schema.Table
+
+	result := db.Exec(`UPDATE ` + table + ` SET sku = sku || '-' || substr(id::text, 1, 8)
+		WHERE id IN (
+			SELECT id FROM (
+				SELECT id, ROW_NUMBER() OVER (PARTITION BY sku ORDER BY created_at, id) AS n FROM ` + table + `
+			) dup WHERE dup.n > 1
+		)`)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		slog.Warn("Duplicated SKUs renamed", "table", table, "rows", result.RowsAffected)
+	}
+	return nil
+}
+
+// helper func, generates the slug of the products that don't have one
+func backfillProductSlugs(db *gorm.DB) error {
+	var products []models.ProductModel
+	if err := db.Unscoped().Select("id", "name").Where("slug IS NULL OR slug = ''").Order("created_at, id").Find(&products).Error; err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		slug, err := models.UniqueProductSlug(db, domain.Slugify(product.Name))
+		if err != nil {
+			return err
+		}
+		if err := db.Unscoped().Model(&models.ProductModel{}).Where("id = ?", product.ID).Update("slug", slug).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// helper func, records the stock of the products and variants without movements as an opening adjustment,
+// so the ledger adds up to their current stock
+func backfillOpeningStock(db *gorm.DB) error {
+	var warehouseID uuid.UUID
+	if err := db.Model(&models.WarehouseModel{}).Select("id").Where("is_default = ?", true).Limit(1).Scan(&warehouseID).Error; err != nil {
+		return err
+	}
+
+	var levels []struct {
+		ProductID uuid.UUID
+		VariantID *uuid.UUID
+		Stock     int64
+	}
+
+	err := db.Raw(`SELECT p.id AS product_id, NULL AS variant_id, p.stock FROM product_models p
+		WHERE p.stock > 0 AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.product_id = p.id AND m.variant_id IS NULL)
+		UNION ALL
+		SELECT v.product_id, v.id AS variant_id, v.stock FROM variant_models v
+		WHERE v.stock > 0 AND NOT EXISTS (SELECT 1 FROM stock_movements m WHERE m.variant_id = v.id)`).Scan(&levels).Error
+	if err != nil {
+		return err
+	}
+
+	for _, level := range levels {
+		err := db.Create(&models.StockMovementModel{
+			ProductID:   level.ProductID,
+			VariantID:   level.VariantID,
+			WarehouseID: &warehouseID,
+			Type:        domain.StockAdjustment,
+			Quantity:    level.Stock,
+			Delta:       level.Stock,
+			Balance:     level.Stock,
+			Reason:      "opening balance",
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+// creates the main warehouse when there is no default warehouse
+func ensureDefaultWarehouse(db *gorm.DB) error {
+	var defaults int64
+	if err := db.Model(&models.WarehouseModel{}).Where("is_default = ?", true).Count(&defaults).Error; err != nil {
+		return err
+	}
+	if defaults > 0 {
+		return nil
+	}
+
+	return db.Create(&models.WarehouseModel{Code: "MAIN", Name: "Main warehouse", IsDefault: true, Active: true}).Error
+}
+
+// moves the movements without a warehouse to the default one and creates the stock of the warehouses from the ledger
+func backfillWarehouseStock(db *gorm.DB) error {
+	var warehouseID uuid.UUID
+	if err := db.Model(&models.WarehouseModel{}).Select("id").Where("is_default = ?", true).Limit(1).Scan(&warehouseID).Error; err != nil {
+		return err
+	}
+
+	err := db.Model(&models.StockMovementModel{}).Where("warehouse_id IS NULL AND delta <> 0").Update("warehouse_id", warehouseID).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Exec(`INSERT INTO warehouse_stock (warehouse_id, product_id, variant_id, stock, updated_at)
+		SELECT m.warehouse_id, m.product_id, COALESCE(m.variant_id, ?), SUM(m.delta), NOW() FROM stock_movements m
+		WHERE m.warehouse_id IS NOT NULL
+		GROUP BY m.warehouse_id, m.product_id, COALESCE(m.variant_id, ?)
+		ON CONFLICT DO NOTHING`, uuid.Nil, uuid.Nil).Error
+}
+
+// prices the lines of the existing orders with the current price of the product or its variant, the best estimate
+// left since the price when they were ordered wasn't recorded
+func backfillOrderProductPrices(db *gorm.DB) error {
+	return db.Exec(`UPDATE order_product_models AS op SET unit_price = COALESCE(
+		(SELECT v.price FROM variant_models v WHERE v.id = op.variant_id),
+		(SELECT p.price FROM product_models p WHERE p.id = op.product_id),
+		0)`).Error
+}
+
+// ImportCachedCarts moves the carts saved only in the cache, before the carts were saved in the database, to the
+// database. It runs once, a marker in the cache records the import. The carts that already are in the database aren't
+// imported, the database is the source of the carts. The carts of the guests expire like a new guest cart
+func ImportCachedCarts(ctx context.Context, db *gorm.DB, cache ports.CacheRepository) error {
+	if marker, err := cache.Get(ctx, cachekeys.CartsImported()); err == nil && len(marker) > 0 {
+		return nil
+	}
+
+	imported := 0
+	for _, prefix := range []string{cachekeys.CartPrefix(), cachekeys.GuestCartPrefix()} {
+		keys, err := cache.Keys(ctx, prefix)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			data, err := cache.Get(ctx, key)
+			if err != nil || len(data) == 0 {
+				continue
+			}
+
+			var cart domain.Cart
+			if err := json.Unmarshal(data, &cart); err != nil || len(cart.Items) == 0 {
+				slog.Warn("skipping cached cart that can't be imported", "key", key)
+				continue
+			}
+
+			// the carts of the guests are cached without user, the key has the id of the guest
+			var expiresAt *time.Time
+			if prefix == cachekeys.GuestCartPrefix() {
+				guestId, err := uuid.Parse(strings.TrimPrefix(key, prefix))
+				if err != nil {
+					slog.Warn("skipping cached cart that can't be imported", "key", key)
+					continue
+				}
+				cart.UserID = guestId
+				expires := time.Now().Add(cachettl.GuestCart)
+				expiresAt = &expires
+			}
+			if cart.UserID == uuid.Nil {
+				slog.Warn("skipping cached cart that can't be imported", "key", key)
+				continue
+			}
+
+			ok, err := importCart(ctx, db, &cart, expiresAt)
+			if err != nil {
+				return err
+			}
+			if ok {
+				imported++
+			}
+		}
+	}
+
+	slog.Info("Imported the carts saved in cache", "carts", imported)
+	return cache.Set(ctx, cachekeys.CartsImported(), []byte(time.Now().Format(time.RFC3339)), 0)
+}
+
+// helper func, saves the cart unless there is one with its id, returns whether it was saved
+func importCart(ctx context.Context, db *gorm.DB, cart *domain.Cart, expiresAt *time.Time) (bool, error) {
+	if cart.UpdatedAt.IsZero() {
+		cart.UpdatedAt = time.Now()
+	}
+
+	imported := false
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		cartDb := database_dtos.ConvertCartDomainToModel(cart)
+		cartDb.ExpiresAt = expiresAt
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Omit("Items").Create(cartDb)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		imported = true
+		return tx.Create(&cartDb.Items).Error
+	})
+	return imported && err == nil, err
+}
+
 // loop for all migrations and execute
 func automigrateSchemas(db *gorm.DB, models ...interface{}) error {
 	for _, model := range models {
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CartModel is the cart of a user or a guest, the carts of the guests are saved under the id signed in their cart
+// token and expire
+type CartModel struct {
+	UserID    uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `gorm:"autoUpdateTime:false;index"` // last change of the items, set by the cart
+	ExpiresAt *time.Time `gorm:"index"`                      // only the carts of the guests expire
+
+	Items []CartItemModel `gorm:"foreignKey:CartUserID;references:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (CartModel) TableName() string {
+	return "carts"
+}
+
+// CartItemModel is a line of a cart, the position keeps the order of the lines
+type CartItemModel struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	CartUserID     uuid.UUID  `gorm:"type:uuid;not null;index"`
+	ProductID      uuid.UUID  `gorm:"type:uuid;not null;index"`
+	VariantID      *uuid.UUID `gorm:"type:uuid"`
+	Quantity       int16      `gorm:"not null"`
+	PriceWhenAdded float64    `gorm:"type:numeric;not null;default:0"`
+	Position       int        `gorm:"not null;default:0"`
+}
+
+func (CartItemModel) TableName() string {
+	return "cart_items"
+}
+
+// This function will be executed before to create a new cart item model
+func (ci *CartItemModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if ci.ID == uuid.Nil {
+		ci.ID = uuid.New()
+	}
+	return
+}
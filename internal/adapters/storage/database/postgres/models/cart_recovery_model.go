@@ -0,0 +1,36 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CartRecoveryModel is a reminder sent for an idle cart, the items are a snapshot of the cart
+type CartRecoveryModel struct {
+	ID             uuid.UUID         `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_cart_recovery_period,priority:1;index:idx_cart_recovery_user_reminded,priority:1"`
+	Items          []domain.CartItem `gorm:"type:jsonb;serializer:json;not null"`
+	CartUpdatedAt  time.Time         `gorm:"not null;uniqueIndex:idx_cart_recovery_period,priority:2"`
+	RemindedAt     time.Time         `gorm:"not null;index;index:idx_cart_recovery_user_reminded,priority:2"`
+	RestoredAt     *time.Time        `gorm:"type:timestamp"`
+	OrderID        *uuid.UUID        `gorm:"type:uuid"`
+	RecoveredAt    *time.Time        `gorm:"type:timestamp"`
+	RecoveredTotal float64           `gorm:"type:numeric;not null;default:0"`
+
+	User *UserModel `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (CartRecoveryModel) TableName() string {
+	return "cart_recoveries"
+}
+
+// This function will be executed before to create a new cart recovery model
+func (c *CartRecoveryModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return
+}
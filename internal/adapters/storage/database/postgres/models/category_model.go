@@ -1,10 +1,17 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type CategoryModel struct {
-	ID        uint64    `gorm:"primaryKey"`
-	Name      string    `gorm:"size:255;not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+	ID        uint64         `gorm:"primaryKey"`
+	ParentID  *uint64        `gorm:"index"`
+	Name      string         `gorm:"size:255;not null"`
+	Slug      string         `gorm:"size:255;index"`
+	CreatedAt time.Time      `gorm:"autoCreateTime"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
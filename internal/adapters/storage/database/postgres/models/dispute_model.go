@@ -0,0 +1,51 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DisputeModel struct {
+	ID            uuid.UUID            `gorm:"type:uuid;primaryKey"`
+	OrderID       uuid.UUID            `gorm:"type:uuid;not null;index"`
+	PaymentID     string               `gorm:"type:varchar(255);not null;uniqueIndex"`
+	ChargebackID  string               `gorm:"type:varchar(255)"`
+	Amount        float64              `gorm:"type:numeric"`
+	Currency      domain.Currencies    `gorm:"type:varchar(10)"`
+	Reason        string               `gorm:"type:text"`
+	Status        domain.DisputeStatus `gorm:"type:varchar(50);index"`
+	EvidenceDueAt time.Time            `gorm:"type:timestamp"`
+	ResolvedAt    *time.Time           `gorm:"type:timestamp"`
+	ResolvedBy    *uuid.UUID           `gorm:"type:uuid"`
+	CreatedAt     time.Time            `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time            `gorm:"autoUpdateTime"`
+
+	// Relations
+	Order    *OrderModel            `gorm:"foreignKey:OrderID;references:ID"`
+	Evidence []DisputeEvidenceModel `gorm:"foreignKey:DisputeID;references:ID"`
+}
+
+type DisputeEvidenceModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	DisputeID uuid.UUID `gorm:"type:uuid;not null;index"`
+	AuthorID  uuid.UUID `gorm:"type:uuid;not null"`
+	Note      string    `gorm:"type:text;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+func (d *DisputeModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return
+}
+
+func (de *DisputeEvidenceModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if de.ID == uuid.Nil {
+		de.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// NotificationModel is a notification of the queue, the sent ones are kept as a log of the messages
+type NotificationModel struct {
+	ID            uuid.UUID                 `gorm:"type:uuid;primaryKey"`
+	Kind          domain.NotificationKind   `gorm:"type:varchar(50);not null"`
+	UserID        uuid.UUID                 `gorm:"type:uuid;index"`
+	To            string                    `gorm:"size:255;not null"`
+	Name          string                    `gorm:"size:255"`
+	Locale        string                    `gorm:"size:5"`
+	Data          map[string]any            `gorm:"type:jsonb;serializer:json"`
+	Status        domain.NotificationStatus `gorm:"type:varchar(20);not null;index:idx_notifications_due,priority:1"`
+	Attempts      int                       `gorm:"not null;default:0"`
+	NextAttemptAt time.Time                 `gorm:"not null;index:idx_notifications_due,priority:2"`
+	LastError     *string                   `gorm:"type:text"`
+	SentAt        *time.Time                `gorm:"type:timestamp"`
+	CreatedAt     time.Time                 `gorm:"autoCreateTime"`
+	UpdatedAt     time.Time                 `gorm:"autoUpdateTime"`
+}
+
+func (NotificationModel) TableName() string {
+	return "notifications"
+}
+
+// This function will be executed before to create a new notification model
+func (n *NotificationModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if n.ID == uuid.Nil {
+		n.ID = uuid.New()
+	}
+	return
+}
@@ -31,6 +31,23 @@ type OrderModel struct {
 	UpdatedAt         time.Time               `gorm:"autoUpdateTime"`
 	ExpiresAt         *time.Time              `gorm:"type:timestamp"`
 	PaidAt            *time.Time              `gorm:"type:timestamp"`
+	CancelledAt       *time.Time              `gorm:"type:timestamp"`
+	CancelledBy       *uuid.UUID              `gorm:"type:uuid"`
+	CancelReason      *string                 `gorm:"type:text"`
+
+	// Authorize-then-capture flow
+	AuthorizedAt           *time.Time `gorm:"type:timestamp"`
+	AuthorizationExpiresAt *time.Time `gorm:"type:timestamp;index"`
+	AuthorizationAlertedAt *time.Time `gorm:"type:timestamp"`
+
+	// Chargebacks
+	Disputed bool `gorm:"type:boolean;default:false"`
+
+	// Shipment
+	ShippedAt      *time.Time `gorm:"type:timestamp"`
+	ShippedBy      *uuid.UUID `gorm:"type:uuid"`
+	Carrier        *string    `gorm:"type:varchar(100)"`
+	TrackingNumber *string    `gorm:"type:varchar(255)"`
 
 	// Relations
 	User  *UserModel          `gorm:"foreignKey:UserID;references:ID"`
@@ -8,10 +8,16 @@ import (
 )
 
 type OrderProductModel struct {
-	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
-	OrderID   uuid.UUID `gorm:"type:uuid;not null"`
-	ProductID uuid.UUID `gorm:"type:uuid;not null"`
-	Quantity  int16     `gorm:"not null"`
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	OrderID   uuid.UUID  `gorm:"type:uuid;not null"`
+	ProductID uuid.UUID  `gorm:"type:uuid;not null"`
+	VariantID *uuid.UUID `gorm:"type:uuid;index"`
+	Quantity  int16      `gorm:"not null"`
+
+	// price and discount of a unit when the order was created
+	UnitPrice    float64 `gorm:"type:numeric;not null;default:0"`
+	UnitDiscount float64 `gorm:"type:numeric;not null;default:0"`
+
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime"`
 
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentAttemptModel struct {
+	ID                uuid.UUID              `gorm:"type:uuid;primaryKey"`
+	OrderID           uuid.UUID              `gorm:"type:uuid;not null;index"`
+	PaymentID         string                 `gorm:"type:varchar(255);not null;uniqueIndex"`
+	Status            domain.PayStatus       `gorm:"type:varchar(50)"`
+	StatusDetail      domain.PayStatusDetail `gorm:"type:varchar(100)"`
+	Amount            float64                `gorm:"type:numeric"`
+	NetReceivedAmount float64                `gorm:"type:numeric"`
+	Currency          domain.Currencies      `gorm:"type:varchar(10)"`
+	PayMethod         *string                `gorm:"type:varchar(50)"`
+	PayResource       *string                `gorm:"type:varchar(50)"`
+	Installments      uint8                  `gorm:"type:numeric"`
+	RawPayload        *string                `gorm:"type:jsonb"`
+	CreatedAt         time.Time              `gorm:"autoCreateTime"`
+	UpdatedAt         time.Time              `gorm:"autoUpdateTime"`
+
+	// Relations
+	Order *OrderModel `gorm:"foreignKey:OrderID;references:ID"`
+}
+
+func (pa *PaymentAttemptModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if pa.ID == uuid.Nil {
+		pa.ID = uuid.New()
+	}
+	return
+}
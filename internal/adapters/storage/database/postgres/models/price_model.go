@@ -0,0 +1,69 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductPriceHistoryModel is an entry of the price history, the rows are never updated
+type ProductPriceHistoryModel struct {
+	ID              uuid.UUID              `gorm:"type:uuid;primaryKey"`
+	ProductID       uuid.UUID              `gorm:"type:uuid;not null;index"`
+	OldPrice        float64                `gorm:"type:numeric;not null"`
+	NewPrice        float64                `gorm:"type:numeric;not null"`
+	OldDiscount     float64                `gorm:"type:numeric;not null;default:0"`
+	NewDiscount     float64                `gorm:"type:numeric;not null;default:0"`
+	OldDiscountType *domain.DisscountTypes `gorm:"type:varchar(50)"`
+	NewDiscountType *domain.DisscountTypes `gorm:"type:varchar(50)"`
+	ChangedBy       *uuid.UUID             `gorm:"type:uuid"`
+	ScheduleID      *uuid.UUID             `gorm:"type:uuid;index"`
+	CreatedAt       time.Time              `gorm:"autoCreateTime;index"`
+
+	Product *ProductModel `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (ProductPriceHistoryModel) TableName() string {
+	return "product_price_history"
+}
+
+// This function will be executed before to create a new price history model
+func (ph *ProductPriceHistoryModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if ph.ID == uuid.Nil {
+		ph.ID = uuid.New()
+	}
+	return
+}
+
+type PriceScheduleModel struct {
+	ID           uuid.UUID                  `gorm:"type:uuid;primaryKey"`
+	ProductID    uuid.UUID                  `gorm:"type:uuid;not null;index"`
+	Price        *float64                   `gorm:"type:numeric"`
+	Discount     *float64                   `gorm:"type:numeric"`
+	DiscountType *domain.DisscountTypes     `gorm:"type:varchar(50)"`
+	StartsAt     time.Time                  `gorm:"not null;index"`
+	EndsAt       *time.Time                 `gorm:"index"`
+	Status       domain.PriceScheduleStatus `gorm:"type:varchar(20);not null;index"`
+	CreatedBy    *uuid.UUID                 `gorm:"type:uuid"`
+	AppliedAt    *time.Time
+	EndedAt      *time.Time
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+	// pricing before a sale started
+	PreviousPrice        *float64               `gorm:"type:numeric"`
+	PreviousDiscount     *float64               `gorm:"type:numeric"`
+	PreviousDiscountType *domain.DisscountTypes `gorm:"type:varchar(50)"`
+
+	Product *ProductModel `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// This function will be executed before to create a new price schedule model
+func (ps *PriceScheduleModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if ps.ID == uuid.Nil {
+		ps.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductImageModel struct {
+	ID           uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID    uuid.UUID `gorm:"type:uuid;not null;index"`
+	Key          string    `gorm:"size:512;not null"`
+	URL          string    `gorm:"size:1024;not null"`
+	ThumbnailKey string    `gorm:"size:512;not null"`
+	ThumbnailURL string    `gorm:"size:1024;not null"`
+	ContentType  string    `gorm:"size:50;not null"`
+	Size         int64     `gorm:"not null"`
+	Position     int       `gorm:"not null;default:0"`
+	IsPrimary    bool      `gorm:"not null;default:false"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	UpdatedAt    time.Time `gorm:"autoUpdateTime"`
+
+	Product *ProductModel `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// This function will be executed before to create a new product image model
+func (pi *ProductImageModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if pi.ID == uuid.Nil {
+		pi.ID = uuid.New()
+	}
+	return
+}
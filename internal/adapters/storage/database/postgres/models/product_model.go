@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"go-ecommerce/internal/core/domain"
 	"time"
 
@@ -8,10 +9,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// ProductSearchVector is the document used by the product full text search, it's indexed with gin in postgres
+const ProductSearchVector = "to_tsvector('simple', name || ' ' || sku)"
+
 type ProductModel struct {
 	ID           uuid.UUID              `gorm:"type:uuid;primaryKey"`
 	Name         string                 `gorm:"size:255;not null"`
-	SKU          string                 `gorm:"size:255;not null"`
+	SKU          string                 `gorm:"size:255;not null;uniqueIndex"`
+	Slug         string                 `gorm:"size:255;uniqueIndex"` // generated on create, see UniqueProductSlug
 	Stock        int64                  `gorm:"not null"`
 	Price        float64                `gorm:"not null"`
 	Discount     float64                `gorm:"type:numeric"`
@@ -19,9 +24,16 @@ type ProductModel struct {
 	Image        string                 `gorm:"size:255;not null"`
 	CreatedAt    time.Time              `gorm:"autoCreateTime"`
 	UpdatedAt    time.Time              `gorm:"autoUpdateTime"`
+	DeletedAt    gorm.DeletedAt         `gorm:"index"` // archived products keep resolving in the orders
+
+	LowStockThreshold int64 `gorm:"not null;default:0"`
+	LowStockAlertedAt *time.Time
 
 	CategoryID uint64         `gorm:"not null"`
-	Category   *CategoryModel `gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Category   *CategoryModel `gorm:"foreignKey:CategoryID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+
+	Variants []VariantModel      `gorm:"foreignKey:ProductID"`
+	Images   []ProductImageModel `gorm:"foreignKey:ProductID"`
 }
 
 // This function will be executed before to create a new product model
@@ -29,5 +41,35 @@ func (p *ProductModel) BeforeCreate(tx *gorm.DB) (err error) {
 	if p.ID == uuid.Nil {
 		p.ID = uuid.New()
 	}
+
+	if p.Slug == "" {
+		p.Slug = domain.Slugify(p.Name)
+	}
+	p.Slug, err = UniqueProductSlug(tx.Session(&gorm.Session{NewDB: true}), p.Slug)
 	return
 }
+
+// UniqueProductSlug returns the slug, or the slug with the first free numeric suffix (e.g. "iphone-15-2") if it's taken.
+// Archived products keep their slug so they can be restored
+func UniqueProductSlug(db *gorm.DB, slug string) (string, error) {
+	if slug == "" {
+		slug = "product"
+	}
+
+	var taken []string
+	err := db.Unscoped().Model(&ProductModel{}).Where("slug = ? OR slug LIKE ?", slug, slug+"-%").Pluck("slug", &taken).Error
+	if err != nil {
+		return "", err
+	}
+
+	used := make(map[string]bool, len(taken))
+	for _, s := range taken {
+		used[s] = true
+	}
+
+	candidate := slug
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", slug, i)
+	}
+	return candidate, nil
+}
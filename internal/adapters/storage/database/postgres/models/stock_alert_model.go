@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockAlertSubscriptionModel is a customer waiting for a product out of stock
+type StockAlertSubscriptionModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_stock_alert_product_user"`
+	UserID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_stock_alert_product_user"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	Product *ProductModel `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User    *UserModel    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (StockAlertSubscriptionModel) TableName() string {
+	return "stock_alert_subscriptions"
+}
+
+// This function will be executed before to create a new stock alert subscription model
+func (s *StockAlertSubscriptionModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return
+}
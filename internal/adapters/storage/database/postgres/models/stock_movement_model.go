@@ -0,0 +1,39 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// StockMovementModel is an entry of the stock ledger, the rows are never updated.
+// The variant has no foreign key, the ledger keeps the movements of deleted variants
+type StockMovementModel struct {
+	ID          uuid.UUID                `gorm:"type:uuid;primaryKey"`
+	ProductID   uuid.UUID                `gorm:"type:uuid;not null;index:idx_stock_movements_product,priority:1"`
+	VariantID   *uuid.UUID               `gorm:"type:uuid;index"`
+	WarehouseID *uuid.UUID               `gorm:"type:uuid;index"`
+	Type        domain.StockMovementType `gorm:"type:varchar(20);not null"`
+	Quantity    int64                    `gorm:"not null"`
+	Delta       int64                    `gorm:"not null"`
+	Balance     int64                    `gorm:"not null"`
+	Reason      string                   `gorm:"size:255;not null"`
+	ActorID     *uuid.UUID               `gorm:"type:uuid"`
+	CreatedAt   time.Time                `gorm:"autoCreateTime;index:idx_stock_movements_product,priority:2"`
+
+	Product *ProductModel `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (StockMovementModel) TableName() string {
+	return "stock_movements"
+}
+
+// This function will be executed before to create a new stock movement model
+func (sm *StockMovementModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if sm.ID == uuid.Nil {
+		sm.ID = uuid.New()
+	}
+	return
+}
@@ -14,8 +14,10 @@ type UserModel struct {
 	Email     string          `gorm:"size:255;unique;not null"`
 	Password  string          `gorm:"size:255;not null"`
 	Role      domain.UserRole `gorm:"size:10;not null;default:client"`
+	Locale    string          `gorm:"size:5;not null;default:es"`
 	CreatedAt time.Time       `gorm:"autoCreateTime"`
 	UpdatedAt time.Time       `gorm:"autoUpdateTime"`
+	DeletedAt gorm.DeletedAt  `gorm:"index"`
 }
 
 // This function will be executed before to create a new product model
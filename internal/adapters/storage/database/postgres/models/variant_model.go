@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type VariantModel struct {
+	ID        uuid.UUID         `gorm:"type:uuid;primaryKey"`
+	ProductID uuid.UUID         `gorm:"type:uuid;not null;index"`
+	SKU       string            `gorm:"size:255;not null;uniqueIndex"`
+	Options   map[string]string `gorm:"type:jsonb;serializer:json;not null"`
+	Price     *float64          `gorm:"type:numeric"`
+	Stock     int64             `gorm:"not null;default:0"`
+	CreatedAt time.Time         `gorm:"autoCreateTime"`
+	UpdatedAt time.Time         `gorm:"autoUpdateTime"`
+
+	Product *ProductModel `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// This function will be executed before to create a new variant model
+func (v *VariantModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if v.ID == uuid.Nil {
+		v.ID = uuid.New()
+	}
+	return
+}
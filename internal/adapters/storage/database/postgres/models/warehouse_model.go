@@ -0,0 +1,71 @@
+package models
+
+import (
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// the booleans have no default, gorm would skip a false value on create
+type WarehouseModel struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Code      string    `gorm:"size:50;not null;uniqueIndex"`
+	Name      string    `gorm:"size:255;not null"`
+	Latitude  *float64
+	Longitude *float64
+	IsDefault bool      `gorm:"not null;index"`
+	Active    bool      `gorm:"not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// This function will be executed before to create a new warehouse model
+func (w *WarehouseModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return
+}
+
+// WarehouseStockModel is the stock of a product or variant in a warehouse. The variant is uuid.Nil for the stock
+// of the product, a NULL would let the primary key repeat the product
+type WarehouseStockModel struct {
+	WarehouseID uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ProductID   uuid.UUID `gorm:"type:uuid;primaryKey;index"`
+	VariantID   uuid.UUID `gorm:"type:uuid;primaryKey"`
+	Stock       int64     `gorm:"not null;default:0"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+
+	Warehouse *WarehouseModel `gorm:"foreignKey:WarehouseID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+	Product   *ProductModel   `gorm:"foreignKey:ProductID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+func (WarehouseStockModel) TableName() string {
+	return "warehouse_stock"
+}
+
+// StockAllocationModel is the part of an item of an order reserved in a warehouse. The order has no foreign key,
+// the allocations are reserved before the order is created
+type StockAllocationModel struct {
+	ID          uuid.UUID                    `gorm:"type:uuid;primaryKey"`
+	OrderID     *uuid.UUID                   `gorm:"type:uuid;index"`
+	WarehouseID uuid.UUID                    `gorm:"type:uuid;not null;index"`
+	ProductID   uuid.UUID                    `gorm:"type:uuid;not null;index"`
+	VariantID   *uuid.UUID                   `gorm:"type:uuid"`
+	Quantity    int64                        `gorm:"not null"`
+	Status      domain.StockAllocationStatus `gorm:"type:varchar(20);not null;index"`
+	CreatedAt   time.Time                    `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time                    `gorm:"autoUpdateTime"`
+
+	Warehouse *WarehouseModel `gorm:"foreignKey:WarehouseID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT"`
+}
+
+// This function will be executed before to create a new stock allocation model
+func (sa *StockAllocationModel) BeforeCreate(tx *gorm.DB) (err error) {
+	if sa.ID == uuid.Nil {
+		sa.ID = uuid.New()
+	}
+	return
+}
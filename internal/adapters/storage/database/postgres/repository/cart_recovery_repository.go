@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartRecoveryRepo struct {
+	db *gorm.DB
+}
+
+func NewCartRecoveryRepo(db *gorm.DB) ports.CartRecoveryRepository {
+	return &CartRecoveryRepo{db: db}
+}
+
+// CreateRecovery implements ports.CartRecoveryRepository.
+func (cr *CartRecoveryRepo) CreateRecovery(ctx context.Context, recovery *domain.CartRecovery) (bool, error) {
+	recoveryDb := database_dtos.ConvertCartRecoveryDomainToModel(recovery)
+
+	result := cr.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "cart_updated_at"}},
+		DoNothing: true,
+	}).Create(recoveryDb)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	recovery.ID = recoveryDb.ID
+	return result.RowsAffected > 0, nil
+}
+
+// GetRecoveryById implements ports.CartRecoveryRepository.
+func (cr *CartRecoveryRepo) GetRecoveryById(ctx context.Context, id uuid.UUID) (*domain.CartRecovery, error) {
+	var recoveryDb models.CartRecoveryModel
+
+	if result := cr.db.WithContext(ctx).First(&recoveryDb, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrCartRecoveryNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertCartRecoveryModelToDomain(&recoveryDb), nil
+}
+
+// MarkRestored implements ports.CartRecoveryRepository.
+func (cr *CartRecoveryRepo) MarkRestored(ctx context.Context, id uuid.UUID, at time.Time) error {
+	return cr.db.WithContext(ctx).
+		Model(&models.CartRecoveryModel{}).
+		Where("id = ? AND restored_at IS NULL", id).
+		UpdateColumn("restored_at", at).Error
+}
+
+// MarkRecovered implements ports.CartRecoveryRepository.
+func (cr *CartRecoveryRepo) MarkRecovered(ctx context.Context, order *domain.Order, since time.Time) (bool, error) {
+	latest := cr.db.Model(&models.CartRecoveryModel{}).
+		Select("id").
+		Where("user_id = ? AND recovered_at IS NULL AND reminded_at >= ?", order.UserID, since).
+		Order("reminded_at DESC").
+		Limit(1)
+
+	result := cr.db.WithContext(ctx).
+		Model(&models.CartRecoveryModel{}).
+		Where("id = (?) AND recovered_at IS NULL", latest).
+		UpdateColumns(map[string]any{
+			"order_id":        order.ID,
+			"recovered_at":    order.CreatedAt,
+			"recovered_total": order.Total,
+		})
+	return result.RowsAffected > 0, result.Error
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CartRepo struct {
+	db *gorm.DB
+}
+
+func NewCartRepo(db *gorm.DB) ports.CartRepository {
+	return &CartRepo{db: db}
+}
+
+// helper func, the lines are loaded in the order they were added
+func preloadCartItems(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// GetCart implements ports.CartRepository.
+func (cr *CartRepo) GetCart(ctx context.Context, userId uuid.UUID) (*domain.Cart, error) {
+	var cartsDb []*models.CartModel
+
+	result := cr.db.WithContext(ctx).Preload("Items", preloadCartItems).Where("user_id = ?", userId).Limit(1).Find(&cartsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(cartsDb) == 0 {
+		return domain.NewCart(userId), nil
+	}
+
+	return database_dtos.ConvertCartModelToDomain(cartsDb[0]), nil
+}
+
+// UpdateCart implements ports.CartRepository.
+func (cr *CartRepo) UpdateCart(ctx context.Context, userId uuid.UUID, change func(cart *domain.Cart) error) (*domain.Cart, error) {
+	return cr.updateCart(ctx, userId, nil, change)
+}
+
+// helper func, the row of the cart is created before locking it, so the first change of a cart is serialized too.
+// The lines are replaced on every change. The carts of the guests have an expiry, an expired cart is changed as an
+// empty cart
+func (cr *CartRepo) updateCart(ctx context.Context, id uuid.UUID, expiresAt *time.Time, change func(cart *domain.Cart) error) (*domain.Cart, error) {
+	var cart *domain.Cart
+	err := cr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.CartModel{UserID: id, ExpiresAt: expiresAt}).Error
+		if err != nil {
+			return err
+		}
+
+		var cartDb models.CartModel
+		err = tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cartDb, "user_id = ?", id).Error
+		if err != nil {
+			return err
+		}
+		expired := cartDb.ExpiresAt != nil && !cartDb.ExpiresAt.After(time.Now())
+		if !expired {
+			if err := tx.Order("position").Find(&cartDb.Items, "cart_user_id = ?", id).Error; err != nil {
+				return err
+			}
+		}
+
+		cart = database_dtos.ConvertCartModelToDomain(&cartDb)
+		if err := change(cart); err != nil {
+			return err
+		}
+
+		if len(cart.Items) == 0 {
+			return tx.Delete(&models.CartModel{}, "user_id = ?", id).Error
+		}
+
+		if err := tx.Delete(&models.CartItemModel{}, "cart_user_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		cartDb = *database_dtos.ConvertCartDomainToModel(cart)
+		if err := tx.Create(&cartDb.Items).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.CartModel{}).Where("user_id = ?", id).
+			UpdateColumns(map[string]any{"updated_at": cart.UpdatedAt, "expires_at": expiresAt}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// ListCarts implements ports.CartRepository.
+func (cr *CartRepo) ListCarts(ctx context.Context) ([]*domain.Cart, error) {
+	var cartsDb []*models.CartModel
+
+	result := cr.db.WithContext(ctx).
+		Where("expires_at IS NULL").
+		Where("EXISTS (?)", cr.db.Model(&models.CartItemModel{}).Select("1").Where("cart_items.cart_user_id = carts.user_id")).
+		Preload("Items", preloadCartItems).
+		Order("updated_at").
+		Find(&cartsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertCartModelsToDomains(cartsDb), nil
+}
+
+// GetGuestCart implements ports.CartRepository.
+func (cr *CartRepo) GetGuestCart(ctx context.Context, guestId uuid.UUID, now time.Time) (*domain.Cart, error) {
+	var cartsDb []*models.CartModel
+
+	result := cr.db.WithContext(ctx).Preload("Items", preloadCartItems).
+		Where("user_id = ? AND expires_at > ?", guestId, now).
+		Limit(1).
+		Find(&cartsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if len(cartsDb) == 0 {
+		return domain.NewCart(guestId), nil
+	}
+
+	return database_dtos.ConvertCartModelToDomain(cartsDb[0]), nil
+}
+
+// UpdateGuestCart implements ports.CartRepository.
+func (cr *CartRepo) UpdateGuestCart(ctx context.Context, guestId uuid.UUID, expiresAt time.Time, change func(cart *domain.Cart) error) (*domain.Cart, error) {
+	return cr.updateCart(ctx, guestId, &expiresAt, change)
+}
+
+// DeleteExpiredGuestCarts implements ports.CartRepository.
+// The lines are deleted by the cascade of the carts
+func (cr *CartRepo) DeleteExpiredGuestCarts(ctx context.Context, now time.Time) (int64, error) {
+	result := cr.db.WithContext(ctx).Delete(&models.CartModel{}, "expires_at <= ?", now)
+	return result.RowsAffected, result.Error
+}
@@ -6,6 +6,7 @@ import (
 	"go-ecommerce/internal/adapters/storage/database/postgres/models"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -22,7 +23,8 @@ func (r *CategoryRepo) SaveCategory(ctx context.Context, category *domain.Catego
 	categoryDb := database_dtos.ConvertCategoryDomainToModel(category)
 
 	if category.ID != 0 {
-		if result := r.db.WithContext(ctx).Where("id = ?", category.ID).Updates(categoryDb); result.Error != nil {
+		// parent_id is selected so a category can be moved to the root
+		if result := r.db.WithContext(ctx).Select("parent_id", "name", "slug", "updated_at").Where("id = ?", category.ID).Updates(categoryDb); result.Error != nil {
 			if result.RowsAffected == 0 {
 				return nil, domain.ErrCategoryNotFound
 			}
@@ -78,3 +80,106 @@ func (r *CategoryRepo) DeleteCategory(ctx context.Context, id uint64) error {
 	}
 	return nil
 }
+
+// CountProducts implements ports.CategoryRepository.
+func (r *CategoryRepo) CountProducts(ctx context.Context, ids []uint64) (int64, error) {
+	var count int64
+
+	result := r.db.WithContext(ctx).Model(&models.ProductModel{}).Where("category_id IN ?", ids).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// DeleteCategoryWithPlan implements ports.CategoryRepository.
+// All the changes of the plan are applied in a transaction
+func (r *CategoryRepo) DeleteCategoryWithPlan(ctx context.Context, plan *domain.CategoryDeletePlan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if plan.Reparent {
+			result := tx.Model(&models.CategoryModel{}).Where("parent_id = ?", plan.CategoryID).Update("parent_id", plan.ReparentTo)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if plan.ReparentTo != nil {
+				result = tx.Model(&models.ProductModel{}).Where("category_id = ?", plan.CategoryID).Update("category_id", *plan.ReparentTo)
+				if result.Error != nil {
+					return result.Error
+				}
+			}
+		}
+
+		// products and categories are archived, the purge removes them once the retention is over
+		if plan.DeleteProducts {
+			if result := tx.Where("category_id IN ?", plan.DeleteIDs).Delete(&models.ProductModel{}); result.Error != nil {
+				return result.Error
+			}
+		}
+
+		result := tx.Where("id IN ?", plan.DeleteIDs).Delete(&models.CategoryModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrCategoryNotFound
+		}
+		return nil
+	})
+}
+
+// RestoreCategory implements ports.CategoryRepository.
+func (r *CategoryRepo) RestoreCategory(ctx context.Context, id uint64) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var categoryDb models.CategoryModel
+		if result := tx.Unscoped().First(&categoryDb, "id = ?", id); result.Error != nil {
+			if result.RowsAffected == 0 {
+				return domain.ErrCategoryNotFound
+			}
+			return result.Error
+		}
+		if !categoryDb.DeletedAt.Valid {
+			return domain.ErrCategoryNotArchived
+		}
+
+		// subtrees are restored from the root down
+		if categoryDb.ParentID != nil {
+			var parents int64
+			if err := tx.Model(&models.CategoryModel{}).Where("id = ?", *categoryDb.ParentID).Count(&parents).Error; err != nil {
+				return err
+			}
+			if parents == 0 {
+				return domain.ErrCategoryParentArchived
+			}
+		}
+
+		return tx.Unscoped().Model(&models.CategoryModel{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+}
+
+// PurgeCategories implements ports.CategoryRepository.
+// Categories still referenced by a product or a child category (archived or not) are kept, the leaves are removed
+// first so nested subtrees are purged in a single call
+func (r *CategoryRepo) PurgeCategories(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	var purged int64
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for {
+			products := tx.Unscoped().Model(&models.ProductModel{}).Select("category_id")
+			children := tx.Unscoped().Model(&models.CategoryModel{}).Select("parent_id").Where("parent_id IS NOT NULL")
+
+			result := tx.Unscoped().
+				Where("deleted_at IS NOT NULL AND deleted_at < ?", archivedBefore).
+				Where("id NOT IN (?) AND id NOT IN (?)", products, children).
+				Delete(&models.CategoryModel{})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+			purged += result.RowsAffected
+		}
+	})
+	return purged, err
+}
@@ -3,8 +3,10 @@ package repository_test
 import (
 	"context"
 	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
 	testhelpers "go-ecommerce/internal/test_helpers"
 	"testing"
+	"time"
 
 	"github.com/go-faker/faker/v4"
 	"github.com/stretchr/testify/assert"
@@ -77,3 +79,42 @@ func Test_UpdateCategory(t *testing.T) {
 	assert.Equal(t, newCateg.ID, updatedCateg.ID)
 	assert.Equal(t, newCateg.Name, updatedCateg.Name)
 }
+
+func Test_RestoreAndPurgeCategories(t *testing.T) {
+	ctx := context.Background()
+	tx, repos := newCategoryRepoTx(t)
+
+	root, err := repos.categRepo.SaveCategory(ctx, testhelpers.NewDomainCategory("Electronics"))
+	require.NoError(t, err)
+	child := testhelpers.NewDomainCategory("Phones")
+	child.ParentID = &root.ID
+	child, err = repos.categRepo.SaveCategory(ctx, child)
+	require.NoError(t, err)
+
+	require.NoError(t, repos.categRepo.DeleteCategoryWithPlan(ctx, &domain.CategoryDeletePlan{
+		CategoryID: root.ID,
+		DeleteIDs:  []uint64{root.ID, child.ID},
+	}))
+	_, err = repos.categRepo.GetCategoryByID(ctx, child.ID)
+	assert.Equal(t, domain.ErrCategoryNotFound, err)
+
+	// subtrees are restored from the root down
+	assert.Equal(t, domain.ErrCategoryParentArchived, repos.categRepo.RestoreCategory(ctx, child.ID))
+	require.NoError(t, repos.categRepo.RestoreCategory(ctx, root.ID))
+	assert.Equal(t, domain.ErrCategoryNotArchived, repos.categRepo.RestoreCategory(ctx, root.ID))
+	require.NoError(t, repos.categRepo.DeleteCategoryWithPlan(ctx, &domain.CategoryDeletePlan{
+		CategoryID: root.ID,
+		DeleteIDs:  []uint64{root.ID},
+	}))
+
+	// the child is purged first, then its parent in the same call
+	purged, err := repos.categRepo.PurgeCategories(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	require.NoError(t, tx.Exec("UPDATE category_models SET deleted_at = ?", time.Now().Add(-2*time.Hour)).Error)
+	purged, err = repos.categRepo.PurgeCategories(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	assert.Equal(t, domain.ErrCategoryNotFound, repos.categRepo.RestoreCategory(ctx, root.ID))
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DisputeRepo struct {
+	db *gorm.DB
+}
+
+func NewDisputeRepo(db *gorm.DB) ports.DisputeRepository {
+	return &DisputeRepo{db: db}
+}
+
+// SaveDispute implements ports.DisputeRepository.
+func (dr *DisputeRepo) SaveDispute(ctx context.Context, dispute *domain.Dispute) (*domain.Dispute, error) {
+	disputeDb := database_dtos.ConvertDisputeDomainToModel(dispute)
+
+	// if exist dispute.ID update, else create new dispute
+	if dispute.ID != uuid.Nil {
+		result := dr.db.WithContext(ctx).Where("id = ?", dispute.ID).Updates(disputeDb)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return dr.GetDisputeById(ctx, dispute.ID)
+	}
+
+	if result := dr.db.WithContext(ctx).Create(disputeDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertDisputeModelToDomain(disputeDb), nil
+}
+
+// GetDisputeById implements ports.DisputeRepository.
+func (dr *DisputeRepo) GetDisputeById(ctx context.Context, id uuid.UUID) (*domain.Dispute, error) {
+	var disputeDb models.DisputeModel
+
+	result := dr.db.WithContext(ctx).
+		Preload("Evidence", func(db *gorm.DB) *gorm.DB { return db.Order("created_at ASC") }).
+		First(&disputeDb, "id = ?", id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertDisputeModelToDomain(&disputeDb), nil
+}
+
+// GetDisputeByPaymentId implements ports.DisputeRepository.
+func (dr *DisputeRepo) GetDisputeByPaymentId(ctx context.Context, paymentId string) (*domain.Dispute, error) {
+	var disputeDb models.DisputeModel
+
+	result := dr.db.WithContext(ctx).Preload("Evidence").First(&disputeDb, "payment_id = ?", paymentId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrDisputeNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertDisputeModelToDomain(&disputeDb), nil
+}
+
+// ListDisputes implements ports.DisputeRepository.
+// If status is nil, disputes are not filtered by status
+func (dr *DisputeRepo) ListDisputes(ctx context.Context, status *domain.DisputeStatus) ([]*domain.Dispute, error) {
+	var disputesDb []*models.DisputeModel
+
+	query := dr.db.WithContext(ctx).Order("evidence_due_at ASC")
+	if status != nil {
+		query = query.Where("status = ?", *status)
+	}
+
+	if result := query.Find(&disputesDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertDisputeModelsToDomains(disputesDb), nil
+}
+
+// SaveEvidence implements ports.DisputeRepository.
+func (dr *DisputeRepo) SaveEvidence(ctx context.Context, evidence *domain.DisputeEvidence) (*domain.DisputeEvidence, error) {
+	evidenceDb := database_dtos.ConvertDisputeEvidenceDomainToModel(evidence)
+
+	if result := dr.db.WithContext(ctx).Create(evidenceDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertDisputeEvidenceModelToDomain(evidenceDb), nil
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"errors"
+	"go-ecommerce/internal/adapters/shared"
+
+	"gorm.io/gorm"
+)
+
+// translateError maps the errors of the database that the services handle, the rest are returned as they are.
+// It relies on the TranslateError option of the connection
+func translateError(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return shared.ErrConflictingData
+	}
+	return err
+}
@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryRepo struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepo(db *gorm.DB) ports.InventoryRepository {
+	return &InventoryRepo{db: db}
+}
+
+// helper func, appends the movement to the ledger. The stock must be already updated and the balance set
+func appendStockMovement(tx *gorm.DB, movement *domain.StockMovement) error {
+	movementDb := database_dtos.ConvertStockMovementDomainToModel(movement)
+	if err := tx.Create(movementDb).Error; err != nil {
+		return err
+	}
+	movement.ID = movementDb.ID
+	movement.CreatedAt = movementDb.CreatedAt
+	return nil
+}
+
+// helper func, records the stock a product or variant is created with as a receipt of the default warehouse
+func appendInitialStock(tx *gorm.DB, productID uuid.UUID, variantID *uuid.UUID, stock int64, actorID *uuid.UUID) error {
+	if stock <= 0 {
+		return nil
+	}
+
+	warehouseID, err := defaultWarehouseID(tx)
+	if err != nil {
+		return err
+	}
+
+	movement, err := domain.NewStockMovement(productID, variantID, &warehouseID, domain.StockReceipt, stock, "initial stock", actorID)
+	if err != nil {
+		return err
+	}
+	if err := applyWarehouseDelta(tx, movement, domain.ErrProductInsufficientStock); err != nil {
+		return err
+	}
+	movement.Balance = stock
+	return appendStockMovement(tx, movement)
+}
+
+// helper func, applies the delta of the movement to the stock of its warehouse. The row of the warehouse is
+// created with the first units received, the stock can't go below 0
+func applyWarehouseDelta(tx *gorm.DB, movement *domain.StockMovement, insufficient error) error {
+	variantID := uuid.Nil
+	if movement.VariantID != nil {
+		variantID = *movement.VariantID
+	}
+
+	if movement.Delta > 0 {
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "warehouse_id"}, {Name: "product_id"}, {Name: "variant_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"stock": gorm.Expr("warehouse_stock.stock + excluded.stock"), "updated_at": time.Now()}),
+		}).Create(&models.WarehouseStockModel{
+			WarehouseID: *movement.WarehouseID,
+			ProductID:   movement.ProductID,
+			VariantID:   variantID,
+			Stock:       movement.Delta,
+		}).Error
+	}
+
+	result := tx.Model(&models.WarehouseStockModel{}).
+		Where("warehouse_id = ? AND product_id = ? AND variant_id = ? AND stock + ? >= 0", *movement.WarehouseID, movement.ProductID, variantID, movement.Delta).
+		Update("stock", gorm.Expr("stock + ?", movement.Delta))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return insufficient
+	}
+	return nil
+}
+
+// helper func, shared by the product and inventory repositories. Applies the delta of the movement to the stock of the
+// product or variant and of its warehouse, and appends it to the ledger. Must run inside a transaction.
+// The updates are conditional so concurrent movements can't take the stock below 0
+func applyStockMovement(tx *gorm.DB, movement *domain.StockMovement) error {
+	notFound, insufficient := domain.ErrProductNotFound, domain.ErrProductInsufficientStock
+	target := func() *gorm.DB {
+		// archived products can't lose stock, but their orders can still be cancelled or paid
+		query := tx.Model(&models.ProductModel{}).Where("id = ?", movement.ProductID)
+		if movement.Delta >= 0 {
+			query = query.Unscoped()
+		}
+		return query
+	}
+	if movement.VariantID != nil {
+		notFound, insufficient = domain.ErrVariantNotFound, domain.ErrVariantInsufficientStock
+		target = func() *gorm.DB {
+			return tx.Model(&models.VariantModel{}).Where("id = ? AND product_id = ?", *movement.VariantID, movement.ProductID)
+		}
+	}
+
+	if movement.Delta != 0 {
+		// the stock changed without a warehouse goes to the default one
+		if movement.WarehouseID == nil {
+			warehouseID, err := defaultWarehouseID(tx)
+			if err != nil {
+				return err
+			}
+			movement.WarehouseID = &warehouseID
+		}
+
+		result := target().Where("stock + ? >= 0", movement.Delta).Update("stock", gorm.Expr("stock + ?", movement.Delta))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var found int64
+			if err := target().Count(&found).Error; err != nil {
+				return err
+			}
+			if found == 0 {
+				return notFound
+			}
+			return insufficient
+		}
+
+		if err := applyWarehouseDelta(tx, movement, insufficient); err != nil {
+			return err
+		}
+	}
+
+	var balances []int64
+	if err := target().Pluck("stock", &balances).Error; err != nil {
+		return err
+	}
+	if len(balances) == 0 {
+		return notFound
+	}
+	movement.Balance = balances[0]
+
+	return appendStockMovement(tx, movement)
+}
+
+// SaveMovement implements ports.InventoryRepository.
+func (ir *InventoryRepo) SaveMovement(ctx context.Context, movement *domain.StockMovement) (*domain.StockMovement, error) {
+	err := ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return applyStockMovement(tx, movement)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return movement, nil
+}
+
+// ListMovements implements ports.InventoryRepository.
+func (ir *InventoryRepo) ListMovements(ctx context.Context, productID uuid.UUID) ([]domain.StockMovement, error) {
+	var movementsDb []models.StockMovementModel
+
+	result := ir.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at ASC, id").Find(&movementsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertStockMovementModelsToDomains(movementsDb), nil
+}
+
+// StockAt implements ports.InventoryRepository.
+func (ir *InventoryRepo) StockAt(ctx context.Context, productID uuid.UUID, at time.Time) ([]domain.StockLevel, error) {
+	var rows []struct {
+		VariantID   *uuid.UUID
+		WarehouseID *uuid.UUID
+		Stock       int64
+	}
+
+	result := ir.db.WithContext(ctx).
+		Model(&models.StockMovementModel{}).
+		Select("variant_id, warehouse_id, SUM(delta) AS stock").
+		Where("product_id = ? AND created_at <= ?", productID, at).
+		Group("variant_id, warehouse_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	levels := make([]domain.StockLevel, 0, len(rows))
+	for _, row := range rows {
+		levels = append(levels, domain.StockLevel{ProductID: productID, VariantID: row.VariantID, WarehouseID: row.WarehouseID, Stock: row.Stock})
+	}
+	return levels, nil
+}
+
+// AuditStock implements ports.InventoryRepository.
+// Archived products are audited too, their stock can still change
+func (ir *InventoryRepo) AuditStock(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	var products []struct {
+		ID          uuid.UUID
+		Stock       int64
+		LedgerStock int64
+	}
+
+	result := ir.db.WithContext(ctx).
+		Table("product_models AS p").
+		Select("p.id, p.stock, COALESCE(SUM(m.delta), 0) AS ledger_stock").
+		Joins("LEFT JOIN stock_movements AS m ON m.product_id = p.id AND m.variant_id IS NULL").
+		Group("p.id, p.stock").
+		Having("p.stock <> COALESCE(SUM(m.delta), 0)").
+		Scan(&products)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var variants []struct {
+		ID          uuid.UUID
+		ProductID   uuid.UUID
+		Stock       int64
+		LedgerStock int64
+	}
+
+	result = ir.db.WithContext(ctx).
+		Table("variant_models AS v").
+		Select("v.id, v.product_id, v.stock, COALESCE(SUM(m.delta), 0) AS ledger_stock").
+		Joins("LEFT JOIN stock_movements AS m ON m.variant_id = v.id").
+		Group("v.id, v.product_id, v.stock").
+		Having("v.stock <> COALESCE(SUM(m.delta), 0)").
+		Scan(&variants)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	var warehouses []struct {
+		WarehouseID uuid.UUID
+		ProductID   uuid.UUID
+		VariantID   uuid.UUID
+		Stock       int64
+		LedgerStock int64
+	}
+
+	result = ir.db.WithContext(ctx).
+		Table("warehouse_stock AS ws").
+		Select("ws.warehouse_id, ws.product_id, ws.variant_id, ws.stock, COALESCE(SUM(m.delta), 0) AS ledger_stock").
+		Joins("LEFT JOIN stock_movements AS m ON m.warehouse_id = ws.warehouse_id AND m.product_id = ws.product_id AND COALESCE(m.variant_id, ?) = ws.variant_id", uuid.Nil).
+		Group("ws.warehouse_id, ws.product_id, ws.variant_id, ws.stock").
+		Having("ws.stock <> COALESCE(SUM(m.delta), 0)").
+		Scan(&warehouses)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	discrepancies := make([]domain.StockDiscrepancy, 0, len(products)+len(variants)+len(warehouses))
+	for _, p := range products {
+		discrepancies = append(discrepancies, domain.StockDiscrepancy{ProductID: p.ID, Stock: p.Stock, LedgerStock: p.LedgerStock})
+	}
+	for _, v := range variants {
+		variantID := v.ID
+		discrepancies = append(discrepancies, domain.StockDiscrepancy{ProductID: v.ProductID, VariantID: &variantID, Stock: v.Stock, LedgerStock: v.LedgerStock})
+	}
+	for _, w := range warehouses {
+		level := database_dtos.ConvertWarehouseStockModelToDomain(&models.WarehouseStockModel{WarehouseID: w.WarehouseID, ProductID: w.ProductID, VariantID: w.VariantID})
+		discrepancies = append(discrepancies, domain.StockDiscrepancy{ProductID: w.ProductID, VariantID: level.VariantID, WarehouseID: &level.WarehouseID, Stock: w.Stock, LedgerStock: w.LedgerStock})
+	}
+	return discrepancies, nil
+}
+
+// ReserveAllocations implements ports.InventoryRepository.
+func (ir *InventoryRepo) ReserveAllocations(ctx context.Context, allocations []domain.StockAllocation) ([]domain.StockAllocation, error) {
+	reserved := make([]domain.StockAllocation, 0, len(allocations))
+
+	err := ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, allocation := range allocations {
+			movement, err := domain.NewStockMovement(allocation.ProductID, allocation.VariantID, &allocation.WarehouseID, domain.StockReservation, allocation.Quantity, "reserved by a new order", nil)
+			if err != nil {
+				return err
+			}
+			if err := applyStockMovement(tx, movement); err != nil {
+				return err
+			}
+
+			allocationDb := database_dtos.ConvertStockAllocationDomainToModel(&allocation)
+			if err := tx.Create(allocationDb).Error; err != nil {
+				return err
+			}
+			reserved = append(reserved, *database_dtos.ConvertStockAllocationModelToDomain(allocationDb))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reserved, nil
+}
+
+// AssignAllocations implements ports.InventoryRepository.
+func (ir *InventoryRepo) AssignAllocations(ctx context.Context, ids []uuid.UUID, orderID uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return ir.db.WithContext(ctx).Model(&models.StockAllocationModel{}).Where("id IN ?", ids).Update("order_id", orderID).Error
+}
+
+// ListOrderAllocations implements ports.InventoryRepository.
+func (ir *InventoryRepo) ListOrderAllocations(ctx context.Context, orderID uuid.UUID) ([]domain.StockAllocation, error) {
+	var allocationsDb []models.StockAllocationModel
+
+	if result := ir.db.WithContext(ctx).Where("order_id = ?", orderID).Order("created_at ASC, id").Find(&allocationsDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertStockAllocationModelsToDomains(allocationsDb), nil
+}
+
+// CloseAllocations implements ports.InventoryRepository.
+// The status is changed only while the allocation is reserved, so an allocation is never released or sold twice
+func (ir *InventoryRepo) CloseAllocations(ctx context.Context, allocations []domain.StockAllocation, status domain.StockAllocationStatus) error {
+	movementType, reason := domain.StockRelease, "reservation released"
+	if status == domain.AllocationSold {
+		movementType, reason = domain.StockSale, "order paid"
+	}
+
+	return ir.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, allocation := range allocations {
+			result := tx.Model(&models.StockAllocationModel{}).
+				Where("id = ? AND status = ?", allocation.ID, domain.AllocationReserved).
+				Update("status", status)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			reason := reason
+			if allocation.OrderID != nil {
+				reason = "order " + allocation.OrderID.String() + " " + string(status)
+			}
+			movement, err := domain.NewStockMovement(allocation.ProductID, allocation.VariantID, &allocation.WarehouseID, movementType, allocation.Quantity, reason, nil)
+			if err != nil {
+				return err
+			}
+			if err := applyStockMovement(tx, movement); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
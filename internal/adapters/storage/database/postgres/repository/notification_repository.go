@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepo struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepo(db *gorm.DB) ports.NotificationRepository {
+	return &NotificationRepo{db: db}
+}
+
+// Enqueue implements ports.NotificationRepository.
+func (nr *NotificationRepo) Enqueue(ctx context.Context, notification *domain.QueuedNotification) (*domain.QueuedNotification, error) {
+	notificationDb := database_dtos.ConvertNotificationDomainToModel(notification)
+
+	if result := nr.db.WithContext(ctx).Create(notificationDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertNotificationModelToDomain(notificationDb), nil
+}
+
+// ClaimDue implements ports.NotificationRepository.
+// A notification is claimed only if it's still due, the claim of another dispatcher moved it to the future
+func (nr *NotificationRepo) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.QueuedNotification, error) {
+	var notificationsDb []models.NotificationModel
+
+	result := nr.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", domain.NotificationPending, now).
+		Order("next_attempt_at ASC, id").
+		Limit(limit).
+		Find(&notificationsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	leasedUntil := now.Add(lease)
+	claimed := make([]models.NotificationModel, 0, len(notificationsDb))
+	for _, notificationDb := range notificationsDb {
+		result := nr.db.WithContext(ctx).
+			Model(&models.NotificationModel{}).
+			Where("id = ? AND status = ? AND next_attempt_at <= ?", notificationDb.ID, domain.NotificationPending, now).
+			UpdateColumn("next_attempt_at", leasedUntil)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		notificationDb.NextAttemptAt = leasedUntil
+		claimed = append(claimed, notificationDb)
+	}
+
+	return database_dtos.ConvertNotificationModelsToDomains(claimed), nil
+}
+
+// SaveAttempt implements ports.NotificationRepository.
+func (nr *NotificationRepo) SaveAttempt(ctx context.Context, notification *domain.QueuedNotification) error {
+	notificationDb := database_dtos.ConvertNotificationDomainToModel(notification)
+
+	// the error and the sent time are cleared or set on every attempt, they are selected to save their zero values
+	return nr.db.WithContext(ctx).
+		Model(&models.NotificationModel{}).
+		Where("id = ?", notification.ID).
+		Select("status", "attempts", "next_attempt_at", "last_error", "sent_at", "updated_at").
+		Updates(notificationDb).Error
+}
@@ -6,6 +6,7 @@ import (
 	"go-ecommerce/internal/adapters/storage/database/postgres/models"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -47,7 +48,7 @@ func (or *OrderRepo) GetOrderById(ctx context.Context, id uuid.UUID) (*domain.Or
 
 	if result := or.db.WithContext(ctx).Preload("Items").First(orderDb, "id = ?", id); result.Error != nil {
 		if result.RowsAffected == 0 {
-			return nil, domain.ErrProductNotFound
+			return nil, domain.ErrOrderNotFound
 		}
 		return nil, result.Error
 	}
@@ -70,3 +71,42 @@ func (or *OrderRepo) ListOrders(ctx context.Context) ([]*domain.Order, error) {
 	orderDomain := database_dtos.ConvertOrdersModelsToDomain(orderDb)
 	return orderDomain, nil
 }
+
+// ListOrdersByStatus implements ports.OrderRepository.
+// If createdAfter is zero, orders are not filtered by creation date
+func (or *OrderRepo) ListOrdersByStatus(ctx context.Context, statuses []domain.PayStatus, createdAfter time.Time) ([]*domain.Order, error) {
+	var ordersDb []*models.OrderModel
+
+	query := or.db.WithContext(ctx).Preload("Items").Where("pay_status IN ?", statuses)
+	if !createdAfter.IsZero() {
+		query = query.Where("created_at >= ?", createdAfter)
+	}
+
+	if result := query.Find(&ordersDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	ordersDomain := make([]*domain.Order, len(ordersDb))
+	for i, o := range ordersDb {
+		ordersDomain[i] = database_dtos.ConvertOrderModelToDomain(o)
+	}
+	return ordersDomain, nil
+}
+
+// DeleteOrder implements ports.OrderRepository.
+func (or *OrderRepo) DeleteOrder(ctx context.Context, id uuid.UUID) error {
+	return or.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.OrderProductModel{}, "order_id = ?", id).Error; err != nil {
+			return err
+		}
+
+		result := tx.Delete(&models.OrderModel{}, "id = ?", id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrOrderNotFound
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentAttemptRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentAttemptRepo(db *gorm.DB) ports.PaymentAttemptRepository {
+	return &PaymentAttemptRepo{db: db}
+}
+
+// SaveAttempt implements ports.PaymentAttemptRepository.
+// The provider notifies the same payment every time it changes, so attempts are upserted by payment id
+func (pr *PaymentAttemptRepo) SaveAttempt(ctx context.Context, attempt *domain.PaymentAttempt) (*domain.PaymentAttempt, error) {
+	attemptDb := database_dtos.ConvertPaymentAttemptDomainToModel(attempt)
+
+	result := pr.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "payment_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"status", "status_detail", "amount", "net_received_amount", "pay_method",
+			"pay_resource", "installments", "raw_payload", "updated_at",
+		}),
+	}).Create(attemptDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	// on conflict the id of the model is not the stored one
+	var savedDb models.PaymentAttemptModel
+	if result := pr.db.WithContext(ctx).First(&savedDb, "payment_id = ?", attempt.PaymentID); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPaymentAttemptModelToDomain(&savedDb), nil
+}
+
+// ListAttemptsByOrder implements ports.PaymentAttemptRepository.
+func (pr *PaymentAttemptRepo) ListAttemptsByOrder(ctx context.Context, orderId uuid.UUID) ([]*domain.PaymentAttempt, error) {
+	var attemptsDb []*models.PaymentAttemptModel
+
+	result := pr.db.WithContext(ctx).Where("order_id = ?", orderId).Order("created_at ASC").Find(&attemptsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPaymentAttemptModelsToDomains(attemptsDb), nil
+}
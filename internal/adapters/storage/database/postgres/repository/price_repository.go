@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PriceRepo struct {
+	db *gorm.DB
+}
+
+func NewPriceRepo(db *gorm.DB) ports.PriceRepository {
+	return &PriceRepo{db: db}
+}
+
+// helper func, shared by the product and price repositories. A nil change is ignored
+func savePriceChange(tx *gorm.DB, change *domain.PriceChange) error {
+	if change == nil {
+		return nil
+	}
+
+	changeDb := database_dtos.ConvertPriceChangeDomainToModel(change)
+	if err := tx.Create(changeDb).Error; err != nil {
+		return err
+	}
+	change.ID = changeDb.ID
+	return nil
+}
+
+// ListPriceHistory implements ports.PriceRepository.
+func (r *PriceRepo) ListPriceHistory(ctx context.Context, productID uuid.UUID) ([]domain.PriceChange, error) {
+	var changesDb []models.ProductPriceHistoryModel
+
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at DESC, id").Find(&changesDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPriceChangeModelsToDomains(changesDb), nil
+}
+
+// SaveSchedule implements ports.PriceRepository.
+func (r *PriceRepo) SaveSchedule(ctx context.Context, schedule *domain.PriceSchedule) (*domain.PriceSchedule, error) {
+	scheduleDb := database_dtos.ConvertPriceScheduleDomainToModel(schedule)
+
+	// Save writes every column, the nullable fields of the schedule can be cleared
+	if result := r.db.WithContext(ctx).Save(scheduleDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPriceScheduleModelToDomain(scheduleDb), nil
+}
+
+// GetScheduleById implements ports.PriceRepository.
+func (r *PriceRepo) GetScheduleById(ctx context.Context, id uuid.UUID) (*domain.PriceSchedule, error) {
+	var scheduleDb models.PriceScheduleModel
+
+	if result := r.db.WithContext(ctx).First(&scheduleDb, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrPriceScheduleNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPriceScheduleModelToDomain(&scheduleDb), nil
+}
+
+// ListSchedules implements ports.PriceRepository.
+func (r *PriceRepo) ListSchedules(ctx context.Context, productID uuid.UUID) ([]*domain.PriceSchedule, error) {
+	var schedulesDb []models.PriceScheduleModel
+
+	result := r.db.WithContext(ctx).Where("product_id = ?", productID).Order("starts_at ASC").Find(&schedulesDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPriceScheduleModelsToDomains(schedulesDb), nil
+}
+
+// ListDueSchedules implements ports.PriceRepository.
+// Pending schedules that must start and running sales that must end, in the order they are due
+func (r *PriceRepo) ListDueSchedules(ctx context.Context, now time.Time) ([]*domain.PriceSchedule, error) {
+	var schedulesDb []models.PriceScheduleModel
+
+	result := r.db.WithContext(ctx).
+		Where("(status = ? AND starts_at <= ?) OR (status = ? AND ends_at <= ?)", domain.SchedulePending, now, domain.ScheduleActive, now).
+		Order(gorm.Expr("CASE WHEN status = ? THEN ends_at ELSE starts_at END ASC", domain.ScheduleActive)).
+		Find(&schedulesDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertPriceScheduleModelsToDomains(schedulesDb), nil
+}
+
+// ApplySchedule implements ports.PriceRepository.
+func (r *PriceRepo) ApplySchedule(ctx context.Context, schedule *domain.PriceSchedule, product *domain.Product, change *domain.PriceChange) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		productDb := database_dtos.ConvertProductDomainToModel(product)
+
+		result := tx.Model(&models.ProductModel{}).
+			Where("id = ?", product.ID).
+			Select("price", "discount", "discount_type", "updated_at").
+			Updates(productDb)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrProductNotFound
+		}
+
+		if err := tx.Save(database_dtos.ConvertPriceScheduleDomainToModel(schedule)).Error; err != nil {
+			return err
+		}
+		return savePriceChange(tx, change)
+	})
+}
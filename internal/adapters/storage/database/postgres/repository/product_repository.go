@@ -2,13 +2,19 @@ package repository
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
 	"go-ecommerce/internal/adapters/storage/database/postgres/models"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepo struct {
@@ -19,22 +25,71 @@ func NewProductRepo(db *gorm.DB) ports.ProductRepository {
 	return &ProductRepo{db: db}
 }
 
+// helper func, the unique index of the sku includes the archived products, their sku can't be reused
+func skuOfArchivedProduct(tx *gorm.DB, product *domain.Product) error {
+	var count int64
+	err := tx.Unscoped().Model(&models.ProductModel{}).
+		Where("sku = ? AND id <> ? AND deleted_at IS NOT NULL", product.SKU, product.ID).
+		Count(&count).Error
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return domain.ErrProductSKUArchived
+	}
+	return nil
+}
+
 // SaveProduct implements ports.ProductRepository.
+// Changes of the price or discount are recorded in the price history in the same transaction. An update keeps the
+// current stock, the stock only changes through the movements of the stock ledger. The sku of an archived product
+// is rejected with its own error, the product must be restored instead
 func (pr *ProductRepo) SaveProduct(ctx context.Context, product *domain.Product) (*domain.Product, error) {
 	productDb := database_dtos.ConvertProductDomainToModel(product)
 
-	// if exist product.ID update, else create new product
-	if product.ID != uuid.Nil {
-		if result := pr.db.WithContext(ctx).Preload("Category").Where("id = ?", product.ID).Updates(productDb); result.Error != nil {
-			if result.RowsAffected == 0 {
-				return nil, domain.ErrProductNotFound
-			}
-			return nil, result.Error
+	err := pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := skuOfArchivedProduct(tx, product); err != nil {
+			return err
 		}
-	} else {
-		if result := pr.db.WithContext(ctx).Create(productDb); result.Error != nil {
-			return nil, result.Error
+
+		var before domain.Pricing
+
+		// if exist product.ID update, else create new product
+		if product.ID != uuid.Nil {
+			var current models.ProductModel
+			if result := tx.Select("id", "stock", "price", "discount", "discount_type").First(&current, "id = ?", product.ID); result.Error != nil {
+				if result.RowsAffected == 0 {
+					return domain.ErrProductNotFound
+				}
+				return result.Error
+			}
+			before = database_dtos.ConvertProductModelToDomain(&current).Pricing()
+
+			if result := tx.Omit("stock").Where("id = ?", product.ID).Updates(productDb); result.Error != nil {
+				return translateError(result.Error)
+			}
+			// the discount and the low stock threshold are selected so they can be removed
+			if result := tx.Model(productDb).Select("discount", "discount_type", "low_stock_threshold").Updates(productDb); result.Error != nil {
+				return result.Error
+			}
+
+			// the stock is changed only by the movements of the ledger
+			productDb.Stock = current.Stock
+		} else {
+			if result := tx.Create(productDb); result.Error != nil {
+				return translateError(result.Error)
+			}
+
+			if err := appendInitialStock(tx, productDb.ID, nil, productDb.Stock, product.UpdatedBy); err != nil {
+				return err
+			}
 		}
+
+		change := domain.NewPriceChange(productDb.ID, before, product.Pricing(), product.UpdatedBy, nil)
+		return savePriceChange(tx, change)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	productDomain := database_dtos.ConvertProductModelToDomain(productDb)
@@ -45,7 +100,37 @@ func (pr *ProductRepo) SaveProduct(ctx context.Context, product *domain.Product)
 func (pr *ProductRepo) GetProductById(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	var productDb = &models.ProductModel{}
 
-	if result := pr.db.WithContext(ctx).Preload("Category").First(productDb, "id = ?", id); result.Error != nil {
+	if result := pr.db.WithContext(ctx).Preload("Category").Preload("Variants").Preload("Images", orderImages).First(productDb, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, result.Error
+	}
+
+	productDomain := database_dtos.ConvertProductModelToDomain(productDb)
+	return productDomain, nil
+}
+
+// GetProductBySKU implements ports.ProductRepository.
+func (pr *ProductRepo) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	var productDb = &models.ProductModel{}
+
+	if result := pr.db.WithContext(ctx).Preload("Category").Preload("Variants").Preload("Images", orderImages).First(productDb, "sku = ?", sku); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, result.Error
+	}
+
+	productDomain := database_dtos.ConvertProductModelToDomain(productDb)
+	return productDomain, nil
+}
+
+// GetProductBySlug implements ports.ProductRepository.
+func (pr *ProductRepo) GetProductBySlug(ctx context.Context, slug string) (*domain.Product, error) {
+	var productDb = &models.ProductModel{}
+
+	if result := pr.db.WithContext(ctx).Preload("Category").Preload("Variants").Preload("Images", orderImages).First(productDb, "slug = ?", slug); result.Error != nil {
 		if result.RowsAffected == 0 {
 			return nil, domain.ErrProductNotFound
 		}
@@ -60,7 +145,7 @@ func (pr *ProductRepo) GetProductById(ctx context.Context, id uuid.UUID) (*domai
 func (pr *ProductRepo) ListProducts(ctx context.Context) ([]*domain.Product, error) {
 	var productsDb []*models.ProductModel
 
-	if result := pr.db.WithContext(ctx).Preload("Category").Find(&productsDb); result.Error != nil {
+	if result := pr.db.WithContext(ctx).Preload("Category").Preload("Variants").Find(&productsDb); result.Error != nil {
 		if result.RowsAffected == 0 {
 			return nil, domain.ErrProductsNotFound
 		}
@@ -72,7 +157,378 @@ func (pr *ProductRepo) ListProducts(ctx context.Context) ([]*domain.Product, err
 }
 
 // DeleteProduct implements ports.ProductRepository.
+// The product is archived (soft deleted), its variants and images are kept so it can be restored
 func (pr *ProductRepo) DeleteProduct(ctx context.Context, id uuid.UUID) error {
+	result := pr.db.WithContext(ctx).Delete(&models.ProductModel{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrProductNotFound
+	}
+	return nil
+}
+
+// GetProductIncludingArchived implements ports.ProductRepository.
+func (pr *ProductRepo) GetProductIncludingArchived(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
 	var productDb = &models.ProductModel{}
-	return pr.db.WithContext(ctx).Delete(productDb, "id = ?", id).Error
+
+	if result := pr.db.WithContext(ctx).Unscoped().Preload("Variants").Preload("Images", orderImages).First(productDb, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrProductNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertProductModelToDomain(productDb), nil
+}
+
+// RestoreProduct implements ports.ProductRepository.
+func (pr *ProductRepo) RestoreProduct(ctx context.Context, id uuid.UUID) error {
+	return pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var productDb models.ProductModel
+		if result := tx.Unscoped().First(&productDb, "id = ?", id); result.Error != nil {
+			if result.RowsAffected == 0 {
+				return domain.ErrProductNotFound
+			}
+			return result.Error
+		}
+		if !productDb.DeletedAt.Valid {
+			return domain.ErrProductNotArchived
+		}
+
+		// the product can't be restored into an archived category
+		var categories int64
+		if err := tx.Model(&models.CategoryModel{}).Where("id = ?", productDb.CategoryID).Count(&categories).Error; err != nil {
+			return err
+		}
+		if categories == 0 {
+			return domain.ErrProductCategoryArchived
+		}
+
+		return tx.Unscoped().Model(&models.ProductModel{}).Where("id = ?", id).Update("deleted_at", nil).Error
+	})
+}
+
+// ListPurgeableProducts implements ports.ProductRepository.
+// Products that appear in orders are kept so the order history keeps resolving them
+func (pr *ProductRepo) ListPurgeableProducts(ctx context.Context, archivedBefore time.Time) ([]*domain.Product, error) {
+	var productsDb []*models.ProductModel
+
+	ordered := pr.db.Model(&models.OrderProductModel{}).Select("product_id")
+	result := pr.db.WithContext(ctx).Unscoped().
+		Preload("Images").
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", archivedBefore).
+		Where("id NOT IN (?)", ordered).
+		Find(&productsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertProductsModelsToDomain(productsDb), nil
+}
+
+// PurgeProduct implements ports.ProductRepository.
+// Only archived products are removed, with their variants and images
+func (pr *ProductRepo) PurgeProduct(ctx context.Context, id uuid.UUID) error {
+	return pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		archived := tx.Unscoped().Model(&models.ProductModel{}).Select("id").Where("id = ? AND deleted_at IS NOT NULL", id)
+
+		// variants and images are removed explicitly, sqlite doesn't enforce the cascade of the foreign key
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.VariantModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.ProductImageModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.ProductPriceHistoryModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.PriceScheduleModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.StockMovementModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.WarehouseStockModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.StockAllocationModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("product_id IN (?)", archived).Delete(&models.StockAlertSubscriptionModel{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).Delete(&models.ProductModel{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrProductNotFound
+		}
+		return nil
+	})
+}
+
+// SaveVariant implements ports.ProductRepository.
+// Like SaveProduct, an update keeps the current stock of the variant, it only changes through the stock ledger
+func (pr *ProductRepo) SaveVariant(ctx context.Context, variant *domain.Variant) (*domain.Variant, error) {
+	variantDb := database_dtos.ConvertVariantDomainToModel(variant)
+
+	err := pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// if exist variant.ID update, else create new variant. Price is selected so it can be cleared
+		if variant.ID != uuid.Nil {
+			result := tx.
+				Select("sku", "options", "price", "updated_at").
+				Where("id = ?", variant.ID).
+				Updates(variantDb)
+			if result.Error != nil {
+				return translateError(result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrVariantNotFound
+			}
+
+			// the stock is changed only by the movements of the ledger
+			return tx.Model(&models.VariantModel{}).Where("id = ?", variant.ID).Select("stock").Scan(&variantDb.Stock).Error
+		}
+
+		if result := tx.Create(variantDb); result.Error != nil {
+			return translateError(result.Error)
+		}
+		return appendInitialStock(tx, variantDb.ProductID, &variantDb.ID, variantDb.Stock, variant.UpdatedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return database_dtos.ConvertVariantModelToDomain(variantDb), nil
+}
+
+// GetVariantById implements ports.ProductRepository.
+func (pr *ProductRepo) GetVariantById(ctx context.Context, id uuid.UUID) (*domain.Variant, error) {
+	var variantDb = &models.VariantModel{}
+
+	if result := pr.db.WithContext(ctx).First(variantDb, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrVariantNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertVariantModelToDomain(variantDb), nil
+}
+
+// DeleteVariant implements ports.ProductRepository.
+func (pr *ProductRepo) DeleteVariant(ctx context.Context, id uuid.UUID) error {
+	result := pr.db.WithContext(ctx).Delete(&models.VariantModel{}, "id = ?", id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrVariantNotFound
+	}
+	return nil
+}
+
+// helper func, the gallery is loaded in the order chosen by the seller
+func orderImages(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
+
+// SaveGallery implements ports.ProductRepository.
+// The images of the product are replaced by the gallery and the image of the product is set to the primary one
+func (pr *ProductRepo) SaveGallery(ctx context.Context, product *domain.Product) error {
+	return pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ids := make([]uuid.UUID, 0, len(product.Images))
+		for i := range product.Images {
+			imageDb := database_dtos.ConvertProductImageDomainToModel(&product.Images[i])
+			imageDb.ProductID = product.ID
+
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"position", "is_primary", "updated_at"}),
+			}).Create(imageDb)
+			if result.Error != nil {
+				return result.Error
+			}
+
+			product.Images[i].ID = imageDb.ID
+			product.Images[i].CreatedAt = imageDb.CreatedAt
+			ids = append(ids, imageDb.ID)
+		}
+
+		removed := tx.Where("product_id = ?", product.ID)
+		if len(ids) > 0 {
+			removed = removed.Where("id NOT IN ?", ids)
+		}
+		if err := removed.Delete(&models.ProductImageModel{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.ProductModel{}).Where("id = ?", product.ID).Update("image", product.Image)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return domain.ErrProductNotFound
+		}
+		return nil
+	})
+}
+
+// productCursor is the position after the last product of a page: its sort key and its id, that breaks the ties.
+// Pages don't skip or repeat products when products are created or deleted between requests
+type productCursor struct {
+	Sort      ports_dtos.ProductSort `json:"sort"`
+	ID        uuid.UUID              `json:"id"`
+	Price     float64                `json:"price"`
+	Name      string                 `json:"name"`
+	CreatedAt time.Time              `json:"created_at"`
+	Rank      float64                `json:"rank"`
+}
+
+// helper funcs, the cursor is encoded so clients treat it as opaque
+func encodeProductCursor(cursor productCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeProductCursor(cursor string, sort ports_dtos.ProductSort) (*productCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, domain.ErrInvalidSearchCursor
+	}
+
+	var decoded productCursor
+	if err := json.Unmarshal(raw, &decoded); err != nil || decoded.ID == uuid.Nil || decoded.Sort != sort {
+		return nil, domain.ErrInvalidSearchCursor
+	}
+	return &decoded, nil
+}
+
+// SearchProducts implements ports.ProductRepository.
+// Postgres uses full text search over name and SKU, other dialects (sqlite in tests) fall back to LIKE
+func (pr *ProductRepo) SearchProducts(ctx context.Context, query ports_dtos.ProductQuery) (*ports.ProductPage, error) {
+	after, err := decodeProductCursor(query.Cursor, query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	isPostgres := pr.db.Dialector.Name() == "postgres"
+	tx := pr.db.WithContext(ctx).Model(&models.ProductModel{})
+
+	if query.Q != "" {
+		if isPostgres {
+			tx = tx.Where(models.ProductSearchVector+" @@ plainto_tsquery('simple', ?)", query.Q)
+		} else {
+			like := "%" + strings.ToLower(query.Q) + "%"
+			tx = tx.Where("LOWER(name) LIKE ? OR LOWER(sku) LIKE ?", like, like)
+		}
+	}
+	if query.CategoryID != nil {
+		// a category filter also matches products of its subcategories
+		tx = tx.Where(`category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM category_models WHERE id = ?
+				UNION ALL
+				SELECT c.id FROM category_models c JOIN tree t ON c.parent_id = t.id
+			)
+			SELECT id FROM tree)`, *query.CategoryID)
+	}
+	if query.MinPrice != nil {
+		tx = tx.Where("price >= ?", *query.MinPrice)
+	}
+	if query.MaxPrice != nil {
+		tx = tx.Where("price <= ?", *query.MaxPrice)
+	}
+	if query.InStock != nil {
+		if *query.InStock {
+			tx = tx.Where("stock > 0")
+		} else {
+			tx = tx.Where("stock <= 0")
+		}
+	}
+
+	var total int64
+	if result := tx.Count(&total); result.Error != nil {
+		return nil, result.Error
+	}
+
+	// id is the last criteria so pages are stable between requests, the cursor continues after the last product
+	// of the previous page in the same order
+	rank := "ts_rank(" + models.ProductSearchVector + ", plainto_tsquery('simple', ?))"
+	byRank := isPostgres && query.Q != "" && query.Sort == ports_dtos.SortRelevance
+	switch query.Sort {
+	case ports_dtos.SortPriceAsc:
+		tx = tx.Order("price ASC")
+		if after != nil {
+			tx = tx.Where("(price > ? OR (price = ? AND id > ?))", after.Price, after.Price, after.ID)
+		}
+	case ports_dtos.SortPriceDesc:
+		tx = tx.Order("price DESC")
+		if after != nil {
+			tx = tx.Where("(price < ? OR (price = ? AND id > ?))", after.Price, after.Price, after.ID)
+		}
+	case ports_dtos.SortNameAsc:
+		tx = tx.Order("name ASC")
+		if after != nil {
+			tx = tx.Where("(name > ? OR (name = ? AND id > ?))", after.Name, after.Name, after.ID)
+		}
+	case ports_dtos.SortRelevance:
+		if byRank {
+			tx = tx.Order(clause.Expr{SQL: rank + " DESC", Vars: []any{query.Q}})
+			if after != nil {
+				tx = tx.Where("("+rank+" < ? OR ("+rank+" = ? AND id > ?))", query.Q, after.Rank, query.Q, after.Rank, after.ID)
+			}
+		} else if after != nil {
+			tx = tx.Where("id > ?", after.ID)
+		}
+	default:
+		tx = tx.Order("created_at DESC")
+		if after != nil {
+			tx = tx.Where("(created_at < ? OR (created_at = ? AND id > ?))", after.CreatedAt, after.CreatedAt, after.ID)
+		}
+	}
+
+	// one more product is read to know if there is a next page
+	var productsDb []*models.ProductModel
+	result := tx.Order("id ASC").Preload("Category").Preload("Variants").Limit(query.Limit + 1).Find(&productsDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	hasNext := len(productsDb) > query.Limit
+	if hasNext {
+		productsDb = productsDb[:query.Limit]
+	}
+
+	page := &ports.ProductPage{
+		Items: make([]*domain.Product, 0, len(productsDb)),
+		Total: total,
+	}
+	for _, p := range productsDb {
+		page.Items = append(page.Items, database_dtos.ConvertProductModelToDomain(p))
+	}
+
+	if hasNext && len(productsDb) > 0 {
+		last := productsDb[len(productsDb)-1]
+		next := productCursor{Sort: query.Sort, ID: last.ID, Price: last.Price, Name: last.Name, CreatedAt: last.CreatedAt}
+		if byRank {
+			err := pr.db.WithContext(ctx).Model(&models.ProductModel{}).
+				Select(rank, query.Q).Where("id = ?", last.ID).Scan(&next.Rank).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+		cursor := encodeProductCursor(next)
+		page.NextCursor = &cursor
+	}
+
+	return page, nil
 }
@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"context"
+	"go-ecommerce/internal/adapters/shared"
 	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports/ports_dtos"
@@ -160,3 +161,185 @@ func Test_DeleteProduct(t *testing.T) {
 	_, err = repos.prodRepo.GetProductById(ctx, newProduct.ID)
 	require.Error(t, domain.ErrProductNotFound, err)
 }
+
+func Test_SearchProducts(t *testing.T) {
+	ctx := context.Background()
+	_, repos := newProductRepoTx(t)
+
+	category, err := repos.categRepo.SaveCategory(ctx, testhelpers.NewDomainCategory("Phones"))
+	require.NoError(t, err)
+
+	for i, name := range []string{"Iphone 15", "Iphone 14", "Galaxy S24", "Pixel 8"} {
+		p := testhelpers.NewDomainProduct(name, category.ID)
+		p.SKU = faker.UUIDDigit()
+		p.Price = float64(100 * (i + 1))
+		_, err := repos.prodRepo.SaveProduct(ctx, p)
+		require.NoError(t, err)
+	}
+
+	// text search and sorting
+	page, err := repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{Q: "iphone", Sort: ports_dtos.SortPriceDesc, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "Iphone 14", page.Items[0].Name)
+	assert.Nil(t, page.NextCursor)
+
+	// price range
+	minPrice, maxPrice := 150.0, 350.0
+	page, err = repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{MinPrice: &minPrice, MaxPrice: &maxPrice, Sort: ports_dtos.SortPriceAsc, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, 200.0, page.Items[0].Price)
+
+	// pagination with the cursor covers every product once
+	seen := map[string]bool{}
+	query := ports_dtos.ProductQuery{Sort: ports_dtos.SortPriceAsc, Limit: 3}
+	for {
+		page, err = repos.prodRepo.SearchProducts(ctx, query)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4), page.Total)
+		for _, p := range page.Items {
+			seen[p.Name] = true
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		query.Cursor = *page.NextCursor
+	}
+	assert.Len(t, seen, 4)
+
+	_, err = repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{Cursor: "not-a-cursor", Limit: 3})
+	assert.ErrorIs(t, err, domain.ErrInvalidSearchCursor)
+}
+
+func Test_SearchProducts_CursorPages(t *testing.T) {
+	ctx := context.Background()
+	_, repos := newProductRepoTx(t)
+
+	category, err := repos.categRepo.SaveCategory(ctx, testhelpers.NewDomainCategory("Phones"))
+	require.NoError(t, err)
+
+	// helper, saves a product, some of them share the price so the id breaks the ties
+	save := func(name string, price float64) {
+		p := testhelpers.NewDomainProduct(name, category.ID)
+		p.SKU = faker.UUIDDigit()
+		p.Price = price
+		_, err := repos.prodRepo.SaveProduct(ctx, p)
+		require.NoError(t, err)
+	}
+	for i, name := range []string{"Iphone 15", "Iphone 14", "Galaxy S24", "Pixel 8", "Moto G"} {
+		save(name, float64(100*(i%3+1)))
+	}
+
+	sorts := []ports_dtos.ProductSort{ports_dtos.SortNewest, ports_dtos.SortPriceAsc, ports_dtos.SortPriceDesc, ports_dtos.SortNameAsc, ports_dtos.SortRelevance}
+	for _, sort := range sorts {
+		t.Run(string(sort), func(t *testing.T) {
+			// every page has one product, a product created between the pages doesn't repeat the others
+			seen := map[string]int{}
+			query := ports_dtos.ProductQuery{Sort: sort, Limit: 1}
+			for pages := 0; ; pages++ {
+				require.Less(t, pages, 10)
+				page, err := repos.prodRepo.SearchProducts(ctx, query)
+				require.NoError(t, err)
+				for _, p := range page.Items {
+					seen[p.Name]++
+				}
+				if pages == 1 {
+					save("Created "+string(sort), 150)
+				}
+				if page.NextCursor == nil {
+					break
+				}
+				query.Cursor = *page.NextCursor
+			}
+
+			for _, name := range []string{"Iphone 15", "Iphone 14", "Galaxy S24", "Pixel 8", "Moto G"} {
+				assert.Equal(t, 1, seen[name], name)
+			}
+		})
+	}
+
+	// a cursor only continues the sort that created it
+	page, err := repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{Sort: ports_dtos.SortPriceAsc, Limit: 1})
+	require.NoError(t, err)
+	require.NotNil(t, page.NextCursor)
+	_, err = repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{Sort: ports_dtos.SortNameAsc, Cursor: *page.NextCursor, Limit: 1})
+	assert.ErrorIs(t, err, domain.ErrInvalidSearchCursor)
+}
+
+func Test_SearchProducts_CategoryDescendants(t *testing.T) {
+	ctx := context.Background()
+	_, repos := newProductRepoTx(t)
+
+	parent, err := repos.categRepo.SaveCategory(ctx, testhelpers.NewDomainCategory("Computers"))
+	require.NoError(t, err)
+
+	c := testhelpers.NewDomainCategory("Laptops")
+	c.ParentID = &parent.ID
+	child, err := repos.categRepo.SaveCategory(ctx, c)
+	require.NoError(t, err)
+
+	for _, categoryID := range []uint64{parent.ID, child.ID} {
+		p := testhelpers.NewDomainProduct(faker.Word(), categoryID)
+		p.SKU = faker.UUIDDigit()
+		_, err := repos.prodRepo.SaveProduct(ctx, p)
+		require.NoError(t, err)
+	}
+
+	page, err := repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{CategoryID: &parent.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+
+	page, err = repos.prodRepo.SearchProducts(ctx, ports_dtos.ProductQuery{CategoryID: &child.ID, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+}
+
+func Test_ProductUniqueSKUAndSlug(t *testing.T) {
+	ctx := context.Background()
+	_, repos := newProductRepoTx(t)
+
+	categ, err := repos.categRepo.SaveCategory(ctx, testhelpers.NewDomainCategory("SmartPhones"))
+	require.NoError(t, err)
+
+	first, err := repos.prodRepo.SaveProduct(ctx, testhelpers.NewDomainProduct("Iphone 15 Pro", categ.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "iphone-15-pro", first.Slug)
+
+	// same name, the slug gets a suffix
+	second, err := repos.prodRepo.SaveProduct(ctx, testhelpers.NewDomainProduct("Iphone 15 Pro", categ.ID))
+	require.NoError(t, err)
+	assert.Equal(t, "iphone-15-pro-2", second.Slug)
+
+	bySlug, err := repos.prodRepo.GetProductBySlug(ctx, second.Slug)
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, bySlug.ID)
+
+	// repeated SKU on create and on update
+	dup := testhelpers.NewDomainProduct("Iphone 15", categ.ID)
+	dup.SKU = first.SKU
+	_, err = repos.prodRepo.SaveProduct(ctx, dup)
+	assert.ErrorIs(t, err, shared.ErrConflictingData)
+
+	second.SKU = first.SKU
+	_, err = repos.prodRepo.SaveProduct(ctx, second)
+	assert.ErrorIs(t, err, shared.ErrConflictingData)
+
+	// the SKU of an archived product asks to restore it
+	require.NoError(t, repos.prodRepo.DeleteProduct(ctx, first.ID))
+
+	dup.SKU = first.SKU
+	_, err = repos.prodRepo.SaveProduct(ctx, dup)
+	assert.ErrorIs(t, err, domain.ErrProductSKUArchived)
+
+	second.SKU = first.SKU
+	_, err = repos.prodRepo.SaveProduct(ctx, second)
+	assert.ErrorIs(t, err, domain.ErrProductSKUArchived)
+
+	// the archived product can still be restored
+	require.NoError(t, repos.prodRepo.RestoreProduct(ctx, first.ID))
+
+	_, err = repos.prodRepo.GetProductBySlug(ctx, "missing")
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+}
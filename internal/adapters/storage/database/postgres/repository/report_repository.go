@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ReportRepo struct {
+	db *gorm.DB
+}
+
+func NewReportRepo(db *gorm.DB) ports.ReportRepository {
+	return &ReportRepo{db: db}
+}
+
+// ListPaidOrders implements ports.ReportRepository.
+func (rr *ReportRepo) ListPaidOrders(ctx context.Context, filters ports_dtos.ReportFilters) ([]*domain.Order, error) {
+	var ordersDb []*models.OrderModel
+
+	result := rr.db.WithContext(ctx).
+		Where("paid = ? AND paid_at >= ? AND paid_at < ?", true, filters.From, filters.To).
+		Order("paid_at ASC").
+		Find(&ordersDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertOrdersModelsToDomain(ordersDb), nil
+}
+
+// CountOrdersByStatus implements ports.ReportRepository.
+func (rr *ReportRepo) CountOrdersByStatus(ctx context.Context, filters ports_dtos.ReportFilters) (map[domain.PayStatus]int64, error) {
+	var rows []struct {
+		PayStatus domain.PayStatus
+		Count     int64
+	}
+
+	result := rr.db.WithContext(ctx).
+		Model(&models.OrderModel{}).
+		Select("pay_status, COUNT(*) AS count").
+		Where("created_at >= ? AND created_at < ?", filters.From, filters.To).
+		Group("pay_status").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[domain.PayStatus]int64, len(rows))
+	for _, row := range rows {
+		counts[row.PayStatus] = row.Count
+	}
+	return counts, nil
+}
+
+// TopProducts implements ports.ReportRepository.
+func (rr *ReportRepo) TopProducts(ctx context.Context, filters ports_dtos.ReportFilters, rankBy domain.TopProductsRanking, limit int) ([]domain.TopProduct, error) {
+	var rows []struct {
+		ProductID uuid.UUID
+		Name      string
+		SKU       string
+		Units     int64
+		Revenue   float64
+	}
+
+	orderBy := "units DESC"
+	if rankBy == domain.ByRevenue {
+		orderBy = "revenue DESC"
+	}
+
+	result := rr.db.WithContext(ctx).
+		Table("order_product_models AS op").
+		Select("op.product_id, p.name, p.sku, SUM(op.quantity) AS units, SUM(op.quantity * (op.unit_price - op.unit_discount)) AS revenue").
+		Joins("JOIN order_models AS o ON o.id = op.order_id").
+		Joins("JOIN product_models AS p ON p.id = op.product_id").
+		Where("o.paid = ? AND o.paid_at >= ? AND o.paid_at < ?", true, filters.From, filters.To).
+		Group("op.product_id, p.name, p.sku").
+		Order(orderBy).
+		Limit(limit).
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	products := make([]domain.TopProduct, len(rows))
+	for i, row := range rows {
+		products[i] = domain.TopProduct{
+			ProductID: row.ProductID,
+			Name:      row.Name,
+			SKU:       row.SKU,
+			Units:     row.Units,
+			Revenue:   row.Revenue,
+		}
+	}
+	return products, nil
+}
+
+// ListDisputes implements ports.ReportRepository.
+func (rr *ReportRepo) ListDisputes(ctx context.Context, filters ports_dtos.ReportFilters) ([]*domain.Dispute, error) {
+	var disputesDb []*models.DisputeModel
+
+	result := rr.db.WithContext(ctx).
+		Where("created_at >= ? AND created_at < ?", filters.From, filters.To).
+		Order("created_at ASC").
+		Find(&disputesDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertDisputeModelsToDomains(disputesDb), nil
+}
+
+// ListCartRecoveries implements ports.ReportRepository.
+// The reminders are filtered by the time they were sent, their conversion can happen after the range
+func (rr *ReportRepo) ListCartRecoveries(ctx context.Context, filters ports_dtos.ReportFilters) ([]*domain.CartRecovery, error) {
+	var recoveriesDb []*models.CartRecoveryModel
+
+	result := rr.db.WithContext(ctx).
+		Where("reminded_at >= ? AND reminded_at < ?", filters.From, filters.To).
+		Order("reminded_at ASC").
+		Find(&recoveriesDb)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertCartRecoveryModelsToDomains(recoveriesDb), nil
+}
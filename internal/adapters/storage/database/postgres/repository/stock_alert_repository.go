@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockAlertRepo struct {
+	db *gorm.DB
+}
+
+func NewStockAlertRepo(db *gorm.DB) ports.StockAlertRepository {
+	return &StockAlertRepo{db: db}
+}
+
+// Subscribe implements ports.StockAlertRepository.
+func (sr *StockAlertRepo) Subscribe(ctx context.Context, subscription *domain.StockAlertSubscription) (*domain.StockAlertSubscription, error) {
+	var subscriptionDb models.StockAlertSubscriptionModel
+
+	err := sr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "product_id"}, {Name: "user_id"}},
+			DoNothing: true,
+		}).Create(database_dtos.ConvertStockAlertSubscriptionDomainToModel(subscription))
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// the existing subscription when it was already taken
+		return tx.Where("product_id = ? AND user_id = ?", subscription.ProductID, subscription.UserID).First(&subscriptionDb).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return database_dtos.ConvertStockAlertSubscriptionModelToDomain(&subscriptionDb), nil
+}
+
+// Unsubscribe implements ports.StockAlertRepository.
+func (sr *StockAlertRepo) Unsubscribe(ctx context.Context, productID, userID uuid.UUID) error {
+	result := sr.db.WithContext(ctx).Where("product_id = ? AND user_id = ?", productID, userID).Delete(&models.StockAlertSubscriptionModel{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrStockAlertNotFound
+	}
+	return nil
+}
+
+// ListSubscriptions implements ports.StockAlertRepository.
+func (sr *StockAlertRepo) ListSubscriptions(ctx context.Context, productID uuid.UUID) ([]domain.StockAlertSubscription, error) {
+	var subscriptionsDb []models.StockAlertSubscriptionModel
+
+	if result := sr.db.WithContext(ctx).Where("product_id = ?", productID).Order("created_at ASC, id").Find(&subscriptionsDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertStockAlertSubscriptionModelsToDomains(subscriptionsDb), nil
+}
+
+// ClaimSubscription implements ports.StockAlertRepository.
+func (sr *StockAlertRepo) ClaimSubscription(ctx context.Context, id uuid.UUID) (bool, error) {
+	result := sr.db.WithContext(ctx).Where("id = ?", id).Delete(&models.StockAlertSubscriptionModel{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkLowStock implements ports.StockAlertRepository.
+// The alert is set only if it isn't, so concurrent checks send it once
+func (sr *StockAlertRepo) MarkLowStock(ctx context.Context, productID uuid.UUID) (bool, error) {
+	result := sr.db.WithContext(ctx).
+		Model(&models.ProductModel{}).
+		Where("id = ? AND low_stock_alerted_at IS NULL", productID).
+		UpdateColumn("low_stock_alerted_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+// ClearLowStock implements ports.StockAlertRepository.
+func (sr *StockAlertRepo) ClearLowStock(ctx context.Context, productID uuid.UUID) error {
+	return sr.db.WithContext(ctx).
+		Model(&models.ProductModel{}).
+		Where("id = ?", productID).
+		UpdateColumn("low_stock_alerted_at", nil).Error
+}
+
+// ListProductsToCheck implements ports.StockAlertRepository.
+// Archived products aren't checked, they can't be sold
+func (sr *StockAlertRepo) ListProductsToCheck(ctx context.Context) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+
+	subscribed := sr.db.Model(&models.StockAlertSubscriptionModel{}).Select("product_id")
+	result := sr.db.WithContext(ctx).
+		Model(&models.ProductModel{}).
+		Where("low_stock_threshold > 0 AND stock <= low_stock_threshold AND low_stock_alerted_at IS NULL").
+		Or("low_stock_alerted_at IS NOT NULL AND (low_stock_threshold = 0 OR stock > low_stock_threshold)").
+		Or("stock > 0 AND id IN (?)", subscribed).
+		Order("id").
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
@@ -6,6 +6,7 @@ import (
 	"go-ecommerce/internal/adapters/storage/database/postgres/models"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -90,8 +91,47 @@ func (repo *UserRepo) ListUsers(ctx context.Context, skip, limit uint64) ([]*dom
 	return domainUsers, nil
 }
 
-// DeleteUser deletes a user
+// ListUsersByRole selects the active users with any of the roles
+func (repo *UserRepo) ListUsersByRole(ctx context.Context, roles ...domain.UserRole) ([]*domain.User, error) {
+	var dbUsers []*models.UserModel
+
+	if result := repo.db.WithContext(ctx).Where("role IN ?", roles).Order("created_at ASC").Find(&dbUsers); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.CovertToDomainUsers(dbUsers), nil
+}
+
+// DeleteUser archives (soft deletes) a user
 func (repo *UserRepo) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	var userDb = &models.UserModel{}
 	return repo.db.WithContext(ctx).Delete(userDb, "id = ?", id).Error
 }
+
+// RestoreUser restores an archived user
+func (repo *UserRepo) RestoreUser(ctx context.Context, id uuid.UUID) error {
+	var dbUser models.UserModel
+
+	if result := repo.db.WithContext(ctx).Unscoped().First(&dbUser, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return domain.ErrUserNotFound
+		}
+		return result.Error
+	}
+	if !dbUser.DeletedAt.Valid {
+		return domain.ErrUserNotArchived
+	}
+
+	return repo.db.WithContext(ctx).Unscoped().Model(&models.UserModel{}).Where("id = ?", id).Update("deleted_at", nil).Error
+}
+
+// PurgeUsers removes the users archived before the date, users with orders are kept
+func (repo *UserRepo) PurgeUsers(ctx context.Context, archivedBefore time.Time) (int64, error) {
+	withOrders := repo.db.Model(&models.OrderModel{}).Select("user_id").Where("user_id IS NOT NULL")
+
+	result := repo.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", archivedBefore).
+		Where("id NOT IN (?)", withOrders).
+		Delete(&models.UserModel{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/database_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WarehouseRepo struct {
+	db *gorm.DB
+}
+
+func NewWarehouseRepo(db *gorm.DB) ports.WarehouseRepository {
+	return &WarehouseRepo{db: db}
+}
+
+// SaveWarehouse implements ports.WarehouseRepository.
+func (wr *WarehouseRepo) SaveWarehouse(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error) {
+	warehouseDb := database_dtos.ConvertWarehouseDomainToModel(warehouse)
+
+	err := wr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// only one warehouse is the default
+		if warehouse.IsDefault {
+			err := tx.Model(&models.WarehouseModel{}).Where("is_default = ? AND id <> ?", true, warehouse.ID).Update("is_default", false).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		// if exist warehouse.ID update, else create new warehouse. The booleans are selected so they can be unset
+		if warehouse.ID != uuid.Nil {
+			result := tx.Select("code", "name", "latitude", "longitude", "is_default", "active", "updated_at").
+				Where("id = ?", warehouse.ID).
+				Updates(warehouseDb)
+			if result.Error != nil {
+				return translateError(result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return domain.ErrWarehouseNotFound
+			}
+			return nil
+		}
+
+		if result := tx.Create(warehouseDb); result.Error != nil {
+			return translateError(result.Error)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return database_dtos.ConvertWarehouseModelToDomain(warehouseDb), nil
+}
+
+// GetWarehouseById implements ports.WarehouseRepository.
+func (wr *WarehouseRepo) GetWarehouseById(ctx context.Context, id uuid.UUID) (*domain.Warehouse, error) {
+	var warehouseDb = &models.WarehouseModel{}
+
+	if result := wr.db.WithContext(ctx).First(warehouseDb, "id = ?", id); result.Error != nil {
+		if result.RowsAffected == 0 {
+			return nil, domain.ErrWarehouseNotFound
+		}
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertWarehouseModelToDomain(warehouseDb), nil
+}
+
+// ListWarehouses implements ports.WarehouseRepository.
+func (wr *WarehouseRepo) ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	var warehousesDb []models.WarehouseModel
+
+	if result := wr.db.WithContext(ctx).Order("code ASC").Find(&warehousesDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	return database_dtos.ConvertWarehouseModelsToDomains(warehousesDb), nil
+}
+
+// ListStockLevels implements ports.WarehouseRepository.
+func (wr *WarehouseRepo) ListStockLevels(ctx context.Context, productIDs []uuid.UUID) ([]domain.WarehouseStock, error) {
+	var levelsDb []models.WarehouseStockModel
+
+	if result := wr.db.WithContext(ctx).Where("product_id IN ?", productIDs).Find(&levelsDb); result.Error != nil {
+		return nil, result.Error
+	}
+
+	levels := make([]domain.WarehouseStock, 0, len(levelsDb))
+	for i := range levelsDb {
+		levels = append(levels, database_dtos.ConvertWarehouseStockModelToDomain(&levelsDb[i]))
+	}
+	return levels, nil
+}
+
+// helper func, the warehouse that receives the stock changed without a warehouse
+func defaultWarehouseID(tx *gorm.DB) (uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := tx.Model(&models.WarehouseModel{}).Where("is_default = ?", true).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return uuid.Nil, err
+	}
+	if len(ids) == 0 {
+		return uuid.Nil, domain.ErrNoDefaultWarehouse
+	}
+	return ids[0], nil
+}
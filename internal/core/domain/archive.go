@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// DefaultArchiveRetention is how long the archived (soft deleted) rows are kept before the purge removes them
+const DefaultArchiveRetention = 90 * 24 * time.Hour
+
+// PurgeReport is the result of removing the archived rows older than the retention.
+// Products and users referenced by orders are never purged, the order history keeps resolving them
+type PurgeReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Products   int
+	Categories int
+	Users      int
+	Errors     []string // images that couldn't be removed from the storage
+}
+
+func NewPurgeReport(startedAt time.Time) *PurgeReport {
+	return &PurgeReport{
+		StartedAt: startedAt,
+		Errors:    []string{},
+	}
+}
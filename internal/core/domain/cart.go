@@ -1,15 +1,37 @@
 package domain
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxCartLineQuantity is the max quantity of a product, or of a variant, in a cart
+const MaxCartLineQuantity = 50
 
 type CartItem struct {
 	ProductID uuid.UUID
+	VariantID *uuid.UUID // nil for products without variants
 	Quantity  int16
+
+	PriceWhenAdded float64 // final unit price when the line was added, 0 for the lines added before it was recorded
+}
+
+// Matches reports if the line of the cart is the given product and variant
+func (ci CartItem) Matches(productID uuid.UUID, variantID *uuid.UUID) bool {
+	if ci.ProductID != productID {
+		return false
+	}
+	if ci.VariantID == nil || variantID == nil {
+		return ci.VariantID == nil && variantID == nil
+	}
+	return *ci.VariantID == *variantID
 }
 
 type Cart struct {
-	UserID uuid.UUID
-	Items  []CartItem
+	UserID    uuid.UUID // nil for the carts of the guests
+	Items     []CartItem
+	UpdatedAt time.Time // last change of the items, zero for the carts saved before it was recorded
 }
 
 // NewCart creates a new cart for a user
@@ -20,16 +42,32 @@ func NewCart(userID uuid.UUID) *Cart {
 	}
 }
 
-func (c *Cart) AddItem(productId uuid.UUID, quantity int16) error {
-	// Check if the item already exists in the cart
+// AddItem adds the quantity to the line of the product and variant, unitPrice is recorded when the line is created.
+// An increase of the line can't exceed the stock nor the max of a line, a decrease is always allowed
+func (c *Cart) AddItem(productId uuid.UUID, variantId *uuid.UUID, quantity int16, unitPrice float64, stock int64) error {
+	if quantity == 0 {
+		return ErrCartQuantityIsRequire
+	}
+
+	if quantity > 0 {
+		total := int64(c.QuantityOf(productId, variantId)) + int64(quantity)
+		if total > MaxCartLineQuantity {
+			return ErrCartLineLimitExceeded
+		}
+		if total > stock {
+			return ErrCartInsufficientStock
+		}
+	}
+
+	// Check if the item already exists in the cart, each variant is a different line
 	for i, item := range c.Items {
-		if item.ProductID == productId {
+		if item.Matches(productId, variantId) {
 			// If it exists, update the quantity
 			c.Items[i].Quantity += quantity
 
 			// Validate if quantity is less than 0
 			if c.Items[i].Quantity <= 0 {
-				c.RemoveItem(productId)
+				c.RemoveItem(productId, variantId)
 				return nil
 			}
 			return nil
@@ -42,21 +80,33 @@ func (c *Cart) AddItem(productId uuid.UUID, quantity int16) error {
 	}
 
 	c.Items = append(c.Items, CartItem{
-		ProductID: productId,
-		Quantity:  quantity,
+		ProductID:      productId,
+		VariantID:      variantId,
+		Quantity:       quantity,
+		PriceWhenAdded: unitPrice,
 	})
 	return nil
 }
 
-// RemoveItem removes an item from the cart by product ID
-func (c *Cart) RemoveItem(productID uuid.UUID) error {
+// QuantityOf returns the quantity of the line of the product and variant, 0 if the cart doesn't have it
+func (c *Cart) QuantityOf(productId uuid.UUID, variantId *uuid.UUID) int16 {
+	for _, item := range c.Items {
+		if item.Matches(productId, variantId) {
+			return item.Quantity
+		}
+	}
+	return 0
+}
+
+// RemoveItem removes an item from the cart by product and variant ID
+func (c *Cart) RemoveItem(productID uuid.UUID, variantID *uuid.UUID) error {
 	if len(c.Items) <= 0 {
 		return ErrAlreadyEmptyCart
 	}
 
 	for i, item := range c.Items {
 		// Check if productID exist in cart
-		if item.ProductID == productID {
+		if item.Matches(productID, variantID) {
 			c.Items = append(c.Items[:i], c.Items[i+1:]...) // Remove the item from the slice
 			return nil
 		}
@@ -65,6 +115,41 @@ func (c *Cart) RemoveItem(productID uuid.UUID) error {
 	return ErrProductNotFoundCart
 }
 
+// MergeItem adds a line of another cart, e.g. the cart of a guest that logged in. The quantities of the same line
+// are summed and capped at the stock and the max of a line, a line of the cart is never reduced by the merge
+func (c *Cart) MergeItem(item CartItem, stock int64) {
+	stock = min(stock, MaxCartLineQuantity)
+	for i, existing := range c.Items {
+		if existing.Matches(item.ProductID, item.VariantID) {
+			quantity := min(int64(existing.Quantity)+int64(item.Quantity), stock)
+			if quantity > int64(existing.Quantity) {
+				c.Items[i].Quantity = int16(quantity)
+			}
+			return
+		}
+	}
+
+	quantity := min(int64(item.Quantity), stock)
+	if quantity > 0 {
+		c.Items = append(c.Items, CartItem{
+			ProductID:      item.ProductID,
+			VariantID:      item.VariantID,
+			Quantity:       int16(quantity),
+			PriceWhenAdded: item.PriceWhenAdded,
+		})
+	}
+}
+
+// Touch records a change of the items
+func (c *Cart) Touch(now time.Time) {
+	c.UpdatedAt = now
+}
+
+// IsIdle reports if the cart has items that weren't changed since idleAfter
+func (c *Cart) IsIdle(now time.Time, idleAfter time.Duration) bool {
+	return len(c.Items) > 0 && !c.UpdatedAt.IsZero() && now.Sub(c.UpdatedAt) >= idleAfter
+}
+
 // Clear removes all items from the cart
 func (c *Cart) Clear() error {
 	if len(c.Items) <= 0 {
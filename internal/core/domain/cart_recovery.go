@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CartRecoveryAttribution is the time after a reminder in which an order of the user is counted as recovered
+const CartRecoveryAttribution = 7 * 24 * time.Hour
+
+// CartRecovery is a reminder sent for an idle cart. It keeps the items of the cart, so the link of the reminder
+// restores them even if the cart expired or was emptied
+type CartRecovery struct {
+	ID             uuid.UUID
+	UserID         uuid.UUID
+	Items          []CartItem
+	CartUpdatedAt  time.Time // identifies the idle period, a cart is reminded once per period
+	RemindedAt     time.Time
+	RestoredAt     *time.Time // first use of the link
+	OrderID        *uuid.UUID
+	RecoveredAt    *time.Time
+	RecoveredTotal float64
+}
+
+func NewCartRecovery(cart *Cart, now time.Time) *CartRecovery {
+	items := make([]CartItem, len(cart.Items))
+	copy(items, cart.Items)
+
+	return &CartRecovery{
+		UserID:        cart.UserID,
+		Items:         items,
+		CartUpdatedAt: cart.UpdatedAt,
+		RemindedAt:    now,
+	}
+}
+
+// CartRecoverySummary represents the reminders sent inside a range and how many of them were converted
+type CartRecoverySummary struct {
+	Reminded         int64
+	Restored         int64
+	Recovered        int64
+	RecoveredRevenue float64
+	Rate             float64 // recovered / reminded
+}
+
+// BuildCartRecoverySummary counts the reminders that were opened and the ones that ended in an order
+func BuildCartRecoverySummary(recoveries []*CartRecovery) CartRecoverySummary {
+	var summary CartRecoverySummary
+
+	for _, r := range recoveries {
+		summary.Reminded++
+		if r.RestoredAt != nil {
+			summary.Restored++
+		}
+		if r.RecoveredAt != nil {
+			summary.Recovered++
+			summary.RecoveredRevenue += r.RecoveredTotal
+		}
+	}
+
+	if summary.Reminded > 0 {
+		summary.Rate = float64(summary.Recovered) / float64(summary.Reminded)
+	}
+	return summary
+}
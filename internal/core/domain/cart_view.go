@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CartItemWarning flags a line of the cart that can't be bought as it was added
+type CartItemWarning string
+
+const (
+	CartItemUnavailable       CartItemWarning = "unavailable"        // the product or its variant doesn't exist anymore
+	CartItemOutOfStock        CartItemWarning = "out_of_stock"       // the product doesn't have stock
+	CartItemInsufficientStock CartItemWarning = "insufficient_stock" // the stock is lower than the quantity of the line
+	CartItemPriceChanged      CartItemWarning = "price_changed"      // the final unit price isn't the one when it was added
+)
+
+// CartLine is a line of the cart priced with the current product
+type CartLine struct {
+	ProductID uuid.UUID
+	VariantID *uuid.UUID
+	Quantity  int16
+	Name      string
+	SKU       string
+	Image     string
+	Options   map[string]string // options of the variant, nil for products without variants
+	Stock     int64
+
+	UnitPrice         float64
+	UnitDiscount      float64
+	PreviousUnitPrice *float64 // final unit price when the line was added, set only when it changed
+	SubTotal          float64
+	Discount          float64
+	Total             float64
+
+	Warnings []CartItemWarning
+}
+
+// NewCartLine prices the line of the cart with the product and its variant
+func NewCartLine(item CartItem, product *Product, variant *Variant) CartLine {
+	unitPrice := product.UnitPrice(variant)
+	unitDiscount := product.UnitDiscount(unitPrice)
+	quantity := float64(item.Quantity)
+
+	line := CartLine{
+		ProductID:    item.ProductID,
+		VariantID:    item.VariantID,
+		Quantity:     item.Quantity,
+		Name:         product.Name,
+		SKU:          product.SKU,
+		Image:        product.Image,
+		Stock:        product.AvailableStock(variant),
+		UnitPrice:    unitPrice,
+		UnitDiscount: unitDiscount,
+		SubTotal:     unitPrice * quantity,
+		Discount:     unitDiscount * quantity,
+		Total:        (unitPrice - unitDiscount) * quantity,
+		Warnings:     []CartItemWarning{},
+	}
+	if variant != nil {
+		line.SKU = variant.SKU
+		line.Options = variant.Options
+	}
+
+	switch {
+	case line.Stock <= 0:
+		line.Warnings = append(line.Warnings, CartItemOutOfStock)
+	case line.Stock < int64(item.Quantity):
+		line.Warnings = append(line.Warnings, CartItemInsufficientStock)
+	}
+
+	finalPrice := unitPrice - unitDiscount
+	if item.PriceWhenAdded > 0 && math.Abs(finalPrice-item.PriceWhenAdded) >= 0.005 {
+		previous := item.PriceWhenAdded
+		line.PreviousUnitPrice = &previous
+		line.Warnings = append(line.Warnings, CartItemPriceChanged)
+	}
+
+	return line
+}
+
+// UnavailableCartLine is a line of a product that can't be bought anymore, it isn't priced
+func UnavailableCartLine(item CartItem) CartLine {
+	return CartLine{
+		ProductID: item.ProductID,
+		VariantID: item.VariantID,
+		Quantity:  item.Quantity,
+		Warnings:  []CartItemWarning{CartItemUnavailable},
+	}
+}
+
+// CartView is the cart with its lines priced, the totals don't include the unavailable lines
+type CartView struct {
+	UserID      uuid.UUID
+	Items       []CartLine
+	SubTotal    float64
+	Discount    float64
+	Total       float64
+	HasWarnings bool
+	UpdatedAt   time.Time
+}
+
+func NewCartView(cart *Cart, lines []CartLine) *CartView {
+	view := &CartView{
+		UserID:    cart.UserID,
+		Items:     lines,
+		UpdatedAt: cart.UpdatedAt,
+	}
+	if view.Items == nil {
+		view.Items = []CartLine{}
+	}
+
+	for _, line := range lines {
+		view.SubTotal += line.SubTotal
+		view.Discount += line.Discount
+		if len(line.Warnings) > 0 {
+			view.HasWarnings = true
+		}
+	}
+	view.Total = view.SubTotal - view.Discount
+
+	return view
+}
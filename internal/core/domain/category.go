@@ -1,25 +1,81 @@
 package domain
 
 import (
+	"strings"
 	"time"
+	"unicode"
 )
 
-// Category is an entity that represents a category of product
+// Category is an entity that represents a category of product, categories can be nested (Electronics > Phones > Accessories)
 type Category struct {
 	ID        uint64
+	ParentID  *uint64 // nil for root categories
 	Name      string
+	Slug      string
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt *time.Time // set while the category is archived
 }
 
-func NewCategory(name string) (*Category, error) {
+// NoParentCategory is the parent id that moves a category to the root, no category has the id 0
+const NoParentCategory uint64 = 0
+
+// CategoryNode is a category with its children, used to render the category tree
+type CategoryNode struct {
+	*Category
+	Children []*CategoryNode
+}
+
+type CategoryDeleteStrategy string
+
+const (
+	RestrictDelete CategoryDeleteStrategy = "restrict" // Only categories without children and products can be deleted
+	ReparentDelete CategoryDeleteStrategy = "reparent" // Children and products are moved to the parent of the deleted category
+	CascadeDelete  CategoryDeleteStrategy = "cascade"  // Children and products of the whole subtree are deleted
+)
+
+// CategoryDeletePlan describes the changes needed to delete a category with a strategy
+type CategoryDeletePlan struct {
+	CategoryID     uint64
+	DeleteIDs      []uint64 // categories to delete, the category itself and its descendants on cascade
+	ReparentTo     *uint64  // new parent of children and products on reparent, nil moves children to the root
+	Reparent       bool
+	DeleteProducts bool
+}
+
+// slugReplacer removes the accents used in spanish names
+var slugReplacer = strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "ü", "u", "ñ", "n")
+
+// Slugify generates the url friendly version of a name, e.g. "Teléfonos y Accesorios" -> "telefonos-y-accesorios"
+func Slugify(name string) string {
+	var b strings.Builder
+	lastDash := true
+
+	for _, r := range slugReplacer.Replace(strings.ToLower(name)) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(r)
+			lastDash = false
+			continue
+		}
+		if !lastDash {
+			b.WriteRune('-')
+			lastDash = true
+		}
+	}
+
+	return strings.TrimSuffix(b.String(), "-")
+}
+
+func NewCategory(name string, parentID *uint64) (*Category, error) {
 	if len(name) == 0 {
 		return nil, ErrCategoryNameIsRequire
 	}
 
 	now := time.Now()
 	return &Category{
+		ParentID:  parentID,
 		Name:      name,
+		Slug:      Slugify(name),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
@@ -35,5 +91,110 @@ func (c *Category) UpdateCategory(name string) error {
 	}
 
 	c.Name = name
+	c.Slug = Slugify(name)
+	c.UpdatedAt = time.Now()
 	return nil
 }
+
+// SetParent moves the category under parentID, all is the list of existing categories used to prevent cycles
+func (c *Category) SetParent(parentID *uint64, all []*Category) error {
+	if parentID == nil {
+		c.ParentID = nil
+		return nil
+	}
+
+	parents := make(map[uint64]*uint64, len(all))
+	for _, category := range all {
+		parents[category.ID] = category.ParentID
+	}
+
+	if _, ok := parents[*parentID]; !ok {
+		return ErrCategoryParentNotFound
+	}
+
+	// walk up from the new parent, if the category is found it would be its own ancestor
+	for current := parentID; current != nil; current = parents[*current] {
+		if c.ID != 0 && *current == c.ID {
+			return ErrCategoryCycle
+		}
+	}
+
+	c.ParentID = parentID
+	c.UpdatedAt = time.Now()
+	return nil
+}
+
+// BuildCategoryTree nests the categories under their parents, categories with an unknown parent are returned as roots
+func BuildCategoryTree(categories []*Category) []*CategoryNode {
+	nodes := make(map[uint64]*CategoryNode, len(categories))
+	for _, c := range categories {
+		nodes[c.ID] = &CategoryNode{Category: c, Children: []*CategoryNode{}}
+	}
+
+	roots := make([]*CategoryNode, 0)
+	for _, c := range categories {
+		node := nodes[c.ID]
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
+}
+
+// CategoryDescendants returns the ids of the subtree of the category, including itself
+func CategoryDescendants(id uint64, categories []*Category) []uint64 {
+	children := make(map[uint64][]uint64, len(categories))
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []uint64{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// PlanCategoryDeletion validates the strategy against the children and products of the category
+func PlanCategoryDeletion(category *Category, all []*Category, productsInCategory int64, strategy CategoryDeleteStrategy) (*CategoryDeletePlan, error) {
+	hasChildren := false
+	for _, c := range all {
+		if c.ParentID != nil && *c.ParentID == category.ID {
+			hasChildren = true
+			break
+		}
+	}
+
+	plan := &CategoryDeletePlan{CategoryID: category.ID, DeleteIDs: []uint64{category.ID}}
+
+	switch strategy {
+	case "", RestrictDelete:
+		if hasChildren {
+			return nil, ErrCategoryHasChildren
+		}
+		if productsInCategory > 0 {
+			return nil, ErrCategoryHasProducts
+		}
+	case ReparentDelete:
+		// products can't be left without category
+		if category.ParentID == nil && productsInCategory > 0 {
+			return nil, ErrCategoryHasProducts
+		}
+		plan.Reparent = true
+		plan.ReparentTo = category.ParentID
+	case CascadeDelete:
+		plan.DeleteIDs = CategoryDescendants(category.ID, all)
+		plan.DeleteProducts = true
+	default:
+		return nil, ErrInvalidCategoryDeleteStrategy
+	}
+
+	return plan, nil
+}
@@ -0,0 +1,182 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DisputeStatus string
+
+const (
+	DisputeOpen              DisputeStatus = "open"               // The chargeback was received and nobody has answered it yet
+	DisputeEvidenceSubmitted DisputeStatus = "evidence_submitted" // Evidence was attached and the dispute waits for the issuer decision
+	DisputeWon               DisputeStatus = "won"                // The issuer resolved the dispute in favor of the seller, funds are returned
+	DisputeLost              DisputeStatus = "lost"               // The issuer resolved the dispute in favor of the buyer
+)
+
+// DisputeEvidenceWindow is the default time to submit evidence when the provider doesn't send a deadline
+const DisputeEvidenceWindow = 10 * 24 * time.Hour
+
+// Dispute is an entity that represents a chargeback opened by the buyer against a payment of an order
+type Dispute struct {
+	ID            uuid.UUID
+	OrderID       uuid.UUID
+	PaymentID     string
+	ChargebackID  string
+	Amount        float64
+	Currency      Currencies
+	Reason        string
+	Status        DisputeStatus
+	EvidenceDueAt time.Time
+	ResolvedAt    *time.Time
+	ResolvedBy    *uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+
+	// Relations
+	Evidence []DisputeEvidence
+}
+
+// DisputeEvidence is a note attached to a dispute to support the seller's case
+type DisputeEvidence struct {
+	ID        uuid.UUID
+	DisputeID uuid.UUID
+	AuthorID  uuid.UUID
+	Note      string
+	CreatedAt time.Time
+}
+
+type NewDisputeInputs struct {
+	OrderID       uuid.UUID
+	PaymentID     string
+	ChargebackID  string
+	Amount        float64
+	Currency      Currencies
+	Reason        string
+	EvidenceDueAt *time.Time
+}
+
+func NewDispute(inputs NewDisputeInputs) (*Dispute, error) {
+	if inputs.OrderID == uuid.Nil {
+		return nil, ErrDisputeOrderIsRequire
+	}
+
+	if len(inputs.PaymentID) == 0 {
+		return nil, ErrDisputePaymentIsRequire
+	}
+
+	if inputs.Amount <= 0 {
+		return nil, ErrDisputeAmountIsRequire
+	}
+
+	now := time.Now()
+	evidenceDueAt := now.Add(DisputeEvidenceWindow)
+	if inputs.EvidenceDueAt != nil {
+		evidenceDueAt = *inputs.EvidenceDueAt
+	}
+
+	reason := inputs.Reason
+	if len(reason) == 0 {
+		reason = "unknown"
+	}
+
+	return &Dispute{
+		ID:            uuid.Nil, // repository will asign the id
+		OrderID:       inputs.OrderID,
+		PaymentID:     inputs.PaymentID,
+		ChargebackID:  inputs.ChargebackID,
+		Amount:        inputs.Amount,
+		Currency:      inputs.Currency,
+		Reason:        reason,
+		Status:        DisputeOpen,
+		EvidenceDueAt: evidenceDueAt,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Evidence:      []DisputeEvidence{},
+	}, nil
+}
+
+func (d *Dispute) IsResolved() bool {
+	return d.Status == DisputeWon || d.Status == DisputeLost
+}
+
+// AddEvidence attaches a note to the dispute while it's still open and the deadline has not passed
+func (d *Dispute) AddEvidence(authorID uuid.UUID, note string) (*DisputeEvidence, error) {
+	if len(note) == 0 {
+		return nil, ErrDisputeEvidenceIsRequire
+	}
+
+	if d.IsResolved() {
+		return nil, ErrDisputeAlreadyResolved
+	}
+
+	now := time.Now()
+	if now.After(d.EvidenceDueAt) {
+		return nil, ErrDisputeEvidenceDeadline
+	}
+
+	evidence := DisputeEvidence{
+		DisputeID: d.ID,
+		AuthorID:  authorID,
+		Note:      note,
+		CreatedAt: now,
+	}
+
+	d.Evidence = append(d.Evidence, evidence)
+	d.Status = DisputeEvidenceSubmitted
+	d.UpdatedAt = now
+
+	return &evidence, nil
+}
+
+// Resolve records the decision of the issuer
+func (d *Dispute) Resolve(outcome DisputeStatus, actorID uuid.UUID) error {
+	if outcome != DisputeWon && outcome != DisputeLost {
+		return ErrDisputeInvalidOutcome
+	}
+
+	if d.IsResolved() {
+		return ErrDisputeAlreadyResolved
+	}
+
+	now := time.Now()
+	d.Status = outcome
+	d.ResolvedAt = &now
+	d.ResolvedBy = &actorID
+	d.UpdatedAt = now
+
+	return nil
+}
+
+// ChargebackSummary represents the chargebacks received inside a range
+type ChargebackSummary struct {
+	Count      int64
+	Amount     float64
+	Open       int64
+	Won        int64
+	Lost       int64
+	LostAmount float64
+}
+
+// BuildChargebackSummary sums the amount of chargebacks grouped by their status
+func BuildChargebackSummary(disputes []*Dispute) ChargebackSummary {
+	var summary ChargebackSummary
+
+	for _, d := range disputes {
+		summary.Count++
+		summary.Amount += d.Amount
+
+		switch d.Status {
+		case DisputeWon:
+			summary.Won++
+		case DisputeLost:
+			summary.Lost++
+			summary.LostAmount += d.Amount
+		default:
+			summary.Open++
+		}
+	}
+
+	return summary
+}
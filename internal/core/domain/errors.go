@@ -4,18 +4,21 @@ import "errors"
 
 var (
 	// User errors
-	ErrEmailExist        = errors.New("an account with this email already exist, try loggin")
-	ErrEmailIsRequire    = errors.New("email is required")
-	ErrCreatingUser      = errors.New("couldn't create the user")
-	ErrNameIsRequire     = errors.New("name is required")
-	ErrPasswordIsRequire = errors.New("password is required")
-	ErrMinLenghtName     = errors.New("name of user must have at least 3 characters")
-	ErrMinLenghtPassword = errors.New("password must have at least 6 characters")
-	ErrRoleIsRequire     = errors.New("role is required")
-	ErrRoleIsInvalid     = errors.New("invalid user role")
-	ErrHashingPassword   = errors.New("error hashing password")
-	ErrUserNotFound      = errors.New("user not found")
-	ErrUsersNotFound     = errors.New("list of users not found")
+	ErrEmailExist         = errors.New("an account with this email already exist, try loggin")
+	ErrEmailIsRequire     = errors.New("email is required")
+	ErrCreatingUser       = errors.New("couldn't create the user")
+	ErrNameIsRequire      = errors.New("name is required")
+	ErrPasswordIsRequire  = errors.New("password is required")
+	ErrMinLenghtName      = errors.New("name of user must have at least 3 characters")
+	ErrMinLenghtPassword  = errors.New("password must have at least 6 characters")
+	ErrRoleIsRequire      = errors.New("role is required")
+	ErrRoleIsInvalid      = errors.New("invalid user role")
+	ErrHashingPassword    = errors.New("error hashing password")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserNotArchived    = errors.New("the user isn't archived")
+	ErrUsersNotFound      = errors.New("list of users not found")
+	ErrLocaleIsInvalid    = errors.New("invalid locale, must be es or en")
+	ErrInvalidCredentials = errors.New("invalid email or password")
 )
 
 var (
@@ -23,7 +26,14 @@ var (
 	ErrCategoryNameIsRequire          = errors.New("name of category is required")
 	ErrMinLenghtCategoryNameIsRequire = errors.New("name of category must have at least 3 characters")
 	ErrCategoryNotFound               = errors.New("category not found")
+	ErrCategoryParentNotFound         = errors.New("parent category not found")
+	ErrCategoryCycle                  = errors.New("a category can't be moved under itself or one of its descendants")
+	ErrCategoryHasChildren            = errors.New("the category has children, use the reparent or cascade strategy")
+	ErrCategoryHasProducts            = errors.New("the category has products, use the reparent or cascade strategy")
+	ErrInvalidCategoryDeleteStrategy  = errors.New("invalid delete strategy, must be restrict, reparent or cascade")
 	ErrCategoriesNotFound             = errors.New("list of categories not found")
+	ErrCategoryNotArchived            = errors.New("the category isn't archived")
+	ErrCategoryParentArchived         = errors.New("the parent category is archived, restore it first")
 )
 
 var (
@@ -38,23 +48,163 @@ var (
 	ErrProductMinLenghtSKU      = errors.New("sku of product must have at least 3 characters")
 	ErrProductNotFound          = errors.New("product not found")
 	ErrProductsNotFound         = errors.New("list of products not found")
+	ErrProductInsufficientStock = errors.New("product doesn't have enough stock")
+	ErrProductNotArchived       = errors.New("the product isn't archived")
+	ErrProductCategoryArchived  = errors.New("the category of the product is archived, restore it first")
+	ErrProductLowStockThreshold = errors.New("low stock threshold of product can't be negative")
+	ErrProductStockNotEditable  = errors.New("the stock of a product can't be updated, record an adjustment in /product/{product_id}/stock/movements")
+	ErrProductSKUArchived       = errors.New("the sku belongs to an archived product, restore the archived product instead")
 )
 
 // Order-Product errors
 var (
-	ErrOrderProductNotFound  = errors.New("order-product not found")
-	ErrOrdersProductNotFound = errors.New("list of orders-product not found")
+	ErrOrderProductNotFound    = errors.New("order-product not found")
+	ErrOrdersProductNotFound   = errors.New("list of orders-product not found")
 	ErrOrderProductMinQuantity = errors.New("the quantity must be greater than 0")
 )
 
+// Cache errors
+var (
+	ErrConcurrentUpdate = errors.New("the value is being changed by another request, try again")
+)
+
 // Cart errors
 var (
 	ErrAlreadyEmptyCart                    = errors.New("the cart is already empty")
 	ErrProductNotFoundCart                 = errors.New("product not found in cart")
 	ErrNegativeQuantityNonExistProductCart = errors.New("product not exist in cart, quantity must be a positive number")
+	ErrCartQuantityIsRequire               = errors.New("quantity of the item is required")
+	ErrCartInsufficientStock               = errors.New("the quantity in the cart exceeds the stock of the product")
+	ErrCartLineLimitExceeded               = errors.New("the quantity in the cart exceeds the max of a product")
+	ErrCartRecoveryNotFound                = errors.New("cart recovery not found")
+	ErrInvalidToken                        = errors.New("the token is invalid")
+	ErrTokenExpired                        = errors.New("the token has expired")
+)
+
+var (
+	ErrOrderNotFound          = errors.New("order not found")
+	ErrOrdersNotFound         = errors.New("list of orders not found")
+	ErrCancelReasonIsRequire  = errors.New("reason of cancellation is required")
+	ErrOrderCannotBeCancelled = errors.New("the order can't be cancelled in its current status")
+	ErrOrderCancelNotAllowed  = errors.New("user is not allowed to cancel this order")
+	ErrOrderNotAuthorized     = errors.New("the order doesn't have an authorized payment to capture")
+	ErrAuthorizationExpired   = errors.New("the authorization of the payment has expired")
+	ErrOrderNotPaid           = errors.New("only paid orders can be shipped")
+	ErrOrderAlreadyShipped    = errors.New("the order was already shipped")
+	ErrCarrierIsRequire       = errors.New("carrier of the shipment is required")
+)
+
+// Report errors
+var (
+	ErrInvalidReportPeriod  = errors.New("invalid report period, must be day, week or month")
+	ErrInvalidReportRange   = errors.New("invalid report range, from must be before to")
+	ErrInvalidReportRanking = errors.New("invalid ranking, must be units or revenue")
+)
+
+// Dispute errors
+var (
+	ErrDisputeNotFound          = errors.New("dispute not found")
+	ErrDisputeOrderIsRequire    = errors.New("order of dispute is required")
+	ErrDisputePaymentIsRequire  = errors.New("payment of dispute is required")
+	ErrDisputeAmountIsRequire   = errors.New("amount of dispute must be greater than 0")
+	ErrDisputeEvidenceIsRequire = errors.New("note of evidence is required")
+	ErrDisputeAlreadyResolved   = errors.New("the dispute has already been resolved")
+	ErrDisputeEvidenceDeadline  = errors.New("the deadline to submit evidence has passed")
+	ErrDisputeInvalidOutcome    = errors.New("invalid outcome, must be won or lost")
+)
+
+// Payment attempts errors
+var (
+	ErrPaymentNotFound          = errors.New("payment not found in the provider")
+	ErrPaymentExternalReference = errors.New("external reference missing in payment")
+	ErrPaymentNotCredited       = errors.New("net received amount of an approved payment is 0 or less")
+	ErrPaymentAmountMismatch    = errors.New("the payment amount doesn't match the order total")
+	ErrPaymentCurrencyMismatch  = errors.New("the payment currency doesn't match the order currency")
+)
+
+// Product search errors
+var (
+	ErrInvalidProductSort  = errors.New("invalid sort, must be newest, price_asc, price_desc, name_asc or relevance")
+	ErrInvalidPriceRange   = errors.New("min_price must be lower than max_price")
+	ErrInvalidSearchCursor = errors.New("invalid cursor")
+)
+
+// Variant errors
+var (
+	ErrVariantNotFound          = errors.New("variant not found")
+	ErrVariantSKUIsRequire      = errors.New("sku of variant is required")
+	ErrVariantMinLenghtSKU      = errors.New("sku of variant must have at least 3 characters")
+	ErrVariantOptionsIsRequire  = errors.New("options of variant are required, e.g. size or color")
+	ErrVariantNegativeStock     = errors.New("stock of variant can't be negative")
+	ErrVariantInvalidPrice      = errors.New("price of variant must be greater than 0")
+	ErrVariantIsRequire         = errors.New("the product has variants, a variant must be selected")
+	ErrVariantInsufficientStock = errors.New("variant doesn't have enough stock")
+	ErrVariantStockNotEditable  = errors.New("the stock of a variant can't be updated, record an adjustment in /product/{product_id}/stock/movements")
+)
+
+// Product image errors
+var (
+	ErrProductImageNotFound      = errors.New("product image not found")
+	ErrProductImageEmpty         = errors.New("the image file is empty")
+	ErrProductImageInvalidType   = errors.New("invalid image type, must be jpeg, png or gif")
+	ErrProductImageTooLarge      = errors.New("the image exceeds the max size of 5MB")
+	ErrProductImageTooManyPixels = errors.New("the image exceeds the max resolution of 40 megapixels")
+	ErrProductImageLimit         = errors.New("the product already has the max number of images")
+	ErrProductImageInvalidOrder  = errors.New("the new order must contain every image of the product once")
+)
+
+// Catalogue import and export errors
+var (
+	ErrImportJobNotFound      = errors.New("import job not found")
+	ErrImportEmptyFile        = errors.New("the file is empty")
+	ErrImportMissingSKUColumn = errors.New("the header of the file must contain the sku column")
+	ErrInvalidExportFormat    = errors.New("invalid format, must be csv or json")
+)
+
+// Pricing errors
+var (
+	ErrInvalidDiscount           = errors.New("discount must be a positive number")
+	ErrInvalidDiscountType       = errors.New("invalid discount type, must be percentage or fixed")
+	ErrDiscountTypeIsRequire     = errors.New("discount type is required when the product has a discount")
+	ErrPercentageDiscountTooHigh = errors.New("percentage discount can't be greater than 100")
+	ErrFixedDiscountTooHigh      = errors.New("fixed discount must be lower than the price")
+	ErrPriceScheduleNotFound     = errors.New("price schedule not found")
+	ErrPriceScheduleIsEmpty      = errors.New("the schedule must change the price or the discount")
+	ErrPriceScheduleInvalidDates = errors.New("starts_at must be in the future and before ends_at")
+	ErrPriceScheduleFinished     = errors.New("the schedule is already finished or cancelled")
+)
+
+// Inventory errors
+var (
+	ErrInvalidStockMovementType     = errors.New("invalid stock movement type, must be receipt, sale, reservation, release, adjustment or return")
+	ErrStockMovementNotManual       = errors.New("only receipts, adjustments and returns can be recorded manually")
+	ErrStockMovementQuantity        = errors.New("quantity of the stock movement must be greater than 0")
+	ErrStockAdjustmentIsZero        = errors.New("quantity of the adjustment can't be 0")
+	ErrStockMovementReasonIsRequire = errors.New("reason of the stock movement is required")
+)
+
+// Warehouse errors
+var (
+	ErrWarehouseNotFound           = errors.New("warehouse not found")
+	ErrWarehouseCodeIsRequire      = errors.New("code of warehouse is required")
+	ErrWarehouseNameIsRequire      = errors.New("name of warehouse is required")
+	ErrWarehouseInvalidLocation    = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180, both or none")
+	ErrWarehouseDefaultInactive    = errors.New("the default warehouse can't be deactivated")
+	ErrNoDefaultWarehouse          = errors.New("there isn't a default warehouse")
+	ErrWarehouseDefaultIsRequire   = errors.New("set another warehouse as default to change the default one")
+	ErrInvalidAllocationRule       = errors.New("invalid allocation rule, must be closest or most_stock")
+	ErrAllocationLocationIsRequire = errors.New("the closest rule requires the location of the shipment")
+)
+
+// Stock alert errors
+var (
+	ErrStockAlertProductInStock = errors.New("the product is in stock, back in stock alerts are only for products out of stock")
+	ErrStockAlertNotFound       = errors.New("back in stock alert not found")
 )
 
+// Notification errors
 var (
-	ErrOrderNotFound  = errors.New("order not found")
-	ErrOrdersNotFound = errors.New("list of orders not found")
+	ErrNotificationRecipientIsRequire = errors.New("recipient of notification is required")
+	ErrNotificationKindIsRequire      = errors.New("kind of notification is required")
+	ErrNotificationTemplateNotFound   = errors.New("there isn't a template for the kind of notification")
 )
@@ -0,0 +1,134 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type StockMovementType string
+
+const (
+	StockReceipt     StockMovementType = "receipt"     // units received, adds stock
+	StockSale        StockMovementType = "sale"        // reserved units of a paid order, the stock was taken by the reservation
+	StockReservation StockMovementType = "reservation" // units held by a new order, takes stock
+	StockRelease     StockMovementType = "release"     // units of a cancelled order, returns the reserved stock
+	StockAdjustment  StockMovementType = "adjustment"  // manual correction, adds or takes stock
+	StockReturn      StockMovementType = "return"      // units returned by a buyer, adds stock
+)
+
+// IsManual reports if the movement can be recorded by a seller, the rest are recorded by the orders
+func (t StockMovementType) IsManual() bool {
+	return t == StockReceipt || t == StockAdjustment || t == StockReturn
+}
+
+// StockMovement is an entry of the append-only ledger of the stock of a product or one of its variants
+type StockMovement struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	VariantID *uuid.UUID // nil when the movement is over the stock of the product
+	// warehouse of the units, the default one when the stock is changed without a warehouse. Only the sales of orders
+	// reserved before the warehouses existed don't have one
+	WarehouseID *uuid.UUID
+	Type        StockMovementType
+	Quantity    int64 // units moved
+	Delta       int64 // change of the available stock, the stock is the sum of the deltas
+	Balance     int64 // stock of the product or variant in every warehouse after the movement
+	Reason      string
+	ActorID     *uuid.UUID // nil when the user is unknown or the movement was recorded by the system
+	CreatedAt   time.Time
+}
+
+// NewStockMovement validates the quantity against the type. Adjustments take a signed quantity,
+// the rest of the types take the units moved
+func NewStockMovement(productID uuid.UUID, variantID, warehouseID *uuid.UUID, t StockMovementType, quantity int64, reason string, actorID *uuid.UUID) (*StockMovement, error) {
+	var delta int64
+
+	switch t {
+	case StockReceipt, StockRelease, StockReturn:
+		delta = quantity
+	case StockReservation:
+		delta = -quantity
+	case StockSale:
+		delta = 0
+	case StockAdjustment:
+		if quantity == 0 {
+			return nil, ErrStockAdjustmentIsZero
+		}
+		delta = quantity
+		if quantity < 0 {
+			quantity = -quantity
+		}
+	default:
+		return nil, ErrInvalidStockMovementType
+	}
+
+	if quantity <= 0 {
+		return nil, ErrStockMovementQuantity
+	}
+
+	return &StockMovement{
+		ID:          uuid.Nil, // repository will asign the id
+		ProductID:   productID,
+		VariantID:   variantID,
+		WarehouseID: warehouseID,
+		Type:        t,
+		Quantity:    quantity,
+		Delta:       delta,
+		Reason:      reason,
+		ActorID:     actorID,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// StockLevel is the stock of a product, or one of its variants, derived from the ledger
+type StockLevel struct {
+	ProductID   uuid.UUID
+	VariantID   *uuid.UUID
+	WarehouseID *uuid.UUID // nil for the total of every warehouse
+	Stock       int64
+}
+
+// StockReport is the stock of a product and its variants at a point in time
+type StockReport struct {
+	ProductID  uuid.UUID
+	At         time.Time
+	Stock      int64        // stock of the product in every warehouse
+	Variants   []StockLevel // stock of each variant in every warehouse
+	Warehouses []StockLevel // stock of the product and its variants by warehouse
+}
+
+// NewStockReport aggregates the levels of the ledger by warehouse into the totals of the product and its variants
+func NewStockReport(productID uuid.UUID, at time.Time, levels []StockLevel) *StockReport {
+	report := &StockReport{ProductID: productID, At: at, Variants: []StockLevel{}, Warehouses: []StockLevel{}}
+	variants := map[uuid.UUID]int{}
+
+	for _, level := range levels {
+		if level.WarehouseID != nil {
+			report.Warehouses = append(report.Warehouses, level)
+		}
+
+		if level.VariantID == nil {
+			report.Stock += level.Stock
+			continue
+		}
+		i, ok := variants[*level.VariantID]
+		if !ok {
+			i = len(report.Variants)
+			variants[*level.VariantID] = i
+			report.Variants = append(report.Variants, StockLevel{ProductID: productID, VariantID: level.VariantID})
+		}
+		report.Variants[i].Stock += level.Stock
+	}
+	return report
+}
+
+// StockDiscrepancy is a product or variant whose stock doesn't match the sum of its ledger,
+// in every warehouse or in the warehouse set
+type StockDiscrepancy struct {
+	ProductID   uuid.UUID
+	VariantID   *uuid.UUID
+	WarehouseID *uuid.UUID
+	Stock       int64
+	LedgerStock int64
+}
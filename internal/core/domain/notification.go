@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationKind string
+
+const (
+	NotificationWelcome         NotificationKind = "welcome"          // to the new user
+	NotificationOrderCreated    NotificationKind = "order_created"    // to the buyer, the order waits to be paid
+	NotificationPaymentApproved NotificationKind = "payment_approved" // to the buyer, the order was paid
+	NotificationPaymentRejected NotificationKind = "payment_rejected" // to the buyer, the payment was declined and can be retried
+	NotificationOrderShipped    NotificationKind = "order_shipped"    // to the buyer, the order left the warehouse
+	NotificationCartReminder    NotificationKind = "cart_reminder"    // to the owner of an idle cart, with the link that restores it
+	NotificationLowStock        NotificationKind = "low_stock"        // to sellers and admins, the stock fell to the threshold
+	NotificationBackInStock     NotificationKind = "back_in_stock"    // to the subscribed customers, the product is available again
+)
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending" // waiting to be sent, or to be retried after a failure
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed" // every attempt failed, it won't be retried
+)
+
+// Locales of the notifications
+const (
+	LocaleES      = "es"
+	LocaleEN      = "en"
+	DefaultLocale = LocaleES
+)
+
+// Retries of the failed notifications, the delay doubles after every attempt up to the max
+const (
+	notificationRetryDelay    = time.Minute
+	notificationMaxRetryDelay = time.Hour
+)
+
+func IsValidLocale(locale string) bool {
+	return locale == LocaleES || locale == LocaleEN
+}
+
+// Notification is a message to a user, the notifier renders it by its kind with the data
+type Notification struct {
+	Kind   NotificationKind
+	UserID uuid.UUID
+	To     string // email of the recipient
+	Name   string // name of the recipient
+	Locale string // language of the message, the default one when empty
+	Data   map[string]any
+}
+
+func NewNotification(kind NotificationKind, user *User, data map[string]any) Notification {
+	return Notification{
+		Kind:   kind,
+		UserID: user.ID,
+		To:     user.Email,
+		Name:   user.Name,
+		Locale: user.Locale,
+		Data:   data,
+	}
+}
+
+// HasRecipient reports if the address of the recipient is known, otherwise it's taken from the user
+func (n Notification) HasRecipient() bool {
+	return n.To != ""
+}
+
+// QueuedNotification is a notification waiting in the queue to be sent
+type QueuedNotification struct {
+	ID            uuid.UUID
+	Notification  Notification
+	Status        NotificationStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     *string
+	SentAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func NewQueuedNotification(n Notification) (*QueuedNotification, error) {
+	if !n.HasRecipient() {
+		return nil, ErrNotificationRecipientIsRequire
+	}
+	if n.Kind == "" {
+		return nil, ErrNotificationKindIsRequire
+	}
+
+	now := time.Now()
+	return &QueuedNotification{
+		Notification:  n,
+		Status:        NotificationPending,
+		NextAttemptAt: now,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// MarkSent records the delivery of the notification
+func (q *QueuedNotification) MarkSent(now time.Time) {
+	q.Attempts++
+	q.Status = NotificationSent
+	q.SentAt = &now
+	q.LastError = nil
+	q.UpdatedAt = now
+}
+
+// MarkFailed records a failed attempt, the notification is retried later with an exponential backoff until
+// maxAttempts is reached
+func (q *QueuedNotification) MarkFailed(reason error, now time.Time, maxAttempts int) {
+	q.Attempts++
+	message := reason.Error()
+	q.LastError = &message
+	q.UpdatedAt = now
+
+	if q.Attempts >= maxAttempts {
+		q.Status = NotificationFailed
+		return
+	}
+
+	delay := time.Duration(float64(notificationRetryDelay) * math.Pow(2, float64(q.Attempts-1)))
+	if delay > notificationMaxRetryDelay {
+		delay = notificationMaxRetryDelay
+	}
+	q.NextAttemptAt = now.Add(delay)
+}
+
+// StockAlertSubscription is a customer waiting for a product out of stock, it's removed when the alert is sent
+type StockAlertSubscription struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	UserID    uuid.UUID
+	CreatedAt time.Time
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"math"
 	"time"
 
 	"github.com/google/uuid"
@@ -32,6 +33,7 @@ const (
 	Expired     PayStatus = "expired"      // The pay order has expired
 	SoftDelete  PayStatus = "soft_delete"  // Order that remained unpaid for a long period of time and will be deleted
 	NonExistent PayStatus = "non-existent" // Order does not exist, the user created their reservation but did not attempt to pay for it
+	Review      PayStatus = "review"       // The payment doesn't match the amount or currency of the order and must be reviewed before the order is considered paid
 )
 
 // PayStatusDetail represents detailed payment status information.
@@ -46,6 +48,9 @@ const (
 	NonExistentDetail PayStatusDetail = "non-existent"          // No payment information found (invalid or deleted payment ID).
 )
 
+// AuthorizationValidity is the time that the issuer holds the funds of an authorized payment before releasing them
+const AuthorizationValidity = 7 * 24 * time.Hour
+
 type Order struct {
 	ID                uuid.UUID
 	Providers         Providers
@@ -69,6 +74,23 @@ type Order struct {
 	UpdatedAt         time.Time
 	PaidAt            *time.Time
 	ExpiresAt         *time.Time
+	CancelledAt       *time.Time
+	CancelledBy       *uuid.UUID
+	CancelReason      *string
+
+	// Authorize-then-capture flow
+	AuthorizedAt           *time.Time
+	AuthorizationExpiresAt *time.Time
+	AuthorizationAlertedAt *time.Time
+
+	// Disputed is true once the buyer opened a chargeback, even if the seller wins it
+	Disputed bool
+
+	// Shipment
+	ShippedAt      *time.Time
+	ShippedBy      *uuid.UUID
+	Carrier        *string
+	TrackingNumber *string
 
 	// Relations
 	User  *User
@@ -150,5 +172,234 @@ func (o *Order) UpdateOrder(inputs UpdateOrderInputs) error {
 		o.ExpiresAt = nil
 	}
 
+	// the funds are reserved by the issuer, the order waits to be captured instead of paid
+	if o.PayStatus == Authorized && o.AuthorizedAt == nil {
+		authorizedAt := o.UpdatedAt
+		authorizationExpiresAt := authorizedAt.Add(AuthorizationValidity)
+		o.AuthorizedAt = &authorizedAt
+		o.AuthorizationExpiresAt = &authorizationExpiresAt
+		o.ExpiresAt = nil
+		o.Paid = false
+		o.PaidAt = nil
+	}
+
+	return nil
+}
+
+// CanBeCaptured validates that the order has an authorized payment that has not expired yet
+func (o *Order) CanBeCaptured(now time.Time) error {
+	if o.PayStatus != Authorized || o.PaymentID == nil {
+		return ErrOrderNotAuthorized
+	}
+
+	if o.AuthorizationExpiresAt != nil && now.After(*o.AuthorizationExpiresAt) {
+		return ErrAuthorizationExpired
+	}
+	return nil
+}
+
+type CaptureOrderInputs struct {
+	PayStatusDetail   PayStatusDetail
+	Fee               float64
+	NetReceivedAmount float64
+}
+
+// Capture marks an authorized order as paid once the provider has captured the funds
+func (o *Order) Capture(inputs CaptureOrderInputs) error {
+	now := time.Now()
+	if err := o.CanBeCaptured(now); err != nil {
+		return err
+	}
+
+	o.PayStatus = Approved
+	o.PayStatusDetail = &inputs.PayStatusDetail
+	o.Fee = &inputs.Fee
+	o.NetReceivedAmount = &inputs.NetReceivedAmount
+	o.Paid = true
+	o.PaidAt = &now
+	o.ExpiresAt = nil
+	o.UpdatedAt = now
+
 	return nil
 }
+
+// AuthorizationExpiresWithin reports if the authorization of the order expires before now + window
+// and no alert has been sent for it yet
+func (o *Order) AuthorizationExpiresWithin(now time.Time, window time.Duration) bool {
+	if o.PayStatus != Authorized || o.PaymentID == nil || o.AuthorizationExpiresAt == nil || o.AuthorizationAlertedAt != nil {
+		return false
+	}
+	return o.AuthorizationExpiresAt.Before(now.Add(window))
+}
+
+// MarkAuthorizationAlerted records that an alert about the authorization expiry has already been sent
+func (o *Order) MarkAuthorizationAlerted(now time.Time) {
+	o.AuthorizationAlertedAt = &now
+	o.UpdatedAt = now
+}
+
+// FeeAmount returns the fee retained by the payment provider, 0 if the order has not been paid yet
+func (o *Order) FeeAmount() float64 {
+	if o.Fee == nil {
+		return 0
+	}
+	return *o.Fee
+}
+
+// NetAmount returns the amount received after provider fees
+func (o *Order) NetAmount() float64 {
+	if o.NetReceivedAmount == nil {
+		return o.Total - o.FeeAmount()
+	}
+	return *o.NetReceivedAmount
+}
+
+// Cancel cancels the order applying the cancellation rules: the owner of the order can cancel it while
+// the payment is pending, and an admin can also cancel orders whose payment was authorized but not captured
+func (o *Order) Cancel(actor *User, reason string) error {
+	if len(reason) == 0 {
+		return ErrCancelReasonIsRequire
+	}
+
+	switch {
+	case actor.Role == Admin:
+		if o.PayStatus != Pending && o.PayStatus != Authorized {
+			return ErrOrderCannotBeCancelled
+		}
+	case actor.ID == o.UserID:
+		if o.PayStatus != Pending {
+			return ErrOrderCannotBeCancelled
+		}
+	default:
+		return ErrOrderCancelNotAllowed
+	}
+
+	now := time.Now()
+	o.PayStatus = Cancelled
+	o.CancelledAt = &now
+	o.CancelledBy = &actor.ID
+	o.CancelReason = &reason
+	o.ExpiresAt = nil
+	o.UpdatedAt = now
+
+	return nil
+}
+
+type ShipOrderInputs struct {
+	Carrier        string
+	TrackingNumber *string
+}
+
+// CanBeShipped validates the shipment regardless of the payment, an authorized order is captured before it's shipped
+func (o *Order) CanBeShipped(inputs ShipOrderInputs) error {
+	if o.ShippedAt != nil {
+		return ErrOrderAlreadyShipped
+	}
+	if len(inputs.Carrier) == 0 {
+		return ErrCarrierIsRequire
+	}
+	return nil
+}
+
+// Ship records the shipment of a paid order, an order is shipped once
+func (o *Order) Ship(actor *User, inputs ShipOrderInputs) error {
+	if !o.Paid {
+		return ErrOrderNotPaid
+	}
+	if err := o.CanBeShipped(inputs); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	o.ShippedAt = &now
+	o.ShippedBy = &actor.ID
+	o.Carrier = &inputs.Carrier
+	o.TrackingNumber = inputs.TrackingNumber
+	o.UpdatedAt = now
+
+	return nil
+}
+
+// MarkChargedBack flags the order after the buyer disputes the payment, the funds are retained by the issuer
+func (o *Order) MarkChargedBack() {
+	o.PayStatus = ChargedBack
+	o.Disputed = true
+	o.UpdatedAt = time.Now()
+}
+
+// ResolveChargeback restores the order if the seller won the dispute, a lost dispute keeps the order charged back
+func (o *Order) ResolveChargeback(outcome DisputeStatus) {
+	if outcome != DisputeWon {
+		return
+	}
+	o.PayStatus = Approved
+	o.UpdatedAt = time.Now()
+}
+
+// ReflectsAttempt reports if the order payment data was already taken from the attempt
+func (o *Order) ReflectsAttempt(a *PaymentAttempt) bool {
+	return o.PaymentID != nil && *o.PaymentID == a.PaymentID &&
+		o.PayStatus == a.Status &&
+		o.PayStatusDetail != nil && *o.PayStatusDetail == a.StatusDetail
+}
+
+// ApplyPaymentAttempt updates the order with the data of the attempt that drives its status
+func (o *Order) ApplyPaymentAttempt(a *PaymentAttempt) error {
+	var paidAt *time.Time
+	if a.IsSuccessful() {
+		paidAt = o.PaidAt
+		if paidAt == nil {
+			now := time.Now()
+			paidAt = &now
+		}
+	}
+
+	return o.UpdateOrder(UpdateOrderInputs{
+		PaymentID:         a.PaymentID,
+		PayStatus:         a.Status,
+		PayStatusDetail:   a.StatusDetail,
+		PayMethod:         a.PayMethod,
+		PayResource:       a.PayResource,
+		Installments:      a.Installments,
+		Paid:              a.IsSuccessful(),
+		Fee:               a.Fee(),
+		NetReceivedAmount: a.NetReceivedAmount,
+		ExternalReference: o.ID.String(),
+		PaidAt:            paidAt,
+	})
+}
+
+// ValidatePayment checks that a payment covers the order, the tolerance absorbs rounding differences of the provider.
+// A payment without currency can't be trusted to be in the currency of the order
+func (o *Order) ValidatePayment(amount float64, currency Currencies, tolerance float64) error {
+	if currency != o.Currency {
+		return ErrPaymentCurrencyMismatch
+	}
+
+	if math.Abs(amount-o.Total) > tolerance {
+		return ErrPaymentAmountMismatch
+	}
+
+	return nil
+}
+
+// MarkForReview takes the data of the attempt but keeps the order unpaid until someone reviews the payment
+func (o *Order) MarkForReview(a *PaymentAttempt) error {
+	err := o.ApplyPaymentAttempt(a)
+	if err != nil {
+		return err
+	}
+
+	o.PayStatus = Review
+	o.Paid = false
+	o.PaidAt = nil
+	o.AuthorizedAt = nil
+	o.AuthorizationExpiresAt = nil
+
+	return nil
+}
+
+// IsUnderReviewFor reports if the order was already sent to review because of the attempt
+func (o *Order) IsUnderReviewFor(a *PaymentAttempt) bool {
+	return o.PayStatus == Review && o.PaymentID != nil && *o.PaymentID == a.PaymentID
+}
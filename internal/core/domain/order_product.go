@@ -11,7 +11,13 @@ type OrderProduct struct {
 	ID        uuid.UUID
 	OrderID   uuid.UUID
 	ProductID uuid.UUID
+	VariantID *uuid.UUID
 	Quantity  int16
+
+	// price and discount of a unit when the order was created, later changes of the product don't change them
+	UnitPrice    float64
+	UnitDiscount float64
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 
@@ -20,12 +26,15 @@ type OrderProduct struct {
 	Product *Product
 }
 
-func NewOrderProduct(orderID, productID uuid.UUID, quantity int16) *OrderProduct {
+func NewOrderProduct(orderID, productID uuid.UUID, variantID *uuid.UUID, quantity int16, unitPrice, unitDiscount float64) *OrderProduct {
 	return &OrderProduct{
-		ID:        uuid.Nil, // repository will asign the id
-		OrderID:   orderID,
-		ProductID: productID,
-		Quantity:  quantity,
+		ID:           uuid.Nil, // repository will asign the id
+		OrderID:      orderID,
+		ProductID:    productID,
+		VariantID:    variantID,
+		Quantity:     quantity,
+		UnitPrice:    unitPrice,
+		UnitDiscount: unitDiscount,
 	}
 }
 
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentAttempt is an entity that represents each payment notified by the provider for an order.
+// An order can be paid after many attempts, e.g. a rejected card followed by an approved one
+type PaymentAttempt struct {
+	ID                uuid.UUID
+	OrderID           uuid.UUID
+	PaymentID         string
+	Status            PayStatus
+	StatusDetail      PayStatusDetail
+	Amount            float64
+	NetReceivedAmount float64
+	Currency          Currencies
+	PayMethod         *string
+	PayResource       *string
+	Installments      uint8
+	RawPayload        json.RawMessage // payment as it was received from the provider
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// attemptPrecedence defines which attempt drives the order status, the higher wins.
+// Statuses not listed (rejected, cancelled, expired...) only apply when there isn't a better attempt
+var attemptPrecedence = map[PayStatus]int{
+	Approved:   5,
+	Authorized: 4,
+	InProcess:  3,
+	Pending:    2,
+	Refunded:   1,
+}
+
+func (a *PaymentAttempt) Fee() float64 {
+	return a.Amount - a.NetReceivedAmount
+}
+
+func (a *PaymentAttempt) IsSuccessful() bool {
+	return a.Status == Approved && a.StatusDetail == Accredited
+}
+
+// EffectiveAttempt returns the attempt that defines the status of the order,
+// ties are resolved by the most recent update. Returns nil if there are no attempts
+func EffectiveAttempt(attempts []*PaymentAttempt) *PaymentAttempt {
+	var effective *PaymentAttempt
+
+	for _, a := range attempts {
+		if effective == nil {
+			effective = a
+			continue
+		}
+
+		current, best := attemptPrecedence[a.Status], attemptPrecedence[effective.Status]
+		if current > best || (current == best && a.UpdatedAt.After(effective.UpdatedAt)) {
+			effective = a
+		}
+	}
+
+	return effective
+}
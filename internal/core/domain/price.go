@@ -0,0 +1,268 @@
+package domain
+
+import (
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Pricing is the price and discount of a product
+type Pricing struct {
+	Price        float64
+	Discount     float64
+	DiscountType DisscountTypes
+}
+
+// ValidatePricing checks the discount against the price. Bundle discounts can't be expressed with a single amount
+// so only percentage and fixed discounts are accepted
+func ValidatePricing(pricing Pricing) error {
+	if pricing.Price <= 0 {
+		return ErrProductPriceIsRequire
+	}
+
+	if pricing.Discount < 0 {
+		return ErrInvalidDiscount
+	}
+
+	switch pricing.DiscountType {
+	case "":
+		if pricing.Discount > 0 {
+			return ErrDiscountTypeIsRequire
+		}
+	case Percentage:
+		if pricing.Discount > 100 {
+			return ErrPercentageDiscountTooHigh
+		}
+	case Fixed:
+		if pricing.Discount >= pricing.Price {
+			return ErrFixedDiscountTooHigh
+		}
+	default:
+		return ErrInvalidDiscountType
+	}
+	return nil
+}
+
+// Pricing returns the current price and discount of the product
+func (p *Product) Pricing() Pricing {
+	return Pricing{Price: p.Price, Discount: p.Disscount, DiscountType: p.DisscountType}
+}
+
+func (p *Product) setPricing(pricing Pricing) {
+	p.Price = pricing.Price
+	p.Disscount = pricing.Discount
+	p.DisscountType = pricing.DiscountType
+}
+
+// UnitDiscount returns the discount of one unit sold at unitPrice
+func (p *Product) UnitDiscount(unitPrice float64) float64 {
+	switch p.DisscountType {
+	case Percentage:
+		return unitPrice * p.Disscount / 100
+	case Fixed:
+		return math.Min(p.Disscount, unitPrice)
+	default:
+		return 0
+	}
+}
+
+// PriceChange is an entry of the price history of a product
+type PriceChange struct {
+	ID              uuid.UUID
+	ProductID       uuid.UUID
+	OldPrice        float64
+	NewPrice        float64
+	OldDiscount     float64
+	NewDiscount     float64
+	OldDiscountType DisscountTypes
+	NewDiscountType DisscountTypes
+	ChangedBy       *uuid.UUID // nil when the user is unknown
+	ScheduleID      *uuid.UUID // set when the change was applied by a price schedule
+	CreatedAt       time.Time
+}
+
+// NewPriceChange returns the entry of the history between both pricings, nil if nothing changed
+func NewPriceChange(productID uuid.UUID, before, after Pricing, changedBy, scheduleID *uuid.UUID) *PriceChange {
+	if before == after {
+		return nil
+	}
+
+	return &PriceChange{
+		ProductID:       productID,
+		OldPrice:        before.Price,
+		NewPrice:        after.Price,
+		OldDiscount:     before.Discount,
+		NewDiscount:     after.Discount,
+		OldDiscountType: before.DiscountType,
+		NewDiscountType: after.DiscountType,
+		ChangedBy:       changedBy,
+		ScheduleID:      scheduleID,
+		CreatedAt:       time.Now(),
+	}
+}
+
+type PriceScheduleStatus string
+
+const (
+	SchedulePending   PriceScheduleStatus = "pending"   // waiting for StartsAt
+	ScheduleActive    PriceScheduleStatus = "active"    // time-boxed sale running until EndsAt
+	ScheduleCompleted PriceScheduleStatus = "completed" // applied, and reverted if it was a sale
+	ScheduleCancelled PriceScheduleStatus = "cancelled"
+)
+
+// PriceSchedule is a future change of the price or discount of a product, applied by a background job.
+// Nil fields keep the value the product has when the schedule starts
+type PriceSchedule struct {
+	ID           uuid.UUID
+	ProductID    uuid.UUID
+	Price        *float64
+	Discount     *float64
+	DiscountType *DisscountTypes
+	StartsAt     time.Time
+	EndsAt       *time.Time // nil for permanent changes
+	Status       PriceScheduleStatus
+	Previous     *Pricing // pricing before a sale started, restored when it ends
+	CreatedBy    *uuid.UUID
+	AppliedAt    *time.Time
+	EndedAt      *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func NewPriceSchedule(productID uuid.UUID, inputs ports_dtos.SchedulePriceInputs, now time.Time) (*PriceSchedule, error) {
+	if inputs.Price == nil && inputs.Discount == nil && inputs.DiscountType == nil {
+		return nil, ErrPriceScheduleIsEmpty
+	}
+
+	if inputs.StartsAt.Before(now) || (inputs.EndsAt != nil && !inputs.EndsAt.After(inputs.StartsAt)) {
+		return nil, ErrPriceScheduleInvalidDates
+	}
+
+	if inputs.Price != nil && *inputs.Price <= 0 {
+		return nil, ErrProductPriceIsRequire
+	}
+
+	if inputs.Discount != nil && *inputs.Discount < 0 {
+		return nil, ErrInvalidDiscount
+	}
+
+	var discountType *DisscountTypes
+	if inputs.DiscountType != nil {
+		t := DisscountTypes(*inputs.DiscountType)
+		if t != Percentage && t != Fixed {
+			return nil, ErrInvalidDiscountType
+		}
+		discountType = &t
+	}
+
+	return &PriceSchedule{
+		ID:           uuid.Nil, // repository will asign the id
+		ProductID:    productID,
+		Price:        inputs.Price,
+		Discount:     inputs.Discount,
+		DiscountType: discountType,
+		StartsAt:     inputs.StartsAt,
+		EndsAt:       inputs.EndsAt,
+		Status:       SchedulePending,
+		CreatedBy:    inputs.CreatedBy,
+	}, nil
+}
+
+// IsDue reports if the schedule has to be started or, for sales, ended
+func (s *PriceSchedule) IsDue(now time.Time) bool {
+	switch s.Status {
+	case SchedulePending:
+		return !s.StartsAt.After(now)
+	case ScheduleActive:
+		return s.EndsAt != nil && !s.EndsAt.After(now)
+	default:
+		return false
+	}
+}
+
+// helper func, the pricing of the product with the values of the schedule
+func (s *PriceSchedule) target(current Pricing) Pricing {
+	if s.Price != nil {
+		current.Price = *s.Price
+	}
+	if s.Discount != nil {
+		current.Discount = *s.Discount
+	}
+	if s.DiscountType != nil {
+		current.DiscountType = *s.DiscountType
+	}
+	return current
+}
+
+// Apply starts or ends the schedule over the product and returns the change of its pricing, nil if it didn't change.
+// When a sale ends only the values still set by the sale are restored, manual changes made during the sale are kept
+func (s *PriceSchedule) Apply(p *Product, now time.Time) (*PriceChange, error) {
+	before := p.Pricing()
+	after := before
+
+	switch {
+	case s.Status == SchedulePending:
+		after = s.target(before)
+		if err := ValidatePricing(after); err != nil {
+			return nil, err
+		}
+
+		s.AppliedAt = &now
+		s.Status = ScheduleCompleted
+		if s.EndsAt != nil {
+			s.Previous = &before
+			s.Status = ScheduleActive
+		}
+
+	case s.Status == ScheduleActive && s.Previous != nil:
+		sale := s.target(*s.Previous)
+		if before.Price == sale.Price {
+			after.Price = s.Previous.Price
+		}
+		if before.Discount == sale.Discount && before.DiscountType == sale.DiscountType {
+			after.Discount = s.Previous.Discount
+			after.DiscountType = s.Previous.DiscountType
+		}
+		// a fixed discount can't be restored over a price lowered during the sale
+		if ValidatePricing(after) != nil {
+			after.Discount = 0
+			after.DiscountType = ""
+		}
+
+		s.EndedAt = &now
+		s.Status = ScheduleCompleted
+
+	default:
+		return nil, ErrPriceScheduleFinished
+	}
+
+	s.UpdatedAt = now
+	p.setPricing(after)
+	p.UpdatedAt = now
+	return NewPriceChange(p.ID, before, after, s.CreatedBy, &s.ID), nil
+}
+
+// Abandon stops a schedule that can't be applied (e.g. the product was archived) without changing the product
+func (s *PriceSchedule) Abandon(now time.Time) {
+	s.Status = ScheduleCancelled
+	s.EndedAt = &now
+	s.UpdatedAt = now
+}
+
+// Cancel cancels a pending schedule, a running sale is ended on the next run of the job
+func (s *PriceSchedule) Cancel(now time.Time) error {
+	switch s.Status {
+	case SchedulePending:
+		s.Status = ScheduleCancelled
+		s.EndedAt = &now
+	case ScheduleActive:
+		s.EndsAt = &now
+	default:
+		return ErrPriceScheduleFinished
+	}
+
+	s.UpdatedAt = now
+	return nil
+}
@@ -25,6 +25,7 @@ type Product struct {
 	ID            uuid.UUID
 	CategoryID    uint64
 	SKU           string
+	Slug          string // unique, generated from the name when the product is created
 	Name          string
 	Stock         int64
 	Price         float64
@@ -33,7 +34,14 @@ type Product struct {
 	Image         string
 	CreatedAt     time.Time
 	UpdatedAt     time.Time
+	DeletedAt     *time.Time // set while the product is archived
+	UpdatedBy     *uuid.UUID // user that performs the change, not persisted but recorded in the price history and stock ledger
 	Category      *Category
+	Variants      []Variant
+	Images        []ProductImage // ordered gallery, Image is the URL of the primary one
+
+	LowStockThreshold int64      // sellers and admins are alerted when the stock falls to it, 0 disables the alert
+	LowStockAlertedAt *time.Time // set while the stock is low and the alert was sent, cleared when the stock recovers
 }
 
 // TODO -> Cambiar esto por un port_dto
@@ -72,6 +80,7 @@ func NewProduct(name, sku, image string, stock int64, price float64, categoryID
 	return &Product{
 		ID:         uuid.Nil, // repository will asign the id
 		Name:       name,
+		Slug:       Slugify(name),
 		SKU:        sku,
 		Stock:      stock,
 		Price:      price,
@@ -86,8 +95,9 @@ func (p *Product) Update(inputs ports_dtos.SaveProductInputs) error {
 		return ErrProductNameIsRequire
 	}
 
-	if inputs.Stock != nil && *inputs.Stock <= 0 {
-		return ErrProductStockIsRequire
+	// the stock only changes through the movements of the stock ledger
+	if inputs.Stock != nil {
+		return ErrProductStockNotEditable
 	}
 
 	if inputs.Price != nil && *inputs.Price <= 0 {
@@ -114,6 +124,25 @@ func (p *Product) Update(inputs ports_dtos.SaveProductInputs) error {
 		return ErrProductMinLenghtSKU
 	}
 
+	if inputs.LowStockThreshold != nil && *inputs.LowStockThreshold < 0 {
+		return ErrProductLowStockThreshold
+	}
+
+	// the discount is validated against the resulting price
+	pricing := p.Pricing()
+	if inputs.Price != nil {
+		pricing.Price = *inputs.Price
+	}
+	if inputs.Discount != nil {
+		pricing.Discount = *inputs.Discount
+	}
+	if inputs.DiscountType != nil {
+		pricing.DiscountType = DisscountTypes(*inputs.DiscountType)
+	}
+	if err := ValidatePricing(pricing); err != nil {
+		return err
+	}
+
 	// update the existing fields
 	if inputs.CategoryID != nil {
 		p.CategoryID = *inputs.CategoryID
@@ -124,15 +153,14 @@ func (p *Product) Update(inputs ports_dtos.SaveProductInputs) error {
 	if inputs.Name != nil {
 		p.Name = *inputs.Name
 	}
-	if inputs.Stock != nil {
-		p.Stock = *inputs.Stock
-	}
-	if inputs.Price != nil {
-		p.Price = *inputs.Price
-	}
+	p.setPricing(pricing)
 	if inputs.Image != nil {
 		p.Image = *inputs.Image
 	}
+	if inputs.LowStockThreshold != nil {
+		p.LowStockThreshold = *inputs.LowStockThreshold
+	}
+	p.UpdatedBy = inputs.UpdatedBy
 	p.UpdatedAt = time.Now()
 
 	return nil
@@ -147,5 +175,12 @@ func (p *Product) ToInputs() ports_dtos.SaveProductInputs {
 		Price:      &p.Price,
 		Stock:      &p.Stock,
 		CategoryID: &p.CategoryID,
+
+		LowStockThreshold: &p.LowStockThreshold,
 	}
 }
+
+// IsLowStock reports if the stock of the product fell to its low stock threshold
+func (p *Product) IsLowStock() bool {
+	return p.LowStockThreshold > 0 && p.Stock <= p.LowStockThreshold
+}
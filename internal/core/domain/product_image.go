@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Product image rules
+const (
+	MaxProductImageSize   = 5 << 20 // 5MB
+	MaxProductImagePixels = 40e6    // 40 megapixels, a small file can declare a huge image that exhausts the memory
+	MaxProductImages      = 10
+	ProductThumbnailSide  = 320 // longest side of the thumbnails in pixels
+)
+
+// allowed content types of the uploaded images and their file extension
+var productImageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+type ProductImage struct {
+	ID           uuid.UUID
+	ProductID    uuid.UUID
+	Key          string // key of the original image in the blob storage
+	URL          string
+	ThumbnailKey string
+	ThumbnailURL string
+	ContentType  string
+	Size         int64
+	Position     int // order in the gallery, starting at 0
+	IsPrimary    bool
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// ProductImageExtension validates the type and size of an upload and returns the extension of the file
+func ProductImageExtension(contentType string, size int64) (string, error) {
+	if size <= 0 {
+		return "", ErrProductImageEmpty
+	}
+
+	if size > MaxProductImageSize {
+		return "", ErrProductImageTooLarge
+	}
+
+	ext, ok := productImageExtensions[contentType]
+	if !ok {
+		return "", ErrProductImageInvalidType
+	}
+	return ext, nil
+}
+
+// ProductImageKey returns the key of a file of the product in the blob storage
+func ProductImageKey(productID, imageID uuid.UUID, suffix, ext string) string {
+	return fmt.Sprintf("products/%s/%s%s%s", productID, imageID, suffix, ext)
+}
+
+// PrimaryImage returns the primary image of the gallery, nil if the product has no images
+func (p *Product) PrimaryImage() *ProductImage {
+	for i := range p.Images {
+		if p.Images[i].IsPrimary {
+			return &p.Images[i]
+		}
+	}
+	return nil
+}
+
+// AddImage appends the image at the end of the gallery, the first image becomes the primary one
+func (p *Product) AddImage(image ProductImage) error {
+	if len(p.Images) >= MaxProductImages {
+		return ErrProductImageLimit
+	}
+
+	image.ProductID = p.ID
+	image.Position = len(p.Images)
+	image.IsPrimary = len(p.Images) == 0
+	p.Images = append(p.Images, image)
+
+	p.syncPrimaryImage()
+	return nil
+}
+
+// RemoveImage removes the image of the gallery, if it was the primary one the first image is promoted
+func (p *Product) RemoveImage(imageID uuid.UUID) (*ProductImage, error) {
+	for i := range p.Images {
+		if p.Images[i].ID != imageID {
+			continue
+		}
+
+		removed := p.Images[i]
+		p.Images = append(p.Images[:i], p.Images[i+1:]...)
+
+		for pos := range p.Images {
+			p.Images[pos].Position = pos
+		}
+		if removed.IsPrimary && len(p.Images) > 0 {
+			p.Images[0].IsPrimary = true
+		}
+
+		p.syncPrimaryImage()
+		return &removed, nil
+	}
+	return nil, ErrProductImageNotFound
+}
+
+// ReorderImages sorts the gallery following the ids, that must contain every image once
+func (p *Product) ReorderImages(imageIDs []uuid.UUID) error {
+	if len(imageIDs) != len(p.Images) {
+		return ErrProductImageInvalidOrder
+	}
+
+	byID := make(map[uuid.UUID]ProductImage, len(p.Images))
+	for _, image := range p.Images {
+		byID[image.ID] = image
+	}
+
+	ordered := make([]ProductImage, 0, len(imageIDs))
+	for pos, id := range imageIDs {
+		image, ok := byID[id]
+		if !ok {
+			return ErrProductImageInvalidOrder
+		}
+		delete(byID, id) // repeated ids are rejected by the lookup above
+
+		image.Position = pos
+		ordered = append(ordered, image)
+	}
+
+	p.Images = ordered
+	return nil
+}
+
+// SetPrimaryImage marks the image as the primary one of the gallery
+func (p *Product) SetPrimaryImage(imageID uuid.UUID) error {
+	found := false
+	for i := range p.Images {
+		if p.Images[i].ID == imageID {
+			found = true
+		}
+	}
+	if !found {
+		return ErrProductImageNotFound
+	}
+
+	for i := range p.Images {
+		p.Images[i].IsPrimary = p.Images[i].ID == imageID
+	}
+
+	p.syncPrimaryImage()
+	return nil
+}
+
+// helper func, the Image field keeps the URL of the primary image for the clients that only read it
+func (p *Product) syncPrimaryImage() {
+	if primary := p.PrimaryImage(); primary != nil {
+		p.Image = primary.URL
+	}
+}
@@ -0,0 +1,164 @@
+package domain
+
+import (
+	"fmt"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportRowErrors is the number of row errors kept in the job, the rest are only counted
+const MaxImportRowErrors = 100
+
+// ProductCSVHeader is the layout of the catalogue in the import and the export. In the import the columns can be
+// in any order and only sku is required, empty cells keep the current value of existing products
+var ProductCSVHeader = []string{"sku", "name", "price", "stock", "category_id", "image"}
+
+type ImportJobStatus string
+
+const (
+	ImportRunning   ImportJobStatus = "running"
+	ImportCompleted ImportJobStatus = "completed"
+	ImportFailed    ImportJobStatus = "failed" // the file couldn't be read, rows processed before the error are kept
+)
+
+// ImportRowError is a row of the file that couldn't be imported, Row is the line in the file
+type ImportRowError struct {
+	Row   int
+	SKU   string
+	Error string
+}
+
+// ImportJob tracks the progress of an asynchronous import of products
+type ImportJob struct {
+	ID         uuid.UUID
+	Status     ImportJobStatus
+	Processed  int
+	Created    int
+	Updated    int
+	Failed     int
+	Errors     []ImportRowError
+	Error      string // set when the job failed
+	StartedAt  time.Time
+	FinishedAt *time.Time
+}
+
+func NewImportJob() *ImportJob {
+	return &ImportJob{
+		ID:        uuid.New(),
+		Status:    ImportRunning,
+		Errors:    []ImportRowError{},
+		StartedAt: time.Now(),
+	}
+}
+
+func (j *ImportJob) RecordCreated() {
+	j.Processed++
+	j.Created++
+}
+
+func (j *ImportJob) RecordUpdated() {
+	j.Processed++
+	j.Updated++
+}
+
+func (j *ImportJob) RecordFailure(row int, sku string, err error) {
+	j.Processed++
+	j.Failed++
+	if len(j.Errors) < MaxImportRowErrors {
+		j.Errors = append(j.Errors, ImportRowError{Row: row, SKU: sku, Error: err.Error()})
+	}
+}
+
+// Finish closes the job, err is the error that stopped the import if any
+func (j *ImportJob) Finish(err error) {
+	now := time.Now()
+	j.FinishedAt = &now
+	j.Status = ImportCompleted
+	if err != nil {
+		j.Status = ImportFailed
+		j.Error = err.Error()
+	}
+}
+
+func (j *ImportJob) IsFinished() bool {
+	return j.FinishedAt != nil
+}
+
+// ProductCSVColumns validates the header of the file and returns the position of each known column
+func ProductCSVColumns(header []string) (map[string]int, error) {
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+
+	if _, ok := columns["sku"]; !ok {
+		return nil, ErrImportMissingSKUColumn
+	}
+	return columns, nil
+}
+
+// ParseProductCSVRow maps a row of the file to the inputs of a product, empty cells are left nil
+func ParseProductCSVRow(columns map[string]int, record []string) (ports_dtos.SaveProductInputs, error) {
+	var inputs ports_dtos.SaveProductInputs
+
+	cell := func(name string) *string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return nil
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	inputs.SKU = cell("sku")
+	if inputs.SKU == nil {
+		return inputs, ErrProductSKUIsRequire
+	}
+	inputs.Name = cell("name")
+	inputs.Image = cell("image")
+
+	if v := cell("price"); v != nil {
+		price, err := strconv.ParseFloat(*v, 64)
+		if err != nil {
+			return inputs, fmt.Errorf("price must be a number: %q", *v)
+		}
+		inputs.Price = &price
+	}
+
+	if v := cell("stock"); v != nil {
+		stock, err := strconv.ParseInt(*v, 10, 64)
+		if err != nil {
+			return inputs, fmt.Errorf("stock must be an integer: %q", *v)
+		}
+		inputs.Stock = &stock
+	}
+
+	if v := cell("category_id"); v != nil {
+		categoryID, err := strconv.ParseUint(*v, 10, 64)
+		if err != nil {
+			return inputs, fmt.Errorf("category_id must be a positive integer: %q", *v)
+		}
+		inputs.CategoryID = &categoryID
+	}
+
+	return inputs, nil
+}
+
+// ProductCSVRecord returns the product following ProductCSVHeader
+func ProductCSVRecord(p *Product) []string {
+	return []string{
+		p.SKU,
+		p.Name,
+		strconv.FormatFloat(p.Price, 'f', -1, 64),
+		strconv.FormatInt(p.Stock, 10),
+		strconv.FormatUint(p.CategoryID, 10),
+		p.Image,
+	}
+}
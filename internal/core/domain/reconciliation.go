@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReconciliationMismatchKind string
+
+const (
+	AmountMismatch   ReconciliationMismatchKind = "amount"   // The payment amount differs from the order total
+	CurrencyMismatch ReconciliationMismatchKind = "currency" // The payment currency differs from the order currency
+)
+
+// ReconciliationMismatch is a difference between an order and one of its payments in the provider
+type ReconciliationMismatch struct {
+	OrderID   uuid.UUID
+	PaymentID string
+	Kind      ReconciliationMismatchKind
+	Expected  string
+	Actual    string
+}
+
+// ReconciliationError is an order that couldn't be reconciled
+type ReconciliationError struct {
+	OrderID uuid.UUID
+	Error   string
+}
+
+// ReconciliationReport is the result of comparing the orders with the payments in the provider
+type ReconciliationReport struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Checked    int
+	Updated    []uuid.UUID
+	Mismatches []ReconciliationMismatch
+	Errors     []ReconciliationError
+}
+
+func NewReconciliationReport(startedAt time.Time) *ReconciliationReport {
+	return &ReconciliationReport{
+		StartedAt:  startedAt,
+		Updated:    []uuid.UUID{},
+		Mismatches: []ReconciliationMismatch{},
+		Errors:     []ReconciliationError{},
+	}
+}
+
+// CompareAmounts records a mismatch if the payment doesn't match the total or currency of the order
+func (r *ReconciliationReport) CompareAmounts(order *Order, paymentID string, amount float64, currency Currencies, tolerance float64) {
+	if math.Abs(amount-order.Total) > tolerance {
+		r.Mismatches = append(r.Mismatches, ReconciliationMismatch{
+			OrderID:   order.ID,
+			PaymentID: paymentID,
+			Kind:      AmountMismatch,
+			Expected:  fmt.Sprintf("%.2f", order.Total),
+			Actual:    fmt.Sprintf("%.2f", amount),
+		})
+	}
+
+	if len(currency) > 0 && currency != order.Currency {
+		r.Mismatches = append(r.Mismatches, ReconciliationMismatch{
+			OrderID:   order.ID,
+			PaymentID: paymentID,
+			Kind:      CurrencyMismatch,
+			Expected:  string(order.Currency),
+			Actual:    string(currency),
+		})
+	}
+}
+
+func (r *ReconciliationReport) AddUpdated(orderID uuid.UUID) {
+	r.Updated = append(r.Updated, orderID)
+}
+
+func (r *ReconciliationReport) AddError(orderID uuid.UUID, err error) {
+	r.Errors = append(r.Errors, ReconciliationError{OrderID: orderID, Error: err.Error()})
+}
+
+func (r *ReconciliationReport) Finish(finishedAt time.Time) {
+	r.FinishedAt = finishedAt
+}
@@ -0,0 +1,181 @@
+package domain
+
+import (
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportPeriod string
+
+const (
+	Daily   ReportPeriod = "day"
+	Weekly  ReportPeriod = "week"
+	Monthly ReportPeriod = "month"
+)
+
+type TopProductsRanking string
+
+const (
+	ByUnits   TopProductsRanking = "units"
+	ByRevenue TopProductsRanking = "revenue"
+)
+
+// RevenueBucket represents gross vs net revenue of paid orders inside a period
+type RevenueBucket struct {
+	Period time.Time
+	Orders int64
+	Gross  float64
+	Fees   float64
+	Net    float64
+}
+
+// FeesByPayMethod represents the fees retained by the provider grouped by payment method
+type FeesByPayMethod struct {
+	PayMethod string
+	Orders    int64
+	Gross     float64
+	Fees      float64
+}
+
+type AverageOrderValue struct {
+	Orders  int64
+	Gross   float64
+	Average float64
+}
+
+type TopProduct struct {
+	ProductID uuid.UUID
+	Name      string
+	SKU       string
+	Units     int64
+	Revenue   float64
+}
+
+// Conversion represents how many orders created in a range reached the approved status
+type Conversion struct {
+	Created  int64
+	Pending  int64
+	Approved int64
+	Rate     float64
+}
+
+func (p ReportPeriod) IsValid() bool {
+	return p == Daily || p == Weekly || p == Monthly
+}
+
+// Truncate returns the start of the period that contains t. Weeks start on monday
+func (p ReportPeriod) Truncate(t time.Time) time.Time {
+	year, month, day := t.Date()
+
+	switch p {
+	case Monthly:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	case Weekly:
+		offset := (int(t.Weekday()) + 6) % 7
+		return time.Date(year, month, day-offset, 0, 0, 0, 0, t.Location())
+	default:
+		return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// BuildRevenueBuckets groups paid orders by period and returns the buckets sorted by date
+func BuildRevenueBuckets(orders []*Order, period ReportPeriod) []RevenueBucket {
+	buckets := make([]RevenueBucket, 0)
+	index := make(map[time.Time]int)
+
+	for _, o := range orders {
+		if !o.Paid || o.PaidAt == nil {
+			continue
+		}
+
+		key := period.Truncate(*o.PaidAt)
+		i, ok := index[key]
+		if !ok {
+			buckets = append(buckets, RevenueBucket{Period: key})
+			i = len(buckets) - 1
+			index[key] = i
+		}
+
+		buckets[i].Orders++
+		buckets[i].Gross += o.Total
+		buckets[i].Fees += o.FeeAmount()
+		buckets[i].Net += o.NetAmount()
+	}
+
+	// keep buckets ordered by date, orders are not guaranteed to be sorted
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Period.Before(buckets[j].Period)
+	})
+
+	return buckets
+}
+
+// BuildFeesByPayMethod groups paid orders by payment method
+func BuildFeesByPayMethod(orders []*Order) []FeesByPayMethod {
+	result := make([]FeesByPayMethod, 0)
+	index := make(map[string]int)
+
+	for _, o := range orders {
+		if !o.Paid {
+			continue
+		}
+
+		method := "unknown"
+		if o.PayMethod != nil && *o.PayMethod != "" {
+			method = *o.PayMethod
+		}
+
+		i, ok := index[method]
+		if !ok {
+			result = append(result, FeesByPayMethod{PayMethod: method})
+			i = len(result) - 1
+			index[method] = i
+		}
+
+		result[i].Orders++
+		result[i].Gross += o.Total
+		result[i].Fees += o.FeeAmount()
+	}
+
+	return result
+}
+
+// CalcAverageOrderValue returns the average total of paid orders
+func CalcAverageOrderValue(orders []*Order) AverageOrderValue {
+	var aov AverageOrderValue
+
+	for _, o := range orders {
+		if !o.Paid {
+			continue
+		}
+		aov.Orders++
+		aov.Gross += o.Total
+	}
+
+	if aov.Orders > 0 {
+		aov.Average = aov.Gross / float64(aov.Orders)
+	}
+	return aov
+}
+
+// CalcConversion returns the ratio between approved and created orders using the count of orders by status
+func CalcConversion(ordersByStatus map[PayStatus]int64) Conversion {
+	var c Conversion
+
+	for status, count := range ordersByStatus {
+		c.Created += count
+		if status == Pending {
+			c.Pending += count
+		}
+		if status == Approved {
+			c.Approved += count
+		}
+	}
+
+	if c.Created > 0 {
+		c.Rate = float64(c.Approved) / float64(c.Created)
+	}
+	return c
+}
@@ -23,6 +23,7 @@ const (
 
 type PasswordHasher interface {
 	Hash(password string) (string, error)
+	ComparePassword(password, hashedPassword string) error
 }
 
 type User struct {
@@ -31,8 +32,10 @@ type User struct {
 	Email     string
 	Password  string
 	Role      UserRole
+	Locale    string // language of the notifications
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	DeletedAt *time.Time // set while the user is archived
 }
 
 type SaveUserInputs struct {
@@ -41,6 +44,7 @@ type SaveUserInputs struct {
 	Email    *string
 	Password *string
 	Role     *UserRole
+	Locale   *string
 }
 
 // NewUser creates a new user applying the bussiness rules
@@ -77,6 +81,14 @@ func NewUser(i SaveUserInputs, hasher PasswordHasher) (*User, error) {
 		return nil, ErrRoleIsInvalid
 	}
 
+	locale := DefaultLocale
+	if i.Locale != nil {
+		if !IsValidLocale(*i.Locale) {
+			return nil, ErrLocaleIsInvalid
+		}
+		locale = *i.Locale
+	}
+
 	// hash password with the provided hasher
 	hashedPassword, err := hasher.Hash(*i.Password)
 	if err != nil {
@@ -89,6 +101,7 @@ func NewUser(i SaveUserInputs, hasher PasswordHasher) (*User, error) {
 		Email:     *i.Email,
 		Password:  hashedPassword,
 		Role:      *i.Role,
+		Locale:    locale,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
@@ -137,6 +150,13 @@ func (u *User) UpdateUser(i SaveUserInputs, hasher PasswordHasher) error {
 		u.Email = *i.Email
 	}
 
+	if i.Locale != nil {
+		if !IsValidLocale(*i.Locale) {
+			return ErrLocaleIsInvalid
+		}
+		u.Locale = *i.Locale
+	}
+
 	u.UpdatedAt = time.Now()
 	return nil
 }
@@ -148,5 +168,6 @@ func (u *User) ToInputs() SaveUserInputs {
 		Email:    &u.Email,
 		Password: &u.Password,
 		Role:     &u.Role,
+		Locale:   &u.Locale,
 	}
 }
@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Variant is a purchasable version of a product (e.g. size M, color red) with its own SKU and stock
+type Variant struct {
+	ID        uuid.UUID
+	ProductID uuid.UUID
+	SKU       string
+	Options   map[string]string // option attributes, e.g. {"size": "M", "color": "red"}
+	Price     *float64          // nil uses the price of the product
+	Stock     int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	UpdatedBy *uuid.UUID // user that performs the change, not persisted but recorded in the stock ledger
+}
+
+// helper func, shared validations of create and update
+func validateVariantInputs(inputs ports_dtos.SaveVariantInputs) error {
+	if inputs.SKU != nil && len(*inputs.SKU) == 0 {
+		return ErrVariantSKUIsRequire
+	}
+
+	if inputs.SKU != nil && len(*inputs.SKU) < minProductSKULength {
+		return ErrVariantMinLenghtSKU
+	}
+
+	if inputs.Options != nil && len(inputs.Options) == 0 {
+		return ErrVariantOptionsIsRequire
+	}
+
+	if inputs.Price != nil && *inputs.Price <= 0 {
+		return ErrVariantInvalidPrice
+	}
+
+	if inputs.Stock != nil && *inputs.Stock < 0 {
+		return ErrVariantNegativeStock
+	}
+	return nil
+}
+
+func NewVariant(productID uuid.UUID, inputs ports_dtos.SaveVariantInputs) (*Variant, error) {
+	if inputs.SKU == nil {
+		return nil, ErrVariantSKUIsRequire
+	}
+
+	if len(inputs.Options) == 0 {
+		return nil, ErrVariantOptionsIsRequire
+	}
+
+	if err := validateVariantInputs(inputs); err != nil {
+		return nil, err
+	}
+
+	var stock int64
+	if inputs.Stock != nil {
+		stock = *inputs.Stock
+	}
+
+	return &Variant{
+		ID:        uuid.Nil, // repository will asign the id
+		ProductID: productID,
+		SKU:       *inputs.SKU,
+		Options:   inputs.Options,
+		Price:     inputs.Price,
+		Stock:     stock,
+		UpdatedBy: inputs.UpdatedBy,
+	}, nil
+}
+
+func (v *Variant) Update(inputs ports_dtos.SaveVariantInputs) error {
+	// the stock only changes through the movements of the stock ledger
+	if inputs.Stock != nil {
+		return ErrVariantStockNotEditable
+	}
+	if err := validateVariantInputs(inputs); err != nil {
+		return err
+	}
+
+	if inputs.SKU != nil {
+		v.SKU = *inputs.SKU
+	}
+	if inputs.Options != nil {
+		v.Options = inputs.Options
+	}
+	if inputs.Price != nil {
+		v.Price = inputs.Price
+	}
+	v.UpdatedBy = inputs.UpdatedBy
+	v.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// Label returns the options in a stable order, e.g. "color: red, size: M"
+func (v *Variant) Label() string {
+	keys := make([]string, 0, len(v.Options))
+	for k := range v.Options {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, k := range keys {
+		parts = append(parts, k+": "+v.Options[k])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// HasVariants reports if the product is sold through its variants
+func (p *Product) HasVariants() bool {
+	return len(p.Variants) > 0
+}
+
+// FindVariant returns the variant of the product with the given id
+func (p *Product) FindVariant(variantID uuid.UUID) (*Variant, error) {
+	for i := range p.Variants {
+		if p.Variants[i].ID == variantID {
+			return &p.Variants[i], nil
+		}
+	}
+	return nil, ErrVariantNotFound
+}
+
+// ResolveVariant validates the variant selected for a line of the cart or order.
+// Products with variants must be bought through one of them, the rest can't receive a variant
+func (p *Product) ResolveVariant(variantID *uuid.UUID) (*Variant, error) {
+	if variantID == nil {
+		if p.HasVariants() {
+			return nil, ErrVariantIsRequire
+		}
+		return nil, nil
+	}
+	return p.FindVariant(*variantID)
+}
+
+// AvailableStock returns the stock of the product, or the stock of the variant when it has one
+func (p *Product) AvailableStock(variant *Variant) int64 {
+	if variant != nil {
+		return variant.Stock
+	}
+	return p.Stock
+}
+
+// UnitPrice returns the price of the product, or the price override of the variant when it has one
+func (p *Product) UnitPrice(variant *Variant) float64 {
+	if variant != nil && variant.Price != nil {
+		return *variant.Price
+	}
+	return p.Price
+}
@@ -0,0 +1,304 @@
+package domain
+
+import (
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Warehouse is a depot the orders are shipped from. The stock of the products is the sum of their stock in every warehouse
+type Warehouse struct {
+	ID        uuid.UUID
+	Code      string
+	Name      string
+	Latitude  *float64 // nil when the location is unknown, the warehouse is the last option of the closest rule
+	Longitude *float64
+	IsDefault bool // receives the stock changed without a warehouse, e.g. the stock of a new product
+	Active    bool // inactive warehouses keep their stock but don't fill orders
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// helper func, both coordinates or none
+func validateWarehouseLocation(latitude, longitude *float64) error {
+	if (latitude == nil) != (longitude == nil) {
+		return ErrWarehouseInvalidLocation
+	}
+	if latitude != nil && (math.Abs(*latitude) > 90 || math.Abs(*longitude) > 180) {
+		return ErrWarehouseInvalidLocation
+	}
+	return nil
+}
+
+func NewWarehouse(inputs ports_dtos.SaveWarehouseInputs) (*Warehouse, error) {
+	if inputs.Code == nil || strings.TrimSpace(*inputs.Code) == "" {
+		return nil, ErrWarehouseCodeIsRequire
+	}
+
+	if inputs.Name == nil || strings.TrimSpace(*inputs.Name) == "" {
+		return nil, ErrWarehouseNameIsRequire
+	}
+
+	if err := validateWarehouseLocation(inputs.Latitude, inputs.Longitude); err != nil {
+		return nil, err
+	}
+
+	warehouse := &Warehouse{
+		ID:        uuid.Nil, // repository will asign the id
+		Code:      strings.ToUpper(strings.TrimSpace(*inputs.Code)),
+		Name:      strings.TrimSpace(*inputs.Name),
+		Latitude:  inputs.Latitude,
+		Longitude: inputs.Longitude,
+		Active:    true,
+	}
+	if inputs.IsDefault != nil {
+		warehouse.IsDefault = *inputs.IsDefault
+	}
+	if inputs.Active != nil {
+		warehouse.Active = *inputs.Active
+	}
+	if warehouse.IsDefault && !warehouse.Active {
+		return nil, ErrWarehouseDefaultInactive
+	}
+	return warehouse, nil
+}
+
+func (w *Warehouse) Update(inputs ports_dtos.SaveWarehouseInputs) error {
+	if inputs.Code != nil && strings.TrimSpace(*inputs.Code) == "" {
+		return ErrWarehouseCodeIsRequire
+	}
+
+	if inputs.Name != nil && strings.TrimSpace(*inputs.Name) == "" {
+		return ErrWarehouseNameIsRequire
+	}
+
+	// the location is replaced as a whole
+	latitude, longitude := w.Latitude, w.Longitude
+	if inputs.Latitude != nil || inputs.Longitude != nil {
+		latitude, longitude = inputs.Latitude, inputs.Longitude
+	}
+	if err := validateWarehouseLocation(latitude, longitude); err != nil {
+		return err
+	}
+
+	// the default warehouse changes when another one is set as default
+	if w.IsDefault && inputs.IsDefault != nil && !*inputs.IsDefault {
+		return ErrWarehouseDefaultIsRequire
+	}
+
+	isDefault, active := w.IsDefault, w.Active
+	if inputs.IsDefault != nil {
+		isDefault = *inputs.IsDefault
+	}
+	if inputs.Active != nil {
+		active = *inputs.Active
+	}
+	if isDefault && !active {
+		return ErrWarehouseDefaultInactive
+	}
+
+	if inputs.Code != nil {
+		w.Code = strings.ToUpper(strings.TrimSpace(*inputs.Code))
+	}
+	if inputs.Name != nil {
+		w.Name = strings.TrimSpace(*inputs.Name)
+	}
+	w.Latitude, w.Longitude = latitude, longitude
+	w.IsDefault, w.Active = isDefault, active
+	w.UpdatedAt = time.Now()
+
+	return nil
+}
+
+// GeoPoint is a location, e.g. the address an order is shipped to
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// DistanceTo returns the great-circle distance in kilometers, false if the location of the warehouse is unknown
+func (w *Warehouse) DistanceTo(p GeoPoint) (float64, bool) {
+	if w.Latitude == nil || w.Longitude == nil {
+		return 0, false
+	}
+
+	const earthRadiusKm = 6371.0
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	lat1, lat2 := toRad(*w.Latitude), toRad(p.Latitude)
+	dLat, dLon := lat2-lat1, toRad(p.Longitude-*w.Longitude)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(a)), true
+}
+
+// WarehouseStock is the stock of a product, or one of its variants, in a warehouse
+type WarehouseStock struct {
+	WarehouseID uuid.UUID
+	ProductID   uuid.UUID
+	VariantID   *uuid.UUID
+	Stock       int64
+}
+
+// helper func, identifies the product or variant of a level, allocation or item
+func stockKey(productID uuid.UUID, variantID *uuid.UUID) string {
+	if variantID == nil {
+		return productID.String()
+	}
+	return productID.String() + "/" + variantID.String()
+}
+
+type AllocationRule string
+
+const (
+	AllocateClosest   AllocationRule = "closest"    // the warehouse closest to the shipment
+	AllocateMostStock AllocationRule = "most_stock" // the warehouse with most units of the items
+)
+
+type StockAllocationStatus string
+
+const (
+	AllocationReserved StockAllocationStatus = "reserved" // the units are held by the order
+	AllocationReleased StockAllocationStatus = "released" // the order was cancelled, the units are back in the warehouse
+	AllocationSold     StockAllocationStatus = "sold"     // the order was paid
+)
+
+// StockAllocation is the part of an item of an order reserved in a warehouse
+type StockAllocation struct {
+	ID          uuid.UUID
+	OrderID     *uuid.UUID // nil until the order is created
+	WarehouseID uuid.UUID
+	ProductID   uuid.UUID
+	VariantID   *uuid.UUID
+	Quantity    int64
+	Status      StockAllocationStatus
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// AllocationItem is a product or variant to allocate
+type AllocationItem struct {
+	ProductID uuid.UUID
+	VariantID *uuid.UUID
+	Quantity  int64
+}
+
+// AllocateStock picks the warehouses that fill the items. A warehouse that fills the whole order is preferred so the
+// order is shipped in one package, otherwise every item is split between the warehouses in the order of the rule
+func AllocateStock(items []AllocationItem, warehouses []Warehouse, levels []WarehouseStock, rule AllocationRule, shipTo *GeoPoint) ([]StockAllocation, error) {
+	if rule == "" {
+		rule = AllocateMostStock
+	}
+	if rule != AllocateClosest && rule != AllocateMostStock {
+		return nil, ErrInvalidAllocationRule
+	}
+	if rule == AllocateClosest && shipTo == nil {
+		return nil, ErrAllocationLocationIsRequire
+	}
+
+	// available units by warehouse and product or variant
+	available := make(map[uuid.UUID]map[string]int64, len(warehouses))
+	candidates := make([]Warehouse, 0, len(warehouses))
+	for _, w := range warehouses {
+		if w.Active {
+			available[w.ID] = map[string]int64{}
+			candidates = append(candidates, w)
+		}
+	}
+	for _, level := range levels {
+		if units, ok := available[level.WarehouseID]; ok {
+			units[stockKey(level.ProductID, level.VariantID)] += level.Stock
+		}
+	}
+
+	sortWarehouses(candidates, available, items, rule, shipTo)
+
+	// one warehouse for the whole order
+	for _, w := range candidates {
+		fills := true
+		for _, item := range items {
+			if available[w.ID][stockKey(item.ProductID, item.VariantID)] < item.Quantity {
+				fills = false
+				break
+			}
+		}
+		if !fills {
+			continue
+		}
+
+		allocations := make([]StockAllocation, 0, len(items))
+		for _, item := range items {
+			allocations = append(allocations, newStockAllocation(w.ID, item, item.Quantity))
+		}
+		return allocations, nil
+	}
+
+	// split the items between the warehouses
+	allocations := []StockAllocation{}
+	for _, item := range items {
+		key := stockKey(item.ProductID, item.VariantID)
+		remaining := item.Quantity
+		for _, w := range candidates {
+			units := min(available[w.ID][key], remaining)
+			if units <= 0 {
+				continue
+			}
+			allocations = append(allocations, newStockAllocation(w.ID, item, units))
+			available[w.ID][key] -= units
+			remaining -= units
+			if remaining == 0 {
+				break
+			}
+		}
+
+		if remaining > 0 {
+			if item.VariantID != nil {
+				return nil, ErrVariantInsufficientStock
+			}
+			return nil, ErrProductInsufficientStock
+		}
+	}
+	return allocations, nil
+}
+
+// helper func, orders the candidates by the rule, ties are broken by code so the allocation is stable
+func sortWarehouses(candidates []Warehouse, available map[uuid.UUID]map[string]int64, items []AllocationItem, rule AllocationRule, shipTo *GeoPoint) {
+	units := make(map[uuid.UUID]int64, len(candidates))
+	for _, w := range candidates {
+		for _, item := range items {
+			units[w.ID] += min(available[w.ID][stockKey(item.ProductID, item.VariantID)], item.Quantity)
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if rule == AllocateClosest {
+			da, okA := a.DistanceTo(*shipTo)
+			db, okB := b.DistanceTo(*shipTo)
+			if okA != okB {
+				return okA
+			}
+			if okA && da != db {
+				return da < db
+			}
+		}
+		if units[a.ID] != units[b.ID] {
+			return units[a.ID] > units[b.ID]
+		}
+		return a.Code < b.Code
+	})
+}
+
+func newStockAllocation(warehouseID uuid.UUID, item AllocationItem, quantity int64) StockAllocation {
+	return StockAllocation{
+		ID:          uuid.Nil, // repository will asign the id
+		WarehouseID: warehouseID,
+		ProductID:   item.ProductID,
+		VariantID:   item.VariantID,
+		Quantity:    quantity,
+		Status:      AllocationReserved,
+	}
+}
@@ -0,0 +1,8 @@
+package ports
+
+import "context"
+
+// Alerter is an interface for raising operational alerts that require the attention of the team
+type Alerter interface {
+	Alert(ctx context.Context, subject string, attrs map[string]any) error
+}
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"time"
+)
+
+// ArchiveService removes the archived (soft deleted) products, categories and users
+type ArchiveService interface {
+	// Purge removes the rows archived for longer than the retention
+	Purge(ctx context.Context, retention time.Duration) (*domain.PurgeReport, error)
+}
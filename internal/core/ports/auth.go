@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+)
+
+// AuthService issues and verifies the access tokens of the users
+type AuthService interface {
+	// Login returns an access token of the user with the email and password
+	Login(ctx context.Context, email, password string) (string, *domain.User, error)
+	// Authenticate returns the user of the access token
+	Authenticate(ctx context.Context, token string) (*domain.User, error)
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStorage stores files by key, e.g. "products/<id>/<file>.jpg". Keys are plain object paths,
+// so the same interface is implemented by the local filesystem and by S3-style object storages
+type BlobStorage interface {
+	// Put stores the content under the key, replacing it if it exists, and returns its public URL
+	Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error)
+	// Delete removes the key, deleting a missing key isn't an error
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of the key
+	URL(key string) string
+}
+
+// ImageProcessor generates derived versions of the uploaded images
+type ImageProcessor interface {
+	// Thumbnail scales the image so its longest side is at most maxSide, returns the encoded thumbnail and its content type
+	Thumbnail(data []byte, maxSide int) ([]byte, string, error)
+}
@@ -11,5 +11,9 @@ type CacheRepository interface {
 	Get(ctx context.Context, key string) ([]byte, error)                        // Get retrieves the value from the cache
 	Delete(ctx context.Context, key string) error                               // Delete removes the value from the cache
 	DeleteByPrefix(ctx context.Context, prefix string) error                    // DeleteByPrefix removes the value from the cache with the given prefix
+	Keys(ctx context.Context, prefix string) ([]string, error)                  // Keys returns the keys with the given prefix
 	Close() error                                                               // Close closes the connection to the cache server
+	// Update replaces the value with the result of fn atomically, fn receives nil if the key doesn't exist and can run
+	// again if the value was changed concurrently. A nil result deletes the key
+	Update(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) ([]byte, error)) error
 }
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"go-ecommerce/internal/core/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,12 +12,35 @@ type Amount struct {
 	SubTotal   float64
 	Discount float64
 	Total      float64
+	Lines    []domain.CartLine // the items priced, the order records their prices
+}
+
+// CartRepository saves the carts of the users and the guests, it's the source of truth of the carts cached in redis
+type CartRepository interface {
+	// GetCart returns an empty cart if the user doesn't have one
+	GetCart(ctx context.Context, userId uuid.UUID) (*domain.Cart, error)
+	// UpdateCart applies the change to the cart while it's locked, the cart has a zero UpdatedAt if it wasn't saved.
+	// A cart left without items is deleted
+	UpdateCart(ctx context.Context, userId uuid.UUID, change func(cart *domain.Cart) error) (*domain.Cart, error)
+	// ListCarts returns the carts of the users with items, the carts of the guests aren't listed
+	ListCarts(ctx context.Context) ([]*domain.Cart, error)
+	// GetGuestCart returns an empty cart if the guest doesn't have one or it expired
+	GetGuestCart(ctx context.Context, guestId uuid.UUID, now time.Time) (*domain.Cart, error)
+	// UpdateGuestCart is UpdateCart for the cart of a guest, the cart expires at expiresAt. An expired cart is changed
+	// as an empty cart
+	UpdateGuestCart(ctx context.Context, guestId uuid.UUID, expiresAt time.Time, change func(cart *domain.Cart) error) (*domain.Cart, error)
+	// DeleteExpiredGuestCarts deletes the carts of the guests expired before now, returns how many were deleted
+	DeleteExpiredGuestCarts(ctx context.Context, now time.Time) (int64, error)
 }
 
 type CartService interface {
 	GetCart(ctx context.Context, userId uuid.UUID) (*domain.Cart, error)
-	AddItemToCart(ctx context.Context, userId, productId uuid.UUID, quantity int16) error
+	// GetCartView returns the cart priced with the current products and the warnings of its lines
+	GetCartView(ctx context.Context, userId uuid.UUID) (*domain.CartView, error)
+	AddItemToCart(ctx context.Context, userId, productId uuid.UUID, variantId *uuid.UUID, quantity int16) error
 	CalcItemsAmount(ctx context.Context, userId uuid.UUID) (*Amount, error)
-	RemoveItem(ctx context.Context, userId, productId uuid.UUID) error
+	RemoveItem(ctx context.Context, userId, productId uuid.UUID, variantId *uuid.UUID) error
 	Clear(ctx context.Context, userId uuid.UUID) error
+	// ListCarts returns the carts of every user, it's used by the background jobs
+	ListCarts(ctx context.Context) ([]*domain.Cart, error)
 }
@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CartRecoveryRepository interface {
+	// CreateRecovery returns false if the idle period of the cart was already reminded
+	CreateRecovery(ctx context.Context, recovery *domain.CartRecovery) (bool, error)
+	GetRecoveryById(ctx context.Context, id uuid.UUID) (*domain.CartRecovery, error)
+	// MarkRestored records the first use of the link of the reminder
+	MarkRestored(ctx context.Context, id uuid.UUID, at time.Time) error
+	// MarkRecovered attributes the order to the last reminder of the user sent after since, false if there isn't one
+	MarkRecovered(ctx context.Context, order *domain.Order, since time.Time) (bool, error)
+}
+
+// CartRecoveryService is an interface for the reminders of the idle carts
+type CartRecoveryService interface {
+	// SendReminders notifies the owners of the carts idle at now, returns the number of reminders sent
+	SendReminders(ctx context.Context, now time.Time) (int, error)
+	// Restore puts back the items of the reminder with the signed token in the cart of its owner
+	Restore(ctx context.Context, token string) (*domain.Cart, error)
+	// MarkRecovered counts the order as recovered if its buyer was reminded inside the attribution window
+	MarkRecovered(ctx context.Context, order *domain.Order) error
+}
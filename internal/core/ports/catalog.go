@@ -0,0 +1,19 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"io"
+
+	"github.com/google/uuid"
+)
+
+// CatalogService imports and exports the catalogue of products in bulk
+type CatalogService interface {
+	// StartImport validates the header of the CSV and imports the rows in background, the returned job can be polled
+	StartImport(ctx context.Context, file io.Reader) (*domain.ImportJob, error)
+	GetImportJob(ctx context.Context, id uuid.UUID) (*domain.ImportJob, error)
+	// ExportProducts calls fn with every product that matches the filters, the cursor and limit of the query are ignored
+	ExportProducts(ctx context.Context, query ports_dtos.ProductQuery, fn func(*domain.Product) error) error
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"go-ecommerce/internal/core/domain"
+	"time"
 )
 
 // interface that category_repository implements
@@ -11,12 +12,19 @@ type CategoryRepository interface {
 	GetCategoryByID(ctx context.Context, id uint64) (*domain.Category, error)
 	ListCategories(ctx context.Context) ([]*domain.Category, error)
 	DeleteCategory(ctx context.Context, id uint64) error
+	DeleteCategoryWithPlan(ctx context.Context, plan *domain.CategoryDeletePlan) error
+	CountProducts(ctx context.Context, ids []uint64) (int64, error)
+	RestoreCategory(ctx context.Context, id uint64) error
+	PurgeCategories(ctx context.Context, archivedBefore time.Time) (int64, error)
 }
 
 // interface that category_service implements
 type CategoryService interface {
-	SaveCategory(ctx context.Context, id uint64, name string) (*domain.Category, error)
+	// SaveCategory keeps the parent when parentID is nil, domain.NoParentCategory moves the category to the root
+	SaveCategory(ctx context.Context, id uint64, name string, parentID *uint64) (*domain.Category, error)
 	GetCategoryByID(ctx context.Context, id uint64) (*domain.Category, error)
 	ListCategories(ctx context.Context) ([]*domain.Category, error)
-	DeleteCategory(ctx context.Context, id uint64) error
+	CategoryTree(ctx context.Context) ([]*domain.CategoryNode, error)
+	DeleteCategory(ctx context.Context, id uint64, strategy domain.CategoryDeleteStrategy) error
+	RestoreCategory(ctx context.Context, id uint64) (*domain.Category, error)
 }
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type DisputeRepository interface {
+	SaveDispute(ctx context.Context, dispute *domain.Dispute) (*domain.Dispute, error)
+	GetDisputeById(ctx context.Context, id uuid.UUID) (*domain.Dispute, error)
+	GetDisputeByPaymentId(ctx context.Context, paymentId string) (*domain.Dispute, error)
+	ListDisputes(ctx context.Context, status *domain.DisputeStatus) ([]*domain.Dispute, error)
+	SaveEvidence(ctx context.Context, evidence *domain.DisputeEvidence) (*domain.DisputeEvidence, error)
+}
+
+// DisputeService is an interface for interacting with chargebacks opened against orders
+type DisputeService interface {
+	RegisterChargeback(ctx context.Context, inputs domain.NewDisputeInputs) (*domain.Dispute, error)
+	GetDisputeById(ctx context.Context, id uuid.UUID) (*domain.Dispute, error)
+	ListDisputes(ctx context.Context, status *domain.DisputeStatus) ([]*domain.Dispute, error)
+	AddEvidence(ctx context.Context, id uuid.UUID, actor *domain.User, note string) (*domain.Dispute, error)
+	Resolve(ctx context.Context, id uuid.UUID, actor *domain.User, outcome domain.DisputeStatus) (*domain.Dispute, error)
+}
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GuestCartService is an interface for the carts of the visitors, a guest cart is identified by a signed cart token
+type GuestCartService interface {
+	// NewToken returns the token of a new empty guest cart
+	NewToken() string
+	GetCart(ctx context.Context, token string) (*domain.Cart, error)
+	GetCartView(ctx context.Context, token string) (*domain.CartView, error)
+	AddItemToCart(ctx context.Context, token string, productId uuid.UUID, variantId *uuid.UUID, quantity int16) error
+	RemoveItem(ctx context.Context, token string, productId uuid.UUID, variantId *uuid.UUID) error
+	Clear(ctx context.Context, token string) error
+	// Merge moves the items of the guest cart to the cart of the user when the guest logs in or registers
+	Merge(ctx context.Context, token string, userId uuid.UUID) (*domain.Cart, error)
+	// PurgeExpired deletes the guest carts expired before now, returns how many were deleted
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,49 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InventoryRepository stores the stock ledger and the allocations of the orders to the warehouses. The stock of new or
+// updated products and variants is recorded by ProductRepository in the same transaction as the stock change
+type InventoryRepository interface {
+	// SaveMovement applies the delta of the movement to the stock and appends it to the ledger in a transaction
+	SaveMovement(ctx context.Context, movement *domain.StockMovement) (*domain.StockMovement, error)
+	ListMovements(ctx context.Context, productID uuid.UUID) ([]domain.StockMovement, error)
+	// StockAt sums the ledger of the product and its variants up to at
+	StockAt(ctx context.Context, productID uuid.UUID, at time.Time) ([]domain.StockLevel, error)
+	// AuditStock compares the stock of every product and variant, in total and by warehouse, with the sum of its ledger
+	AuditStock(ctx context.Context) ([]domain.StockDiscrepancy, error)
+
+	// ReserveAllocations takes the stock of the allocations from their warehouses in a transaction
+	ReserveAllocations(ctx context.Context, allocations []domain.StockAllocation) ([]domain.StockAllocation, error)
+	AssignAllocations(ctx context.Context, ids []uuid.UUID, orderID uuid.UUID) error
+	ListOrderAllocations(ctx context.Context, orderID uuid.UUID) ([]domain.StockAllocation, error)
+	// CloseAllocations releases or sells the reserved allocations, the rest are ignored
+	CloseAllocations(ctx context.Context, allocations []domain.StockAllocation, status domain.StockAllocationStatus) error
+}
+
+type InventoryService interface {
+	// RecordMovement records a receipt, adjustment or return made by a seller
+	RecordMovement(ctx context.Context, productID uuid.UUID, inputs ports_dtos.StockMovementInputs) (*domain.StockMovement, error)
+	ListMovements(ctx context.Context, productID uuid.UUID) ([]domain.StockMovement, error)
+	StockAt(ctx context.Context, productID uuid.UUID, at time.Time) (*domain.StockReport, error)
+	AuditStock(ctx context.Context) ([]domain.StockDiscrepancy, error)
+	// RecordSale records the sale of the items of a paid order, their stock was taken when the order was reserved
+	RecordSale(ctx context.Context, order *domain.Order) error
+
+	// ReserveItems allocates the items between the warehouses by the rule and reserves their stock
+	ReserveItems(ctx context.Context, items []domain.CartItem, rule domain.AllocationRule, shipTo *domain.GeoPoint) ([]domain.StockAllocation, error)
+	// AssignOrder links the allocations reserved at checkout with the order created
+	AssignOrder(ctx context.Context, allocations []domain.StockAllocation, orderID uuid.UUID) error
+	// ReleaseAllocations returns the stock of allocations without order, e.g. the order couldn't be created
+	ReleaseAllocations(ctx context.Context, allocations []domain.StockAllocation) error
+	// ReleaseOrder returns the stock reserved by a cancelled order to its warehouses
+	ReleaseOrder(ctx context.Context, order *domain.Order) error
+	ListOrderAllocations(ctx context.Context, orderID uuid.UUID) ([]domain.StockAllocation, error)
+}
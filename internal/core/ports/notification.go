@@ -0,0 +1,28 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"time"
+)
+
+// Notifier is an interface for sending notifications to the users
+type Notifier interface {
+	Notify(ctx context.Context, notification domain.Notification) error
+}
+
+type NotificationRepository interface {
+	Enqueue(ctx context.Context, notification *domain.QueuedNotification) (*domain.QueuedNotification, error)
+	// ClaimDue takes the pending notifications due at now and delays them by lease, so concurrent dispatchers don't
+	// send them twice. A notification whose dispatcher died is retried after the lease
+	ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*domain.QueuedNotification, error)
+	SaveAttempt(ctx context.Context, notification *domain.QueuedNotification) error
+}
+
+// NotificationService queues the notifications, Notify returns once the notification is queued and Dispatch sends
+// them with the delivery notifier
+type NotificationService interface {
+	Notifier
+	// Dispatch sends the queued notifications due at now, failures are retried later. Returns the number sent
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"go-ecommerce/internal/core/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,6 +12,9 @@ type OrderRepository interface {
 	SaveOrder(ctx context.Context, order *domain.Order) (*domain.Order, error)
 	GetOrderById(ctx context.Context, id uuid.UUID) (*domain.Order, error)
 	ListOrders(ctx context.Context) ([]*domain.Order, error)
+	ListOrdersByStatus(ctx context.Context, statuses []domain.PayStatus, createdAfter time.Time) ([]*domain.Order, error)
+	// DeleteOrder removes an order and its items in a transaction, e.g. the order couldn't be completed at checkout
+	DeleteOrder(ctx context.Context, id uuid.UUID) error
 }
 
 // SaveOrderInputs is the input struct for saving or updating an order
@@ -22,6 +26,9 @@ type SaveOrderInputs struct {
 	PaymentID         *string
 	PayStatus         *domain.PayStatus
 	PayStatusDetail   *domain.PayStatusDetail
+	// Allocation and ShipTo choose the warehouses of a new order, most stock when the rule is empty
+	Allocation domain.AllocationRule
+	ShipTo     *domain.GeoPoint
 }
 
 // OrderService is an interface for interacting with order-related business logic
@@ -29,4 +36,8 @@ type OrderService interface {
 	SaveOrder(ctx context.Context, inputs SaveOrderInputs) (*domain.Order, error)
 	GetOrderById(ctx context.Context, id uuid.UUID) (*domain.Order, error)
 	ListOrders(ctx context.Context) ([]*domain.Order, error)
+	CancelOrder(ctx context.Context, id uuid.UUID, actor *domain.User, reason string) (*domain.Order, error)
+	// ShipOrder records the shipment of a paid order and notifies the buyer
+	ShipOrder(ctx context.Context, id uuid.UUID, actor *domain.User, inputs domain.ShipOrderInputs) (*domain.Order, error)
+	ListPaymentAttempts(ctx context.Context, id uuid.UUID) ([]*domain.PaymentAttempt, error)
 }
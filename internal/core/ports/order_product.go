@@ -14,6 +14,7 @@ type OrderProductRepository interface {
 }
 
 type OrderProductService interface {
-	AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, quantity int16) (*domain.OrderProduct, error)
+	// AddProductToOrder records the line with the price and discount of a unit when the order is created
+	AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, variantID *uuid.UUID, quantity int16, unitPrice, unitDiscount float64) (*domain.OrderProduct, error)
 	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*domain.OrderProduct, error)
 }
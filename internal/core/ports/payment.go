@@ -4,6 +4,7 @@ import (
 	"context"
 	"go-ecommerce/internal/adapters/mercadopago/mp_dtos"
 	"go-ecommerce/internal/core/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,10 +12,18 @@ import (
 type PaymentService interface {
 	StartPayment(ctx context.Context, orderId uuid.UUID) (*string, error)
 	VerifyPayment(ctx context.Context, paymentId, topic *string) error
+	Capture(ctx context.Context, orderId uuid.UUID) (*domain.Order, error)
+	AlertExpiringAuthorizations(ctx context.Context, window time.Duration) (int, error)
+	Reconcile(ctx context.Context, maxAge time.Duration) (*domain.ReconciliationReport, error)
 }
 
 type PaymentProvider interface {
 	GeneratePreference(ctx context.Context, order *domain.Order, items []mp_dtos.MpItem, user *domain.User) *mp_dtos.MpPreferenceRequest
 	GenerateNewPayment(ctx context.Context, preference *mp_dtos.MpPreferenceRequest) (*string, error)
 	VerifyPayment(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error)
+	CancelPayment(ctx context.Context, paymentId string) error
+	CapturePayment(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error)
+	GetChargeback(ctx context.Context, chargebackId string) (*mp_dtos.MpChargeback, error)
+	GetPayment(ctx context.Context, paymentId string) (*mp_dtos.MpSimplifiedPayment, error)
+	SearchPayments(ctx context.Context, externalReference string) ([]*mp_dtos.MpSimplifiedPayment, error)
 }
@@ -0,0 +1,13 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type PaymentAttemptRepository interface {
+	SaveAttempt(ctx context.Context, attempt *domain.PaymentAttempt) (*domain.PaymentAttempt, error)
+	ListAttemptsByOrder(ctx context.Context, orderId uuid.UUID) ([]*domain.PaymentAttempt, error)
+}
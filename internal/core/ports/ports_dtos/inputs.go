@@ -1,6 +1,10 @@
 package ports_dtos
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 // ProductService is an interface for interacting with product-related business logic
 type SaveProductInputs struct {
@@ -11,4 +15,81 @@ type SaveProductInputs struct {
 	Price      *float64
 	Stock      *int64
 	CategoryID *uint64
+
+	Discount     *float64
+	DiscountType *string    // percentage or fixed
+	UpdatedBy    *uuid.UUID // user that performs the change, recorded in the price history
+
+	LowStockThreshold *int64 // 0 disables the low stock alert
+}
+
+// SchedulePriceInputs schedules a change of the price or discount of a product. With EndsAt it's a time-boxed sale
+// and the previous values are restored when it ends
+type SchedulePriceInputs struct {
+	Price        *float64
+	Discount     *float64
+	DiscountType *string
+	StartsAt     time.Time
+	EndsAt       *time.Time
+	CreatedBy    *uuid.UUID
+}
+
+// ReportFilters are the filters shared by all sales reports
+type ReportFilters struct {
+	From time.Time
+	To   time.Time
+}
+
+type ProductSort string
+
+const (
+	SortNewest    ProductSort = "newest"
+	SortPriceAsc  ProductSort = "price_asc"
+	SortPriceDesc ProductSort = "price_desc"
+	SortNameAsc   ProductSort = "name_asc"
+	SortRelevance ProductSort = "relevance" // only valid when Q is set
+)
+
+// ProductQuery is the spec used to search products in the storefront, nil filters are not applied
+type ProductQuery struct {
+	Q          string
+	CategoryID *uint64
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    *bool
+	Sort       ProductSort
+	Cursor     string // opaque cursor returned by the previous page
+	Limit      int
+}
+
+// SaveVariantInputs are the inputs to create or update a variant of a product, nil fields are not updated
+type SaveVariantInputs struct {
+	ID      uuid.UUID
+	SKU     *string
+	Options map[string]string
+	Price   *float64 // overrides the price of the product
+	Stock   *int64
+
+	UpdatedBy *uuid.UUID
+}
+
+// StockMovementInputs are the inputs of a movement recorded manually in the stock ledger
+type StockMovementInputs struct {
+	VariantID   *uuid.UUID // nil for the stock of the product
+	WarehouseID *uuid.UUID // nil for the default warehouse
+	Type        string
+	Quantity    int64 // signed for adjustments, the units moved for the rest
+	Reason      string
+	ActorID     *uuid.UUID
+}
+
+// SaveWarehouseInputs are the inputs to create or update a warehouse, nil fields are not updated
+type SaveWarehouseInputs struct {
+	ID        uuid.UUID
+	Code      *string
+	Name      *string
+	Latitude  *float64
+	Longitude *float64
+	IsDefault *bool
+	Active    *bool
 }
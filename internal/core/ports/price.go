@@ -0,0 +1,31 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PriceRepository stores the price history and the price schedules of the products.
+// The history of manual changes is written by ProductRepository.SaveProduct
+type PriceRepository interface {
+	ListPriceHistory(ctx context.Context, productID uuid.UUID) ([]domain.PriceChange, error)
+	SaveSchedule(ctx context.Context, schedule *domain.PriceSchedule) (*domain.PriceSchedule, error)
+	GetScheduleById(ctx context.Context, id uuid.UUID) (*domain.PriceSchedule, error)
+	ListSchedules(ctx context.Context, productID uuid.UUID) ([]*domain.PriceSchedule, error)
+	ListDueSchedules(ctx context.Context, now time.Time) ([]*domain.PriceSchedule, error)
+	// ApplySchedule saves the pricing of the product, the schedule and the change in a transaction
+	ApplySchedule(ctx context.Context, schedule *domain.PriceSchedule, product *domain.Product, change *domain.PriceChange) error
+}
+
+type PriceService interface {
+	PriceHistory(ctx context.Context, productID uuid.UUID) ([]domain.PriceChange, error)
+	SchedulePrice(ctx context.Context, productID uuid.UUID, inputs ports_dtos.SchedulePriceInputs) (*domain.PriceSchedule, error)
+	ListSchedules(ctx context.Context, productID uuid.UUID) ([]*domain.PriceSchedule, error)
+	CancelSchedule(ctx context.Context, productID, scheduleID uuid.UUID) (*domain.PriceSchedule, error)
+	// ApplyDueSchedules starts and ends the schedules due at now, it's run by a background job
+	ApplyDueSchedules(ctx context.Context, now time.Time) (int, error)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports/ports_dtos"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -12,13 +13,52 @@ import (
 type ProductRepository interface {
 	SaveProduct(ctx context.Context, product *domain.Product) (*domain.Product, error)
 	GetProductById(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*domain.Product, error)
 	ListProducts(ctx context.Context) ([]*domain.Product, error)
+	SearchProducts(ctx context.Context, query ports_dtos.ProductQuery) (*ProductPage, error)
 	DeleteProduct(ctx context.Context, id uuid.UUID) error
+
+	// DeleteProduct archives the product, archived products are only found by GetProductIncludingArchived
+	GetProductIncludingArchived(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	RestoreProduct(ctx context.Context, id uuid.UUID) error
+	ListPurgeableProducts(ctx context.Context, archivedBefore time.Time) ([]*domain.Product, error)
+	PurgeProduct(ctx context.Context, id uuid.UUID) error
+
+	// Variants are part of the product aggregate
+	SaveVariant(ctx context.Context, variant *domain.Variant) (*domain.Variant, error)
+	GetVariantById(ctx context.Context, id uuid.UUID) (*domain.Variant, error)
+	DeleteVariant(ctx context.Context, id uuid.UUID) error
+
+	// SaveGallery replaces the images of the product with product.Images
+	SaveGallery(ctx context.Context, product *domain.Product) error
+}
+
+type ProductImageService interface {
+	UploadImage(ctx context.Context, productID uuid.UUID, data []byte, contentType string) (*domain.ProductImage, error)
+	ListImages(ctx context.Context, productID uuid.UUID) ([]domain.ProductImage, error)
+	DeleteImage(ctx context.Context, productID, imageID uuid.UUID) error
+	ReorderImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]domain.ProductImage, error)
+	SetPrimaryImage(ctx context.Context, productID, imageID uuid.UUID) ([]domain.ProductImage, error)
+}
+
+// ProductPage is the envelope returned by the product search
+type ProductPage struct {
+	Items      []*domain.Product
+	Total      int64
+	NextCursor *string // nil when there are no more pages
 }
 
 type ProductService interface {
 	SaveProduct(ctx context.Context, inputs ports_dtos.SaveProductInputs) (*domain.Product, error)
 	GetProductById(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*domain.Product, error)
+	GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error)
 	ListProducts(ctx context.Context) ([]*domain.Product, error)
+	SearchProducts(ctx context.Context, query ports_dtos.ProductQuery) (*ProductPage, error)
 	DeleteProduct(ctx context.Context, id uuid.UUID) error
+	RestoreProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error)
+	SaveVariant(ctx context.Context, productID uuid.UUID, inputs ports_dtos.SaveVariantInputs) (*domain.Variant, error)
+	ListVariants(ctx context.Context, productID uuid.UUID) ([]domain.Variant, error)
+	DeleteVariant(ctx context.Context, productID, variantID uuid.UUID) error
 }
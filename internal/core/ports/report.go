@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+)
+
+// ReportRepository is an interface that contains the read-only queries used by the sales reports
+type ReportRepository interface {
+	ListPaidOrders(ctx context.Context, filters ports_dtos.ReportFilters) ([]*domain.Order, error)
+	CountOrdersByStatus(ctx context.Context, filters ports_dtos.ReportFilters) (map[domain.PayStatus]int64, error)
+	TopProducts(ctx context.Context, filters ports_dtos.ReportFilters, rankBy domain.TopProductsRanking, limit int) ([]domain.TopProduct, error)
+	ListDisputes(ctx context.Context, filters ports_dtos.ReportFilters) ([]*domain.Dispute, error)
+	ListCartRecoveries(ctx context.Context, filters ports_dtos.ReportFilters) ([]*domain.CartRecovery, error)
+}
+
+// ReportService is an interface for interacting with sales and fees analytics
+type ReportService interface {
+	Revenue(ctx context.Context, filters ports_dtos.ReportFilters, period domain.ReportPeriod) ([]domain.RevenueBucket, error)
+	FeesByPayMethod(ctx context.Context, filters ports_dtos.ReportFilters) ([]domain.FeesByPayMethod, error)
+	AverageOrderValue(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.AverageOrderValue, error)
+	TopProducts(ctx context.Context, filters ports_dtos.ReportFilters, rankBy domain.TopProductsRanking, limit int) ([]domain.TopProduct, error)
+	Conversion(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.Conversion, error)
+	Chargebacks(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.ChargebackSummary, error)
+	CartRecovery(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.CartRecoverySummary, error)
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "time"
+
+// TokenSigner is an interface for signing payloads shared with the users, e.g. the links sent by email
+type TokenSigner interface {
+	Sign(payload string, expiresAt time.Time) string
+	// Verify returns the payload of a token signed by Sign, if it wasn't tampered and it hasn't expired
+	Verify(token string, now time.Time) (string, error)
+}
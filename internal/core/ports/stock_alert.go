@@ -0,0 +1,33 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+
+	"github.com/google/uuid"
+)
+
+type StockAlertRepository interface {
+	// Subscribe is idempotent, subscribing twice keeps the first subscription
+	Subscribe(ctx context.Context, subscription *domain.StockAlertSubscription) (*domain.StockAlertSubscription, error)
+	Unsubscribe(ctx context.Context, productID, userID uuid.UUID) error
+	ListSubscriptions(ctx context.Context, productID uuid.UUID) ([]domain.StockAlertSubscription, error)
+	// ClaimSubscription removes the subscription, false if it was already removed, e.g. its alert was sent
+	ClaimSubscription(ctx context.Context, id uuid.UUID) (bool, error)
+
+	// MarkLowStock sets the low stock alert of the product, false if it was already set
+	MarkLowStock(ctx context.Context, productID uuid.UUID) (bool, error)
+	ClearLowStock(ctx context.Context, productID uuid.UUID) error
+	// ListProductsToCheck returns the products whose low stock alert or back in stock alerts are pending
+	ListProductsToCheck(ctx context.Context) ([]uuid.UUID, error)
+}
+
+type StockAlertService interface {
+	// Subscribe asks for a back in stock alert of a product out of stock
+	Subscribe(ctx context.Context, productID uuid.UUID, userID uuid.UUID) (*domain.StockAlertSubscription, error)
+	Unsubscribe(ctx context.Context, productID uuid.UUID, userID uuid.UUID) error
+	// CheckStock sends the alerts of the product after its stock changed
+	CheckStock(ctx context.Context, productID uuid.UUID) error
+	// CheckAllStock sends the pending alerts of every product, it catches the stock changed outside the inventory
+	CheckAllStock(ctx context.Context) error
+}
@@ -3,6 +3,7 @@ package ports
 import (
 	"context"
 	"go-ecommerce/internal/core/domain"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -13,7 +14,10 @@ type UserRepository interface {
 	GetUserByID(ctx context.Context, id uuid.UUID) (*domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	ListUsers(ctx context.Context, skip, limit uint64) ([]*domain.User, error)
+	ListUsersByRole(ctx context.Context, roles ...domain.UserRole) ([]*domain.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) error
+	PurgeUsers(ctx context.Context, archivedBefore time.Time) (int64, error)
 }
 
 
@@ -24,4 +28,5 @@ type UserService interface {
 	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 	ListUsers(ctx context.Context, skip, limit uint64) ([]*domain.User, error)
 	DeleteUser(ctx context.Context, id uuid.UUID) error
+	RestoreUser(ctx context.Context, id uuid.UUID) (*domain.User, error)
 }
@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+
+	"github.com/google/uuid"
+)
+
+type WarehouseRepository interface {
+	// SaveWarehouse unsets the previous default warehouse when the warehouse is the new default
+	SaveWarehouse(ctx context.Context, warehouse *domain.Warehouse) (*domain.Warehouse, error)
+	GetWarehouseById(ctx context.Context, id uuid.UUID) (*domain.Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]domain.Warehouse, error)
+	// ListStockLevels returns the stock in every warehouse of the products and their variants
+	ListStockLevels(ctx context.Context, productIDs []uuid.UUID) ([]domain.WarehouseStock, error)
+}
+
+type WarehouseService interface {
+	SaveWarehouse(ctx context.Context, inputs ports_dtos.SaveWarehouseInputs) (*domain.Warehouse, error)
+	GetWarehouseById(ctx context.Context, id uuid.UUID) (*domain.Warehouse, error)
+	ListWarehouses(ctx context.Context) ([]domain.Warehouse, error)
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"time"
+)
+
+type ArchiveService struct {
+	productRepo  ports.ProductRepository
+	categoryRepo ports.CategoryRepository
+	userRepo     ports.UserRepository
+	storage      ports.BlobStorage
+}
+
+func NewArchiveService(
+	productRepo ports.ProductRepository,
+	categoryRepo ports.CategoryRepository,
+	userRepo ports.UserRepository,
+	storage ports.BlobStorage,
+) ports.ArchiveService {
+	return &ArchiveService{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		userRepo:     userRepo,
+		storage:      storage,
+	}
+}
+
+// Purge implements ports.ArchiveService.
+// Products go first, a category can't be removed while an archived product still points to it
+func (as *ArchiveService) Purge(ctx context.Context, retention time.Duration) (*domain.PurgeReport, error) {
+	if retention <= 0 {
+		retention = domain.DefaultArchiveRetention
+	}
+	report := domain.NewPurgeReport(time.Now())
+	archivedBefore := report.StartedAt.Add(-retention)
+
+	products, err := as.productRepo.ListPurgeableProducts(ctx, archivedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, product := range products {
+		if err := as.productRepo.PurgeProduct(ctx, product.ID); err != nil {
+			return nil, err
+		}
+		report.Products++
+
+		// the rows are gone, a file that couldn't be removed is only reported
+		for _, image := range product.Images {
+			for _, key := range []string{image.Key, image.ThumbnailKey} {
+				if err := as.storage.Delete(ctx, key); err != nil {
+					slog.Warn("error deleting image of purged product", "product_id", product.ID, "key", key, "error", err)
+					report.Errors = append(report.Errors, key+": "+err.Error())
+				}
+			}
+		}
+	}
+
+	categories, err := as.categoryRepo.PurgeCategories(ctx, archivedBefore)
+	if err != nil {
+		return nil, err
+	}
+	report.Categories = int(categories)
+
+	users, err := as.userRepo.PurgeUsers(ctx, archivedBefore)
+	if err != nil {
+		return nil, err
+	}
+	report.Users = int(users)
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
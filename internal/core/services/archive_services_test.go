@@ -0,0 +1,113 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/imaging"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ArchiveService_RestoreAndPurge(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	storage := mocks.NewMockBlobStorage()
+	prodRepo := repository.NewProductRepo(tx)
+	catRepo := repository.NewCategoryRepo(tx)
+	userRepo := repository.NewUserRepo(tx)
+	orderProdSrv := services.NewOrderProductService(repository.NewOrderProductRepo(tx))
+	orderRepo := repository.NewOrderRepo(orderProdSrv, tx)
+
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(catRepo, redis)
+	imageSrv := services.NewProductImageService(prodRepo, storage, imaging.NewThumbnailer(), redis)
+	archiveSrv := services.NewArchiveService(prodRepo, catRepo, userRepo, storage)
+
+	category, err := categSrv.SaveCategory(ctx, 0, "Consoles", nil)
+	require.NoError(t, err)
+	ordered, err := prodSrv.SaveProduct(ctx, testhelpers.NewDomainProduct("Playstation 5", category.ID).ToInputs())
+	require.NoError(t, err)
+	unsold, err := prodSrv.SaveProduct(ctx, testhelpers.NewDomainProduct("Xbox Series X", category.ID).ToInputs())
+	require.NoError(t, err)
+	_, err = imageSrv.UploadImage(ctx, unsold.ID, newPNG(t, 10, 10), "image/png")
+	require.NoError(t, err)
+
+	buyer, err := userRepo.SaveUser(ctx, testhelpers.NewDomainUser("Buyer", "buyer@test.com"))
+	require.NoError(t, err)
+	visitor, err := userRepo.SaveUser(ctx, testhelpers.NewDomainUser("Visitor", "visitor@test.com"))
+	require.NoError(t, err)
+
+	order, err := orderRepo.SaveOrder(ctx, testhelpers.NewDomainOrder(buyer.ID))
+	require.NoError(t, err)
+	_, err = orderProdSrv.AddProductToOrder(ctx, order.ID, ordered.ID, nil, 1, ordered.Price, 0)
+	require.NoError(t, err)
+
+	// archive everything, the category has no active products left
+	require.NoError(t, prodSrv.DeleteProduct(ctx, ordered.ID))
+	require.NoError(t, prodSrv.DeleteProduct(ctx, unsold.ID))
+	require.NoError(t, categSrv.DeleteCategory(ctx, category.ID, domain.RestrictDelete))
+	require.NoError(t, userRepo.DeleteUser(ctx, buyer.ID))
+	require.NoError(t, userRepo.DeleteUser(ctx, visitor.ID))
+
+	_, err = prodSrv.GetProductById(ctx, unsold.ID)
+	assert.Equal(t, domain.ErrProductNotFound, err)
+
+	// archived products keep resolving for the orders
+	archived, err := prodRepo.GetProductIncludingArchived(ctx, ordered.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, archived.DeletedAt)
+
+	// a product can't be restored into an archived category
+	_, err = prodSrv.RestoreProduct(ctx, unsold.ID)
+	assert.Equal(t, domain.ErrProductCategoryArchived, err)
+
+	restoredCategory, err := categSrv.RestoreCategory(ctx, category.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restoredCategory.DeletedAt)
+
+	restored, err := prodSrv.RestoreProduct(ctx, unsold.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Len(t, restored.Images, 1)
+
+	_, err = prodSrv.RestoreProduct(ctx, unsold.ID)
+	assert.Equal(t, domain.ErrProductNotArchived, err)
+
+	// archive again, nothing is purged inside the retention
+	require.NoError(t, prodSrv.DeleteProduct(ctx, unsold.ID))
+	require.NoError(t, categSrv.DeleteCategory(ctx, category.ID, domain.RestrictDelete))
+
+	report, err := archiveSrv.Purge(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Zero(t, report.Products+report.Categories+report.Users)
+
+	for _, table := range []string{"product_models", "category_models", "user_models"} {
+		require.NoError(t, tx.Exec("UPDATE "+table+" SET deleted_at = ? WHERE deleted_at IS NOT NULL", time.Now().Add(-2*time.Hour)).Error)
+	}
+
+	// the ordered product, its category and the buyer are kept
+	report, err = archiveSrv.Purge(ctx, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Products)
+	assert.Equal(t, 0, report.Categories)
+	assert.Equal(t, 1, report.Users)
+	assert.Empty(t, storage.Blobs)
+
+	_, err = prodRepo.GetProductIncludingArchived(ctx, unsold.ID)
+	assert.Equal(t, domain.ErrProductNotFound, err)
+	_, err = prodRepo.GetProductIncludingArchived(ctx, ordered.ID)
+	require.NoError(t, err)
+	assert.NoError(t, userRepo.RestoreUser(ctx, buyer.ID))
+	assert.Equal(t, domain.ErrUserNotFound, userRepo.RestoreUser(ctx, visitor.ID))
+}
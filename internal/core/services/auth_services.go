@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthService issues access tokens signed with the id of the user, they expire after tokenTTL
+type AuthService struct {
+	repo     ports.UserRepository
+	users    ports.UserService
+	hasher   domain.PasswordHasher
+	signer   ports.TokenSigner
+	tokenTTL time.Duration
+}
+
+func NewAuthService(repo ports.UserRepository, users ports.UserService, hasher domain.PasswordHasher, signer ports.TokenSigner, tokenTTL time.Duration) ports.AuthService {
+	return &AuthService{
+		repo:     repo,
+		users:    users,
+		hasher:   hasher,
+		signer:   signer,
+		tokenTTL: tokenTTL,
+	}
+}
+
+// Login implements ports.AuthService.
+// An unknown email and a wrong password return the same error
+func (as *AuthService) Login(ctx context.Context, email, password string) (string, *domain.User, error) {
+	user, err := as.repo.GetUserByEmail(ctx, email)
+	if err == domain.ErrUserNotFound {
+		return "", nil, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := as.hasher.ComparePassword(password, user.Password); err != nil {
+		return "", nil, domain.ErrInvalidCredentials
+	}
+
+	token := as.signer.Sign(user.ID.String(), time.Now().Add(as.tokenTTL))
+	return token, user, nil
+}
+
+// Authenticate implements ports.AuthService.
+// The user is loaded on every request, an archived user can't use the tokens issued before
+func (as *AuthService) Authenticate(ctx context.Context, token string) (*domain.User, error) {
+	payload, err := as.signer.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := uuid.Parse(payload)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	user, err := as.users.GetUserByID(ctx, userId)
+	if err == domain.ErrUserNotFound || (err == nil && user == nil) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,77 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/security"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Auth_Login(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	hasher := &security.Hasher{}
+
+	// services
+	userRepo := repository.NewUserRepo(tx)
+	userSrv := services.NewUserService(userRepo, redis, hasher, &mocks.MockNotifier{})
+	authSrv := services.NewAuthService(userRepo, userSrv, hasher, security.NewSigner("secret"), time.Hour)
+
+	// factory user
+	u := testhelpers.NewDomainUser("John", "john@mail.test")
+	password := u.Password
+	newUser, err := userSrv.SaveUser(ctx, domain.SaveUserInputs{
+		Name:     &u.Name,
+		Email:    &u.Email,
+		Password: &password,
+		Role:     &u.Role,
+	})
+	require.NoError(t, err)
+
+	t.Run("the token of the login authenticates the user", func(t *testing.T) {
+		token, user, err := authSrv.Login(ctx, u.Email, password)
+		require.NoError(t, err)
+		assert.Equal(t, newUser.ID, user.ID)
+
+		authenticated, err := authSrv.Authenticate(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, newUser.ID, authenticated.ID)
+		assert.Equal(t, newUser.Role, authenticated.Role)
+	})
+
+	t.Run("a wrong password or an unknown email is rejected", func(t *testing.T) {
+		_, _, err := authSrv.Login(ctx, u.Email, password+"x")
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+
+		_, _, err = authSrv.Login(ctx, "nobody@mail.test", password)
+		assert.ErrorIs(t, err, domain.ErrInvalidCredentials)
+	})
+
+	t.Run("a token that isn't signed with the secret is rejected", func(t *testing.T) {
+		forged := security.NewSigner("other").Sign(newUser.ID.String(), time.Now().Add(time.Hour))
+		_, err := authSrv.Authenticate(ctx, forged)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+		// the id of the user alone isn't a credential
+		_, err = authSrv.Authenticate(ctx, newUser.ID.String())
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("an expired token is rejected", func(t *testing.T) {
+		expired := security.NewSigner("secret").Sign(newUser.ID.String(), time.Now().Add(-time.Minute))
+		_, err := authSrv.Authenticate(ctx, expired)
+		assert.ErrorIs(t, err, domain.ErrTokenExpired)
+	})
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CartRecoveryService struct {
+	repo     ports.CartRecoveryRepository
+	cart     ports.CartService
+	notifier ports.Notifier
+	signer   ports.TokenSigner
+
+	// a cart without changes for idleAfter is reminded, the link of the reminder expires after linkTTL
+	idleAfter time.Duration
+	linkTTL   time.Duration
+	linkURL   string
+}
+
+// NewCartRecoveryService creates the reminders of the idle carts, the link of the reminder is linkURL with the signed
+// token in the query
+func NewCartRecoveryService(repo ports.CartRecoveryRepository, cart ports.CartService, notifier ports.Notifier, signer ports.TokenSigner, idleAfter, linkTTL time.Duration, linkURL string) ports.CartRecoveryService {
+	return &CartRecoveryService{
+		repo:      repo,
+		cart:      cart,
+		notifier:  notifier,
+		signer:    signer,
+		idleAfter: idleAfter,
+		linkTTL:   linkTTL,
+		linkURL:   linkURL,
+	}
+}
+
+// SendReminders implements ports.CartRecoveryService.
+// A cart is reminded once per idle period, a change of its items starts a new one. Every cart is tried even if one
+// of them fails, the first error is returned
+func (cs *CartRecoveryService) SendReminders(ctx context.Context, now time.Time) (int, error) {
+	carts, err := cs.cart.ListCarts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var firstErr error
+	for _, cart := range carts {
+		if !cart.IsIdle(now, cs.idleAfter) {
+			continue
+		}
+
+		reminded, err := cs.remind(ctx, cart, now)
+		if err != nil {
+			slog.Error("error reminding cart", "user_id", cart.UserID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if reminded {
+			sent++
+		}
+	}
+
+	return sent, firstErr
+}
+
+// helper func, returns false if the idle period of the cart was already reminded
+func (cs *CartRecoveryService) remind(ctx context.Context, cart *domain.Cart, now time.Time) (bool, error) {
+	recovery := domain.NewCartRecovery(cart, now)
+	created, err := cs.repo.CreateRecovery(ctx, recovery)
+	if err != nil || !created {
+		return false, err
+	}
+
+	var items int
+	for _, item := range recovery.Items {
+		items += int(item.Quantity)
+	}
+
+	err = cs.notifier.Notify(ctx, domain.Notification{
+		Kind:   domain.NotificationCartReminder,
+		UserID: cart.UserID,
+		Data: map[string]any{
+			"link":  cs.link(recovery.ID, now),
+			"items": items,
+		},
+	})
+	return err == nil, err
+}
+
+// helper func
+func (cs *CartRecoveryService) link(recoveryID uuid.UUID, now time.Time) string {
+	token := cs.signer.Sign(recoveryID.String(), now.Add(cs.linkTTL))
+	return cs.linkURL + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// Restore implements ports.CartRecoveryService.
+// The items are only put back when the cart is empty, a cart that the user kept using isn't overwritten. Items that
+// can't be added anymore, e.g. a deleted product, are skipped
+func (cs *CartRecoveryService) Restore(ctx context.Context, token string) (*domain.Cart, error) {
+	payload, err := cs.signer.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	recoveryID, err := uuid.Parse(payload)
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	recovery, err := cs.repo.GetRecoveryById(ctx, recoveryID)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := cs.cart.GetCart(ctx, recovery.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cart.Items) == 0 {
+		for _, item := range recovery.Items {
+			if err := cs.cart.AddItemToCart(ctx, recovery.UserID, item.ProductID, item.VariantID, item.Quantity); err != nil {
+				slog.Warn("error restoring item of cart", "recovery_id", recovery.ID, "product_id", item.ProductID, "error", err)
+			}
+		}
+
+		cart, err = cs.cart.GetCart(ctx, recovery.UserID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cs.repo.MarkRestored(ctx, recovery.ID, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return cart, nil
+}
+
+// MarkRecovered implements ports.CartRecoveryService.
+func (cs *CartRecoveryService) MarkRecovered(ctx context.Context, order *domain.Order) error {
+	_, err := cs.repo.MarkRecovered(ctx, order, order.CreatedAt.Add(-domain.CartRecoveryAttribution))
+	return err
+}
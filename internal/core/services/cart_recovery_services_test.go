@@ -0,0 +1,130 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CartRecoveryServices(t *testing.T) {
+	srv := newOrderSrvTest(t)
+	ctx := context.Background()
+
+	// factory user
+	u := testhelpers.NewDomainUser("John", "john@mail.test")
+	newUser, err := srv.userSrv.SaveUser(ctx, domain.SaveUserInputs{
+		Name:     &u.Name,
+		Email:    &u.Email,
+		Password: &u.Password,
+		Role:     &u.Role,
+	})
+	require.NoError(t, err)
+
+	// factory, create a new category and product
+	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, testhelpers.NewDomainCategory("Tablets").Name, nil)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Ipad 14 pro", savedCateg.ID)
+	newProd, err := srv.productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+		Name:       &p.Name,
+		Image:      &p.Image,
+		SKU:        &p.SKU,
+		Price:      &p.Price,
+		Stock:      &p.Stock,
+		CategoryID: &savedCateg.ID,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5))
+
+	var token string
+
+	t.Run("a recent cart isn't reminded", func(t *testing.T) {
+		sent, err := srv.recovery.SendReminders(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("an idle cart is reminded once with a signed link", func(t *testing.T) {
+		later := time.Now().Add(2 * time.Hour)
+
+		sent, err := srv.recovery.SendReminders(ctx, later)
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		// the same idle period isn't reminded again
+		sent, err = srv.recovery.SendReminders(ctx, later.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		reminders := srv.notifier.Sent(domain.NotificationCartReminder)
+		require.Len(t, reminders, 1)
+		assert.Equal(t, newUser.ID, reminders[0].UserID)
+		assert.Equal(t, 5, reminders[0].Data["items"])
+
+		link, err := url.Parse(reminders[0].Data["link"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, "/cart/recover", link.Path)
+		token = link.Query().Get("token")
+		require.NotEmpty(t, token)
+	})
+
+	t.Run("the link restores the items of an emptied cart", func(t *testing.T) {
+		require.NoError(t, srv.cartSrv.Clear(ctx, newUser.ID))
+
+		cart, err := srv.recovery.Restore(ctx, token)
+		require.NoError(t, err)
+		require.Len(t, cart.Items, 1)
+		assert.Equal(t, newProd.ID, cart.Items[0].ProductID)
+		assert.Equal(t, int16(5), cart.Items[0].Quantity)
+
+		// a cart with items isn't overwritten
+		cart, err = srv.recovery.Restore(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, int16(5), cart.Items[0].Quantity)
+	})
+
+	t.Run("a tampered token is rejected", func(t *testing.T) {
+		_, err := srv.recovery.Restore(ctx, token+"x")
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+		_, err = srv.recovery.Restore(ctx, "not-a-token")
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("an order after the reminder is counted as recovered", func(t *testing.T) {
+		// the restored cart started a new idle period
+		sent, err := srv.recovery.SendReminders(ctx, time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		o := testhelpers.NewDomainOrder(newUser.ID)
+		order, err := srv.orderSrv.SaveOrder(ctx, ports.SaveOrderInputs{
+			UserID:            o.UserID,
+			Currency:          o.Currency,
+			ExternalReference: o.ExternalReference,
+			PaymentID:         o.PaymentID,
+			PayStatus:         &o.PayStatus,
+			PayStatusDetail:   o.PayStatusDetail,
+		})
+		require.NoError(t, err)
+
+		filters := ports_dtos.ReportFilters{From: time.Now().Add(-time.Hour), To: time.Now().Add(4 * time.Hour)}
+		summary, err := srv.reportSrv.CartRecovery(ctx, filters)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), summary.Reminded)
+		assert.Equal(t, int64(1), summary.Restored)
+		// only the last reminder is attributed the order
+		assert.Equal(t, int64(1), summary.Recovered)
+		assert.InDelta(t, order.Total, summary.RecoveredRevenue, 0.001)
+		assert.InDelta(t, 0.5, summary.Rate, 0.001)
+	})
+}
@@ -9,78 +9,161 @@ import (
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// CartService handles the carts of the users, they're saved in the database and written through to the cache
 type CartService struct {
+	repo  ports.CartRepository
 	ps    ports.ProductService
 	cache ports.CacheRepository
 }
 
-func NewCartService(cache ports.CacheRepository, ps ports.ProductService) ports.CartService {
-	return &CartService{cache: cache, ps: ps}
+func NewCartService(repo ports.CartRepository, cache ports.CacheRepository, ps ports.ProductService) ports.CartService {
+	return &CartService{repo: repo, cache: cache, ps: ps}
 }
 
-// helper func
-func (c *CartService) loadCart(ctx context.Context, userId uuid.UUID) *domain.Cart {
-	cacheKey := cachekeys.Cart(userId.String())
+// helper func, the cart is read from the cache, the database is used when it isn't cached or can't be deserialized
+func (c *CartService) loadCart(ctx context.Context, userId uuid.UUID) (*domain.Cart, error) {
+	key := cachekeys.Cart(userId.String())
+	if cart := c.cachedCart(ctx, key); cart != nil {
+		return cart, nil
+	}
 
-	data, err := c.cache.Get(ctx, cacheKey)
+	cart, err := c.repo.GetCart(ctx, userId)
 	if err != nil {
-		slog.Warn("error obtaining cart in cache", "user_id", userId, "error", err)
+		return nil, err
 	}
+	c.cacheCart(ctx, key, cachettl.Cart, cart)
+	return cart, nil
+}
 
-	// if cart doesn't exist, create one and return
-	if len(data) == 0 {
-		return domain.NewCart(userId)
+// helper func, returns nil if the cart isn't cached
+func (c *CartService) cachedCart(ctx context.Context, key string) *domain.Cart {
+	data, err := c.cache.Get(ctx, key)
+	if err != nil || len(data) == 0 {
+		return nil
 	}
 
 	var cart domain.Cart
-	err = json.Unmarshal(data, &cart)
+	if err := json.Unmarshal(data, &cart); err != nil {
+		slog.Warn("error deserializing cached cart", "key", key, "error", err)
+		return nil
+	}
+	return &cart
+}
+
+// helper func, writes the saved cart to the cache. A cart older than the cached one isn't written, e.g. when two
+// requests save the cart in one order and write the cache in the other. If the cart can't be written the cached one
+// is deleted, a stale cart would be read until it expires
+func (c *CartService) cacheCart(ctx context.Context, key string, ttl time.Duration, cart *domain.Cart) {
+	err := c.cache.Update(ctx, key, ttl, func(current []byte) ([]byte, error) {
+		var cached domain.Cart
+		if len(current) > 0 && json.Unmarshal(current, &cached) == nil && cached.UpdatedAt.After(cart.UpdatedAt) {
+			return current, nil
+		}
+		return json.Marshal(cart)
+	})
+	if err == nil {
+		return
+	}
+
+	slog.Warn("error caching cart", "key", key, "error", err)
+	if err := c.cache.Delete(ctx, key); err != nil {
+		slog.Error("error deleting stale cached cart", "key", key, "error", err)
+	}
+}
+
+// helper func, applies the change to the cart of the user in the database and writes it to the cache
+func (c *CartService) updateCart(ctx context.Context, userId uuid.UUID, change func(cart *domain.Cart) error) (*domain.Cart, error) {
+	cart, err := c.repo.UpdateCart(ctx, userId, func(cart *domain.Cart) error {
+		if err := change(cart); err != nil {
+			return err
+		}
+		cart.Touch(time.Now())
+		return nil
+	})
 	if err != nil {
-		slog.Warn("error deserializing items of cart", "error", err)
-		domain.NewCart(userId)
+		return nil, err
 	}
 
-	// return cart with values
-	return &cart
+	c.cacheCart(ctx, cachekeys.Cart(userId.String()), cachettl.Cart, cart)
+	return cart, nil
 }
 
-// helper func
-func (c *CartService) saveCart(ctx context.Context, cart *domain.Cart) error {
-	cacheKey := cachekeys.Cart(cart.UserID.String())
+// helper func, validates that the product exists and isn't archived and that the variant belongs to it. Returns the
+// change that adds the item, the quantity of the line is validated against the stock when it's applied
+func (c *CartService) addItemChange(ctx context.Context, productId uuid.UUID, variantId *uuid.UUID, quantity int16) (func(cart *domain.Cart) error, error) {
+	product, err := c.ps.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
 
-	// serialized data before caching
-	data, err := json.Marshal(cart)
+	variant, err := product.ResolveVariant(variantId)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// If cart hasn't items, delete it
-	if len(cart.Items) <= 0 {
-		err := c.cache.Delete(ctx, cacheKey)
-		return err
+	unitPrice := product.UnitPrice(variant)
+	finalPrice := unitPrice - product.UnitDiscount(unitPrice)
+	stock := product.AvailableStock(variant)
+
+	return func(cart *domain.Cart) error {
+		return cart.AddItem(productId, variantId, quantity, finalPrice, stock)
+	}, nil
+}
+
+// helper func, prices the lines of the cart. The lines of products or variants that don't exist anymore are
+// returned as unavailable
+func (c *CartService) buildCartView(ctx context.Context, cart *domain.Cart) (*domain.CartView, error) {
+	lines := make([]domain.CartLine, 0, len(cart.Items))
+	for _, item := range cart.Items {
+		product, err := c.ps.GetProductById(ctx, item.ProductID)
+		if err == domain.ErrProductNotFound {
+			lines = append(lines, domain.UnavailableCartLine(item))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		variant, err := product.ResolveVariant(item.VariantID)
+		if err != nil {
+			lines = append(lines, domain.UnavailableCartLine(item))
+			continue
+		}
+
+		lines = append(lines, domain.NewCartLine(item, product, variant))
 	}
 
-	// set cache with new values of cart
-	return c.cache.Set(ctx, cacheKey, data, cachettl.Cart)
+	return domain.NewCartView(cart, lines), nil
 }
 
 // AddItemToCart implements ports.CartService.
-func (c *CartService) AddItemToCart(ctx context.Context, userId, productId uuid.UUID, quantity int16) error {
-	cart := c.loadCart(ctx, userId)
-	err := cart.AddItem(productId, quantity)
+func (c *CartService) AddItemToCart(ctx context.Context, userId, productId uuid.UUID, variantId *uuid.UUID, quantity int16) error {
+	change, err := c.addItemChange(ctx, productId, variantId, quantity)
 	if err != nil {
 		return err
 	}
-	return c.saveCart(ctx, cart)
+
+	_, err = c.updateCart(ctx, userId, change)
+	return err
 }
 
 // GetCart implements ports.CartService.
 func (c *CartService) GetCart(ctx context.Context, userId uuid.UUID) (*domain.Cart, error) {
-	cart := c.loadCart(ctx, userId)
-	return cart, nil
+	return c.loadCart(ctx, userId)
+}
+
+// GetCartView implements ports.CartService.
+func (c *CartService) GetCartView(ctx context.Context, userId uuid.UUID) (*domain.CartView, error) {
+	cart, err := c.loadCart(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return c.buildCartView(ctx, cart)
 }
 
 // CalcItemsAmount implements ports.CartService.
@@ -98,13 +181,20 @@ func (c *CartService) CalcItemsAmount(ctx context.Context, userId uuid.UUID) (*p
 		return nil, fmt.Errorf("items not found in cart")
 	}
 
+	lines := make([]domain.CartLine, 0, len(cart.Items))
 	for _, item := range cart.Items {
 		prod, err := c.ps.GetProductById(ctx, item.ProductID)
 		if err != nil {
 			return nil, err
 		}
-		subTotal += prod.Price * float64(item.Quantity)
-		discount += prod.Disscount * float64(item.Quantity)
+		variant, err := prod.ResolveVariant(item.VariantID)
+		if err != nil {
+			return nil, err
+		}
+		line := domain.NewCartLine(item, prod, variant)
+		lines = append(lines, line)
+		subTotal += line.SubTotal
+		discount += line.Discount
 		total = subTotal - discount
 	}
 
@@ -112,28 +202,29 @@ func (c *CartService) CalcItemsAmount(ctx context.Context, userId uuid.UUID) (*p
 		SubTotal: subTotal,
 		Discount: discount,
 		Total:    total,
+		Lines:    lines,
 	}
 
 	return amount, nil
 }
 
 // RemoveItem implements ports.CartService.
-func (c *CartService) RemoveItem(ctx context.Context, userId, productId uuid.UUID) error {
-	cart := c.loadCart(ctx, userId)
-	err := cart.RemoveItem(productId)
-	if err != nil {
-		return err
-	}
-	return c.saveCart(ctx, cart)
+func (c *CartService) RemoveItem(ctx context.Context, userId, productId uuid.UUID, variantId *uuid.UUID) error {
+	_, err := c.updateCart(ctx, userId, func(cart *domain.Cart) error {
+		return cart.RemoveItem(productId, variantId)
+	})
+	return err
 }
 
 // Clear implements ports.CartService.
 func (c *CartService) Clear(ctx context.Context, userId uuid.UUID) error {
-	cart := c.loadCart(ctx, userId)
-	err := cart.Clear()
-	if err != nil {
-		return err
-	}
-	cacheKey := cachekeys.Cart(userId.String())
-	return c.cache.Delete(ctx, cacheKey)
+	_, err := c.updateCart(ctx, userId, func(cart *domain.Cart) error {
+		return cart.Clear()
+	})
+	return err
+}
+
+// ListCarts implements ports.CartService.
+func (c *CartService) ListCarts(ctx context.Context) ([]*domain.Cart, error) {
+	return c.repo.ListCarts(ctx)
 }
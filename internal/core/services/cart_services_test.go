@@ -2,15 +2,23 @@ package services_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"go-ecommerce/internal/adapters/security"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	"go-ecommerce/internal/adapters/storage/database/postgres"
 	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
 	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
 	"go-ecommerce/internal/core/ports/ports_dtos"
 	"go-ecommerce/internal/core/services"
 	testhelpers "go-ecommerce/internal/test_helpers"
 	"go-ecommerce/internal/test_helpers/mocks"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -32,10 +40,10 @@ func Test_Cart_AddItem(t *testing.T) {
 	categRepo := repository.NewCategoryRepo(tx)
 
 	// services
-	userSrv := services.NewUserService(userRepo, redis, hasher)
+	userSrv := services.NewUserService(userRepo, redis, hasher, &mocks.MockNotifier{})
 	productSrv := services.NewProductService(productRepo, redis)
 	categSrv := services.NewCategoryService(categRepo, redis)
-	cartSrv := services.NewCartService(redis, productSrv)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
 
 	// factory user
 	u := testhelpers.NewDomainUser("John", "john@mail.test")
@@ -54,7 +62,7 @@ func Test_Cart_AddItem(t *testing.T) {
 
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -76,7 +84,7 @@ func Test_Cart_AddItem(t *testing.T) {
 	assert.Equal(t, p.CategoryID, newProd.CategoryID)
 
 	// add products to cart before create the order
-	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 }
 
@@ -97,10 +105,10 @@ func Test_Cart_GetCart(t *testing.T) {
 	categRepo := repository.NewCategoryRepo(tx)
 
 	// services
-	userSrv := services.NewUserService(userRepo, redis, hasher)
+	userSrv := services.NewUserService(userRepo, redis, hasher, &mocks.MockNotifier{})
 	productSrv := services.NewProductService(productRepo, redis)
 	categSrv := services.NewCategoryService(categRepo, redis)
-	cartSrv := services.NewCartService(redis, productSrv)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
 
 	// factory user
 	u := testhelpers.NewDomainUser("John", "john@mail.test")
@@ -119,7 +127,7 @@ func Test_Cart_GetCart(t *testing.T) {
 
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -141,7 +149,7 @@ func Test_Cart_GetCart(t *testing.T) {
 	assert.Equal(t, p.CategoryID, newProd.CategoryID)
 
 	// add products to cart before create the order
-	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 
 	// verify items in cart
@@ -168,10 +176,10 @@ func Test_Cart_DeleteProductByID(t *testing.T) {
 	categRepo := repository.NewCategoryRepo(tx)
 
 	// services
-	userSrv := services.NewUserService(userRepo, redis, hasher)
+	userSrv := services.NewUserService(userRepo, redis, hasher, &mocks.MockNotifier{})
 	productSrv := services.NewProductService(productRepo, redis)
 	categSrv := services.NewCategoryService(categRepo, redis)
-	cartSrv := services.NewCartService(redis, productSrv)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
 
 	// factory user
 	u := testhelpers.NewDomainUser("John", "john@mail.test")
@@ -190,7 +198,7 @@ func Test_Cart_DeleteProductByID(t *testing.T) {
 
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -212,7 +220,7 @@ func Test_Cart_DeleteProductByID(t *testing.T) {
 	assert.Equal(t, p.CategoryID, newProd.CategoryID)
 
 	// add products to cart before create the order
-	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 
 	// verify items in cart
@@ -221,7 +229,7 @@ func Test_Cart_DeleteProductByID(t *testing.T) {
 	assert.Equal(t, newProd.ID, cart.Items[0].ProductID)
 
 	// delete product in cart and verify
-	err = cartSrv.RemoveItem(ctx, newUser.ID, newProd.ID)
+	err = cartSrv.RemoveItem(ctx, newUser.ID, newProd.ID, nil)
 	require.NoError(t, err)
 
 	// reload the cart
@@ -249,10 +257,10 @@ func Test_Cart_Clear(t *testing.T) {
 	categRepo := repository.NewCategoryRepo(tx)
 
 	// services
-	userSrv := services.NewUserService(userRepo, redis, hasher)
+	userSrv := services.NewUserService(userRepo, redis, hasher, &mocks.MockNotifier{})
 	productSrv := services.NewProductService(productRepo, redis)
 	categSrv := services.NewCategoryService(categRepo, redis)
-	cartSrv := services.NewCartService(redis, productSrv)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
 
 	// factory user
 	u := testhelpers.NewDomainUser("John", "john@mail.test")
@@ -271,7 +279,7 @@ func Test_Cart_Clear(t *testing.T) {
 
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -309,11 +317,11 @@ func Test_Cart_Clear(t *testing.T) {
 	assert.Equal(t, p2.CategoryID, newProd2.CategoryID)
 
 	// add product-1 to cart
-	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd1.ID, 5)
+	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd1.ID, nil, 5)
 	require.NoError(t, err)
 
 	// add product-2 to cart
-	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd2.ID, 3)
+	err = cartSrv.AddItemToCart(ctx, newUser.ID, newProd2.ID, nil, 3)
 	require.NoError(t, err)
 
 	// verify items in cart
@@ -333,3 +341,503 @@ func Test_Cart_Clear(t *testing.T) {
 	// ensure the cart is empty
 	assert.Empty(t, cart.Items)
 }
+
+func Test_Cart_GetCartView(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	userSrv := services.NewUserService(repository.NewUserRepo(tx), redis, &security.Hasher{}, &mocks.MockNotifier{})
+	prodRepo := repository.NewProductRepo(tx)
+	productSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, repository.NewUserRepo(tx), &mocks.MockNotifier{})
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), repository.NewWarehouseRepo(tx), prodRepo, stockAlertSrv, redis)
+
+	// factory user
+	u := testhelpers.NewDomainUser("John", "john@mail.test")
+	newUser, err := userSrv.SaveUser(ctx, domain.SaveUserInputs{
+		Name:     &u.Name,
+		Email:    &u.Email,
+		Password: &u.Password,
+		Role:     &u.Role,
+	})
+	require.NoError(t, err)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	// factory, two products with a price of 10 and a stock of 100
+	var products []*domain.Product
+	for _, name := range []string{"Ipad 14 pro", "Ipad mini"} {
+		p := testhelpers.NewDomainProduct(name, savedCateg.ID)
+		sku := uuid.NewString()
+		newProd, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name:       &p.Name,
+			Image:      &p.Image,
+			SKU:        &sku,
+			Price:      &p.Price,
+			Stock:      &p.Stock,
+			CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+		products = append(products, newProd)
+	}
+
+	require.NoError(t, cartSrv.AddItemToCart(ctx, newUser.ID, products[0].ID, nil, 5))
+	require.NoError(t, cartSrv.AddItemToCart(ctx, newUser.ID, products[1].ID, nil, 2))
+
+	t.Run("prices the lines with the products", func(t *testing.T) {
+		view, err := cartSrv.GetCartView(ctx, newUser.ID)
+		require.NoError(t, err)
+		require.Len(t, view.Items, 2)
+		assert.False(t, view.HasWarnings)
+
+		line := view.Items[0]
+		assert.Equal(t, "Ipad 14 pro", line.Name)
+		assert.Equal(t, products[0].Image, line.Image)
+		assert.Equal(t, 10.0, line.UnitPrice)
+		assert.Equal(t, 50.0, line.Total)
+		assert.Empty(t, line.Warnings)
+
+		// the totals are the amount of the cart
+		amount, err := cartSrv.CalcItemsAmount(ctx, newUser.ID)
+		require.NoError(t, err)
+		assert.Equal(t, amount.SubTotal, view.SubTotal)
+		assert.Equal(t, amount.Total, view.Total)
+	})
+
+	t.Run("flags the price changes, the low stock and the unavailable products", func(t *testing.T) {
+		price := 12.0
+		_, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{ID: products[0].ID, Price: &price})
+		require.NoError(t, err)
+		_, err = inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: products[0].ID, Quantity: int16(products[0].Stock - 3)}}, domain.AllocateMostStock, nil)
+		require.NoError(t, err)
+		require.NoError(t, productSrv.DeleteProduct(ctx, products[1].ID))
+
+		view, err := cartSrv.GetCartView(ctx, newUser.ID)
+		require.NoError(t, err)
+		require.Len(t, view.Items, 2)
+		assert.True(t, view.HasWarnings)
+
+		changed := view.Items[0]
+		assert.ElementsMatch(t, []domain.CartItemWarning{domain.CartItemInsufficientStock, domain.CartItemPriceChanged}, changed.Warnings)
+		require.NotNil(t, changed.PreviousUnitPrice)
+		assert.Equal(t, 10.0, *changed.PreviousUnitPrice)
+		assert.Equal(t, 60.0, changed.Total)
+
+		unavailable := view.Items[1]
+		assert.Equal(t, []domain.CartItemWarning{domain.CartItemUnavailable}, unavailable.Warnings)
+		assert.Zero(t, unavailable.Total)
+
+		// the unavailable line isn't included in the totals
+		assert.Equal(t, 60.0, view.Total)
+	})
+}
+
+func Test_Cart_AddItem_Validations(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	productSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	// factory, a product with a stock of 8 and one with a large stock
+	var products []*domain.Product
+	for _, stock := range []int64{8, 500} {
+		p := testhelpers.NewDomainProduct("Ipad "+uuid.NewString()[:8], savedCateg.ID)
+		sku := uuid.NewString()
+		newProd, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name:       &p.Name,
+			Image:      &p.Image,
+			SKU:        &sku,
+			Price:      &p.Price,
+			Stock:      &stock,
+			CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+		products = append(products, newProd)
+	}
+
+	userId := uuid.New()
+
+	t.Run("rejects products that don't exist or are archived", func(t *testing.T) {
+		err := cartSrv.AddItemToCart(ctx, userId, uuid.New(), nil, 1)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+
+		p := testhelpers.NewDomainProduct("Archived ipad", savedCateg.ID)
+		sku := uuid.NewString()
+		archived, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name:       &p.Name,
+			Image:      &p.Image,
+			SKU:        &sku,
+			Price:      &p.Price,
+			Stock:      &p.Stock,
+			CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+		require.NoError(t, productSrv.DeleteProduct(ctx, archived.ID))
+
+		err = cartSrv.AddItemToCart(ctx, userId, archived.ID, nil, 1)
+		assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	})
+
+	t.Run("rejects a quantity of 0", func(t *testing.T) {
+		err := cartSrv.AddItemToCart(ctx, userId, products[0].ID, nil, 0)
+		assert.ErrorIs(t, err, domain.ErrCartQuantityIsRequire)
+	})
+
+	t.Run("the line can't exceed the stock", func(t *testing.T) {
+		require.NoError(t, cartSrv.AddItemToCart(ctx, userId, products[0].ID, nil, 6))
+
+		err := cartSrv.AddItemToCart(ctx, userId, products[0].ID, nil, 3)
+		assert.ErrorIs(t, err, domain.ErrCartInsufficientStock)
+
+		// the line keeps its quantity and can be decreased
+		require.NoError(t, cartSrv.AddItemToCart(ctx, userId, products[0].ID, nil, -2))
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		assert.Equal(t, int16(4), cart.QuantityOf(products[0].ID, nil))
+	})
+
+	t.Run("the line can't exceed the max of a line", func(t *testing.T) {
+		err := cartSrv.AddItemToCart(ctx, userId, products[1].ID, nil, domain.MaxCartLineQuantity+1)
+		assert.ErrorIs(t, err, domain.ErrCartLineLimitExceeded)
+
+		require.NoError(t, cartSrv.AddItemToCart(ctx, userId, products[1].ID, nil, domain.MaxCartLineQuantity))
+		err = cartSrv.AddItemToCart(ctx, userId, products[1].ID, nil, 1)
+		assert.ErrorIs(t, err, domain.ErrCartLineLimitExceeded)
+	})
+}
+
+func Test_Cart_ConcurrentUpdates(t *testing.T) {
+	ctx := context.Background()
+
+	// the requests commit, each one in its own transaction
+	db := testhelpers.NewIsolatedSQLiteTestDB(t)
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	productSrv := services.NewProductService(repository.NewProductRepo(db), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(db), redis)
+	cartSrv := services.NewCartService(repository.NewCartRepo(db), redis, productSrv)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	// factory, two products with a stock of 100
+	var products []*domain.Product
+	for range 2 {
+		p := testhelpers.NewDomainProduct("Ipad "+uuid.NewString()[:8], savedCateg.ID)
+		sku := uuid.NewString()
+		newProd, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name:       &p.Name,
+			Image:      &p.Image,
+			SKU:        &sku,
+			Price:      &p.Price,
+			Stock:      &p.Stock,
+			CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+		products = append(products, newProd)
+
+	}
+
+	userId := uuid.New()
+
+	t.Run("no add is lost when the requests change the cart at the same time", func(t *testing.T) {
+		const requests = 20
+
+		var wg sync.WaitGroup
+		errs := make(chan error, requests*2)
+		for range requests {
+			for _, product := range products {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					errs <- cartSrv.AddItemToCart(ctx, userId, product.ID, nil, 1)
+				}()
+			}
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			require.NoError(t, err)
+		}
+
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		require.Len(t, cart.Items, 2)
+		for _, product := range products {
+			assert.Equal(t, int16(requests), cart.QuantityOf(product.ID, nil))
+		}
+	})
+
+	t.Run("the line limit holds when the requests add at the same time", func(t *testing.T) {
+		var wg sync.WaitGroup
+		errs := make(chan error, domain.MaxCartLineQuantity)
+		for range domain.MaxCartLineQuantity {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- cartSrv.AddItemToCart(ctx, userId, products[0].ID, nil, 1)
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		// the line had 20, only 30 of the adds fit in the limit
+		rejected := 0
+		for err := range errs {
+			if err != nil {
+				assert.ErrorIs(t, err, domain.ErrCartLineLimitExceeded)
+				rejected++
+			}
+		}
+		assert.Equal(t, 20, rejected)
+
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		assert.Equal(t, int16(domain.MaxCartLineQuantity), cart.QuantityOf(products[0].ID, nil))
+	})
+}
+
+func Test_Cart_SurvivesCacheLoss(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	productSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartRepo := repository.NewCartRepo(tx)
+	cartSrv := services.NewCartService(cartRepo, redis, productSrv)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	// factory, two products
+	var products []*domain.Product
+	for range 2 {
+		p := testhelpers.NewDomainProduct("Ipad "+uuid.NewString()[:8], savedCateg.ID)
+		sku := uuid.NewString()
+		newProd, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name:       &p.Name,
+			Image:      &p.Image,
+			SKU:        &sku,
+			Price:      &p.Price,
+			Stock:      &p.Stock,
+			CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+		products = append(products, newProd)
+	}
+
+	userId := uuid.New()
+	cacheKey := cachekeys.Cart(userId.String())
+
+	require.NoError(t, cartSrv.AddItemToCart(ctx, userId, products[0].ID, nil, 3))
+	require.NoError(t, cartSrv.AddItemToCart(ctx, userId, products[1].ID, nil, 1))
+
+	t.Run("the cart is saved in the database", func(t *testing.T) {
+		saved, err := cartRepo.GetCart(ctx, userId)
+		require.NoError(t, err)
+		require.Len(t, saved.Items, 2)
+		assert.Equal(t, products[0].ID, saved.Items[0].ProductID)
+		assert.Equal(t, int16(3), saved.Items[0].Quantity)
+		assert.False(t, saved.UpdatedAt.IsZero())
+	})
+
+	t.Run("a cart evicted from the cache is loaded from the database", func(t *testing.T) {
+		require.NoError(t, redis.Delete(ctx, cacheKey))
+
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		require.Len(t, cart.Items, 2)
+		assert.Equal(t, int16(3), cart.QuantityOf(products[0].ID, nil))
+
+		// and cached again
+		cached, err := redis.Get(ctx, cacheKey)
+		require.NoError(t, err)
+		assert.NotEmpty(t, cached)
+	})
+
+	t.Run("a cached cart that can't be read is loaded from the database", func(t *testing.T) {
+		require.NoError(t, redis.Set(ctx, cacheKey, []byte("{not json"), 0))
+
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		assert.Len(t, cart.Items, 2)
+	})
+
+	t.Run("the carts are listed from the database", func(t *testing.T) {
+		require.NoError(t, redis.Delete(ctx, cacheKey))
+
+		carts, err := cartSrv.ListCarts(ctx)
+		require.NoError(t, err)
+		require.Len(t, carts, 1)
+		assert.Equal(t, userId, carts[0].UserID)
+	})
+
+	t.Run("a cleared cart is deleted from the database", func(t *testing.T) {
+		require.NoError(t, cartSrv.Clear(ctx, userId))
+
+		carts, err := cartSrv.ListCarts(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, carts)
+
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		assert.Empty(t, cart.Items)
+	})
+
+	t.Run("a cleared cart isn't filled again from a stale cache", func(t *testing.T) {
+		stale := domain.NewCart(userId)
+		stale.Items = append(stale.Items, domain.CartItem{ProductID: products[0].ID, Quantity: 2})
+		data, err := json.Marshal(stale)
+		require.NoError(t, err)
+		require.NoError(t, redis.Set(ctx, cacheKey, data, 0))
+
+		require.NoError(t, cartSrv.AddItemToCart(ctx, userId, products[1].ID, nil, 1))
+
+		saved, err := cartRepo.GetCart(ctx, userId)
+		require.NoError(t, err)
+		require.Len(t, saved.Items, 1)
+		assert.Equal(t, int16(1), saved.QuantityOf(products[1].ID, nil))
+	})
+
+	t.Run("a failed cache write drops the cached cart", func(t *testing.T) {
+		failingSrv := services.NewCartService(cartRepo, &failingCacheUpdate{CacheRepository: redis}, productSrv)
+		require.NoError(t, failingSrv.AddItemToCart(ctx, userId, products[0].ID, nil, 1))
+
+		cached, err := redis.Get(ctx, cacheKey)
+		require.NoError(t, err)
+		assert.Empty(t, cached)
+
+		cart, err := cartSrv.GetCart(ctx, userId)
+		require.NoError(t, err)
+		assert.Len(t, cart.Items, 2)
+	})
+}
+
+func Test_Cart_ImportCachedCarts(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	productSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartRepo := repository.NewCartRepo(tx)
+	cartSrv := services.NewCartService(cartRepo, redis, productSrv)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Ipad "+uuid.NewString()[:8], savedCateg.ID)
+	sku := uuid.NewString()
+	product, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+		Name:       &p.Name,
+		Image:      &p.Image,
+		SKU:        &sku,
+		Price:      &p.Price,
+		Stock:      &p.Stock,
+		CategoryID: &savedCateg.ID,
+	})
+	require.NoError(t, err)
+
+	// a cart saved only in the cache, and a user whose cart is already in the database
+	legacyUserId, savedUserId := uuid.New(), uuid.New()
+	for _, userId := range []uuid.UUID{legacyUserId, savedUserId} {
+		legacy := domain.NewCart(userId)
+		legacy.Items = append(legacy.Items, domain.CartItem{ProductID: product.ID, Quantity: 2})
+		data, err := json.Marshal(legacy)
+		require.NoError(t, err)
+		require.NoError(t, redis.Set(ctx, cachekeys.Cart(userId.String()), data, 0))
+	}
+	_, err = cartRepo.UpdateCart(ctx, savedUserId, func(cart *domain.Cart) error {
+		cart.Items = append(cart.Items, domain.CartItem{ProductID: product.ID, Quantity: 5})
+		return nil
+	})
+	require.NoError(t, err)
+
+	// a guest cart saved only in the cache
+	guestId := uuid.New()
+	guestCart := domain.NewCart(uuid.Nil)
+	guestCart.Items = append(guestCart.Items, domain.CartItem{ProductID: product.ID, Quantity: 3})
+	data, err := json.Marshal(guestCart)
+	require.NoError(t, err)
+	require.NoError(t, redis.Set(ctx, cachekeys.GuestCart(guestId.String()), data, 0))
+
+	require.NoError(t, postgres.ImportCachedCarts(ctx, tx, redis))
+
+	t.Run("a guest cart saved only in the cache is imported", func(t *testing.T) {
+		saved, err := cartRepo.GetGuestCart(ctx, guestId, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int16(3), saved.QuantityOf(product.ID, nil))
+
+		// and it isn't a cart of a user
+		carts, err := cartRepo.ListCarts(ctx)
+		require.NoError(t, err)
+		assert.Len(t, carts, 2)
+	})
+
+	t.Run("a cart saved only in the cache is imported", func(t *testing.T) {
+		saved, err := cartRepo.GetCart(ctx, legacyUserId)
+		require.NoError(t, err)
+		assert.Equal(t, int16(2), saved.QuantityOf(product.ID, nil))
+	})
+
+	t.Run("a cart saved in the database isn't overwritten", func(t *testing.T) {
+		saved, err := cartRepo.GetCart(ctx, savedUserId)
+		require.NoError(t, err)
+		assert.Equal(t, int16(5), saved.QuantityOf(product.ID, nil))
+	})
+
+	t.Run("the carts are imported only once", func(t *testing.T) {
+		require.NoError(t, cartSrv.Clear(ctx, legacyUserId))
+
+		stale := domain.NewCart(legacyUserId)
+		stale.Items = append(stale.Items, domain.CartItem{ProductID: product.ID, Quantity: 2})
+		data, err := json.Marshal(stale)
+		require.NoError(t, err)
+		require.NoError(t, redis.Set(ctx, cachekeys.Cart(legacyUserId.String()), data, 0))
+
+		require.NoError(t, postgres.ImportCachedCarts(ctx, tx, redis))
+
+		saved, err := cartRepo.GetCart(ctx, legacyUserId)
+		require.NoError(t, err)
+		assert.Empty(t, saved.Items)
+	})
+}
+
+// failingCacheUpdate fails every update of the cache, e.g. redis is down
+type failingCacheUpdate struct {
+	ports.CacheRepository
+}
+
+func (f *failingCacheUpdate) Update(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) ([]byte, error)) error {
+	return errors.New("cache unavailable")
+}
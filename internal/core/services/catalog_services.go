@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	cachettl "go-ecommerce/internal/adapters/storage/cache/cache_ttl"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/google/uuid"
+)
+
+const (
+	importProgressEvery = 100 // rows processed between progress updates of the job
+	exportPageSize      = 500
+)
+
+type CatalogService struct {
+	repo  ports.ProductRepository
+	cache ports.CacheRepository
+}
+
+func NewCatalogService(repo ports.ProductRepository, cache ports.CacheRepository) ports.CatalogService {
+	return &CatalogService{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+// helper func, the jobs are kept in cache so any instance of the api can answer the polling
+func (cs *CatalogService) saveJob(ctx context.Context, job *domain.ImportJob) {
+	serialized, err := json.Marshal(job)
+	if err != nil {
+		slog.Error("error marshaling import job", "job_id", job.ID, "error", err)
+		return
+	}
+
+	err = cs.cache.Set(ctx, cachekeys.ProductImportJob(job.ID.String()), serialized, cachettl.ImportJob)
+	if err != nil {
+		slog.Error("error saving import job", "job_id", job.ID, "error", err)
+	}
+}
+
+// StartImport implements ports.CatalogService.
+// The file is copied to a temp file, so the import can keep reading it after the request ends
+func (cs *CatalogService) StartImport(ctx context.Context, file io.Reader) (*domain.ImportJob, error) {
+	tmp, err := os.CreateTemp("", "product-import-*.csv")
+	if err != nil {
+		return nil, err
+	}
+
+	cleanup := func() {
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}
+
+	if _, err := io.Copy(tmp, file); err != nil {
+		cleanup()
+		return nil, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	// the header is validated before starting the job, so a wrong file is rejected in the request
+	reader := csv.NewReader(tmp)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		cleanup()
+		if errors.Is(err, io.EOF) {
+			return nil, domain.ErrImportEmptyFile
+		}
+		return nil, err
+	}
+
+	columns, err := domain.ProductCSVColumns(header)
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+
+	job := domain.NewImportJob()
+	cs.saveJob(ctx, job)
+	snapshot := *job // the job is updated by the import, the caller gets its initial state
+
+	go func() {
+		defer cleanup()
+		cs.runImport(context.Background(), job, reader, columns)
+	}()
+
+	return &snapshot, nil
+}
+
+// helper func, imports the rows one by one, so memory doesn't grow with the size of the file
+func (cs *CatalogService) runImport(ctx context.Context, job *domain.ImportJob, reader *csv.Reader, columns map[string]int) {
+	var fatal error
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				fatal = err
+				break
+			}
+			// malformed rows are reported and skipped
+			job.RecordFailure(parseErr.Line, "", parseErr.Err)
+		} else {
+			row, _ := reader.FieldPos(0)
+			cs.importRow(ctx, job, row, columns, record)
+		}
+
+		if job.Processed%importProgressEvery == 0 {
+			cs.saveJob(ctx, job)
+		}
+	}
+
+	// imported products can be in any cached product, list or search page
+	cs.invalidateProducts(ctx)
+
+	job.Finish(fatal)
+	cs.saveJob(ctx, job)
+	slog.Info("Products imported", "job_id", job.ID, "status", job.Status, "created", job.Created, "updated", job.Updated, "failed", job.Failed)
+}
+
+// helper func, upserts the product of the row by its SKU
+func (cs *CatalogService) importRow(ctx context.Context, job *domain.ImportJob, row int, columns map[string]int, record []string) {
+	inputs, err := domain.ParseProductCSVRow(columns, record)
+	sku := ""
+	if inputs.SKU != nil {
+		sku = *inputs.SKU
+	}
+	if err != nil {
+		job.RecordFailure(row, sku, err)
+		return
+	}
+
+	product, err := cs.repo.GetProductBySKU(ctx, sku)
+	switch {
+	case err == nil:
+		// the stock of an existing product only changes through the stock ledger, an exported catalogue has the stock
+		// cell filled so it's ignored instead of rejecting the row
+		inputs.Stock = nil
+		if err := product.Update(inputs); err != nil {
+			job.RecordFailure(row, sku, err)
+			return
+		}
+		if _, err := cs.repo.SaveProduct(ctx, product); err != nil {
+			job.RecordFailure(row, sku, err)
+			return
+		}
+		job.RecordUpdated()
+
+	case errors.Is(err, domain.ErrProductNotFound):
+		// new products need every field, NewProduct reports the first missing one
+		newProduct, err := domain.NewProduct(
+			deref(inputs.Name), sku, deref(inputs.Image), deref(inputs.Stock), deref(inputs.Price), deref(inputs.CategoryID),
+		)
+		if err != nil {
+			job.RecordFailure(row, sku, err)
+			return
+		}
+		if _, err := cs.repo.SaveProduct(ctx, newProduct); err != nil {
+			job.RecordFailure(row, sku, err)
+			return
+		}
+		job.RecordCreated()
+
+	default:
+		job.RecordFailure(row, sku, err)
+	}
+}
+
+// helper func, zero value for the empty cells
+func deref[T any](v *T) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return *v
+}
+
+// helper func
+func (cs *CatalogService) invalidateProducts(ctx context.Context) {
+	for _, prefix := range []string{cachekeys.ProductPrefix(), cachekeys.ProductQueriesPrefix()} {
+		if err := cs.cache.DeleteByPrefix(ctx, prefix); err != nil {
+			slog.Warn("error invalidating cached products", "prefix", prefix, "error", err)
+		}
+	}
+
+	if err := cs.cache.Delete(ctx, cachekeys.AllProducts()); err != nil {
+		slog.Warn("error invalidating list of all products", "error", err)
+	}
+}
+
+// GetImportJob implements ports.CatalogService.
+func (cs *CatalogService) GetImportJob(ctx context.Context, id uuid.UUID) (*domain.ImportJob, error) {
+	data, err := cs.cache.Get(ctx, cachekeys.ProductImportJob(id.String()))
+	if err != nil || len(data) == 0 {
+		return nil, domain.ErrImportJobNotFound
+	}
+
+	var job domain.ImportJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ExportProducts implements ports.CatalogService.
+// The catalogue is read by pages from the repository, the cached search pages aren't used
+func (cs *CatalogService) ExportProducts(ctx context.Context, query ports_dtos.ProductQuery, fn func(*domain.Product) error) error {
+	query, err := validateProductQuery(query)
+	if err != nil {
+		return err
+	}
+
+	query.Cursor = ""
+	query.Limit = exportPageSize
+	for {
+		page, err := cs.repo.SearchProducts(ctx, query)
+		if err != nil {
+			return err
+		}
+
+		for _, product := range page.Items {
+			if err := fn(product); err != nil {
+				return err
+			}
+		}
+
+		if page.NextCursor == nil {
+			return nil
+		}
+		query.Cursor = *page.NextCursor
+	}
+}
@@ -0,0 +1,112 @@
+package services_test
+
+import (
+	"context"
+	"fmt"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CatalogService_ImportExport(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodRepo := repository.NewProductRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	catalogSrv := services.NewCatalogService(prodRepo, redis)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Monitors", nil)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Dell 24", savedCateg.ID)
+	p.SKU = "dell-24"
+	existing, err := prodSrv.SaveProduct(ctx, p.ToInputs())
+	require.NoError(t, err)
+
+	// rejected before starting the job
+	_, err = catalogSrv.StartImport(ctx, strings.NewReader(""))
+	assert.Equal(t, domain.ErrImportEmptyFile, err)
+	_, err = catalogSrv.StartImport(ctx, strings.NewReader("name,price\nLG,10\n"))
+	assert.Equal(t, domain.ErrImportMissingSKUColumn, err)
+
+	file := fmt.Sprintf(`name,sku,price,stock,category_id,image
+LG 27,lg-27,300,10,%[1]d,https://img.test/lg.png
+,dell-24,199.9,,,
+Bad price,bad-1,cheap,1,%[1]d,https://img.test/bad.png
+Missing category,new-2,10,1,,https://img.test/new.png
+`, savedCateg.ID)
+
+	job, err := catalogSrv.StartImport(ctx, strings.NewReader(file))
+	require.NoError(t, err)
+	assert.Equal(t, domain.ImportRunning, job.Status)
+
+	require.Eventually(t, func() bool {
+		job, err = catalogSrv.GetImportJob(ctx, job.ID)
+		return err == nil && job.IsFinished()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, domain.ImportCompleted, job.Status)
+	assert.Equal(t, 4, job.Processed)
+	assert.Equal(t, 1, job.Created)
+	assert.Equal(t, 1, job.Updated)
+	assert.Equal(t, 2, job.Failed)
+	require.Len(t, job.Errors, 2)
+	assert.Equal(t, domain.ImportRowError{Row: 4, SKU: "bad-1", Error: `price must be a number: "cheap"`}, job.Errors[0])
+	assert.Equal(t, 5, job.Errors[1].Row)
+	assert.Equal(t, domain.ErrProductCategoryIsRequire.Error(), job.Errors[1].Error)
+
+	// empty cells keep the values of the existing product
+	updated, err := prodSrv.GetProductById(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 199.9, updated.Price)
+	assert.Equal(t, existing.Name, updated.Name)
+	assert.Equal(t, existing.Stock, updated.Stock)
+
+	// a re-imported catalogue has the stock of the existing products, it doesn't change it
+	file = fmt.Sprintf(`name,sku,price,stock,category_id,image
+Dell 24 v2,dell-24,199.9,999,%[1]d,https://img.test/dell.png
+`, savedCateg.ID)
+
+	job, err = catalogSrv.StartImport(ctx, strings.NewReader(file))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		job, err = catalogSrv.GetImportJob(ctx, job.ID)
+		return err == nil && job.IsFinished()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 1, job.Updated)
+	assert.Equal(t, 0, job.Failed)
+	assert.Empty(t, job.Errors)
+
+	updated, err = prodSrv.GetProductById(ctx, existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Dell 24 v2", updated.Name)
+	assert.Equal(t, existing.Stock, updated.Stock)
+
+	// the export follows the filters of the storefront
+	minPrice := 250.0
+	var exported []string
+	err = catalogSrv.ExportProducts(ctx, ports_dtos.ProductQuery{MinPrice: &minPrice}, func(p *domain.Product) error {
+		exported = append(exported, p.SKU)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lg-27"}, exported)
+
+	_, err = catalogSrv.GetImportJob(ctx, existing.ID)
+	assert.Equal(t, domain.ErrImportJobNotFound, err)
+}
@@ -23,19 +23,61 @@ func NewCategoryService(repo ports.CategoryRepository, cache ports.CacheReposito
 	}
 }
 
+// helper func, the tree and the product search pages depend on the hierarchy of categories
+func (cs *CategoryService) invalidateCategoryLists(ctx context.Context) {
+	err := cs.cache.Delete(ctx, cachekeys.AllCategories())
+	if err != nil {
+		slog.Warn("error invalidating list of categories", "error", err)
+	}
+
+	err = cs.cache.Delete(ctx, cachekeys.CategoryTree())
+	if err != nil {
+		slog.Warn("error invalidating tree of categories", "error", err)
+	}
+
+	err = cs.cache.DeleteByPrefix(ctx, cachekeys.ProductQueriesPrefix())
+	if err != nil {
+		slog.Warn("error invalidating product search pages", "error", err)
+	}
+}
+
 // RegisterCategory implements ports.CategoryService.
-func (cs *CategoryService) SaveCategory(ctx context.Context, id uint64, name string) (*domain.Category, error) {
+func (cs *CategoryService) SaveCategory(ctx context.Context, id uint64, name string, parentID *uint64) (*domain.Category, error) {
 	var category *domain.Category
 
 	if id == 0 {
-		newCategory, err := domain.NewCategory(name)
+		newCategory, err := domain.NewCategory(name, nil)
 		if err != nil {
 			return nil, err
 		}
 		category = newCategory
 
 	} else {
-		err := category.UpdateCategory(name)
+		existing, err := cs.repo.GetCategoryByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		err = existing.UpdateCategory(name)
+		if err != nil {
+			return nil, err
+		}
+		category = existing
+	}
+
+	// the parent is changed only when it's sent, the whole list is needed to validate that the category is not moved
+	// under one of its descendants
+	if parentID != nil && *parentID == domain.NoParentCategory {
+		if err := category.SetParent(nil, nil); err != nil {
+			return nil, err
+		}
+	} else if parentID != nil {
+		categories, err := cs.repo.ListCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		err = category.SetParent(parentID, categories)
 		if err != nil {
 			return nil, err
 		}
@@ -59,11 +101,7 @@ func (cs *CategoryService) SaveCategory(ctx context.Context, id uint64, name str
 		slog.Warn("error saving category in cache", "category_id", result.ID, "error", err)
 	}
 
-	// invalid the cached list of all categories
-	err = cs.cache.Delete(ctx, cachekeys.AllCategories())
-	if err != nil {
-		slog.Warn("error invalidating list of categories", "error", err)
-	}
+	cs.invalidateCategoryLists(ctx)
 
 	return result, nil
 }
@@ -133,26 +171,109 @@ func (cs *CategoryService) ListCategories(ctx context.Context) ([]*domain.Catego
 	return categories, nil
 }
 
+// CategoryTree implements ports.CategoryService.
+func (cs *CategoryService) CategoryTree(ctx context.Context) ([]*domain.CategoryNode, error) {
+	data, err := cs.cache.Get(ctx, cachekeys.CategoryTree())
+	if err == nil && len(data) > 0 {
+		var tree []*domain.CategoryNode
+		if decodeErr := json.Unmarshal(data, &tree); decodeErr == nil {
+			return tree, nil
+		}
+	}
+
+	categories, err := cs.repo.ListCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := domain.BuildCategoryTree(categories)
+
+	serialized, err := json.Marshal(tree)
+	if err != nil {
+		slog.Warn("Error marshaling category tree for cache", "error", err)
+		return tree, nil
+	}
+
+	err = cs.cache.Set(ctx, cachekeys.CategoryTree(), serialized, cachettl.Category)
+	if err != nil {
+		slog.Warn("error caching category tree", "error", err)
+	}
+
+	return tree, nil
+}
+
 // DeleteCategory implements ports.CategoryService.
-func (cs *CategoryService) DeleteCategory(ctx context.Context, id uint64) error {
-	cacheKey := cachekeys.Category(id)
+// Categories with children or products need the reparent or cascade strategy
+func (cs *CategoryService) DeleteCategory(ctx context.Context, id uint64, strategy domain.CategoryDeleteStrategy) error {
+	category, err := cs.repo.GetCategoryByID(ctx, id)
+	if err != nil {
+		return err
+	}
 
-	// delete from database
-	err := cs.repo.DeleteCategory(ctx, id)
+	categories, err := cs.repo.ListCategories(ctx)
+	if err != nil {
+		return err
+	}
+
+	products, err := cs.repo.CountProducts(ctx, []uint64{id})
 	if err != nil {
 		return err
 	}
 
-	// delete from cache
-	err = cs.cache.Delete(ctx, cacheKey)
+	plan, err := domain.PlanCategoryDeletion(category, categories, products, strategy)
 	if err != nil {
-		slog.Warn("error deleteing category of cache", "category_id", id, "error", err)
+		return err
 	}
 
-	err = cs.cache.Delete(ctx, cachekeys.AllCategories())
+	// delete from database
+	err = cs.repo.DeleteCategoryWithPlan(ctx, plan)
 	if err != nil {
-		slog.Warn("error invalidating list of all categories", "error", err)
+		return err
 	}
 
+	// delete from cache, the moved children changed their parent
+	for _, deletedId := range plan.DeleteIDs {
+		err = cs.cache.Delete(ctx, cachekeys.Category(deletedId))
+		if err != nil {
+			slog.Warn("error deleteing category of cache", "category_id", deletedId, "error", err)
+		}
+	}
+	if plan.Reparent {
+		for _, c := range categories {
+			if c.ParentID != nil && *c.ParentID == id {
+				if err := cs.cache.Delete(ctx, cachekeys.Category(c.ID)); err != nil {
+					slog.Warn("error deleteing category of cache", "category_id", c.ID, "error", err)
+				}
+			}
+		}
+	}
+
+	// the products of the category were moved or deleted
+	if products > 0 || plan.DeleteProducts {
+		err = cs.cache.DeleteByPrefix(ctx, cachekeys.ProductPrefix())
+		if err != nil {
+			slog.Warn("error invalidating products of deleted category", "category_id", id, "error", err)
+		}
+		err = cs.cache.Delete(ctx, cachekeys.AllProducts())
+		if err != nil {
+			slog.Warn("error invalidating list of all products", "error", err)
+		}
+	}
+
+	cs.invalidateCategoryLists(ctx)
+
 	return nil
 }
+
+// RestoreCategory implements ports.CategoryService.
+// Only the category is restored, its archived products and children are restored one by one
+func (cs *CategoryService) RestoreCategory(ctx context.Context, id uint64) (*domain.Category, error) {
+	err := cs.repo.RestoreCategory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cs.invalidateCategoryLists(ctx)
+
+	return cs.GetCategoryByID(ctx, id)
+}
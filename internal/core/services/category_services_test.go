@@ -37,7 +37,7 @@ func Test_CategoryService_Create(t *testing.T) {
 
 	// factory, create a category
 	c := testhelpers.NewDomainCategory("SmartPhones")
-	newCateg, err := srv.SaveCategory(ctx, 0, c.Name)
+	newCateg, err := srv.SaveCategory(ctx, 0, c.Name, nil)
 
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, newCateg.Name)
@@ -51,7 +51,7 @@ func Test_CategoryService_Update(t *testing.T) {
 
 	// factory, create a category
 	c := testhelpers.NewDomainCategory("SmartPhones")
-	newCateg, err := srv.SaveCategory(ctx, 0, c.Name)
+	newCateg, err := srv.SaveCategory(ctx, 0, c.Name, nil)
 
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, newCateg.Name)
@@ -64,7 +64,7 @@ func Test_CategoryService_Update(t *testing.T) {
 	// update category
 	name := "smart-phones"
 	newCateg.UpdateCategory(name)
-	updatedCateg, err := srv.SaveCategory(ctx, 0, name)
+	updatedCateg, err := srv.SaveCategory(ctx, 0, name, nil)
 
 	require.NoError(t, err)
 	assert.Equal(t, name, updatedCateg.Name)
@@ -80,7 +80,7 @@ func Test_CategoryService_FindByID(t *testing.T) {
 	c := testhelpers.NewDomainCategory("SmartPhones")
 
 	// create category
-	newCateg, err := srv.SaveCategory(ctx, 0, c.Name)
+	newCateg, err := srv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, newCateg.Name)
 
@@ -101,15 +101,130 @@ func Test_CategoryService_Delete(t *testing.T) {
 	c := testhelpers.NewDomainCategory("SmartPhones")
 
 	// create category
-	newCateg, err := srv.SaveCategory(ctx, 0, c.Name)
+	newCateg, err := srv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, newCateg.Name)
 
 	// delete
-	err = srv.DeleteCategory(ctx, newCateg.ID)
+	err = srv.DeleteCategory(ctx, newCateg.ID, domain.RestrictDelete)
 	require.NoError(t, err)
 
 	// find by id
 	_, err = srv.GetCategoryByID(ctx, newCateg.ID)
 	require.Error(t, domain.ErrCategoriesNotFound, err)
 }
+
+func Test_CategoryService_Tree(t *testing.T) {
+	ctx := context.Background()
+	srv := newCategoryServices(t)
+
+	root, err := srv.SaveCategory(ctx, 0, "Electronics", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "electronics", root.Slug)
+
+	phones, err := srv.SaveCategory(ctx, 0, "Smart Phones", &root.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "smart-phones", phones.Slug)
+
+	android, err := srv.SaveCategory(ctx, 0, "Android", &phones.ID)
+	require.NoError(t, err)
+
+	tree, err := srv.CategoryTree(ctx)
+	require.NoError(t, err)
+	require.Len(t, tree, 1)
+	assert.Equal(t, root.ID, tree[0].ID)
+	require.Len(t, tree[0].Children, 1)
+	require.Len(t, tree[0].Children[0].Children, 1)
+	assert.Equal(t, android.ID, tree[0].Children[0].Children[0].ID)
+
+	// a category can't be moved below one of its descendants
+	_, err = srv.SaveCategory(ctx, root.ID, root.Name, &android.ID)
+	assert.Equal(t, domain.ErrCategoryCycle, err)
+
+	missing := uint64(9999)
+	_, err = srv.SaveCategory(ctx, 0, "Orphan", &missing)
+	assert.Equal(t, domain.ErrCategoryParentNotFound, err)
+}
+
+func Test_CategoryService_RenameNested(t *testing.T) {
+	ctx := context.Background()
+	srv := newCategoryServices(t)
+
+	root, err := srv.SaveCategory(ctx, 0, "Electronics", nil)
+	require.NoError(t, err)
+	phones, err := srv.SaveCategory(ctx, 0, "Smart Phones", &root.ID)
+	require.NoError(t, err)
+
+	// a rename without parent keeps the category under its parent
+	renamed, err := srv.SaveCategory(ctx, phones.ID, "Phones", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "phones", renamed.Slug)
+	require.NotNil(t, renamed.ParentID)
+	assert.Equal(t, root.ID, *renamed.ParentID)
+
+	reloaded, err := srv.GetCategoryByID(ctx, phones.ID)
+	require.NoError(t, err)
+	require.NotNil(t, reloaded.ParentID)
+	assert.Equal(t, root.ID, *reloaded.ParentID)
+
+	// the category is detached only with the explicit value
+	noParent := domain.NoParentCategory
+	detached, err := srv.SaveCategory(ctx, phones.ID, "Phones", &noParent)
+	require.NoError(t, err)
+	assert.Nil(t, detached.ParentID)
+
+	tree, err := srv.CategoryTree(ctx)
+	require.NoError(t, err)
+	assert.Len(t, tree, 2)
+}
+
+func Test_CategoryService_DeleteStrategies(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodRepo := repository.NewProductRepo(tx)
+	srv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+
+	root, err := srv.SaveCategory(ctx, 0, "Computers", nil)
+	require.NoError(t, err)
+	laptops, err := srv.SaveCategory(ctx, 0, "Laptops", &root.ID)
+	require.NoError(t, err)
+	gaming, err := srv.SaveCategory(ctx, 0, "Gaming", &laptops.ID)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Macbook", laptops.ID)
+	product, err := prodRepo.SaveProduct(ctx, p)
+	require.NoError(t, err)
+
+	// restrict refuses categories with children or products
+	err = srv.DeleteCategory(ctx, laptops.ID, domain.RestrictDelete)
+	assert.Equal(t, domain.ErrCategoryHasChildren, err)
+
+	err = srv.DeleteCategory(ctx, laptops.ID, "unknown")
+	assert.Equal(t, domain.ErrInvalidCategoryDeleteStrategy, err)
+
+	// reparent moves children and products to the parent
+	err = srv.DeleteCategory(ctx, laptops.ID, domain.ReparentDelete)
+	require.NoError(t, err)
+
+	movedChild, err := srv.GetCategoryByID(ctx, gaming.ID)
+	require.NoError(t, err)
+	require.NotNil(t, movedChild.ParentID)
+	assert.Equal(t, root.ID, *movedChild.ParentID)
+
+	movedProduct, err := prodRepo.GetProductById(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, movedProduct.CategoryID)
+
+	// cascade removes the whole subtree and its products
+	err = srv.DeleteCategory(ctx, root.ID, domain.CascadeDelete)
+	require.NoError(t, err)
+
+	_, err = srv.GetCategoryByID(ctx, gaming.ID)
+	assert.Equal(t, domain.ErrCategoryNotFound, err)
+	_, err = prodRepo.GetProductById(ctx, product.ID)
+	assert.Error(t, err)
+}
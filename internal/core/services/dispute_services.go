@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+type DisputeService struct {
+	disputeRepo ports.DisputeRepository
+	orderRepo   ports.OrderRepository
+	alerter     ports.Alerter
+	cache       ports.CacheRepository
+}
+
+func NewDisputeService(disputeRepo ports.DisputeRepository, orderRepo ports.OrderRepository, alerter ports.Alerter, cache ports.CacheRepository) ports.DisputeService {
+	return &DisputeService{
+		disputeRepo: disputeRepo,
+		orderRepo:   orderRepo,
+		alerter:     alerter,
+		cache:       cache,
+	}
+}
+
+// helper func, the order changes its status with the dispute so the cached one is no longer valid
+func (ds *DisputeService) saveDisputedOrder(ctx context.Context, order *domain.Order) error {
+	_, err := ds.orderRepo.SaveOrder(ctx, order)
+	if err != nil {
+		return err
+	}
+
+	if err := ds.cache.Delete(ctx, cachekeys.Order(order.ID.String())); err != nil {
+		slog.Warn("error invalidating disputed order", "order_id", order.ID, "error", err)
+	}
+	return nil
+}
+
+// RegisterChargeback implements ports.DisputeService.
+// Creates the dispute, flags the order and notifies the sellers. Notifications about a payment already disputed are ignored
+func (ds *DisputeService) RegisterChargeback(ctx context.Context, inputs domain.NewDisputeInputs) (*domain.Dispute, error) {
+	existing, err := ds.disputeRepo.GetDisputeByPaymentId(ctx, inputs.PaymentID)
+	if err == nil {
+		return existing, nil
+	}
+	if err != domain.ErrDisputeNotFound {
+		return nil, err
+	}
+
+	order, err := ds.orderRepo.GetOrderById(ctx, inputs.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(inputs.Currency) == 0 {
+		inputs.Currency = order.Currency
+	}
+
+	dispute, err := domain.NewDispute(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute, err = ds.disputeRepo.SaveDispute(ctx, dispute)
+	if err != nil {
+		return nil, err
+	}
+
+	order.MarkChargedBack()
+	if err := ds.saveDisputedOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	err = ds.alerter.Alert(ctx, "chargeback received", map[string]any{
+		"dispute_id":      dispute.ID,
+		"order_id":        order.ID,
+		"payment_id":      dispute.PaymentID,
+		"amount":          dispute.Amount,
+		"reason":          dispute.Reason,
+		"evidence_due_at": dispute.EvidenceDueAt,
+	})
+	if err != nil {
+		slog.Error("error alerting chargeback", "dispute_id", dispute.ID, "error", err)
+	}
+
+	return dispute, nil
+}
+
+// GetDisputeById implements ports.DisputeService.
+func (ds *DisputeService) GetDisputeById(ctx context.Context, id uuid.UUID) (*domain.Dispute, error) {
+	return ds.disputeRepo.GetDisputeById(ctx, id)
+}
+
+// ListDisputes implements ports.DisputeService.
+func (ds *DisputeService) ListDisputes(ctx context.Context, status *domain.DisputeStatus) ([]*domain.Dispute, error) {
+	return ds.disputeRepo.ListDisputes(ctx, status)
+}
+
+// AddEvidence implements ports.DisputeService.
+func (ds *DisputeService) AddEvidence(ctx context.Context, id uuid.UUID, actor *domain.User, note string) (*domain.Dispute, error) {
+	dispute, err := ds.disputeRepo.GetDisputeById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	evidence, err := dispute.AddEvidence(actor.ID, note)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = ds.disputeRepo.SaveEvidence(ctx, evidence)
+	if err != nil {
+		return nil, err
+	}
+
+	return ds.disputeRepo.SaveDispute(ctx, dispute)
+}
+
+// Resolve implements ports.DisputeService.
+func (ds *DisputeService) Resolve(ctx context.Context, id uuid.UUID, actor *domain.User, outcome domain.DisputeStatus) (*domain.Dispute, error) {
+	dispute, err := ds.disputeRepo.GetDisputeById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = dispute.Resolve(outcome, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := ds.orderRepo.GetOrderById(ctx, dispute.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	dispute, err = ds.disputeRepo.SaveDispute(ctx, dispute)
+	if err != nil {
+		return nil, err
+	}
+
+	order.ResolveChargeback(outcome)
+	if err := ds.saveDisputedOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return dispute, nil
+}
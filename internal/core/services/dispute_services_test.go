@@ -0,0 +1,90 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/mercadopago/mp_dtos"
+	"go-ecommerce/internal/core/domain"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DisputeServices_ChargebackWorkflow(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	order := newAuthorizedOrder(t, ctx, srv, "chargeback@mail.test")
+
+	srv.mp.GetChargebackFunc = func(ctx context.Context, chargebackId string) (*mp_dtos.MpChargeback, error) {
+		return &mp_dtos.MpChargeback{
+			ID:       chargebackId,
+			Payments: []int{987654},
+			Amount:   1000,
+			Reason:   "fraud",
+		}, nil
+	}
+	srv.mp.GetPaymentFunc = func(ctx context.Context, paymentId string) (*mp_dtos.MpSimplifiedPayment, error) {
+		return &mp_dtos.MpSimplifiedPayment{
+			ID:                987654,
+			Status:            domain.ChargedBack,
+			TransactionAmount: 1000,
+			ExternalReference: order.ID.String(),
+		}, nil
+	}
+
+	id, topic := "cb-1", "chargebacks"
+	require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+	// notifications are repeated by the provider, only one dispute is recorded
+	require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+	disputes, err := srv.disputeSrv.ListDisputes(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, disputes, 1)
+	dispute := disputes[0]
+	assert.Equal(t, order.ID, dispute.OrderID)
+	assert.Equal(t, domain.DisputeOpen, dispute.Status)
+	assert.Equal(t, "fraud", dispute.Reason)
+	assert.Equal(t, 1000.0, dispute.Amount)
+	require.Len(t, srv.alerter.Alerts, 1)
+
+	flagged, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.True(t, flagged.Disputed)
+	assert.Equal(t, domain.ChargedBack, flagged.PayStatus)
+
+	// evidence
+	admin := testhelpers.NewDomainUser("Admin", "admin-dispute@mail.test")
+	admin.Role = domain.Admin
+	admin, err = srv.userRepo.SaveUser(ctx, admin)
+	require.NoError(t, err)
+
+	_, err = srv.disputeSrv.AddEvidence(ctx, dispute.ID, admin, "")
+	assert.ErrorIs(t, err, domain.ErrDisputeEvidenceIsRequire)
+
+	withEvidence, err := srv.disputeSrv.AddEvidence(ctx, dispute.ID, admin, "tracking number 123, delivered and signed")
+	require.NoError(t, err)
+	assert.Equal(t, domain.DisputeEvidenceSubmitted, withEvidence.Status)
+	require.Len(t, withEvidence.Evidence, 1)
+	assert.Equal(t, admin.ID, withEvidence.Evidence[0].AuthorID)
+
+	// outcome
+	_, err = srv.disputeSrv.Resolve(ctx, dispute.ID, admin, domain.DisputeOpen)
+	assert.ErrorIs(t, err, domain.ErrDisputeInvalidOutcome)
+
+	resolved, err := srv.disputeSrv.Resolve(ctx, dispute.ID, admin, domain.DisputeWon)
+	require.NoError(t, err)
+	assert.Equal(t, domain.DisputeWon, resolved.Status)
+	require.NotNil(t, resolved.ResolvedBy)
+	assert.Equal(t, admin.ID, *resolved.ResolvedBy)
+
+	restored, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Approved, restored.PayStatus)
+	assert.True(t, restored.Disputed)
+
+	_, err = srv.disputeSrv.Resolve(ctx, dispute.ID, admin, domain.DisputeLost)
+	assert.ErrorIs(t, err, domain.ErrDisputeAlreadyResolved)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	cachettl "go-ecommerce/internal/adapters/storage/cache/cache_ttl"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// GuestCartService handles the carts of the visitors with the same rules as the carts of the users, the carts are
+// saved in the database under the id signed in the cart token, written through to the cache, and expire with the token
+type GuestCartService struct {
+	carts  *CartService
+	signer ports.TokenSigner
+}
+
+// NewGuestCartService merges the guest carts in the carts of the users saved in repo
+func NewGuestCartService(repo ports.CartRepository, cache ports.CacheRepository, ps ports.ProductService, signer ports.TokenSigner) ports.GuestCartService {
+	return &GuestCartService{
+		carts:  &CartService{repo: repo, cache: cache, ps: ps},
+		signer: signer,
+	}
+}
+
+// helper func, returns the id of the guest signed in the token
+func (gs *GuestCartService) guestID(token string) (uuid.UUID, error) {
+	payload, err := gs.signer.Verify(token, time.Now())
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	guestId, err := uuid.Parse(payload)
+	if err != nil {
+		return uuid.Nil, domain.ErrInvalidToken
+	}
+	return guestId, nil
+}
+
+// helper func, the cart is read from the cache, the database is used when it isn't cached. The carts of the guests
+// don't belong to a user
+func (gs *GuestCartService) loadCart(ctx context.Context, guestId uuid.UUID) (*domain.Cart, error) {
+	key := cachekeys.GuestCart(guestId.String())
+	if cart := gs.carts.cachedCart(ctx, key); cart != nil {
+		return cart, nil
+	}
+
+	cart, err := gs.carts.repo.GetGuestCart(ctx, guestId, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	cart.UserID = uuid.Nil
+	gs.carts.cacheCart(ctx, key, cachettl.GuestCart, cart)
+	return cart, nil
+}
+
+// helper func, applies the change to the cart of the guest in the database and writes it to the cache, every change
+// extends the expiry of the cart
+func (gs *GuestCartService) updateCart(ctx context.Context, guestId uuid.UUID, change func(cart *domain.Cart) error) (*domain.Cart, error) {
+	now := time.Now()
+	cart, err := gs.carts.repo.UpdateGuestCart(ctx, guestId, now.Add(cachettl.GuestCart), func(cart *domain.Cart) error {
+		if err := change(cart); err != nil {
+			return err
+		}
+		cart.Touch(now)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	cart.UserID = uuid.Nil
+	gs.carts.cacheCart(ctx, cachekeys.GuestCart(guestId.String()), cachettl.GuestCart, cart)
+	return cart, nil
+}
+
+// NewToken implements ports.GuestCartService.
+func (gs *GuestCartService) NewToken() string {
+	return gs.signer.Sign(uuid.New().String(), time.Now().Add(cachettl.GuestCart))
+}
+
+// GetCart implements ports.GuestCartService.
+func (gs *GuestCartService) GetCart(ctx context.Context, token string) (*domain.Cart, error) {
+	guestId, err := gs.guestID(token)
+	if err != nil {
+		return nil, err
+	}
+	return gs.loadCart(ctx, guestId)
+}
+
+// GetCartView implements ports.GuestCartService.
+func (gs *GuestCartService) GetCartView(ctx context.Context, token string) (*domain.CartView, error) {
+	cart, err := gs.GetCart(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return gs.carts.buildCartView(ctx, cart)
+}
+
+// AddItemToCart implements ports.GuestCartService.
+func (gs *GuestCartService) AddItemToCart(ctx context.Context, token string, productId uuid.UUID, variantId *uuid.UUID, quantity int16) error {
+	guestId, err := gs.guestID(token)
+	if err != nil {
+		return err
+	}
+
+	change, err := gs.carts.addItemChange(ctx, productId, variantId, quantity)
+	if err != nil {
+		return err
+	}
+
+	_, err = gs.updateCart(ctx, guestId, change)
+	return err
+}
+
+// RemoveItem implements ports.GuestCartService.
+func (gs *GuestCartService) RemoveItem(ctx context.Context, token string, productId uuid.UUID, variantId *uuid.UUID) error {
+	guestId, err := gs.guestID(token)
+	if err != nil {
+		return err
+	}
+
+	_, err = gs.updateCart(ctx, guestId, func(cart *domain.Cart) error {
+		return cart.RemoveItem(productId, variantId)
+	})
+	return err
+}
+
+// Clear implements ports.GuestCartService.
+func (gs *GuestCartService) Clear(ctx context.Context, token string) error {
+	guestId, err := gs.guestID(token)
+	if err != nil {
+		return err
+	}
+
+	_, err = gs.updateCart(ctx, guestId, func(cart *domain.Cart) error {
+		return cart.Clear()
+	})
+	return err
+}
+
+// Merge implements ports.GuestCartService.
+// The quantities of the lines in both carts are summed and capped at the stock. The lines of products that can't be
+// bought anymore, e.g. a deleted product, are dropped. The guest cart is deleted after the merge
+func (gs *GuestCartService) Merge(ctx context.Context, token string, userId uuid.UUID) (*domain.Cart, error) {
+	guestId, err := gs.guestID(token)
+	if err != nil {
+		return nil, err
+	}
+
+	guestCart, err := gs.loadCart(ctx, guestId)
+	if err != nil {
+		return nil, err
+	}
+	if len(guestCart.Items) == 0 {
+		return gs.carts.loadCart(ctx, userId)
+	}
+
+	// the stock is read before the change, the change can run again and must only change the cart
+	items := make([]domain.CartItem, 0, len(guestCart.Items))
+	stocks := make([]int64, 0, len(guestCart.Items))
+	for _, item := range guestCart.Items {
+		product, err := gs.carts.ps.GetProductById(ctx, item.ProductID)
+		if err != nil {
+			slog.Warn("error merging item of guest cart", "user_id", userId, "product_id", item.ProductID, "error", err)
+			continue
+		}
+		variant, err := product.ResolveVariant(item.VariantID)
+		if err != nil {
+			slog.Warn("error merging item of guest cart", "user_id", userId, "product_id", item.ProductID, "error", err)
+			continue
+		}
+
+		items = append(items, item)
+		stocks = append(stocks, product.AvailableStock(variant))
+	}
+
+	cart, err := gs.carts.updateCart(ctx, userId, func(cart *domain.Cart) error {
+		for i, item := range items {
+			cart.MergeItem(item, stocks[i])
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = gs.updateCart(ctx, guestId, func(cart *domain.Cart) error {
+		cart.Items = []domain.CartItem{}
+		return nil
+	})
+	if err != nil {
+		slog.Warn("error deleting merged guest cart", "guest_id", guestId, "error", err)
+	}
+	return cart, nil
+}
+
+// PurgeExpired implements ports.GuestCartService.
+func (gs *GuestCartService) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	return gs.carts.repo.DeleteExpiredGuestCarts(ctx, now)
+}
@@ -0,0 +1,200 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/security"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GuestCart_Merge(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	userSrv := services.NewUserService(repository.NewUserRepo(tx), redis, &security.Hasher{}, &mocks.MockNotifier{})
+	productSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
+	guestSrv := services.NewGuestCartService(repository.NewCartRepo(tx), redis, productSrv, security.NewSigner("secret"))
+
+	// factory user
+	u := testhelpers.NewDomainUser("John", "john@mail.test")
+	newUser, err := userSrv.SaveUser(ctx, domain.SaveUserInputs{
+		Name:     &u.Name,
+		Email:    &u.Email,
+		Password: &u.Password,
+		Role:     &u.Role,
+	})
+	require.NoError(t, err)
+
+	// factory, two products with a stock of 10
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	var products []*domain.Product
+	for _, name := range []string{"Ipad 14 pro", "Ipad mini"} {
+		p := testhelpers.NewDomainProduct(name, savedCateg.ID)
+		sku := uuid.NewString()
+		stock := int64(10)
+		newProd, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name:       &p.Name,
+			Image:      &p.Image,
+			SKU:        &sku,
+			Price:      &p.Price,
+			Stock:      &stock,
+			CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+		products = append(products, newProd)
+	}
+
+	token := guestSrv.NewToken()
+
+	t.Run("the guest shops with the cart token", func(t *testing.T) {
+		require.NoError(t, guestSrv.AddItemToCart(ctx, token, products[0].ID, nil, 6))
+		require.NoError(t, guestSrv.AddItemToCart(ctx, token, products[1].ID, nil, 2))
+
+		cart, err := guestSrv.GetCart(ctx, token)
+		require.NoError(t, err)
+		require.Len(t, cart.Items, 2)
+		assert.Equal(t, uuid.Nil, cart.UserID)
+
+		// another token is another cart
+		other, err := guestSrv.GetCart(ctx, guestSrv.NewToken())
+		require.NoError(t, err)
+		assert.Empty(t, other.Items)
+	})
+
+	t.Run("a tampered token is rejected", func(t *testing.T) {
+		_, err := guestSrv.GetCart(ctx, token+"x")
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+
+		err = guestSrv.AddItemToCart(ctx, "not-a-token", products[0].ID, nil, 1)
+		assert.ErrorIs(t, err, domain.ErrInvalidToken)
+	})
+
+	t.Run("the merge sums the quantities capped at the stock", func(t *testing.T) {
+		require.NoError(t, cartSrv.AddItemToCart(ctx, newUser.ID, products[0].ID, nil, 7))
+
+		cart, err := guestSrv.Merge(ctx, token, newUser.ID)
+		require.NoError(t, err)
+		require.Len(t, cart.Items, 2)
+		assert.Equal(t, newUser.ID, cart.UserID)
+
+		quantities := map[uuid.UUID]int16{}
+		for _, item := range cart.Items {
+			quantities[item.ProductID] = item.Quantity
+		}
+		assert.Equal(t, int16(10), quantities[products[0].ID])
+		assert.Equal(t, int16(2), quantities[products[1].ID])
+
+		// the user cart is saved and the guest cart is gone
+		saved, err := cartSrv.GetCart(ctx, newUser.ID)
+		require.NoError(t, err)
+		assert.Len(t, saved.Items, 2)
+
+		guestCart, err := guestSrv.GetCart(ctx, token)
+		require.NoError(t, err)
+		assert.Empty(t, guestCart.Items)
+	})
+
+	t.Run("merging an empty guest cart keeps the user cart", func(t *testing.T) {
+		cart, err := guestSrv.Merge(ctx, token, newUser.ID)
+		require.NoError(t, err)
+		assert.Len(t, cart.Items, 2)
+	})
+}
+
+func Test_GuestCart_SavedInDatabase(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+
+	// services
+	productSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartRepo := repository.NewCartRepo(tx)
+	cartSrv := services.NewCartService(cartRepo, redis, productSrv)
+	guestSrv := services.NewGuestCartService(cartRepo, redis, productSrv, security.NewSigner("secret"))
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Ipad "+uuid.NewString()[:8], savedCateg.ID)
+	sku := uuid.NewString()
+	product, err := productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+		Name:       &p.Name,
+		Image:      &p.Image,
+		SKU:        &sku,
+		Price:      &p.Price,
+		Stock:      &p.Stock,
+		CategoryID: &savedCateg.ID,
+	})
+	require.NoError(t, err)
+
+	token := guestSrv.NewToken()
+	require.NoError(t, guestSrv.AddItemToCart(ctx, token, product.ID, nil, 2))
+
+	t.Run("a guest cart evicted from the cache is loaded from the database", func(t *testing.T) {
+		require.NoError(t, redis.DeleteByPrefix(ctx, cachekeys.GuestCartPrefix()))
+
+		cart, err := guestSrv.GetCart(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, int16(2), cart.QuantityOf(product.ID, nil))
+		assert.Equal(t, uuid.Nil, cart.UserID)
+	})
+
+	t.Run("the guest carts aren't listed with the carts of the users", func(t *testing.T) {
+		carts, err := cartSrv.ListCarts(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, carts)
+	})
+
+	t.Run("an expired guest cart is empty and purged", func(t *testing.T) {
+		guestId := uuid.New()
+		_, err := cartRepo.UpdateGuestCart(ctx, guestId, time.Now().Add(-time.Minute), func(cart *domain.Cart) error {
+			return cart.AddItem(product.ID, nil, 1, product.Price, product.Stock)
+		})
+		require.NoError(t, err)
+
+		cart, err := cartRepo.GetGuestCart(ctx, guestId, time.Now())
+		require.NoError(t, err)
+		assert.Empty(t, cart.Items)
+
+		// a change starts an empty cart
+		cart, err = cartRepo.UpdateGuestCart(ctx, guestId, time.Now().Add(-time.Minute), func(cart *domain.Cart) error {
+			assert.Empty(t, cart.Items)
+			return cart.AddItem(product.ID, nil, 1, product.Price, product.Stock)
+		})
+		require.NoError(t, err)
+		assert.Len(t, cart.Items, 1)
+
+		purged, err := guestSrv.PurgeExpired(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		// the cart of the token didn't expire
+		cart, err = guestSrv.GetCart(ctx, token)
+		require.NoError(t, err)
+		assert.Len(t, cart.Items, 1)
+	})
+}
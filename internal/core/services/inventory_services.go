@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InventoryService struct {
+	repo          ports.InventoryRepository
+	warehouseRepo ports.WarehouseRepository
+	productRepo   ports.ProductRepository
+	alerts        ports.StockAlertService
+	cache         ports.CacheRepository
+}
+
+func NewInventoryService(repo ports.InventoryRepository, warehouseRepo ports.WarehouseRepository, productRepo ports.ProductRepository, alerts ports.StockAlertService, cache ports.CacheRepository) ports.InventoryService {
+	return &InventoryService{
+		repo:          repo,
+		warehouseRepo: warehouseRepo,
+		productRepo:   productRepo,
+		alerts:        alerts,
+		cache:         cache,
+	}
+}
+
+// helper func, the cached product and the pages that could contain it are stale after its stock changes,
+// and the stock could have fallen to the low stock threshold or come back
+func (is *InventoryService) stockChanged(ctx context.Context, id uuid.UUID) {
+	if err := is.alerts.CheckStock(ctx, id); err != nil {
+		slog.Error("error checking stock alerts", "product_id", id, "error", err)
+	}
+
+	err := is.cache.Delete(ctx, cachekeys.Product(id.String()))
+	if err != nil {
+		slog.Warn("error deleting product of cache", "product_id", id, "error", err)
+	}
+
+	err = is.cache.Delete(ctx, cachekeys.AllProducts())
+	if err != nil {
+		slog.Warn("error invalidating list of all products", "error", err)
+	}
+
+	err = is.cache.DeleteByPrefix(ctx, cachekeys.ProductQueriesPrefix())
+	if err != nil {
+		slog.Warn("error invalidating product search pages", "error", err)
+	}
+}
+
+// RecordMovement implements ports.InventoryService.
+func (is *InventoryService) RecordMovement(ctx context.Context, productID uuid.UUID, inputs ports_dtos.StockMovementInputs) (*domain.StockMovement, error) {
+	reason := strings.TrimSpace(inputs.Reason)
+	if reason == "" {
+		return nil, domain.ErrStockMovementReasonIsRequire
+	}
+
+	movement, err := domain.NewStockMovement(productID, inputs.VariantID, inputs.WarehouseID, domain.StockMovementType(inputs.Type), inputs.Quantity, reason, inputs.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// reservations, releases and sales are recorded by the orders
+	if !movement.Type.IsManual() {
+		return nil, domain.ErrStockMovementNotManual
+	}
+
+	product, err := is.productRepo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if inputs.VariantID != nil {
+		if _, err := product.FindVariant(*inputs.VariantID); err != nil {
+			return nil, err
+		}
+	}
+	// without warehouse the movement goes to the default one
+	if inputs.WarehouseID != nil {
+		if _, err := is.warehouseRepo.GetWarehouseById(ctx, *inputs.WarehouseID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := is.repo.SaveMovement(ctx, movement)
+	if err != nil {
+		return nil, err
+	}
+
+	is.stockChanged(ctx, productID)
+	return result, nil
+}
+
+// ListMovements implements ports.InventoryService.
+// The ledger of archived products can still be read
+func (is *InventoryService) ListMovements(ctx context.Context, productID uuid.UUID) ([]domain.StockMovement, error) {
+	if _, err := is.productRepo.GetProductIncludingArchived(ctx, productID); err != nil {
+		return nil, err
+	}
+	return is.repo.ListMovements(ctx, productID)
+}
+
+// StockAt implements ports.InventoryService.
+func (is *InventoryService) StockAt(ctx context.Context, productID uuid.UUID, at time.Time) (*domain.StockReport, error) {
+	if _, err := is.productRepo.GetProductIncludingArchived(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	levels, err := is.repo.StockAt(ctx, productID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.NewStockReport(productID, at, levels), nil
+}
+
+// AuditStock implements ports.InventoryService.
+func (is *InventoryService) AuditStock(ctx context.Context) ([]domain.StockDiscrepancy, error) {
+	return is.repo.AuditStock(ctx)
+}
+
+// RecordSale implements ports.InventoryService.
+// The allocations of the order are sold in their warehouses. A rejected payment releases the allocations and can be
+// followed by an approved one, in that case the stock is reserved again in the same warehouses before it's sold.
+// Orders created before the warehouses existed have no allocations, every item is recorded even if one of them fails
+// and the first error is returned
+func (is *InventoryService) RecordSale(ctx context.Context, order *domain.Order) error {
+	allocations, err := is.repo.ListOrderAllocations(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(allocations) > 0 {
+		if allReleased(allocations) {
+			allocations, err = is.reserveAgain(ctx, order.ID, allocations)
+			if err != nil {
+				return err
+			}
+		}
+		return is.repo.CloseAllocations(ctx, allocations, domain.AllocationSold)
+	}
+
+	var firstErr error
+	for _, item := range order.Items {
+		movement, err := domain.NewStockMovement(item.ProductID, item.VariantID, nil, domain.StockSale, int64(item.Quantity), "order "+order.ID.String()+" paid", nil)
+		if err == nil {
+			_, err = is.repo.SaveMovement(ctx, movement)
+		}
+		if err != nil {
+			slog.Error("error recording sale in the stock ledger", "order_id", order.ID, "product_id", item.ProductID, "variant_id", item.VariantID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// ReserveItems implements ports.InventoryService.
+func (is *InventoryService) ReserveItems(ctx context.Context, items []domain.CartItem, rule domain.AllocationRule, shipTo *domain.GeoPoint) ([]domain.StockAllocation, error) {
+	allocationItems := make([]domain.AllocationItem, 0, len(items))
+	productIDs := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		product, err := is.productRepo.GetProductById(ctx, item.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if item.VariantID != nil {
+			if _, err := product.FindVariant(*item.VariantID); err != nil {
+				return nil, err
+			}
+		}
+
+		allocationItems = append(allocationItems, domain.AllocationItem{ProductID: item.ProductID, VariantID: item.VariantID, Quantity: int64(item.Quantity)})
+		productIDs = append(productIDs, item.ProductID)
+	}
+
+	warehouses, err := is.warehouseRepo.ListWarehouses(ctx)
+	if err != nil {
+		return nil, err
+	}
+	levels, err := is.warehouseRepo.ListStockLevels(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	allocations, err := domain.AllocateStock(allocationItems, warehouses, levels, rule, shipTo)
+	if err != nil {
+		return nil, err
+	}
+
+	// the stock can change between the read of the levels and the reservation, the repository checks it again
+	reserved, err := is.repo.ReserveAllocations(ctx, allocations)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, id := range productIDs {
+		is.stockChanged(ctx, id)
+	}
+	return reserved, nil
+}
+
+// AssignOrder implements ports.InventoryService.
+func (is *InventoryService) AssignOrder(ctx context.Context, allocations []domain.StockAllocation, orderID uuid.UUID) error {
+	ids := make([]uuid.UUID, 0, len(allocations))
+	for _, allocation := range allocations {
+		ids = append(ids, allocation.ID)
+	}
+	return is.repo.AssignAllocations(ctx, ids, orderID)
+}
+
+// ReleaseAllocations implements ports.InventoryService.
+func (is *InventoryService) ReleaseAllocations(ctx context.Context, allocations []domain.StockAllocation) error {
+	if err := is.repo.CloseAllocations(ctx, allocations, domain.AllocationReleased); err != nil {
+		return err
+	}
+
+	for _, allocation := range allocations {
+		is.stockChanged(ctx, allocation.ProductID)
+	}
+	return nil
+}
+
+// ReleaseOrder implements ports.InventoryService.
+// Orders created before the warehouses existed have no allocations, their items go back to the default warehouse
+func (is *InventoryService) ReleaseOrder(ctx context.Context, order *domain.Order) error {
+	allocations, err := is.repo.ListOrderAllocations(ctx, order.ID)
+	if err != nil {
+		return err
+	}
+	if len(allocations) > 0 {
+		return is.ReleaseAllocations(ctx, allocations)
+	}
+
+	var firstErr error
+	for _, item := range order.Items {
+		movement, err := domain.NewStockMovement(item.ProductID, item.VariantID, nil, domain.StockRelease, int64(item.Quantity), "reservation released", nil)
+		if err == nil {
+			_, err = is.repo.SaveMovement(ctx, movement)
+		}
+		if err != nil {
+			slog.Error("error releasing stock of cancelled order", "order_id", order.ID, "product_id", item.ProductID, "variant_id", item.VariantID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		is.stockChanged(ctx, item.ProductID)
+	}
+	return firstErr
+}
+
+// helper func, reserves again the released allocations of an order in the same warehouses
+func (is *InventoryService) reserveAgain(ctx context.Context, orderID uuid.UUID, released []domain.StockAllocation) ([]domain.StockAllocation, error) {
+	allocations := make([]domain.StockAllocation, 0, len(released))
+	for _, allocation := range released {
+		allocation.ID = uuid.Nil
+		allocation.OrderID = &orderID
+		allocation.Status = domain.AllocationReserved
+		allocation.CreatedAt, allocation.UpdatedAt = time.Time{}, time.Time{}
+		allocations = append(allocations, allocation)
+	}
+
+	reserved, err := is.repo.ReserveAllocations(ctx, allocations)
+	if err != nil {
+		return nil, err
+	}
+	for _, allocation := range reserved {
+		is.stockChanged(ctx, allocation.ProductID)
+	}
+	return reserved, nil
+}
+
+// helper func, reports if none of the allocations keeps its stock reserved or sold
+func allReleased(allocations []domain.StockAllocation) bool {
+	for _, allocation := range allocations {
+		if allocation.Status != domain.AllocationReleased {
+			return false
+		}
+	}
+	return true
+}
+
+// ListOrderAllocations implements ports.InventoryService.
+func (is *InventoryService) ListOrderAllocations(ctx context.Context, orderID uuid.UUID) ([]domain.StockAllocation, error) {
+	return is.repo.ListOrderAllocations(ctx, orderID)
+}
@@ -0,0 +1,157 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_InventoryService_Ledger(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodRepo := repository.NewProductRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, repository.NewUserRepo(tx), &mocks.MockNotifier{})
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), repository.NewWarehouseRepo(tx), prodRepo, stockAlertSrv, redis)
+
+	category, err := categSrv.SaveCategory(ctx, 0, "Consoles", nil)
+	require.NoError(t, err)
+
+	seller := uuid.New()
+	inputs := testhelpers.NewDomainProduct("Playstation 5", category.ID).ToInputs()
+	inputs.UpdatedBy = &seller
+	product, err := prodSrv.SaveProduct(ctx, inputs)
+	require.NoError(t, err)
+	createdAt := time.Now()
+
+	t.Run("records the stock changes of the products and orders", func(t *testing.T) {
+		// the stock isn't updated with the product, it's adjusted in the ledger
+		stock := int64(80)
+		_, err := prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{ID: product.ID, Stock: &stock, UpdatedBy: &seller})
+		assert.ErrorIs(t, err, domain.ErrProductStockNotEditable)
+
+		adjustment, err := inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{
+			Type: string(domain.StockAdjustment), Quantity: -20, Reason: "count", ActorID: &seller,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(80), adjustment.Balance)
+
+		_, err = inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: product.ID, Quantity: 2}}, domain.AllocateMostStock, nil)
+		require.NoError(t, err)
+		released, err := inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: product.ID, Quantity: 1}}, domain.AllocateMostStock, nil)
+		require.NoError(t, err)
+		require.NoError(t, inventorySrv.ReleaseAllocations(ctx, released))
+
+		movements, err := inventorySrv.ListMovements(ctx, product.ID)
+		require.NoError(t, err)
+		require.Len(t, movements, 5)
+
+		assert.Equal(t, domain.StockReceipt, movements[0].Type)
+		assert.Equal(t, int64(100), movements[0].Balance)
+		assert.Equal(t, domain.StockAdjustment, movements[1].Type)
+		assert.Equal(t, int64(-20), movements[1].Delta)
+		assert.Equal(t, &seller, movements[1].ActorID)
+		assert.Equal(t, domain.StockReservation, movements[2].Type)
+		assert.Equal(t, domain.StockReservation, movements[3].Type)
+		assert.Equal(t, domain.StockRelease, movements[4].Type)
+		assert.Equal(t, int64(78), movements[4].Balance)
+	})
+
+	t.Run("records manual movements", func(t *testing.T) {
+		receipt, err := inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{
+			Type: string(domain.StockReceipt), Quantity: 10, Reason: "supplier delivery", ActorID: &seller,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(88), receipt.Balance)
+
+		adjustment, err := inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{
+			Type: string(domain.StockAdjustment), Quantity: -8, Reason: "damaged units",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), adjustment.Quantity)
+		assert.Equal(t, int64(80), adjustment.Balance)
+
+		_, err = inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{Type: string(domain.StockReturn), Quantity: 1})
+		assert.ErrorIs(t, err, domain.ErrStockMovementReasonIsRequire)
+
+		_, err = inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{Type: string(domain.StockSale), Quantity: 1, Reason: "sold"})
+		assert.ErrorIs(t, err, domain.ErrStockMovementNotManual)
+
+		_, err = inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{Type: string(domain.StockReceipt), Quantity: -1, Reason: "supplier delivery"})
+		assert.ErrorIs(t, err, domain.ErrStockMovementQuantity)
+
+		_, err = inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{Type: string(domain.StockAdjustment), Quantity: -81, Reason: "count"})
+		assert.ErrorIs(t, err, domain.ErrProductInsufficientStock)
+
+		current, err := prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(80), current.Stock)
+	})
+
+	t.Run("records the stock of the variants and the sales", func(t *testing.T) {
+		sku, variantStock := "PS5-DIGITAL-"+uuid.NewString()[:8], int64(5)
+		variant, err := prodSrv.SaveVariant(ctx, product.ID, ports_dtos.SaveVariantInputs{
+			SKU: &sku, Options: map[string]string{"edition": "digital"}, Stock: &variantStock,
+		})
+		require.NoError(t, err)
+
+		allocations, err := inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: product.ID, VariantID: &variant.ID, Quantity: 2}}, domain.AllocateMostStock, nil)
+		require.NoError(t, err)
+
+		order := &domain.Order{ID: uuid.New(), Items: []domain.OrderProduct{{ProductID: product.ID, VariantID: &variant.ID, Quantity: 2}}}
+		require.NoError(t, inventorySrv.AssignOrder(ctx, allocations, order.ID))
+		require.NoError(t, inventorySrv.RecordSale(ctx, order))
+
+		report, err := inventorySrv.StockAt(ctx, product.ID, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(80), report.Stock)
+		require.Len(t, report.Variants, 1)
+		assert.Equal(t, int64(3), report.Variants[0].Stock)
+
+		movements, err := inventorySrv.ListMovements(ctx, product.ID)
+		require.NoError(t, err)
+		sale := movements[len(movements)-1]
+		assert.Equal(t, domain.StockSale, sale.Type)
+		assert.Equal(t, int64(2), sale.Quantity)
+		assert.Equal(t, int64(0), sale.Delta)
+		assert.Equal(t, int64(3), sale.Balance)
+	})
+
+	t.Run("reports the stock at a point in time and audits it", func(t *testing.T) {
+		report, err := inventorySrv.StockAt(ctx, product.ID, createdAt)
+		require.NoError(t, err)
+		assert.Equal(t, int64(100), report.Stock)
+		assert.Empty(t, report.Variants)
+
+		discrepancies, err := inventorySrv.AuditStock(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, discrepancies)
+
+		// a write that bypasses the ledger
+		require.NoError(t, tx.Model(&models.ProductModel{}).Where("id = ?", product.ID).Update("stock", 50).Error)
+
+		discrepancies, err = inventorySrv.AuditStock(ctx)
+		require.NoError(t, err)
+		require.Len(t, discrepancies, 1)
+		assert.Equal(t, product.ID, discrepancies[0].ProductID)
+		assert.Nil(t, discrepancies[0].VariantID)
+		assert.Equal(t, int64(50), discrepancies[0].Stock)
+		assert.Equal(t, int64(80), discrepancies[0].LedgerStock)
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+	"time"
+)
+
+// Batches of the dispatch, a notification claimed by a dispatcher that died is retried after the lease
+const (
+	notificationBatchSize = 50
+	notificationLease     = 5 * time.Minute
+)
+
+type NotificationService struct {
+	repo     ports.NotificationRepository
+	userRepo ports.UserRepository
+	sender   ports.Notifier
+
+	// attempts of a notification before it's marked as failed
+	maxAttempts int
+}
+
+// NewNotificationService creates the queue of the notifications, they are delivered by the sender
+func NewNotificationService(repo ports.NotificationRepository, userRepo ports.UserRepository, sender ports.Notifier, maxAttempts int) ports.NotificationService {
+	return &NotificationService{
+		repo:        repo,
+		userRepo:    userRepo,
+		sender:      sender,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Notify implements ports.NotificationService.
+// The recipient is taken from the user when the notification doesn't have it
+func (ns *NotificationService) Notify(ctx context.Context, notification domain.Notification) error {
+	if !notification.HasRecipient() {
+		user, err := ns.userRepo.GetUserByID(ctx, notification.UserID)
+		if err != nil {
+			return err
+		}
+		notification.To = user.Email
+		notification.Name = user.Name
+		if notification.Locale == "" {
+			notification.Locale = user.Locale
+		}
+	}
+
+	queued, err := domain.NewQueuedNotification(notification)
+	if err != nil {
+		return err
+	}
+
+	_, err = ns.repo.Enqueue(ctx, queued)
+	return err
+}
+
+// Dispatch implements ports.NotificationService.
+func (ns *NotificationService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	notifications, err := ns.repo.ClaimDue(ctx, now, notificationLease, notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var firstErr error
+	for _, queued := range notifications {
+		err := ns.sender.Notify(ctx, queued.Notification)
+		if err != nil {
+			queued.MarkFailed(err, time.Now(), ns.maxAttempts)
+			slog.Warn("error sending notification", "notification_id", queued.ID, "kind", queued.Notification.Kind, "attempts", queued.Attempts, "status", queued.Status, "error", err)
+		} else {
+			queued.MarkSent(time.Now())
+			sent++
+		}
+
+		if err := ns.repo.SaveAttempt(ctx, queued); err != nil {
+			slog.Error("error saving attempt of notification", "notification_id", queued.ID, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	return sent, firstErr
+}
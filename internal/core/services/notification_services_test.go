@@ -0,0 +1,116 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NotificationService_Queue(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	sender := &mocks.MockNotifier{}
+	userRepo := repository.NewUserRepo(tx)
+	notificationSrv := services.NewNotificationService(repository.NewNotificationRepo(tx), userRepo, sender, 3)
+
+	user := testhelpers.NewDomainUser("John", "john-"+uuid.NewString()[:8]+"@test.com")
+	user.Locale = domain.LocaleEN
+	user, err := userRepo.SaveUser(ctx, user)
+	require.NoError(t, err)
+
+	statusOf := func(kind domain.NotificationKind) *models.NotificationModel {
+		var notification models.NotificationModel
+		require.NoError(t, tx.Where("kind = ?", kind).First(&notification).Error)
+		return &notification
+	}
+
+	t.Run("queues the notification and sends it on dispatch", func(t *testing.T) {
+		err := notificationSrv.Notify(ctx, domain.Notification{
+			Kind:   domain.NotificationOrderCreated,
+			UserID: user.ID,
+			Data:   map[string]any{"total": 1500.5},
+		})
+		require.NoError(t, err)
+		assert.Empty(t, sender.Notifications)
+
+		sent, err := notificationSrv.Dispatch(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+
+		// the recipient is taken from the user
+		notifications := sender.Sent(domain.NotificationOrderCreated)
+		require.Len(t, notifications, 1)
+		assert.Equal(t, user.Email, notifications[0].To)
+		assert.Equal(t, "John", notifications[0].Name)
+		assert.Equal(t, domain.LocaleEN, notifications[0].Locale)
+		assert.Equal(t, 1500.5, notifications[0].Data["total"])
+
+		queued := statusOf(domain.NotificationOrderCreated)
+		assert.Equal(t, domain.NotificationSent, queued.Status)
+		assert.NotNil(t, queued.SentAt)
+
+		// it isn't sent again
+		sent, err = notificationSrv.Dispatch(ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+	})
+
+	t.Run("retries the failed notifications with backoff until the max attempts", func(t *testing.T) {
+		sender.Fail(errors.New("connection refused"))
+		require.NoError(t, notificationSrv.Notify(ctx, domain.NewNotification(domain.NotificationWelcome, user, nil)))
+
+		now := time.Now()
+		sent, err := notificationSrv.Dispatch(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+
+		queued := statusOf(domain.NotificationWelcome)
+		assert.Equal(t, domain.NotificationPending, queued.Status)
+		assert.Equal(t, 1, queued.Attempts)
+		require.NotNil(t, queued.LastError)
+		assert.Equal(t, "connection refused", *queued.LastError)
+
+		// not due yet
+		sent, err = notificationSrv.Dispatch(ctx, now.Add(30*time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Equal(t, 1, statusOf(domain.NotificationWelcome).Attempts)
+
+		// the second attempt fails, the third one waits twice as long
+		_, err = notificationSrv.Dispatch(ctx, now.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 2, statusOf(domain.NotificationWelcome).Attempts)
+
+		_, err = notificationSrv.Dispatch(ctx, now.Add(5*time.Minute))
+		require.NoError(t, err)
+		queued = statusOf(domain.NotificationWelcome)
+		assert.Equal(t, 3, queued.Attempts)
+		assert.Equal(t, domain.NotificationFailed, queued.Status)
+
+		// a failed notification isn't retried anymore
+		sender.Fail(nil)
+		sent, err = notificationSrv.Dispatch(ctx, now.Add(24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, sent)
+		assert.Empty(t, sender.Sent(domain.NotificationWelcome))
+	})
+
+	t.Run("rejects the notifications of unknown users", func(t *testing.T) {
+		err := notificationSrv.Notify(ctx, domain.Notification{Kind: domain.NotificationWelcome, UserID: uuid.New()})
+		assert.ErrorIs(t, err, domain.ErrUserNotFound)
+	})
+}
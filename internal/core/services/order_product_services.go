@@ -17,8 +17,8 @@ func NewOrderProductService(repo ports.OrderProductRepository) ports.OrderProduc
 }
 
 // AddProductToOrder implements ports.OrderProductService.
-func (ops *OrderProductService) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, quantity int16) (*domain.OrderProduct, error) {
-	orderProduct := domain.NewOrderProduct(orderID, productID, quantity)
+func (ops *OrderProductService) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, variantID *uuid.UUID, quantity int16, unitPrice, unitDiscount float64) (*domain.OrderProduct, error) {
+	orderProduct := domain.NewOrderProduct(orderID, productID, variantID, quantity, unitPrice, unitDiscount)
 	savedOrderProduct, err := ops.repo.SaveOrderProduct(ctx, orderProduct)
 	if err != nil {
 		return nil, err
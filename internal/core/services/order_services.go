@@ -8,23 +8,76 @@ import (
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
 	"log/slog"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type OrderService struct {
-	orderRepo ports.OrderRepository
-	ops       ports.OrderProductService
-	cart      ports.CartService
-	cache     ports.CacheRepository
+	orderRepo   ports.OrderRepository
+	attemptRepo ports.PaymentAttemptRepository
+	ops         ports.OrderProductService
+	cart        ports.CartService
+	ps          ports.ProductService
+	inventory   ports.InventoryService
+	mp          ports.PaymentProvider
+	payments    ports.PaymentService
+	notifier    ports.Notifier
+	recovery    ports.CartRecoveryService
+	cache       ports.CacheRepository
 }
 
-func NewOrderService(orderRepo ports.OrderRepository, ops ports.OrderProductService, cart ports.CartService, cache ports.CacheRepository) ports.OrderService {
+func NewOrderService(orderRepo ports.OrderRepository, attemptRepo ports.PaymentAttemptRepository, ops ports.OrderProductService, cart ports.CartService, ps ports.ProductService, inventory ports.InventoryService, mp ports.PaymentProvider, payments ports.PaymentService, notifier ports.Notifier, recovery ports.CartRecoveryService, cache ports.CacheRepository) ports.OrderService {
 	return &OrderService{
-		orderRepo: orderRepo,
-		ops:       ops,
-		cart:      cart,
-		cache:     cache,
+		orderRepo:   orderRepo,
+		attemptRepo: attemptRepo,
+		ops:         ops,
+		cart:        cart,
+		ps:          ps,
+		inventory:   inventory,
+		mp:          mp,
+		payments:    payments,
+		notifier:    notifier,
+		recovery:    recovery,
+		cache:       cache,
+	}
+}
+
+// helper func, caches the order and invalidates the list of orders
+func (os *OrderService) refreshOrderCache(ctx context.Context, order *domain.Order) {
+	orderSerialized, err := json.Marshal(order)
+	if err != nil {
+		slog.Warn("error marshaling order for cache", "order_id", order.ID, "error", err)
+	} else {
+		err = os.cache.Set(ctx, cachekeys.Order(order.ID.String()), orderSerialized, cachettl.Order)
+		if err != nil {
+			slog.Warn("error caching order", "order_id", order.ID, "error", err)
+		}
+	}
+
+	err = os.cache.Delete(ctx, cachekeys.AllOrders())
+	if err != nil {
+		slog.Warn("error invalidating list of all orders", "error", err)
+	}
+}
+
+// helper func, undoes an order that couldn't be completed at checkout, its stock goes back to the warehouses and the
+// order is deleted so the buyer can check out again. The cart is kept
+func (os *OrderService) discardOrder(ctx context.Context, orderID uuid.UUID, allocations []domain.StockAllocation) {
+	if err := os.inventory.ReleaseAllocations(ctx, allocations); err != nil {
+		slog.Error("error releasing reserved stock", "order_id", orderID, "error", err)
+	}
+	if err := os.orderRepo.DeleteOrder(ctx, orderID); err != nil {
+		slog.Error("error deleting incomplete order", "order_id", orderID, "error", err)
+	}
+}
+
+// helper func, notifies the buyer of the order. A failure doesn't undo the change of the order
+func (os *OrderService) notifyBuyer(ctx context.Context, kind domain.NotificationKind, order *domain.Order, data map[string]any) {
+	data["order_id"] = order.ID
+	err := os.notifier.Notify(ctx, domain.Notification{Kind: kind, UserID: order.UserID, Data: data})
+	if err != nil {
+		slog.Error("error notifying buyer of order", "order_id", order.ID, "kind", kind, "error", err)
 	}
 }
 
@@ -74,25 +127,58 @@ func (os *OrderService) SaveOrder(ctx context.Context, inputs ports.SaveOrderInp
 		order = existingOrder
 	}
 
+	// reserve the stock of the items in the warehouses before creating the order
+	var allocations []domain.StockAllocation
+	if inputs.ID == uuid.Nil {
+		allocations, err = os.inventory.ReserveItems(ctx, cart.Items, inputs.Allocation, inputs.ShipTo)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	result, err := os.orderRepo.SaveOrder(ctx, order)
 	if err != nil {
+		if inputs.ID == uuid.Nil {
+			if releaseErr := os.inventory.ReleaseAllocations(ctx, allocations); releaseErr != nil {
+				slog.Error("error releasing reserved stock", "error", releaseErr)
+			}
+		}
 		return nil, err
 	}
 
 	// if a order was created, creates order-product for each item of cart
 	if inputs.ID == uuid.Nil {
-		for _, item := range cart.Items {
-			_, err := os.ops.AddProductToOrder(ctx, result.ID, item.ProductID, item.Quantity)
+		// an order without its allocations couldn't release or sell its stock
+		err := os.inventory.AssignOrder(ctx, allocations, result.ID)
+		if err != nil {
+			os.discardOrder(ctx, result.ID, allocations)
+			return nil, err
+		}
+
+		for _, line := range amount.Lines {
+			_, err := os.ops.AddProductToOrder(ctx, result.ID, line.ProductID, line.VariantID, line.Quantity, line.UnitPrice, line.UnitDiscount)
 			if err != nil {
+				os.discardOrder(ctx, result.ID, allocations)
 				return nil, err
 			}
 		}
 
 		// if all is ok, clean cart
-		err := os.cart.Clear(ctx, inputs.UserID)
+		err = os.cart.Clear(ctx, inputs.UserID)
 		if err != nil {
 			slog.Error("error cleaning cart", "UserID", inputs.UserID, "error", err)
 		}
+
+		os.notifyBuyer(ctx, domain.NotificationOrderCreated, result, map[string]any{
+			"total":    result.Total,
+			"currency": result.Currency,
+			"items":    len(cart.Items),
+		})
+
+		// the order converts the last reminder of an abandoned cart of the buyer
+		if err := os.recovery.MarkRecovered(ctx, result); err != nil {
+			slog.Error("error marking cart as recovered", "order_id", result.ID, "error", err)
+		}
 	}
 
 	// create new order cache key and serialize order created or udpated
@@ -167,3 +253,96 @@ func (os *OrderService) ListOrders(ctx context.Context) ([]*domain.Order, error)
 
 	return orders, nil
 }
+
+// CancelOrder implements ports.OrderService.
+func (os *OrderService) CancelOrder(ctx context.Context, id uuid.UUID, actor *domain.User, reason string) (*domain.Order, error) {
+	order, err := os.orderRepo.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// an authorized payment holds funds of the buyer, it must be voided at the provider
+	wasAuthorized := order.PayStatus == domain.Authorized
+
+	err = order.Cancel(actor, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	if wasAuthorized && order.PaymentID != nil {
+		err := os.mp.CancelPayment(ctx, *order.PaymentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := os.orderRepo.SaveOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	// release the stock reserved in the warehouses when the order was created
+	err = os.inventory.ReleaseOrder(ctx, order)
+	if err != nil {
+		slog.Error("error releasing stock of cancelled order", "order_id", order.ID, "error", err)
+	}
+
+	os.refreshOrderCache(ctx, result)
+
+	return result, nil
+}
+
+// ShipOrder implements ports.OrderService.
+func (os *OrderService) ShipOrder(ctx context.Context, id uuid.UUID, actor *domain.User, inputs domain.ShipOrderInputs) (*domain.Order, error) {
+	order, err := os.orderRepo.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = order.CanBeShipped(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// the funds of an authorized order are captured when it's shipped, a failed capture doesn't ship it
+	if order.CanBeCaptured(time.Now()) == nil {
+		order, err = os.payments.Capture(ctx, order.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = order.Ship(actor, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := os.orderRepo.SaveOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+
+	os.refreshOrderCache(ctx, result)
+
+	trackingNumber := ""
+	if result.TrackingNumber != nil {
+		trackingNumber = *result.TrackingNumber
+	}
+	os.notifyBuyer(ctx, domain.NotificationOrderShipped, result, map[string]any{
+		"carrier":         inputs.Carrier,
+		"tracking_number": trackingNumber,
+	})
+
+	return result, nil
+}
+
+// ListPaymentAttempts implements ports.OrderService.
+func (os *OrderService) ListPaymentAttempts(ctx context.Context, id uuid.UUID) ([]*domain.PaymentAttempt, error) {
+	// validates that the order exists, an unknown order has no attempts
+	_, err := os.orderRepo.GetOrderById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.attemptRepo.ListAttemptsByOrder(ctx, id)
+}
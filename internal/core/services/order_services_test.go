@@ -2,6 +2,8 @@ package services_test
 
 import (
 	"context"
+	"errors"
+	"go-ecommerce/internal/adapters/mercadopago/mp_dtos"
 	"go-ecommerce/internal/adapters/security"
 	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
 	"go-ecommerce/internal/core/domain"
@@ -11,19 +13,53 @@ import (
 	testhelpers "go-ecommerce/internal/test_helpers"
 	"go-ecommerce/internal/test_helpers/mocks"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// failingOrderProducts fails to add the lines of the orders while err is set
+type failingOrderProducts struct {
+	ports.OrderProductService
+	err error
+}
+
+func (f *failingOrderProducts) AddProductToOrder(ctx context.Context, orderID, productID uuid.UUID, variantID *uuid.UUID, quantity int16, unitPrice, unitDiscount float64) (*domain.OrderProduct, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.OrderProductService.AddProductToOrder(ctx, orderID, productID, variantID, quantity, unitPrice, unitDiscount)
+}
+
+// failingInventory fails to assign the allocations to the orders while assignErr is set
+type failingInventory struct {
+	ports.InventoryService
+	assignErr error
+}
+
+func (f *failingInventory) AssignOrder(ctx context.Context, allocations []domain.StockAllocation, orderID uuid.UUID) error {
+	if f.assignErr != nil {
+		return f.assignErr
+	}
+	return f.InventoryService.AssignOrder(ctx, allocations, orderID)
+}
+
 type depToTestingOrderSrv struct {
 	userSrv    ports.UserService
 	opSrv      ports.OrderProductService
+	orderLines *failingOrderProducts
+	inventory  *failingInventory
 	productSrv ports.ProductService
 	categSrv   ports.CategoryService
 	cartSrv    ports.CartService
 	orderSrv   ports.OrderService
+	orderRepo  ports.OrderRepository
+	mp         *mocks.MockPaymentProvider
+	notifier   *mocks.MockNotifier
+	recovery   ports.CartRecoveryService
+	reportSrv  ports.ReportService
 }
 
 func newOrderSrvTest(t *testing.T) *depToTestingOrderSrv {
@@ -43,20 +79,38 @@ func newOrderSrvTest(t *testing.T) *depToTestingOrderSrv {
 	orderRepo := repository.NewOrderRepo(orderProdSrv, tx).(*repository.OrderRepo)
 
 	// services
-	userSrv := services.NewUserService(userRepo, redis, hasher)
+	notifier := &mocks.MockNotifier{}
+	userSrv := services.NewUserService(userRepo, redis, hasher, notifier)
 	opSrv := services.NewOrderProductService(orderProdRepo)
 	productSrv := services.NewProductService(prodRepo, redis)
 	categSrv := services.NewCategoryService(categRepo, redis)
-	cartSrv := services.NewCartService(redis, productSrv)
-	orderSrv := services.NewOrderService(orderRepo, orderProdSrv, cartSrv, redis)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, productSrv)
+	mp := &mocks.MockPaymentProvider{}
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, repository.NewUserRepo(tx), &mocks.MockNotifier{})
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), repository.NewWarehouseRepo(tx), prodRepo, stockAlertSrv, redis)
+	recovery := services.NewCartRecoveryService(repository.NewCartRecoveryRepo(tx), cartSrv, notifier, security.NewSigner("secret"), time.Hour, 24*time.Hour, "http://shop.test/cart/recover")
+	orderLines := &failingOrderProducts{OrderProductService: orderProdSrv}
+	inventory := &failingInventory{InventoryService: inventorySrv}
+	attemptRepo := repository.NewPaymentAttemptRepo(tx)
+	alerter := &mocks.MockAlerter{}
+	disputeSrv := services.NewDisputeService(repository.NewDisputeRepo(tx), orderRepo, alerter, redis)
+	paymentSrv := services.NewPaymentService(userRepo, orderRepo, attemptRepo, prodRepo, mp, disputeSrv, inventorySrv, alerter, notifier, 0.01)
+	orderSrv := services.NewOrderService(orderRepo, attemptRepo, orderLines, cartSrv, productSrv, inventory, mp, paymentSrv, notifier, recovery, redis)
 
 	srvs := &depToTestingOrderSrv{
 		userSrv:    userSrv,
 		opSrv:      opSrv,
+		orderLines: orderLines,
+		inventory:  inventory,
 		productSrv: productSrv,
 		categSrv:   categSrv,
 		cartSrv:    cartSrv,
 		orderSrv:   orderSrv,
+		orderRepo:  orderRepo,
+		mp:         mp,
+		notifier:   notifier,
+		recovery:   recovery,
+		reportSrv:  services.NewReportService(repository.NewReportRepo(tx)),
 	}
 
 	return srvs
@@ -83,9 +137,15 @@ func Test_OrderServices_Create(t *testing.T) {
 	assert.Equal(t, u.Name, newUser.Name)
 	assert.Equal(t, u.Email, newUser.Email)
 
+	// the new user is welcomed
+	welcome := srv.notifier.Sent(domain.NotificationWelcome)
+	require.Len(t, welcome, 1)
+	assert.Equal(t, newUser.ID, welcome[0].UserID)
+	assert.Equal(t, u.Email, welcome[0].To)
+
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -107,7 +167,7 @@ func Test_OrderServices_Create(t *testing.T) {
 	assert.Equal(t, p.CategoryID, newProd.CategoryID)
 
 	// add products to cart before create the order
-	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 
 	// factory, create a new order
@@ -134,6 +194,68 @@ func Test_OrderServices_Create(t *testing.T) {
 	item := orderItems[0]
 	assert.Equal(t, newProd.ID, item.ProductID)
 	assert.Equal(t, int16(5), item.Quantity)
+	assert.Equal(t, newProd.Price, item.UnitPrice)
+
+	// the buyer is notified, the queue takes the recipient from the user
+	created := srv.notifier.Sent(domain.NotificationOrderCreated)
+	require.Len(t, created, 1)
+	assert.Equal(t, newUser.ID, created[0].UserID)
+	assert.Equal(t, newOrder.ID, created[0].Data["order_id"])
+	assert.Equal(t, newOrder.Total, created[0].Data["total"])
+}
+
+func Test_OrderServices_CreateFails(t *testing.T) {
+	srv := newOrderSrvTest(t)
+	ctx := context.Background()
+
+	u := testhelpers.NewDomainUser("John", "john@mail.test")
+	newUser, err := srv.userSrv.SaveUser(ctx, domain.SaveUserInputs{Name: &u.Name, Email: &u.Email, Password: &u.Password, Role: &u.Role})
+	require.NoError(t, err)
+
+	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+	p := testhelpers.NewDomainProduct("Ipad 14 pro", savedCateg.ID)
+	newProd, err := srv.productSrv.SaveProduct(ctx, p.ToInputs())
+	require.NoError(t, err)
+
+	require.NoError(t, srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5))
+
+	// helper, the checkout fails, the stock is released and the order deleted
+	assertDiscarded := func() {
+		_, err := srv.orderSrv.SaveOrder(ctx, ports.SaveOrderInputs{UserID: newUser.ID, Currency: domain.ARS})
+		require.Error(t, err)
+
+		prod, err := srv.productSrv.GetProductById(ctx, newProd.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.Stock, prod.Stock)
+
+		orders, err := srv.orderRepo.ListOrders(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, orders)
+		assert.Empty(t, srv.notifier.Sent(domain.NotificationOrderCreated))
+	}
+
+	// the allocations can't be linked to the order
+	srv.inventory.assignErr = errors.New("connection reset")
+	assertDiscarded()
+	srv.inventory.assignErr = nil
+
+	// the lines of the order can't be saved
+	srv.orderLines.err = errors.New("connection reset")
+	assertDiscarded()
+	srv.orderLines.err = nil
+
+	// the cart is kept so the buyer can check out again
+	newOrder, err := srv.orderSrv.SaveOrder(ctx, ports.SaveOrderInputs{UserID: newUser.ID, Currency: domain.ARS})
+	require.NoError(t, err)
+
+	prod, err := srv.productSrv.GetProductById(ctx, newProd.ID)
+	require.NoError(t, err)
+	assert.Equal(t, p.Stock-5, prod.Stock)
+
+	orderItems, err := srv.opSrv.GetByOrderID(ctx, newOrder.ID)
+	require.NoError(t, err)
+	assert.Len(t, orderItems, 1)
 }
 
 func Test_OrderServices_Update(t *testing.T) {
@@ -159,7 +281,7 @@ func Test_OrderServices_Update(t *testing.T) {
 
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -181,7 +303,7 @@ func Test_OrderServices_Update(t *testing.T) {
 	assert.Equal(t, p.CategoryID, newProd.CategoryID)
 
 	// add products to cart before create the order
-	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 
 	// factory, create a new order
@@ -226,7 +348,7 @@ func Test_OrderServices_Update(t *testing.T) {
 	}
 
 	// add products again because after save, the cart is cleaned
-	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 
 	// save updated order in db
@@ -261,7 +383,7 @@ func Test_OrderServices_GetByID(t *testing.T) {
 
 	// factory, create a new category to will use as foreign key of product
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -283,7 +405,7 @@ func Test_OrderServices_GetByID(t *testing.T) {
 	assert.Equal(t, p.CategoryID, newProd.CategoryID)
 
 	// add products to cart before create the order
-	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, 5)
+	err = srv.cartSrv.AddItemToCart(ctx, newUser.ID, newProd.ID, nil, 5)
 	require.NoError(t, err)
 
 	// factory, create a new order
@@ -318,3 +440,227 @@ func Test_OrderServices_GetByID(t *testing.T) {
 	assert.Equal(t, order.Total, newOrder.Total)
 	assert.Equal(t, order.UserID, newOrder.UserID)
 }
+
+func Test_OrderServices_Cancel(t *testing.T) {
+	t.Helper()
+
+	srv := newOrderSrvTest(t)
+	ctx := context.Background()
+
+	// factory users, the owner of the order, another client and an admin
+	owner := testhelpers.NewDomainUser("John", "john@mail.test")
+	other := testhelpers.NewDomainUser("Jane", "jane@mail.test")
+	admin := testhelpers.NewDomainUser("Admin", "admin@mail.test")
+	admin.Role = domain.Admin
+
+	savedUsers := make([]*domain.User, 0)
+	for _, u := range []*domain.User{owner, other, admin} {
+		newUser, err := srv.userSrv.SaveUser(ctx, domain.SaveUserInputs{
+			Name:     &u.Name,
+			Email:    &u.Email,
+			Password: &u.Password,
+			Role:     &u.Role,
+		})
+		require.NoError(t, err)
+		savedUsers = append(savedUsers, newUser)
+	}
+	savedOwner, savedOther, savedAdmin := savedUsers[0], savedUsers[1], savedUsers[2]
+
+	// factory, create a new category and product
+	c := testhelpers.NewDomainCategory("Tablets")
+	savedCateg, err := srv.categSrv.SaveCategory(ctx, 0, c.Name, nil)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Ipad 14 pro", savedCateg.ID)
+	newProd, err := srv.productSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+		Name:       &p.Name,
+		Image:      &p.Image,
+		SKU:        &p.SKU,
+		Price:      &p.Price,
+		Stock:      &p.Stock,
+		CategoryID: &savedCateg.ID,
+	})
+	require.NoError(t, err)
+
+	// helper, creates an order of 5 units for the owner
+	createOrder := func() *domain.Order {
+		err := srv.cartSrv.AddItemToCart(ctx, savedOwner.ID, newProd.ID, nil, 5)
+		require.NoError(t, err)
+
+		newOrder, err := srv.orderSrv.SaveOrder(ctx, ports.SaveOrderInputs{
+			UserID:   savedOwner.ID,
+			Currency: domain.ARS,
+		})
+		require.NoError(t, err)
+		return newOrder
+	}
+
+	t.Run("owner cancels a pending order and the stock is released", func(t *testing.T) {
+		newOrder := createOrder()
+
+		// stock was reserved when the order was created
+		prod, err := srv.productSrv.GetProductById(ctx, newProd.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.Stock-5, prod.Stock)
+
+		cancelled, err := srv.orderSrv.CancelOrder(ctx, newOrder.ID, savedOwner, "changed my mind")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Cancelled, cancelled.PayStatus)
+		assert.Equal(t, savedOwner.ID, *cancelled.CancelledBy)
+		assert.Equal(t, "changed my mind", *cancelled.CancelReason)
+
+		prod, err = srv.productSrv.GetProductById(ctx, newProd.ID)
+		require.NoError(t, err)
+		assert.Equal(t, p.Stock, prod.Stock)
+
+		// a cancelled order can't be cancelled again
+		_, err = srv.orderSrv.CancelOrder(ctx, newOrder.ID, savedOwner, "again")
+		assert.ErrorIs(t, err, domain.ErrOrderCannotBeCancelled)
+	})
+
+	t.Run("other clients can't cancel the order", func(t *testing.T) {
+		newOrder := createOrder()
+
+		_, err := srv.orderSrv.CancelOrder(ctx, newOrder.ID, savedOther, "not mine")
+		assert.ErrorIs(t, err, domain.ErrOrderCancelNotAllowed)
+	})
+
+	t.Run("admin cancels an authorized order and voids the payment", func(t *testing.T) {
+		newOrder := createOrder()
+
+		// simulate an authorized payment
+		paymentId := "123456"
+		newOrder.PayStatus = domain.Authorized
+		newOrder.PaymentID = &paymentId
+		_, err := srv.orderRepo.SaveOrder(ctx, newOrder)
+		require.NoError(t, err)
+
+		// the owner can't cancel an authorized order
+		_, err = srv.orderSrv.CancelOrder(ctx, newOrder.ID, savedOwner, "too late")
+		assert.ErrorIs(t, err, domain.ErrOrderCannotBeCancelled)
+
+		var voided string
+		srv.mp.CancelPaymentFunc = func(ctx context.Context, id string) error {
+			voided = id
+			return nil
+		}
+
+		cancelled, err := srv.orderSrv.CancelOrder(ctx, newOrder.ID, savedAdmin, "fraud suspicion")
+		require.NoError(t, err)
+		assert.Equal(t, domain.Cancelled, cancelled.PayStatus)
+		assert.Equal(t, paymentId, voided)
+	})
+}
+
+func Test_OrderServices_Ship(t *testing.T) {
+	srv := newOrderSrvTest(t)
+	ctx := context.Background()
+
+	buyer := testhelpers.NewDomainUser("John", "john@mail.test")
+	admin := testhelpers.NewDomainUser("Admin", "admin@mail.test")
+	admin.Role = domain.Admin
+	savedUsers := make([]*domain.User, 0)
+	for _, u := range []*domain.User{buyer, admin} {
+		newUser, err := srv.userSrv.SaveUser(ctx, domain.SaveUserInputs{
+			Name:     &u.Name,
+			Email:    &u.Email,
+			Password: &u.Password,
+			Role:     &u.Role,
+		})
+		require.NoError(t, err)
+		savedUsers = append(savedUsers, newUser)
+	}
+	savedBuyer, savedAdmin := savedUsers[0], savedUsers[1]
+
+	o := testhelpers.NewDomainOrder(savedBuyer.ID)
+	o.PayStatus = domain.Pending
+	o.Paid = false
+	order, err := srv.orderRepo.SaveOrder(ctx, o)
+	require.NoError(t, err)
+
+	tracking := "AR123456789"
+	inputs := domain.ShipOrderInputs{Carrier: "Correo Argentino", TrackingNumber: &tracking}
+
+	// an unpaid order can't be shipped
+	_, err = srv.orderSrv.ShipOrder(ctx, order.ID, savedAdmin, inputs)
+	assert.ErrorIs(t, err, domain.ErrOrderNotPaid)
+
+	now := time.Now()
+	order.PayStatus = domain.Approved
+	order.Paid = true
+	order.PaidAt = &now
+	_, err = srv.orderRepo.SaveOrder(ctx, order)
+	require.NoError(t, err)
+
+	_, err = srv.orderSrv.ShipOrder(ctx, order.ID, savedAdmin, domain.ShipOrderInputs{})
+	assert.ErrorIs(t, err, domain.ErrCarrierIsRequire)
+
+	shipped, err := srv.orderSrv.ShipOrder(ctx, order.ID, savedAdmin, inputs)
+	require.NoError(t, err)
+	require.NotNil(t, shipped.ShippedAt)
+	assert.Equal(t, savedAdmin.ID, *shipped.ShippedBy)
+	assert.Equal(t, "Correo Argentino", *shipped.Carrier)
+
+	stored, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tracking, *stored.TrackingNumber)
+
+	notifications := srv.notifier.Sent(domain.NotificationOrderShipped)
+	require.Len(t, notifications, 1)
+	assert.Equal(t, savedBuyer.ID, notifications[0].UserID)
+	assert.Equal(t, tracking, notifications[0].Data["tracking_number"])
+
+	// an order is shipped once
+	_, err = srv.orderSrv.ShipOrder(ctx, order.ID, savedAdmin, inputs)
+	assert.ErrorIs(t, err, domain.ErrOrderAlreadyShipped)
+	assert.Len(t, srv.notifier.Sent(domain.NotificationOrderShipped), 1)
+
+	t.Run("an authorized order is captured before it's shipped", func(t *testing.T) {
+		o := testhelpers.NewDomainOrder(savedBuyer.ID)
+		o.PayStatus = domain.Pending
+		o.Paid = false
+		o.Total = 1000
+		authorized, err := srv.orderRepo.SaveOrder(ctx, o)
+		require.NoError(t, err)
+		require.NoError(t, authorized.UpdateOrder(domain.UpdateOrderInputs{
+			PaymentID:         "987654",
+			PayStatus:         domain.Authorized,
+			PayStatusDetail:   domain.PendingCapture,
+			ExternalReference: authorized.ID.String(),
+		}))
+		_, err = srv.orderRepo.SaveOrder(ctx, authorized)
+		require.NoError(t, err)
+
+		// a failed capture doesn't ship the order
+		srv.mp.CapturePaymentFunc = func(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error) {
+			return nil, errors.New("provider unavailable")
+		}
+		_, err = srv.orderSrv.ShipOrder(ctx, authorized.ID, savedAdmin, inputs)
+		require.Error(t, err)
+
+		stored, err := srv.orderRepo.GetOrderById(ctx, authorized.ID)
+		require.NoError(t, err)
+		assert.Nil(t, stored.ShippedAt)
+		assert.Equal(t, domain.Authorized, stored.PayStatus)
+
+		var captured string
+		srv.mp.CapturePaymentFunc = func(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error) {
+			captured = paymentId
+			return &mp_dtos.MpSimplifiedPayment{
+				ID: 987654, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: amount,
+				TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950},
+			}, nil
+		}
+		shipped, err := srv.orderSrv.ShipOrder(ctx, authorized.ID, savedAdmin, inputs)
+		require.NoError(t, err)
+		assert.Equal(t, "987654", captured)
+		assert.True(t, shipped.Paid)
+		assert.Equal(t, domain.Approved, shipped.PayStatus)
+		require.NotNil(t, shipped.ShippedAt)
+
+		stored, err = srv.orderRepo.GetOrderById(ctx, authorized.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.Paid)
+		assert.NotNil(t, stored.ShippedAt)
+	})
+}
@@ -2,11 +2,11 @@ package services
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"go-ecommerce/internal/adapters/mercadopago/mp_dtos"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports"
+	"log/slog"
 	"strconv"
 	"time"
 
@@ -16,17 +16,89 @@ import (
 type PaymentService struct {
 	userRepo    ports.UserRepository
 	orderRepo   ports.OrderRepository
+	attemptRepo ports.PaymentAttemptRepository
 	productRepo ports.ProductRepository
 	mp          ports.PaymentProvider
+	disputes    ports.DisputeService
+	inventory   ports.InventoryService
+	alerter     ports.Alerter
+	notifier    ports.Notifier
+
+	// max difference accepted between the payment amount and the order total
+	amountTolerance float64
 }
 
-func NewPaymentService(userRepo ports.UserRepository, orderRepo ports.OrderRepository, productRepo ports.ProductRepository, mp ports.PaymentProvider) ports.PaymentService {
+// topic sent by mercado pago when a buyer disputes a payment
+const chargebacksTopic = "chargebacks"
+
+func NewPaymentService(userRepo ports.UserRepository, orderRepo ports.OrderRepository, attemptRepo ports.PaymentAttemptRepository, productRepo ports.ProductRepository, mp ports.PaymentProvider, disputes ports.DisputeService, inventory ports.InventoryService, alerter ports.Alerter, notifier ports.Notifier, amountTolerance float64) ports.PaymentService {
 	return &PaymentService{
 		userRepo:    userRepo,
 		orderRepo:   orderRepo,
+		attemptRepo: attemptRepo,
 		productRepo: productRepo,
 		mp:          mp,
+		disputes:    disputes,
+		inventory:   inventory,
+		alerter:     alerter,
+		notifier:    notifier,
+
+		amountTolerance: amountTolerance,
+	}
+}
+
+// helper func, records a dispute for a charged back payment instead of overwriting the order payment data
+func (p *PaymentService) registerChargeback(ctx context.Context, payment *mp_dtos.MpSimplifiedPayment, chargeback *mp_dtos.MpChargeback) error {
+	orderId, err := uuid.Parse(payment.ExternalReference)
+	if err != nil {
+		return err
+	}
+
+	_, err = p.attemptRepo.SaveAttempt(ctx, newPaymentAttempt(orderId, payment))
+	if err != nil {
+		return err
+	}
+
+	inputs := domain.NewDisputeInputs{
+		OrderID:   orderId,
+		PaymentID: strconv.Itoa(payment.ID),
+		Amount:    payment.TransactionAmount,
+		Currency:  domain.Currencies(payment.CurrencyID),
+		Reason:    string(payment.StatusDetail),
+	}
+
+	if chargeback != nil {
+		inputs.ChargebackID = chargeback.ID
+		inputs.Reason = chargeback.Reason
+		inputs.EvidenceDueAt = chargeback.DateDocumentationDeadline
+		if chargeback.Amount > 0 {
+			inputs.Amount = chargeback.Amount
+		}
+	}
+
+	_, err = p.disputes.RegisterChargeback(ctx, inputs)
+	return err
+}
+
+// helper func, a chargeback notification can contain more than one payment
+func (p *PaymentService) handleChargeback(ctx context.Context, chargebackId string) error {
+	chargeback, err := p.mp.GetChargeback(ctx, chargebackId)
+	if err != nil {
+		return err
 	}
+
+	for _, paymentId := range chargeback.Payments {
+		payment, err := p.mp.GetPayment(ctx, strconv.Itoa(paymentId))
+		if err != nil {
+			return err
+		}
+
+		err = p.registerChargeback(ctx, payment, chargeback)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // CreatePayment implements ports.PaymentProvider.
@@ -44,13 +116,14 @@ func (p *PaymentService) StartPayment(ctx context.Context, orderId uuid.UUID) (*
 	// search products of order and generate mp items
 	items := make([]mp_dtos.MpItem, 0)
 
+	// products archived after the order was placed are still charged
 	for _, orderItem := range order.Items {
-		product, err := p.productRepo.GetProductById(ctx, orderItem.ProductID)
+		product, err := p.productRepo.GetProductIncludingArchived(ctx, orderItem.ProductID)
 		if err != nil {
 			return nil, err
 		}
 
-		items = append(items, mp_dtos.MpItem{
+		item := mp_dtos.MpItem{
 			ID:          orderItem.ProductID.String(),
 			Title:       product.Name,
 			Description: product.SKU,
@@ -58,7 +131,21 @@ func (p *PaymentService) StartPayment(ctx context.Context, orderId uuid.UUID) (*
 			CurrencyID:  fmt.Sprint(order.Currency),
 			Quantity:    int(orderItem.Quantity),
 			UnitPrice:   product.Price,
-		})
+		}
+
+		// lines of a variant are charged with the SKU and price of the variant
+		if orderItem.VariantID != nil {
+			variant, err := product.FindVariant(*orderItem.VariantID)
+			if err != nil {
+				return nil, err
+			}
+			item.ID = variant.ID.String()
+			item.Title = fmt.Sprintf("%s (%s)", product.Name, variant.Label())
+			item.Description = variant.SKU
+			item.UnitPrice = product.UnitPrice(variant)
+		}
+
+		items = append(items, item)
 	}
 
 	// generate mercado pago preference
@@ -71,16 +158,228 @@ func (p *PaymentService) StartPayment(ctx context.Context, orderId uuid.UUID) (*
 	return redirectUrl, nil
 }
 
+// helper func, provider payment -> domain.PaymentAttempt
+func newPaymentAttempt(orderId uuid.UUID, payment *mp_dtos.MpSimplifiedPayment) *domain.PaymentAttempt {
+	return &domain.PaymentAttempt{
+		OrderID:           orderId,
+		PaymentID:         strconv.Itoa(payment.ID),
+		Status:            payment.Status,
+		StatusDetail:      payment.StatusDetail,
+		Amount:            payment.TransactionAmount,
+		NetReceivedAmount: payment.TransactionDetails.NetReceivedAmount,
+		Currency:          domain.Currencies(payment.CurrencyID),
+		PayMethod:         payment.PayMethod.ID,
+		PayResource:       payment.PayMethod.Type,
+		Installments:      payment.Installments,
+		RawPayload:        payment.Raw,
+	}
+}
+
+// helper func, applies a provider payment to its order. It's shared by webhooks and the reconciler,
+// returns true if the order was updated
+func (p *PaymentService) applyPayment(ctx context.Context, order *domain.Order, payment *mp_dtos.MpSimplifiedPayment) (bool, error) {
+	// a charged back payment opens a dispute, the order keeps the data of the original payment
+	if payment.Status == domain.ChargedBack {
+		return false, p.registerChargeback(ctx, payment, nil)
+	}
+
+	// a payment that charges a cancelled order is given back once, repeated notifications of it are ignored
+	attempt := newPaymentAttempt(order.ID, payment)
+	chargesCancelledOrder := false
+	if order.PayStatus == domain.Cancelled && !order.Disputed && (attempt.Status == domain.Approved || attempt.Status == domain.Authorized) {
+		recorded, err := p.isAttemptRecorded(ctx, attempt)
+		if err != nil {
+			return false, err
+		}
+		chargesCancelledOrder = !recorded
+	}
+
+	// every payment notified is recorded, a rejected attempt can be followed by an approved one
+	_, err := p.attemptRepo.SaveAttempt(ctx, attempt)
+	if err != nil {
+		return false, err
+	}
+
+	// disputed and cancelled orders are no longer driven by their payments
+	if order.Disputed || order.PayStatus == domain.Cancelled {
+		if chargesCancelledOrder {
+			p.returnPayment(ctx, order, attempt)
+		}
+		return false, nil
+	}
+
+	attempts, err := p.attemptRepo.ListAttemptsByOrder(ctx, order.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// the order status is derived from the best of its attempts
+	effective := domain.EffectiveAttempt(attempts)
+	if effective == nil || order.ReflectsAttempt(effective) || order.IsUnderReviewFor(effective) {
+		return false, nil
+	}
+
+	// a payment that doesn't cover the order is not trusted, e.g. a tampered preference
+	if effective.Status == domain.Approved || effective.Status == domain.Authorized {
+		if err := order.ValidatePayment(effective.Amount, effective.Currency, p.amountTolerance); err != nil {
+			return p.sendToReview(ctx, order, effective, err)
+		}
+	}
+
+	// avoids updating a order with an approved payment but that was never was credited due to account errors or holds
+	if effective.Status == domain.Approved && effective.NetReceivedAmount <= 0 {
+		return false, fmt.Errorf("%w, payment: %s", domain.ErrPaymentNotCredited, effective.PaymentID)
+	}
+
+	wasPaid := order.Paid
+	err = order.ApplyPaymentAttempt(effective)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = p.orderRepo.SaveOrder(ctx, order)
+	if err != nil {
+		return false, err
+	}
+
+	if !wasPaid && order.Paid {
+		p.recordSale(ctx, order)
+		p.notifyBuyer(ctx, domain.NotificationPaymentApproved, order)
+	} else if order.PayStatus == domain.Rejected {
+		// each rejected attempt is notified once, the order already reflects it on the next notification
+		p.releaseStock(ctx, order)
+		p.notifyBuyer(ctx, domain.NotificationPaymentRejected, order)
+	} else if order.PayStatus == domain.Cancelled || order.PayStatus == domain.Expired {
+		p.releaseStock(ctx, order)
+	}
+	return true, nil
+}
+
+// helper func, returns true if the attempt was already recorded with the same status
+func (p *PaymentService) isAttemptRecorded(ctx context.Context, attempt *domain.PaymentAttempt) (bool, error) {
+	attempts, err := p.attemptRepo.ListAttemptsByOrder(ctx, attempt.OrderID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, a := range attempts {
+		if a.PaymentID == attempt.PaymentID && a.Status == attempt.Status {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// helper func, the buyer paid an order that was already cancelled and its stock released. An authorized payment is
+// voided at the provider, an approved one can't be refunded through the provider so it's alerted to be refunded by
+// hand, like an authorized payment that couldn't be voided
+func (p *PaymentService) returnPayment(ctx context.Context, order *domain.Order, attempt *domain.PaymentAttempt) {
+	subject := "approved payment of a cancelled order must be refunded"
+	if attempt.Status == domain.Authorized {
+		err := p.mp.CancelPayment(ctx, attempt.PaymentID)
+		if err == nil {
+			slog.Info("authorized payment of cancelled order voided", "order_id", order.ID, "payment_id", attempt.PaymentID)
+			return
+		}
+
+		slog.Error("error voiding authorized payment of cancelled order", "order_id", order.ID, "payment_id", attempt.PaymentID, "error", err)
+		subject = "authorized payment of a cancelled order couldn't be voided"
+	}
+
+	err := p.alerter.Alert(ctx, subject, map[string]any{
+		"order_id":   order.ID,
+		"payment_id": attempt.PaymentID,
+		"status":     attempt.Status,
+		"amount":     attempt.Amount,
+		"currency":   attempt.Currency,
+	})
+	if err != nil {
+		slog.Error("error alerting payment of cancelled order", "order_id", order.ID, "error", err)
+	}
+}
+
+// helper func, returns the stock reserved by an order whose payment ended without paying it. A later approved attempt
+// reserves it again when the sale is recorded. Orders created before the warehouses existed have no allocations and
+// are released only when they are cancelled, a repeated notification would return their stock twice
+func (p *PaymentService) releaseStock(ctx context.Context, order *domain.Order) {
+	allocations, err := p.inventory.ListOrderAllocations(ctx, order.ID)
+	if err == nil {
+		err = p.inventory.ReleaseAllocations(ctx, allocations)
+	}
+	if err != nil {
+		slog.Error("error releasing stock of unpaid order", "order_id", order.ID, "error", err)
+	}
+}
+
+// helper func, the stock of a paid order was taken when it was reserved, the sale is only recorded in the ledger.
+// A failure doesn't undo the payment, the audit of the stock isn't affected by sales
+func (p *PaymentService) recordSale(ctx context.Context, order *domain.Order) {
+	if err := p.inventory.RecordSale(ctx, order); err != nil {
+		slog.Error("error recording sale of paid order", "order_id", order.ID, "error", err)
+	}
+}
+
+// helper func, notifies the buyer about the payment of the order. A failure doesn't undo the payment
+func (p *PaymentService) notifyBuyer(ctx context.Context, kind domain.NotificationKind, order *domain.Order) {
+	err := p.notifier.Notify(ctx, domain.Notification{
+		Kind:   kind,
+		UserID: order.UserID,
+		Data: map[string]any{
+			"order_id": order.ID,
+			"total":    order.Total,
+			"currency": order.Currency,
+		},
+	})
+	if err != nil {
+		slog.Error("error notifying buyer of payment", "order_id", order.ID, "kind", kind, "error", err)
+	}
+}
+
+// helper func, the order keeps unpaid until the payment is reviewed
+func (p *PaymentService) sendToReview(ctx context.Context, order *domain.Order, attempt *domain.PaymentAttempt, reason error) (bool, error) {
+	err := order.MarkForReview(attempt)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = p.orderRepo.SaveOrder(ctx, order)
+	if err != nil {
+		return false, err
+	}
+
+	err = p.alerter.Alert(ctx, "payment sent to review", map[string]any{
+		"order_id":       order.ID,
+		"payment_id":     attempt.PaymentID,
+		"reason":         reason.Error(),
+		"order_total":    order.Total,
+		"order_currency": order.Currency,
+		"amount":         attempt.Amount,
+		"currency":       attempt.Currency,
+	})
+	if err != nil {
+		slog.Error("error alerting payment under review", "order_id", order.ID, "error", err)
+	}
+
+	return true, nil
+}
+
 // VerifyPayment implements ports.PaymentProvider.
 func (p *PaymentService) VerifyPayment(ctx context.Context, id *string, topic *string) error {
-	// tiene que retornar el id y a partir de ahi actualizar la orden
+	if id != nil && topic != nil && *topic == chargebacksTopic {
+		return p.handleChargeback(ctx, *id)
+	}
+
 	payment, err := p.mp.VerifyPayment(ctx, id, topic)
 	if err != nil {
 		return err
 	}
 
+	if payment == nil {
+		return domain.ErrPaymentNotFound
+	}
+
 	if payment.ExternalReference == "" {
-		return errors.New("external reference missing in payment")
+		return domain.ErrPaymentExternalReference
 	}
 
 	// parse external reference (order.ID generated in mp preference) to uuid
@@ -95,64 +394,144 @@ func (p *PaymentService) VerifyPayment(ctx context.Context, id *string, topic *s
 		return err
 	}
 
-	// if order has been updated, return
-	if order.PaymentID != nil {
-		return nil
+	_, err = p.applyPayment(ctx, order, payment)
+	return err
+}
+
+// Reconcile implements ports.PaymentService.
+// Searches the provider for the payments of pending orders created inside maxAge, so the updates of lost webhooks are applied
+func (p *PaymentService) Reconcile(ctx context.Context, maxAge time.Duration) (*domain.ReconciliationReport, error) {
+	report := domain.NewReconciliationReport(time.Now())
+
+	orders, err := p.orderRepo.ListOrdersByStatus(ctx, []domain.PayStatus{domain.Pending, domain.InProcess}, report.StartedAt.Add(-maxAge))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		report.Checked++
+
+		payments, err := p.mp.SearchPayments(ctx, order.ID.String())
+		if err != nil {
+			report.AddError(order.ID, err)
+			continue
+		}
+
+		for _, payment := range payments {
+			// only the payments that charge the order are compared, a rejected attempt of another amount isn't a mismatch
+			if payment.Status == domain.Approved || payment.Status == domain.Authorized {
+				report.CompareAmounts(order, strconv.Itoa(payment.ID), payment.TransactionAmount, domain.Currencies(payment.CurrencyID), p.amountTolerance)
+			}
+
+			updated, err := p.applyPayment(ctx, order, payment)
+			if err != nil {
+				report.AddError(order.ID, err)
+				continue
+			}
+			if updated {
+				report.AddUpdated(order.ID)
+			}
+		}
 	}
 
-	// validations and handling errors
-	// external_reference must be equal than order id
-	if parsedExtRef != order.ID {
-		return fmt.Errorf("payment: %s external_reference does not match with order: %s", payment.ExternalReference, order.ID)
+	report.Finish(time.Now())
+
+	for _, mismatch := range report.Mismatches {
+		err := p.alerter.Alert(ctx, "payment mismatch found by reconciliation", map[string]any{
+			"order_id":   mismatch.OrderID,
+			"payment_id": mismatch.PaymentID,
+			"kind":       mismatch.Kind,
+			"expected":   mismatch.Expected,
+			"actual":     mismatch.Actual,
+		})
+		if err != nil {
+			slog.Error("error alerting reconciliation mismatch", "order_id", mismatch.OrderID, "error", err)
+		}
 	}
 
-	// avoids updating a order with an approved payment but that was never was credited due to account errors or holds
-	if payment.TransactionDetails.NetReceivedAmount <= 0 {
-		return fmt.Errorf("net received amount is 0 or less for payment: %v", payment.ID)
+	return report, nil
+}
+
+// Capture implements ports.PaymentService.
+func (p *PaymentService) Capture(ctx context.Context, orderId uuid.UUID) (*domain.Order, error) {
+	order, err := p.orderRepo.GetOrderById(ctx, orderId)
+	if err != nil {
+		return nil, err
 	}
 
-	// if the order has already been updated with this payment_id, return and do nothing
-	strPaymentId := strconv.Itoa(payment.ID)
-	if order.PaymentID == &strPaymentId {
-		return fmt.Errorf("payment: %v already processed for order: %s", payment.ID, order.ID)
+	// validate before calling the provider, an expired authorization can't be captured
+	err = order.CanBeCaptured(time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	// update order with payment data
-	successfullyPayment := payment.Status == domain.Approved && payment.StatusDetail == domain.Accredited
-	fee := payment.TransactionAmount - payment.TransactionDetails.NetReceivedAmount
+	payment, err := p.mp.CapturePayment(ctx, *order.PaymentID, order.Total)
+	if err != nil {
+		return nil, err
+	}
 
-	var paidAt time.Time
-	if successfullyPayment {
-		paidAt = time.Now()
+	// the capture changes the payment, so the attempt is updated like any other notification
+	_, err = p.attemptRepo.SaveAttempt(ctx, newPaymentAttempt(order.ID, payment))
+	if err != nil {
+		return nil, err
 	}
 
-	var isPaid bool
-	if successfullyPayment {
-		isPaid = true
+	if payment.Status != domain.Approved {
+		return nil, fmt.Errorf("payment: %v was not captured, status: %s", payment.ID, payment.Status)
 	}
 
-	dataToUpdate := domain.UpdateOrderInputs{
-		PayStatus:         domain.PayStatus(payment.Status),
-		PayStatusDetail:   domain.PayStatusDetail(payment.StatusDetail),
-		PaymentID:         fmt.Sprint(payment.ID),
-		PayMethod:         payment.PayMethod.ID,
-		PayResource:       payment.PayMethod.Type,
-		Installments:      payment.Installments,
-		ExternalReference: payment.ExternalReference,
+	err = order.Capture(domain.CaptureOrderInputs{
+		PayStatusDetail:   payment.StatusDetail,
+		Fee:               payment.TransactionAmount - payment.TransactionDetails.NetReceivedAmount,
 		NetReceivedAmount: payment.TransactionDetails.NetReceivedAmount,
-		Fee:               fee,
-		PaidAt:            &paidAt,
-		Paid:              isPaid,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	err = order.UpdateOrder(dataToUpdate)
+	result, err := p.orderRepo.SaveOrder(ctx, order)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	_, err = p.orderRepo.SaveOrder(ctx, order)
+	p.recordSale(ctx, order)
+	p.notifyBuyer(ctx, domain.NotificationPaymentApproved, order)
+	return result, nil
+}
+
+// AlertExpiringAuthorizations implements ports.PaymentService.
+// Raises an alert for each authorized order that expires within the window, returns the amount of alerts raised
+func (p *PaymentService) AlertExpiringAuthorizations(ctx context.Context, window time.Duration) (int, error) {
+	orders, err := p.orderRepo.ListOrdersByStatus(ctx, []domain.PayStatus{domain.Authorized}, time.Time{})
 	if err != nil {
-		return err
+		return 0, err
 	}
-	return nil
+
+	now := time.Now()
+	alerted := 0
+
+	for _, order := range orders {
+		if !order.AuthorizationExpiresWithin(now, window) {
+			continue
+		}
+
+		err := p.alerter.Alert(ctx, "payment authorization near expiry", map[string]any{
+			"order_id":   order.ID,
+			"payment_id": *order.PaymentID,
+			"total":      order.Total,
+			"expires_at": *order.AuthorizationExpiresAt,
+		})
+		if err != nil {
+			return alerted, err
+		}
+
+		order.MarkAuthorizationAlerted(now)
+		_, err = p.orderRepo.SaveOrder(ctx, order)
+		if err != nil {
+			return alerted, err
+		}
+		alerted++
+	}
+
+	return alerted, nil
 }
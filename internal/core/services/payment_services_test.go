@@ -0,0 +1,488 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"go-ecommerce/internal/adapters/mercadopago/mp_dtos"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type depToTestingPaymentSrv struct {
+	userRepo     ports.UserRepository
+	orderRepo    ports.OrderRepository
+	attemptRepo  ports.PaymentAttemptRepository
+	mp           *mocks.MockPaymentProvider
+	alerter      *mocks.MockAlerter
+	notifier     *mocks.MockNotifier
+	disputeSrv   ports.DisputeService
+	inventorySrv ports.InventoryService
+	productSrv   ports.ProductService
+	categSrv     ports.CategoryService
+	paymentSrv   ports.PaymentService
+}
+
+func newPaymentSrvTest(t *testing.T) *depToTestingPaymentSrv {
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	userRepo := repository.NewUserRepo(tx)
+	prodRepo := repository.NewProductRepo(tx)
+	orderProdSrv := services.NewOrderProductService(repository.NewOrderProductRepo(tx))
+	orderRepo := repository.NewOrderRepo(orderProdSrv, tx)
+	attemptRepo := repository.NewPaymentAttemptRepo(tx)
+
+	mp := &mocks.MockPaymentProvider{}
+	alerter := &mocks.MockAlerter{}
+	notifier := &mocks.MockNotifier{}
+	disputeSrv := services.NewDisputeService(repository.NewDisputeRepo(tx), orderRepo, alerter, mocks.NewMockRedis())
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, userRepo, &mocks.MockNotifier{})
+	redis := mocks.NewMockRedis()
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), repository.NewWarehouseRepo(tx), prodRepo, stockAlertSrv, redis)
+
+	return &depToTestingPaymentSrv{
+		userRepo:     userRepo,
+		orderRepo:    orderRepo,
+		attemptRepo:  attemptRepo,
+		mp:           mp,
+		alerter:      alerter,
+		notifier:     notifier,
+		disputeSrv:   disputeSrv,
+		inventorySrv: inventorySrv,
+		productSrv:   services.NewProductService(prodRepo, redis),
+		categSrv:     services.NewCategoryService(repository.NewCategoryRepo(tx), redis),
+		paymentSrv:   services.NewPaymentService(userRepo, orderRepo, attemptRepo, prodRepo, mp, disputeSrv, inventorySrv, alerter, notifier, 0.01),
+	}
+}
+
+// helper, creates an order with an authorized payment
+func newAuthorizedOrder(t *testing.T, ctx context.Context, srv *depToTestingPaymentSrv, email string) *domain.Order {
+	newUser, err := srv.userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", email))
+	require.NoError(t, err)
+
+	o := testhelpers.NewDomainOrder(newUser.ID)
+	o.PayStatus = domain.Pending
+	o.Total = 1000
+	savedOrder, err := srv.orderRepo.SaveOrder(ctx, o)
+	require.NoError(t, err)
+
+	err = savedOrder.UpdateOrder(domain.UpdateOrderInputs{
+		PaymentID:         "987654",
+		PayStatus:         domain.Authorized,
+		PayStatusDetail:   domain.PendingCapture,
+		ExternalReference: savedOrder.ID.String(),
+	})
+	require.NoError(t, err)
+
+	authorizedOrder, err := srv.orderRepo.SaveOrder(ctx, savedOrder)
+	require.NoError(t, err)
+	return authorizedOrder
+}
+
+func Test_PaymentServices_Capture(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	order := newAuthorizedOrder(t, ctx, srv, "capture@mail.test")
+	require.NotNil(t, order.AuthorizationExpiresAt)
+	assert.False(t, order.Paid)
+
+	srv.mp.CapturePaymentFunc = func(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error) {
+		assert.Equal(t, "987654", paymentId)
+		assert.Equal(t, 1000.0, amount)
+
+		return &mp_dtos.MpSimplifiedPayment{
+			ID:                987654,
+			Status:            domain.Approved,
+			StatusDetail:      domain.Accredited,
+			TransactionAmount: amount,
+			TransactionDetails: mp_dtos.TransactionDetails{
+				NetReceivedAmount: 950,
+			},
+		}, nil
+	}
+
+	captured, err := srv.paymentSrv.Capture(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Approved, captured.PayStatus)
+	assert.True(t, captured.Paid)
+	assert.Equal(t, 50.0, *captured.Fee)
+
+	// a captured order can't be captured again
+	_, err = srv.paymentSrv.Capture(ctx, order.ID)
+	assert.ErrorIs(t, err, domain.ErrOrderNotAuthorized)
+}
+
+func Test_PaymentServices_AlertExpiringAuthorizations(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	order := newAuthorizedOrder(t, ctx, srv, "alert@mail.test")
+
+	// the authorization is valid for 7 days, a window of 1 day doesn't raise alerts
+	alerted, err := srv.paymentSrv.AlertExpiringAuthorizations(ctx, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, alerted)
+
+	alerted, err = srv.paymentSrv.AlertExpiringAuthorizations(ctx, domain.AuthorizationValidity+time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, alerted)
+	require.Len(t, srv.alerter.Alerts, 1)
+	assert.Equal(t, order.ID, srv.alerter.Alerts[0].Attrs["order_id"])
+
+	// the alert is raised only once per order
+	alerted, err = srv.paymentSrv.AlertExpiringAuthorizations(ctx, domain.AuthorizationValidity+time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, alerted)
+}
+
+func Test_PaymentServices_VerifyPaymentAttempts(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	newUser, err := srv.userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", "attempts@mail.test"))
+	require.NoError(t, err)
+
+	o := testhelpers.NewDomainOrder(newUser.ID)
+	o.PayStatus = domain.Pending
+	o.Total = 1000
+	order, err := srv.orderRepo.SaveOrder(ctx, o)
+	require.NoError(t, err)
+
+	payments := map[string]*mp_dtos.MpSimplifiedPayment{
+		"1": {ID: 1, Status: domain.Rejected, StatusDetail: domain.BankError, TransactionAmount: 1000, ExternalReference: order.ID.String()},
+		"2": {
+			ID: 2, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: 1000, CurrencyID: string(order.Currency), ExternalReference: order.ID.String(),
+			TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950},
+			Raw:                []byte(`{"id":2,"status":"approved"}`),
+		},
+	}
+	srv.mp.VerifyPaymentFunc = func(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error) {
+		return payments[*id], nil
+	}
+
+	topic := "payment"
+	verify := func(id string) *domain.Order {
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+		updated, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+		require.NoError(t, err)
+		return updated
+	}
+
+	// the first attempt is rejected
+	updated := verify("1")
+	assert.Equal(t, domain.Rejected, updated.PayStatus)
+	assert.False(t, updated.Paid)
+	rejected := srv.notifier.Sent(domain.NotificationPaymentRejected)
+	require.Len(t, rejected, 1)
+	assert.Equal(t, newUser.ID, rejected[0].UserID)
+
+	// the same notification of the provider doesn't notify the buyer again
+	verify("1")
+	assert.Len(t, srv.notifier.Sent(domain.NotificationPaymentRejected), 1)
+
+	// the retry is approved and the order is paid
+	updated = verify("2")
+	assert.Equal(t, domain.Approved, updated.PayStatus)
+	assert.Equal(t, "2", *updated.PaymentID)
+	assert.True(t, updated.Paid)
+	assert.Equal(t, 50.0, *updated.Fee)
+	approved := srv.notifier.Sent(domain.NotificationPaymentApproved)
+	require.Len(t, approved, 1)
+	assert.Equal(t, order.ID, approved[0].Data["order_id"])
+
+	// a late notification of the rejected attempt doesn't override the approved one
+	updated = verify("1")
+	assert.Equal(t, domain.Approved, updated.PayStatus)
+	assert.Equal(t, "2", *updated.PaymentID)
+	assert.Len(t, srv.notifier.Sent(domain.NotificationPaymentRejected), 1)
+	assert.Len(t, srv.notifier.Sent(domain.NotificationPaymentApproved), 1)
+
+	attempts, err := srv.attemptRepo.ListAttemptsByOrder(ctx, order.ID)
+	require.NoError(t, err)
+	require.Len(t, attempts, 2)
+	assert.JSONEq(t, `{"id":2,"status":"approved"}`, string(attempts[1].RawPayload))
+}
+
+func Test_PaymentServices_ReleaseStockOfUnpaidOrder(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	category, err := srv.categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+	product, err := srv.productSrv.SaveProduct(ctx, testhelpers.NewDomainProduct("Ipad 14 pro", category.ID).ToInputs())
+	require.NoError(t, err)
+
+	newUser, err := srv.userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", "release@mail.test"))
+	require.NoError(t, err)
+
+	// helper, creates an order that reserved 5 units in the warehouses
+	createOrder := func() *domain.Order {
+		o := testhelpers.NewDomainOrder(newUser.ID)
+		o.PayStatus = domain.Pending
+		o.Total = 1000
+		order, err := srv.orderRepo.SaveOrder(ctx, o)
+		require.NoError(t, err)
+
+		allocations, err := srv.inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: product.ID, Quantity: 5}}, domain.AllocateMostStock, nil)
+		require.NoError(t, err)
+		require.NoError(t, srv.inventorySrv.AssignOrder(ctx, allocations, order.ID))
+		return order
+	}
+
+	stock := func() int64 {
+		prod, err := srv.productSrv.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		return prod.Stock
+	}
+
+	var payments map[string]*mp_dtos.MpSimplifiedPayment
+	srv.mp.VerifyPaymentFunc = func(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error) {
+		return payments[*id], nil
+	}
+	topic := "payment"
+	verify := func(id string) {
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+	}
+
+	t.Run("a rejected payment releases the stock and an approved retry takes it again", func(t *testing.T) {
+		order := createOrder()
+		assert.Equal(t, product.Stock-5, stock())
+
+		payments = map[string]*mp_dtos.MpSimplifiedPayment{
+			"11": {ID: 11, Status: domain.Rejected, StatusDetail: domain.BankError, TransactionAmount: 1000, ExternalReference: order.ID.String()},
+			"12": {
+				ID: 12, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: 1000, CurrencyID: string(order.Currency), ExternalReference: order.ID.String(),
+				TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950},
+			},
+		}
+
+		verify("11")
+		assert.Equal(t, product.Stock, stock())
+
+		// a repeated notification doesn't release the stock twice
+		verify("11")
+		assert.Equal(t, product.Stock, stock())
+
+		verify("12")
+		assert.Equal(t, product.Stock-5, stock())
+
+		allocations, err := srv.inventorySrv.ListOrderAllocations(ctx, order.ID)
+		require.NoError(t, err)
+		sold := 0
+		for _, allocation := range allocations {
+			if allocation.Status == domain.AllocationSold {
+				sold += int(allocation.Quantity)
+			}
+		}
+		assert.Equal(t, 5, sold)
+	})
+
+	t.Run("an expired payment releases the stock", func(t *testing.T) {
+		before := stock()
+		order := createOrder()
+		assert.Equal(t, before-5, stock())
+
+		payments = map[string]*mp_dtos.MpSimplifiedPayment{
+			"21": {ID: 21, Status: domain.Expired, StatusDetail: domain.ExpiredDetail, TransactionAmount: 1000, ExternalReference: order.ID.String()},
+		}
+		verify("21")
+
+		updated, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.Expired, updated.PayStatus)
+		assert.Equal(t, before, stock())
+	})
+}
+
+func Test_PaymentServices_Reconcile(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	newUser, err := srv.userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", "reconcile@mail.test"))
+	require.NoError(t, err)
+
+	o := testhelpers.NewDomainOrder(newUser.ID)
+	o.PayStatus = domain.Pending
+	o.Total = 1000
+	order, err := srv.orderRepo.SaveOrder(ctx, o)
+	require.NoError(t, err)
+
+	// the webhook of an approved payment was lost and the amount charged differs from the total, a rejected attempt
+	// of another amount isn't a mismatch
+	srv.mp.SearchPaymentsFunc = func(ctx context.Context, externalReference string) ([]*mp_dtos.MpSimplifiedPayment, error) {
+		assert.Equal(t, order.ID.String(), externalReference)
+		return []*mp_dtos.MpSimplifiedPayment{{
+			ID:                 55,
+			Status:             domain.Approved,
+			StatusDetail:       domain.Accredited,
+			TransactionAmount:  900,
+			CurrencyID:         string(order.Currency),
+			ExternalReference:  order.ID.String(),
+			TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 850},
+		}, {
+			ID:                56,
+			Status:            domain.Rejected,
+			TransactionAmount: 100,
+			CurrencyID:        string(order.Currency),
+			ExternalReference: order.ID.String(),
+		}}, nil
+	}
+
+	report, err := srv.paymentSrv.Reconcile(ctx, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Equal(t, []uuid.UUID{order.ID}, report.Updated)
+	require.Len(t, report.Mismatches, 1)
+	assert.Equal(t, domain.AmountMismatch, report.Mismatches[0].Kind)
+	assert.Equal(t, "1000.00", report.Mismatches[0].Expected)
+	assert.Equal(t, "900.00", report.Mismatches[0].Actual)
+
+	// a payment that doesn't cover the total is sent to review instead of being paid
+	updated, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Review, updated.PayStatus)
+	assert.False(t, updated.Paid)
+	require.Len(t, srv.alerter.Alerts, 2)
+	assert.Equal(t, "payment sent to review", srv.alerter.Alerts[0].Subject)
+
+	// the order is no longer pending, so it's not checked again
+	report, err = srv.paymentSrv.Reconcile(ctx, 7*24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, 0, report.Checked)
+}
+
+func Test_PaymentServices_PaymentOfCancelledOrder(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	newUser, err := srv.userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", "cancelled@mail.test"))
+	require.NoError(t, err)
+
+	o := testhelpers.NewDomainOrder(newUser.ID)
+	o.PayStatus = domain.Cancelled
+	o.Total = 1000
+	order, err := srv.orderRepo.SaveOrder(ctx, o)
+	require.NoError(t, err)
+
+	payments := map[string]*mp_dtos.MpSimplifiedPayment{
+		"1": {ID: 1, Status: domain.Authorized, StatusDetail: domain.PendingCapture, TransactionAmount: 1000, CurrencyID: string(order.Currency), ExternalReference: order.ID.String()},
+		"2": {ID: 2, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: 1000, CurrencyID: string(order.Currency), ExternalReference: order.ID.String(), TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950}},
+	}
+	srv.mp.VerifyPaymentFunc = func(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error) {
+		return payments[*id], nil
+	}
+	var voided []string
+	srv.mp.CancelPaymentFunc = func(ctx context.Context, paymentId string) error {
+		voided = append(voided, paymentId)
+		return nil
+	}
+	topic := "payment"
+
+	t.Run("an authorized payment is voided at the provider", func(t *testing.T) {
+		id := "1"
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+		assert.Equal(t, []string{"1"}, voided)
+		assert.Empty(t, srv.alerter.Alerts)
+	})
+
+	t.Run("an approved payment is alerted to be refunded once", func(t *testing.T) {
+		id := "2"
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+		require.Len(t, srv.alerter.Alerts, 1)
+		assert.Equal(t, "approved payment of a cancelled order must be refunded", srv.alerter.Alerts[0].Subject)
+		assert.Equal(t, "2", srv.alerter.Alerts[0].Attrs["payment_id"])
+	})
+
+	t.Run("an authorized payment that can't be voided is alerted", func(t *testing.T) {
+		srv.mp.CancelPaymentFunc = func(ctx context.Context, paymentId string) error {
+			return errors.New("provider unavailable")
+		}
+		payments["3"] = &mp_dtos.MpSimplifiedPayment{ID: 3, Status: domain.Authorized, StatusDetail: domain.PendingCapture, TransactionAmount: 1000, CurrencyID: string(order.Currency), ExternalReference: order.ID.String()}
+
+		id := "3"
+		require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+		require.Len(t, srv.alerter.Alerts, 2)
+		assert.Equal(t, "authorized payment of a cancelled order couldn't be voided", srv.alerter.Alerts[1].Subject)
+	})
+
+	// the order stays cancelled
+	updated, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Cancelled, updated.PayStatus)
+	assert.False(t, updated.Paid)
+}
+
+func Test_PaymentServices_VerifyPaymentMismatch(t *testing.T) {
+	ctx := context.Background()
+	srv := newPaymentSrvTest(t)
+
+	newUser, err := srv.userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", "mismatch@mail.test"))
+	require.NoError(t, err)
+
+	o := testhelpers.NewDomainOrder(newUser.ID)
+	o.PayStatus = domain.Pending
+	o.Total = 1000
+	order, err := srv.orderRepo.SaveOrder(ctx, o)
+	require.NoError(t, err)
+
+	payments := map[string]*mp_dtos.MpSimplifiedPayment{
+		// rounding differences inside the tolerance are accepted
+		"1": {ID: 1, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: 999.995, CurrencyID: string(order.Currency), ExternalReference: order.ID.String(), TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950}},
+		// the same amount in another currency is not
+		"2": {ID: 2, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: 1000, CurrencyID: "BRL", ExternalReference: order.ID.String(), TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950}},
+	}
+	srv.mp.VerifyPaymentFunc = func(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error) {
+		return payments[*id], nil
+	}
+
+	id, topic := "2", "payment"
+	require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+	updated, err := srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Review, updated.PayStatus)
+	assert.False(t, updated.Paid)
+	require.Len(t, srv.alerter.Alerts, 1)
+	assert.Equal(t, domain.ErrPaymentCurrencyMismatch.Error(), srv.alerter.Alerts[0].Attrs["reason"])
+
+	// repeated notifications don't raise the alert again
+	require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+	assert.Len(t, srv.alerter.Alerts, 1)
+
+	// a payment without currency isn't trusted either
+	payments["3"] = &mp_dtos.MpSimplifiedPayment{ID: 3, Status: domain.Approved, StatusDetail: domain.Accredited, TransactionAmount: 1000, ExternalReference: order.ID.String(), TransactionDetails: mp_dtos.TransactionDetails{NetReceivedAmount: 950}}
+	id = "3"
+	require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+	updated, err = srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Review, updated.PayStatus)
+	assert.False(t, updated.Paid)
+	require.Len(t, srv.alerter.Alerts, 2)
+	assert.Equal(t, domain.ErrPaymentCurrencyMismatch.Error(), srv.alerter.Alerts[1].Attrs["reason"])
+
+	id = "1"
+	require.NoError(t, srv.paymentSrv.VerifyPayment(ctx, &id, &topic))
+
+	updated, err = srv.orderRepo.GetOrderById(ctx, order.ID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Approved, updated.PayStatus)
+	assert.True(t, updated.Paid)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PriceService struct {
+	repo        ports.PriceRepository
+	productRepo ports.ProductRepository
+	cache       ports.CacheRepository
+}
+
+func NewPriceService(repo ports.PriceRepository, productRepo ports.ProductRepository, cache ports.CacheRepository) ports.PriceService {
+	return &PriceService{
+		repo:        repo,
+		productRepo: productRepo,
+		cache:       cache,
+	}
+}
+
+// helper func, the cached product and the pages that could contain it are stale after a price change
+func (ps *PriceService) invalidateProduct(ctx context.Context, id uuid.UUID) {
+	err := ps.cache.Delete(ctx, cachekeys.Product(id.String()))
+	if err != nil {
+		slog.Warn("error deleting product of cache", "product_id", id, "error", err)
+	}
+
+	err = ps.cache.Delete(ctx, cachekeys.AllProducts())
+	if err != nil {
+		slog.Warn("error invalidating list of all products", "error", err)
+	}
+
+	err = ps.cache.DeleteByPrefix(ctx, cachekeys.ProductQueriesPrefix())
+	if err != nil {
+		slog.Warn("error invalidating product search pages", "error", err)
+	}
+}
+
+// PriceHistory implements ports.PriceService.
+func (ps *PriceService) PriceHistory(ctx context.Context, productID uuid.UUID) ([]domain.PriceChange, error) {
+	if _, err := ps.productRepo.GetProductById(ctx, productID); err != nil {
+		return nil, err
+	}
+	return ps.repo.ListPriceHistory(ctx, productID)
+}
+
+// SchedulePrice implements ports.PriceService.
+func (ps *PriceService) SchedulePrice(ctx context.Context, productID uuid.UUID, inputs ports_dtos.SchedulePriceInputs) (*domain.PriceSchedule, error) {
+	if _, err := ps.productRepo.GetProductById(ctx, productID); err != nil {
+		return nil, err
+	}
+
+	schedule, err := domain.NewPriceSchedule(productID, inputs, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return ps.repo.SaveSchedule(ctx, schedule)
+}
+
+// ListSchedules implements ports.PriceService.
+func (ps *PriceService) ListSchedules(ctx context.Context, productID uuid.UUID) ([]*domain.PriceSchedule, error) {
+	if _, err := ps.productRepo.GetProductById(ctx, productID); err != nil {
+		return nil, err
+	}
+	return ps.repo.ListSchedules(ctx, productID)
+}
+
+// CancelSchedule implements ports.PriceService.
+func (ps *PriceService) CancelSchedule(ctx context.Context, productID, scheduleID uuid.UUID) (*domain.PriceSchedule, error) {
+	schedule, err := ps.repo.GetScheduleById(ctx, scheduleID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the schedule must belong to the product of the url
+	if schedule.ProductID != productID {
+		return nil, domain.ErrPriceScheduleNotFound
+	}
+
+	if err := schedule.Cancel(time.Now()); err != nil {
+		return nil, err
+	}
+
+	return ps.repo.SaveSchedule(ctx, schedule)
+}
+
+// ApplyDueSchedules implements ports.PriceService.
+// A schedule that can't be applied is abandoned so it isn't retried on every run
+func (ps *PriceService) ApplyDueSchedules(ctx context.Context, now time.Time) (int, error) {
+	schedules, err := ps.repo.ListDueSchedules(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, schedule := range schedules {
+		product, err := ps.productRepo.GetProductById(ctx, schedule.ProductID)
+		if err != nil && err != domain.ErrProductNotFound {
+			return applied, err
+		}
+
+		var change *domain.PriceChange
+		if err == nil {
+			change, err = schedule.Apply(product, now)
+		}
+		if err != nil {
+			slog.Warn("price schedule abandoned", "schedule_id", schedule.ID, "product_id", schedule.ProductID, "error", err)
+			schedule.Abandon(now)
+			if _, err := ps.repo.SaveSchedule(ctx, schedule); err != nil {
+				return applied, err
+			}
+			continue
+		}
+
+		if err := ps.repo.ApplySchedule(ctx, schedule, product, change); err != nil {
+			return applied, err
+		}
+		ps.invalidateProduct(ctx, product.ID)
+		applied++
+	}
+
+	return applied, nil
+}
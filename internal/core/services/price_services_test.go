@@ -0,0 +1,190 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_PriceService_HistoryAndSchedules(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodRepo := repository.NewProductRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	priceRepo := repository.NewPriceRepo(tx)
+	priceSrv := services.NewPriceService(priceRepo, prodRepo, redis)
+
+	category, err := categSrv.SaveCategory(ctx, 0, "Consoles", nil)
+	require.NoError(t, err)
+
+	seller := uuid.New()
+	inputs := testhelpers.NewDomainProduct("Playstation 5", category.ID).ToInputs()
+	inputs.UpdatedBy = &seller
+	product, err := prodSrv.SaveProduct(ctx, inputs)
+	require.NoError(t, err)
+
+	t.Run("records creation and manual changes", func(t *testing.T) {
+		discount, discountType := 20.0, string(domain.Percentage)
+		_, err := prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			ID:           product.ID,
+			Discount:     &discount,
+			DiscountType: &discountType,
+			UpdatedBy:    &seller,
+		})
+		require.NoError(t, err)
+
+		// a change of the low stock threshold alone isn't a price change
+		threshold := int64(5)
+		_, err = prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{ID: product.ID, LowStockThreshold: &threshold})
+		require.NoError(t, err)
+
+		// a fixed discount can't cover the whole price
+		fixed, fixedType := 10.0, string(domain.Fixed)
+		_, err = prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{ID: product.ID, Discount: &fixed, DiscountType: &fixedType})
+		assert.ErrorIs(t, err, domain.ErrFixedDiscountTooHigh)
+
+		history, err := priceSrv.PriceHistory(ctx, product.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		for _, change := range history {
+			require.NotNil(t, change.ChangedBy)
+			assert.Equal(t, seller, *change.ChangedBy)
+		}
+		assert.Equal(t, domain.Percentage, findChange(t, history, 20).NewDiscountType)
+	})
+
+	t.Run("applies a permanent change and a time-boxed sale", func(t *testing.T) {
+		now := time.Now()
+		newPrice := 8.0
+		permanent, err := priceSrv.SchedulePrice(ctx, product.ID, ports_dtos.SchedulePriceInputs{
+			Price:    &newPrice,
+			StartsAt: now.Add(time.Hour),
+		})
+		require.NoError(t, err)
+
+		saleDiscount, saleType := 50.0, string(domain.Percentage)
+		saleEnds := now.Add(3 * time.Hour)
+		sale, err := priceSrv.SchedulePrice(ctx, product.ID, ports_dtos.SchedulePriceInputs{
+			Discount:     &saleDiscount,
+			DiscountType: &saleType,
+			StartsAt:     now.Add(2 * time.Hour),
+			EndsAt:       &saleEnds,
+		})
+		require.NoError(t, err)
+
+		_, err = priceSrv.SchedulePrice(ctx, product.ID, ports_dtos.SchedulePriceInputs{Price: &newPrice, StartsAt: now.Add(-time.Hour)})
+		assert.ErrorIs(t, err, domain.ErrPriceScheduleInvalidDates)
+
+		// nothing is due yet
+		applied, err := priceSrv.ApplyDueSchedules(ctx, now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+
+		applied, err = priceSrv.ApplyDueSchedules(ctx, now.Add(150*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 2, applied)
+
+		current, err := prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 8.0, current.Price)
+		assert.Equal(t, 50.0, current.Disscount)
+
+		// the sale ends, the discount before it is restored and the new price is kept
+		applied, err = priceSrv.ApplyDueSchedules(ctx, saleEnds)
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+
+		current, err = prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 8.0, current.Price)
+		assert.Equal(t, 20.0, current.Disscount)
+		assert.Equal(t, domain.Percentage, current.DisscountType)
+
+		schedules, err := priceSrv.ListSchedules(ctx, product.ID)
+		require.NoError(t, err)
+		require.Len(t, schedules, 2)
+		for _, schedule := range schedules {
+			assert.Equal(t, domain.ScheduleCompleted, schedule.Status)
+		}
+
+		history, err := priceSrv.PriceHistory(ctx, product.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 5)
+		scheduled := 0
+		for _, change := range history {
+			if change.ScheduleID != nil {
+				assert.Contains(t, []uuid.UUID{permanent.ID, sale.ID}, *change.ScheduleID)
+				scheduled++
+			}
+		}
+		assert.Equal(t, 3, scheduled)
+
+		_, err = priceSrv.CancelSchedule(ctx, product.ID, sale.ID)
+		assert.ErrorIs(t, err, domain.ErrPriceScheduleFinished)
+	})
+
+	t.Run("cancels and abandons schedules", func(t *testing.T) {
+		now := time.Now()
+		price := 12.0
+		cancelled, err := priceSrv.SchedulePrice(ctx, product.ID, ports_dtos.SchedulePriceInputs{Price: &price, StartsAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+
+		_, err = priceSrv.CancelSchedule(ctx, uuid.New(), cancelled.ID)
+		assert.ErrorIs(t, err, domain.ErrPriceScheduleNotFound)
+
+		cancelled, err = priceSrv.CancelSchedule(ctx, product.ID, cancelled.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScheduleCancelled, cancelled.Status)
+
+		abandoned, err := priceSrv.SchedulePrice(ctx, product.ID, ports_dtos.SchedulePriceInputs{Price: &price, StartsAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+
+		// the product is archived before the schedule starts
+		require.NoError(t, prodSrv.DeleteProduct(ctx, product.ID))
+
+		applied, err := priceSrv.ApplyDueSchedules(ctx, now.Add(2*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+
+		// it's not picked up again
+		applied, err = priceSrv.ApplyDueSchedules(ctx, now.Add(3*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 0, applied)
+
+		current, err := prodRepo.GetProductIncludingArchived(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 8.0, current.Price)
+
+		abandoned, err = priceRepo.GetScheduleById(ctx, abandoned.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.ScheduleCancelled, abandoned.Status)
+		assert.NotNil(t, abandoned.EndedAt)
+	})
+}
+
+// helper func, finds the change that set the given discount
+func findChange(t *testing.T, history []domain.PriceChange, discount float64) domain.PriceChange {
+	t.Helper()
+	for _, change := range history {
+		if change.NewDiscount == discount {
+			return change
+		}
+	}
+	t.Fatalf("no price change with discount %v", discount)
+	return domain.PriceChange{}
+}
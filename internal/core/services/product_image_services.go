@@ -0,0 +1,199 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+type ProductImageService struct {
+	repo      ports.ProductRepository
+	storage   ports.BlobStorage
+	processor ports.ImageProcessor
+	cache     ports.CacheRepository
+}
+
+func NewProductImageService(repo ports.ProductRepository, storage ports.BlobStorage, processor ports.ImageProcessor, cache ports.CacheRepository) ports.ProductImageService {
+	return &ProductImageService{
+		repo:      repo,
+		storage:   storage,
+		processor: processor,
+		cache:     cache,
+	}
+}
+
+// helper func, the gallery is part of the cached product and of the listings
+func (is *ProductImageService) invalidateProduct(ctx context.Context, id uuid.UUID) {
+	err := is.cache.Delete(ctx, cachekeys.Product(id.String()))
+	if err != nil {
+		slog.Warn("error deleting product of cache", "product_id", id, "error", err)
+	}
+
+	err = is.cache.Delete(ctx, cachekeys.AllProducts())
+	if err != nil {
+		slog.Warn("error invalidating list of all products", "error", err)
+	}
+
+	err = is.cache.DeleteByPrefix(ctx, cachekeys.ProductQueriesPrefix())
+	if err != nil {
+		slog.Warn("error invalidating product search pages", "error", err)
+	}
+}
+
+// helper func, removes files of the storage that are no longer referenced
+func (is *ProductImageService) deleteBlobs(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := is.storage.Delete(ctx, key); err != nil {
+			slog.Warn("error deleting blob", "key", key, "error", err)
+		}
+	}
+}
+
+// helper func, persists the gallery and invalidates the cached product
+func (is *ProductImageService) saveGallery(ctx context.Context, product *domain.Product) error {
+	err := is.repo.SaveGallery(ctx, product)
+	if err != nil {
+		return err
+	}
+
+	is.invalidateProduct(ctx, product.ID)
+	return nil
+}
+
+// UploadImage implements ports.ProductImageService.
+// The original image and its thumbnail are stored, then the image is appended to the gallery
+func (is *ProductImageService) UploadImage(ctx context.Context, productID uuid.UUID, data []byte, contentType string) (*domain.ProductImage, error) {
+	ext, err := domain.ProductImageExtension(contentType, int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := is.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(product.Images) >= domain.MaxProductImages {
+		return nil, domain.ErrProductImageLimit
+	}
+
+	// the thumbnail is generated first, it also validates that the file is a decodable image
+	thumbnail, thumbnailType, err := is.processor.Thumbnail(data, domain.ProductThumbnailSide)
+	if errors.Is(err, domain.ErrProductImageTooManyPixels) {
+		return nil, domain.ErrProductImageTooManyPixels
+	}
+	if err != nil {
+		slog.Warn("error generating thumbnail", "product_id", productID, "error", err)
+		return nil, domain.ErrProductImageInvalidType
+	}
+
+	thumbnailExt, err := domain.ProductImageExtension(thumbnailType, int64(len(thumbnail)))
+	if err != nil {
+		return nil, err
+	}
+
+	image := domain.ProductImage{
+		ID:          uuid.New(),
+		ContentType: contentType,
+		Size:        int64(len(data)),
+	}
+	image.Key = domain.ProductImageKey(productID, image.ID, "", ext)
+	image.ThumbnailKey = domain.ProductImageKey(productID, image.ID, "_thumb", thumbnailExt)
+
+	image.URL, err = is.storage.Put(ctx, image.Key, bytes.NewReader(data), contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	image.ThumbnailURL, err = is.storage.Put(ctx, image.ThumbnailKey, bytes.NewReader(thumbnail), thumbnailType)
+	if err != nil {
+		is.deleteBlobs(ctx, image.Key)
+		return nil, err
+	}
+
+	err = product.AddImage(image)
+	if err == nil {
+		err = is.saveGallery(ctx, product)
+	}
+	if err != nil {
+		is.deleteBlobs(ctx, image.Key, image.ThumbnailKey)
+		return nil, err
+	}
+
+	return &product.Images[len(product.Images)-1], nil
+}
+
+// ListImages implements ports.ProductImageService.
+func (is *ProductImageService) ListImages(ctx context.Context, productID uuid.UUID) ([]domain.ProductImage, error) {
+	product, err := is.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return product.Images, nil
+}
+
+// DeleteImage implements ports.ProductImageService.
+func (is *ProductImageService) DeleteImage(ctx context.Context, productID, imageID uuid.UUID) error {
+	product, err := is.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := product.RemoveImage(imageID)
+	if err != nil {
+		return err
+	}
+
+	err = is.saveGallery(ctx, product)
+	if err != nil {
+		return err
+	}
+
+	// files are deleted once the gallery doesn't reference them
+	is.deleteBlobs(ctx, removed.Key, removed.ThumbnailKey)
+	return nil
+}
+
+// ReorderImages implements ports.ProductImageService.
+func (is *ProductImageService) ReorderImages(ctx context.Context, productID uuid.UUID, imageIDs []uuid.UUID) ([]domain.ProductImage, error) {
+	product, err := is.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = product.ReorderImages(imageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = is.saveGallery(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	return product.Images, nil
+}
+
+// SetPrimaryImage implements ports.ProductImageService.
+func (is *ProductImageService) SetPrimaryImage(ctx context.Context, productID, imageID uuid.UUID) ([]domain.ProductImage, error) {
+	product, err := is.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = product.SetPrimaryImage(imageID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = is.saveGallery(ctx, product)
+	if err != nil {
+		return nil, err
+	}
+	return product.Images, nil
+}
@@ -0,0 +1,127 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"go-ecommerce/internal/adapters/imaging"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helper func, encodes a png of the given size
+func newPNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, x%height, color.NRGBA{R: 255, A: 255})
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// helper func, encodes a tiny png whose header declares the given size, it's never decoded in full
+func newPNGBomb(t *testing.T, width, height uint32) []byte {
+	t.Helper()
+
+	data := newPNG(t, 1, 1)
+	// the IHDR chunk follows the 8 bytes signature: length, type, width, height, ..., crc
+	ihdr := data[8 : 8+8+13+4]
+	binary.BigEndian.PutUint32(ihdr[8:], width)
+	binary.BigEndian.PutUint32(ihdr[12:], height)
+	binary.BigEndian.PutUint32(ihdr[21:], crc32.ChecksumIEEE(ihdr[4:21]))
+	return data
+}
+
+func Test_ProductImageService_Gallery(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	storage := mocks.NewMockBlobStorage()
+	prodRepo := repository.NewProductRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	imageSrv := services.NewProductImageService(prodRepo, storage, imaging.NewThumbnailer(), redis)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Cameras", nil)
+	require.NoError(t, err)
+	p := testhelpers.NewDomainProduct("Canon R5", savedCateg.ID)
+	product, err := prodSrv.SaveProduct(ctx, p.ToInputs())
+	require.NoError(t, err)
+
+	// invalid uploads
+	_, err = imageSrv.UploadImage(ctx, product.ID, []byte("not an image"), "text/plain")
+	assert.Equal(t, domain.ErrProductImageInvalidType, err)
+	_, err = imageSrv.UploadImage(ctx, product.ID, []byte("corrupted"), "image/png")
+	assert.Equal(t, domain.ErrProductImageInvalidType, err)
+	_, err = imageSrv.UploadImage(ctx, product.ID, make([]byte, domain.MaxProductImageSize+1), "image/png")
+	assert.Equal(t, domain.ErrProductImageTooLarge, err)
+	_, err = imageSrv.UploadImage(ctx, product.ID, newPNGBomb(t, 100000, 100000), "image/png")
+	assert.Equal(t, domain.ErrProductImageTooManyPixels, err)
+
+	// the first image is the primary one and has a scaled thumbnail
+	first, err := imageSrv.UploadImage(ctx, product.ID, newPNG(t, 800, 400), "image/png")
+	require.NoError(t, err)
+	assert.True(t, first.IsPrimary)
+	require.Contains(t, storage.Blobs, first.Key)
+	require.Contains(t, storage.Blobs, first.ThumbnailKey)
+
+	thumb, err := png.Decode(bytes.NewReader(storage.Blobs[first.ThumbnailKey]))
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProductThumbnailSide, thumb.Bounds().Dx())
+	assert.Equal(t, domain.ProductThumbnailSide/2, thumb.Bounds().Dy())
+
+	second, err := imageSrv.UploadImage(ctx, product.ID, newPNG(t, 100, 100), "image/png")
+	require.NoError(t, err)
+	assert.False(t, second.IsPrimary)
+	assert.Equal(t, 1, second.Position)
+
+	// reorder and change the primary image
+	_, err = imageSrv.ReorderImages(ctx, product.ID, []uuid.UUID{second.ID, second.ID})
+	assert.Equal(t, domain.ErrProductImageInvalidOrder, err)
+
+	images, err := imageSrv.ReorderImages(ctx, product.ID, []uuid.UUID{second.ID, first.ID})
+	require.NoError(t, err)
+	assert.Equal(t, second.ID, images[0].ID)
+
+	_, err = imageSrv.SetPrimaryImage(ctx, product.ID, second.ID)
+	require.NoError(t, err)
+
+	reloaded, err := prodSrv.GetProductById(ctx, product.ID)
+	require.NoError(t, err)
+	require.Len(t, reloaded.Images, 2)
+	assert.Equal(t, second.ID, reloaded.Images[0].ID)
+	assert.True(t, reloaded.Images[0].IsPrimary)
+	assert.Equal(t, second.URL, reloaded.Image)
+
+	// deleting the primary image promotes the next one and removes the files
+	err = imageSrv.DeleteImage(ctx, product.ID, second.ID)
+	require.NoError(t, err)
+	assert.NotContains(t, storage.Blobs, second.Key)
+	assert.NotContains(t, storage.Blobs, second.ThumbnailKey)
+
+	images, err = imageSrv.ListImages(ctx, product.ID)
+	require.NoError(t, err)
+	require.Len(t, images, 1)
+	assert.Equal(t, first.ID, images[0].ID)
+	assert.True(t, images[0].IsPrimary)
+	assert.Equal(t, 0, images[0].Position)
+}
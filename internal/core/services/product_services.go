@@ -2,6 +2,8 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
 	cachettl "go-ecommerce/internal/adapters/storage/cache/cache_ttl"
@@ -13,6 +15,12 @@ import (
 	"github.com/google/uuid"
 )
 
+// size of the pages of the product search
+const (
+	defaultProductPageSize = 20
+	maxProductPageSize     = 100
+)
+
 type ProductService struct {
 	repo  ports.ProductRepository
 	cache ports.CacheRepository
@@ -28,6 +36,7 @@ func NewProductService(repo ports.ProductRepository, cache ports.CacheRepository
 // SaveProduct implements ports.ProductService.
 func (ps *ProductService) SaveProduct(ctx context.Context, inputs ports_dtos.SaveProductInputs) (*domain.Product, error) {
 	var product *domain.Product
+	var previousSKU string
 
 	if inputs.ID == uuid.Nil {
 		// create a new product if inputs.ID doesn't exist
@@ -42,6 +51,16 @@ func (ps *ProductService) SaveProduct(ctx context.Context, inputs ports_dtos.Sav
 		if err != nil {
 			return nil, err
 		}
+
+		err = newProduct.Update(ports_dtos.SaveProductInputs{
+			Discount:          inputs.Discount,
+			DiscountType:      inputs.DiscountType,
+			UpdatedBy:         inputs.UpdatedBy,
+			LowStockThreshold: inputs.LowStockThreshold,
+		})
+		if err != nil {
+			return nil, err
+		}
 		product = newProduct
 
 	} else {
@@ -51,14 +70,22 @@ func (ps *ProductService) SaveProduct(ctx context.Context, inputs ports_dtos.Sav
 		}
 
 		updateData := ports_dtos.SaveProductInputs{
-			Name:       inputs.Name,
-			Image:      inputs.Image,
-			SKU:        inputs.SKU,
-			Price:      inputs.Price,
-			Stock:      inputs.Stock,
-			CategoryID: inputs.CategoryID,
+			Name:         inputs.Name,
+			Image:        inputs.Image,
+			SKU:          inputs.SKU,
+			Price:        inputs.Price,
+			Stock:        inputs.Stock,
+			CategoryID:   inputs.CategoryID,
+			Discount:     inputs.Discount,
+			DiscountType: inputs.DiscountType,
+			UpdatedBy:    inputs.UpdatedBy,
+
+			LowStockThreshold: inputs.LowStockThreshold,
+		}
+		previousSKU = prod.SKU
+		if err := prod.Update(updateData); err != nil {
+			return nil, err
 		}
-		prod.Update(updateData)
 		product = prod
 	}
 
@@ -66,6 +93,9 @@ func (ps *ProductService) SaveProduct(ctx context.Context, inputs ports_dtos.Sav
 	if err != nil {
 		return nil, err
 	}
+	// variants and images aren't saved with the product, keep the loaded ones so the cached product is complete
+	result.Variants = product.Variants
+	result.Images = product.Images
 
 	// create new product cache key and serialize product created or udpated
 	cacheKey := cachekeys.Product(result.ID.String())
@@ -80,11 +110,20 @@ func (ps *ProductService) SaveProduct(ctx context.Context, inputs ports_dtos.Sav
 		slog.Warn("error caching new product created", "product_id", result.ID, "error", err)
 	}
 
+	// the old SKU is free again, drop its mapping
+	if previousSKU != "" && previousSKU != result.SKU {
+		err = ps.cache.Delete(ctx, cachekeys.ProductBySKU(previousSKU))
+		if err != nil {
+			slog.Warn("error deleting product SKU mapping of cache", "sku", previousSKU, "error", err)
+		}
+	}
+
 	// invalidate product list
 	err = ps.cache.Delete(ctx, cachekeys.AllProducts())
 	if err != nil {
 		slog.Warn("error invalidating list of all products", "error", err)
 	}
+	ps.invalidateProductQueries(ctx)
 
 	return result, nil
 }
@@ -122,6 +161,57 @@ func (ps *ProductService) GetProductById(ctx context.Context, id uuid.UUID) (*do
 	return product, nil
 }
 
+// GetProductBySlug implements ports.ProductService.
+func (ps *ProductService) GetProductBySlug(ctx context.Context, slug string) (*domain.Product, error) {
+	return ps.getProductByMapping(ctx, cachekeys.ProductBySlug(slug),
+		func(p *domain.Product) bool { return p.Slug == slug },
+		func() (*domain.Product, error) { return ps.repo.GetProductBySlug(ctx, slug) },
+	)
+}
+
+// GetProductBySKU implements ports.ProductService.
+func (ps *ProductService) GetProductBySKU(ctx context.Context, sku string) (*domain.Product, error) {
+	return ps.getProductByMapping(ctx, cachekeys.ProductBySKU(sku),
+		func(p *domain.Product) bool { return p.SKU == sku },
+		func() (*domain.Product, error) { return ps.repo.GetProductBySKU(ctx, sku) },
+	)
+}
+
+// helper func, the cache keeps a mapping between the slug or SKU and the id of the product, the product itself is
+// cached by GetProductById. A stale mapping (deleted product or changed SKU) falls back to the repository
+func (ps *ProductService) getProductByMapping(ctx context.Context, mappingKey string, matches func(*domain.Product) bool, find func() (*domain.Product, error)) (*domain.Product, error) {
+	idBytes, err := ps.cache.Get(ctx, mappingKey)
+	if err == nil && len(idBytes) > 0 {
+		if id, parseErr := uuid.ParseBytes(idBytes); parseErr == nil {
+			product, err := ps.GetProductById(ctx, id)
+			if err == nil && matches(product) {
+				return product, nil
+			}
+		}
+	}
+
+	product, err := find()
+	if err != nil {
+		return nil, err
+	}
+
+	serialized, err := json.Marshal(product)
+	if err != nil {
+		slog.Warn("Error marshaling product for cache", "error", err)
+	}
+
+	err = ps.cache.Set(ctx, cachekeys.Product(product.ID.String()), serialized, cachettl.Product)
+	if err != nil {
+		slog.Warn("error setting product in cache", "product_id", product.ID, "error", err)
+	}
+	err = ps.cache.Set(ctx, mappingKey, []byte(product.ID.String()), cachettl.Product)
+	if err != nil {
+		slog.Warn("error setting product mapping in cache", "key", mappingKey, "product_id", product.ID, "error", err)
+	}
+
+	return product, nil
+}
+
 // ListProducts implements ports.ProductService.
 func (ps *ProductService) ListProducts(ctx context.Context) ([]*domain.Product, error) {
 	// check if the products exists in cache
@@ -158,20 +248,201 @@ func (ps *ProductService) ListProducts(ctx context.Context) ([]*domain.Product,
 func (ps *ProductService) DeleteProduct(ctx context.Context, id uuid.UUID) error {
 	cacheKey := cachekeys.Product(id.String())
 
-	err := ps.repo.DeleteProduct(ctx, id)
+	// load the product to drop the slug and SKU mappings
+	product, err := ps.repo.GetProductById(ctx, id)
 	if err != nil {
 		return err
 	}
 
-	err = ps.cache.Delete(ctx, cacheKey)
+	err = ps.repo.DeleteProduct(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{cacheKey, cachekeys.ProductBySlug(product.Slug), cachekeys.ProductBySKU(product.SKU)} {
+		err = ps.cache.Delete(ctx, key)
+		if err != nil {
+			slog.Warn("error deleteing product of cache", "product_id", id, "key", key, "error", err)
+		}
+	}
+
+	err = ps.cache.Delete(ctx, cachekeys.AllProducts())
+	if err != nil {
+		slog.Warn("error invalidating list of all products", "error", err)
+	}
+	ps.invalidateProductQueries(ctx)
+
+	return nil
+}
+
+// RestoreProduct implements ports.ProductService.
+func (ps *ProductService) RestoreProduct(ctx context.Context, id uuid.UUID) (*domain.Product, error) {
+	err := ps.repo.RestoreProduct(ctx, id)
 	if err != nil {
-		slog.Warn("error deleteing product of cache", "product_id", id, "error", err)
+		return nil, err
 	}
 
 	err = ps.cache.Delete(ctx, cachekeys.AllProducts())
 	if err != nil {
 		slog.Warn("error invalidating list of all products", "error", err)
 	}
+	ps.invalidateProductQueries(ctx)
+
+	return ps.GetProductById(ctx, id)
+}
+
+// SaveVariant implements ports.ProductService.
+func (ps *ProductService) SaveVariant(ctx context.Context, productID uuid.UUID, inputs ports_dtos.SaveVariantInputs) (*domain.Variant, error) {
+	product, err := ps.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	var variant *domain.Variant
+	if inputs.ID == uuid.Nil {
+		// create a new variant if inputs.ID doesn't exist
+		newVariant, err := domain.NewVariant(product.ID, inputs)
+		if err != nil {
+			return nil, err
+		}
+		variant = newVariant
+
+	} else {
+		// the variant must belong to the product of the url
+		existing, err := product.FindVariant(inputs.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		err = existing.Update(inputs)
+		if err != nil {
+			return nil, err
+		}
+		variant = existing
+	}
+
+	result, err := ps.repo.SaveVariant(ctx, variant)
+	if err != nil {
+		return nil, err
+	}
+
+	ps.invalidateProductCache(ctx, product.ID)
+	return result, nil
+}
+
+// ListVariants implements ports.ProductService.
+func (ps *ProductService) ListVariants(ctx context.Context, productID uuid.UUID) ([]domain.Variant, error) {
+	product, err := ps.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	return product.Variants, nil
+}
+
+// DeleteVariant implements ports.ProductService.
+func (ps *ProductService) DeleteVariant(ctx context.Context, productID, variantID uuid.UUID) error {
+	product, err := ps.repo.GetProductById(ctx, productID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := product.FindVariant(variantID); err != nil {
+		return err
+	}
+
+	err = ps.repo.DeleteVariant(ctx, variantID)
+	if err != nil {
+		return err
+	}
 
+	ps.invalidateProductCache(ctx, product.ID)
 	return nil
 }
+
+// helper func, removes the cached product and the cached list after its stock changes
+func (ps *ProductService) invalidateProductCache(ctx context.Context, id uuid.UUID) {
+	err := ps.cache.Delete(ctx, cachekeys.Product(id.String()))
+	if err != nil {
+		slog.Warn("error deleting product of cache", "product_id", id, "error", err)
+	}
+
+	err = ps.cache.Delete(ctx, cachekeys.AllProducts())
+	if err != nil {
+		slog.Warn("error invalidating list of all products", "error", err)
+	}
+	ps.invalidateProductQueries(ctx)
+}
+
+// helper func, any change of a product can move it between the cached search pages
+func (ps *ProductService) invalidateProductQueries(ctx context.Context) {
+	err := ps.cache.DeleteByPrefix(ctx, cachekeys.ProductQueriesPrefix())
+	if err != nil {
+		slog.Warn("error invalidating product search pages", "error", err)
+	}
+}
+
+// helper func, identifies the query in the cache
+func hashProductQuery(query ports_dtos.ProductQuery) string {
+	serialized, _ := json.Marshal(query)
+	sum := sha256.Sum256(serialized)
+	return hex.EncodeToString(sum[:])
+}
+
+// helper func, validates the filters of the search and sets the default sort
+func validateProductQuery(query ports_dtos.ProductQuery) (ports_dtos.ProductQuery, error) {
+	switch query.Sort {
+	case "":
+		query.Sort = ports_dtos.SortNewest
+	case ports_dtos.SortNewest, ports_dtos.SortPriceAsc, ports_dtos.SortPriceDesc, ports_dtos.SortNameAsc, ports_dtos.SortRelevance:
+	default:
+		return query, domain.ErrInvalidProductSort
+	}
+
+	if query.MinPrice != nil && query.MaxPrice != nil && *query.MinPrice > *query.MaxPrice {
+		return query, domain.ErrInvalidPriceRange
+	}
+	return query, nil
+}
+
+// SearchProducts implements ports.ProductService.
+func (ps *ProductService) SearchProducts(ctx context.Context, query ports_dtos.ProductQuery) (*ports.ProductPage, error) {
+	query, err := validateProductQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = defaultProductPageSize
+	}
+	if query.Limit > maxProductPageSize {
+		query.Limit = maxProductPageSize
+	}
+
+	// check if the page exists in cache
+	cacheKey := cachekeys.ProductQuery(hashProductQuery(query))
+	data, err := ps.cache.Get(ctx, cacheKey)
+	if err == nil && len(data) > 0 {
+		var page ports.ProductPage
+		if decodeErr := json.Unmarshal(data, &page); decodeErr == nil {
+			return &page, nil
+		}
+	}
+
+	page, err := ps.repo.SearchProducts(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	serialized, err := json.Marshal(page)
+	if err != nil {
+		slog.Warn("error marshaling product page for cache", "error", err)
+		return page, nil
+	}
+
+	err = ps.cache.Set(ctx, cacheKey, serialized, cachettl.ProductQuery)
+	if err != nil {
+		slog.Warn("error caching product page", "error", err)
+	}
+
+	return page, nil
+}
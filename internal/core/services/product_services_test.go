@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"context"
+	"go-ecommerce/internal/adapters/shared"
 	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
 	"go-ecommerce/internal/core/domain"
 	"go-ecommerce/internal/core/ports/ports_dtos"
@@ -10,6 +11,7 @@ import (
 	"go-ecommerce/internal/test_helpers/mocks"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,7 +36,7 @@ func Test_ProductService_Create(t *testing.T) {
 
 	// factory, create a category as foreign key
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -76,7 +78,7 @@ func Test_ProductService_Update(t *testing.T) {
 
 	// factory, create a category as foreign key
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -102,28 +104,29 @@ func Test_ProductService_Update(t *testing.T) {
 	name := "Ipad 16 pro max"
 	image := "image-ipad16-test"
 	price := 199.99
-	var stock int64 = 48
 
 	updateInputs := ports_dtos.SaveProductInputs{
 		ID:    newProd.ID,
 		Name:  &name,
 		Image: &image,
 		Price: &price,
-		Stock: &stock,
 	}
 	err = newProd.Update(updateInputs)
 	require.NoError(t, err)
 
-	lal := newProd.ToInputs()
-
 	// save the updated product
-	updatedProd, err := prodSrv.SaveProduct(ctx, lal)
+	updatedProd, err := prodSrv.SaveProduct(ctx, updateInputs)
 	require.NoError(t, err)
 
 	assert.Equal(t, name, updatedProd.Name)
 	assert.Equal(t, image, updatedProd.Image)
 	assert.Equal(t, price, updatedProd.Price)
-	assert.Equal(t, stock, updatedProd.Stock)
+	assert.Equal(t, p.Stock, updatedProd.Stock)
+
+	// the stock only changes through the inventory
+	var stock int64 = 48
+	_, err = prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{ID: newProd.ID, Stock: &stock})
+	assert.ErrorIs(t, err, domain.ErrProductStockNotEditable)
 }
 func Test_ProductService_FindByID(t *testing.T) {
 	t.Helper()
@@ -145,7 +148,7 @@ func Test_ProductService_FindByID(t *testing.T) {
 
 	// factory, create a category as foreign key
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -195,7 +198,7 @@ func Test_ProductService_Delete(t *testing.T) {
 
 	// factory, create a category as foreign key
 	c := testhelpers.NewDomainCategory("Tablets")
-	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name)
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, c.Name, nil)
 	require.NoError(t, err)
 	assert.Equal(t, c.Name, savedCateg.Name)
 
@@ -224,3 +227,171 @@ func Test_ProductService_Delete(t *testing.T) {
 	_, err = prodSrv.GetProductById(ctx, newProd.ID)
 	assert.Error(t, domain.ErrProductNotFound, err)
 }
+
+func Test_ProductService_Search(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Notebooks", nil)
+	require.NoError(t, err)
+
+	newProduct := func(name, sku string) {
+		p := testhelpers.NewDomainProduct(name, savedCateg.ID)
+		_, err := prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{
+			Name: &name, Image: &p.Image, SKU: &sku, Price: &p.Price, Stock: &p.Stock, CategoryID: &savedCateg.ID,
+		})
+		require.NoError(t, err)
+	}
+	newProduct("Macbook Air", "mba-13")
+
+	query := ports_dtos.ProductQuery{Q: "macbook"}
+	page, err := prodSrv.SearchProducts(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), page.Total)
+
+	// a new product invalidates the cached pages
+	newProduct("Macbook Pro", "mbp-14")
+	page, err = prodSrv.SearchProducts(ctx, query)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), page.Total)
+
+	_, err = prodSrv.SearchProducts(ctx, ports_dtos.ProductQuery{Sort: "cheapest"})
+	assert.ErrorIs(t, err, domain.ErrInvalidProductSort)
+
+	minPrice, maxPrice := 100.0, 10.0
+	_, err = prodSrv.SearchProducts(ctx, ports_dtos.ProductQuery{MinPrice: &minPrice, MaxPrice: &maxPrice})
+	assert.ErrorIs(t, err, domain.ErrInvalidPriceRange)
+}
+
+func Test_ProductService_Variants(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodRepo := repository.NewProductRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	cartSrv := services.NewCartService(repository.NewCartRepo(tx), redis, prodSrv)
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, repository.NewUserRepo(tx), &mocks.MockNotifier{})
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), repository.NewWarehouseRepo(tx), prodRepo, stockAlertSrv, redis)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "T-Shirts", nil)
+	require.NoError(t, err)
+
+	p := testhelpers.NewDomainProduct("Basic T-Shirt", savedCateg.ID)
+	product, err := prodSrv.SaveProduct(ctx, p.ToInputs())
+	require.NoError(t, err)
+
+	// cache the product before adding variants, they must invalidate it
+	_, err = prodSrv.GetProductById(ctx, product.ID)
+	require.NoError(t, err)
+
+	sku, stock, price := "tshirt-m-red", int64(2), 25.0
+	variant, err := prodSrv.SaveVariant(ctx, product.ID, ports_dtos.SaveVariantInputs{
+		SKU:     &sku,
+		Options: map[string]string{"size": "M", "color": "red"},
+		Price:   &price,
+		Stock:   &stock,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "color: red, size: M", variant.Label())
+
+	_, err = prodSrv.SaveVariant(ctx, product.ID, ports_dtos.SaveVariantInputs{SKU: &sku})
+	assert.Equal(t, domain.ErrVariantOptionsIsRequire, err)
+
+	variants, err := prodSrv.ListVariants(ctx, product.ID)
+	require.NoError(t, err)
+	require.Len(t, variants, 1)
+
+	// a product with variants can't be added to the cart without one
+	userID := uuid.New()
+	err = cartSrv.AddItemToCart(ctx, userID, product.ID, nil, 1)
+	assert.Equal(t, domain.ErrVariantIsRequire, err)
+
+	unknown := uuid.New()
+	err = cartSrv.AddItemToCart(ctx, userID, product.ID, &unknown, 1)
+	assert.Equal(t, domain.ErrVariantNotFound, err)
+
+	err = cartSrv.AddItemToCart(ctx, userID, product.ID, &variant.ID, 2)
+	require.NoError(t, err)
+
+	// the price override of the variant is used
+	amount, err := cartSrv.CalcItemsAmount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, amount.SubTotal)
+
+	// the stock is reserved from the variant, not the product
+	items := []domain.CartItem{{ProductID: product.ID, VariantID: &variant.ID, Quantity: 2}}
+	_, err = inventorySrv.ReserveItems(ctx, items, domain.AllocateMostStock, nil)
+	require.NoError(t, err)
+	items[0].Quantity = 1
+	_, err = inventorySrv.ReserveItems(ctx, items, domain.AllocateMostStock, nil)
+	assert.Equal(t, domain.ErrVariantInsufficientStock, err)
+
+	reloaded, err := prodSrv.GetProductById(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, p.Stock, reloaded.Stock)
+	assert.Equal(t, int64(0), reloaded.Variants[0].Stock)
+
+	err = prodSrv.DeleteVariant(ctx, product.ID, variant.ID)
+	require.NoError(t, err)
+	variants, err = prodSrv.ListVariants(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Empty(t, variants)
+}
+
+func Test_ProductService_FindBySlugAndSKU(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodSrv := services.NewProductService(repository.NewProductRepo(tx), redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+
+	savedCateg, err := categSrv.SaveCategory(ctx, 0, "Tablets", nil)
+	require.NoError(t, err)
+
+	product, err := prodSrv.SaveProduct(ctx, testhelpers.NewDomainProduct("Ipad 14 Pro", savedCateg.ID).ToInputs())
+	require.NoError(t, err)
+	assert.Equal(t, "ipad-14-pro", product.Slug)
+
+	bySlug, err := prodSrv.GetProductBySlug(ctx, "ipad-14-pro")
+	require.NoError(t, err)
+	assert.Equal(t, product.ID, bySlug.ID)
+
+	bySKU, err := prodSrv.GetProductBySKU(ctx, product.SKU)
+	require.NoError(t, err)
+	assert.Equal(t, product.ID, bySKU.ID)
+
+	// the old SKU stops resolving after an update, the slug is kept
+	oldSKU, newSKU := product.SKU, "ipad-14-pro-wifi"
+	updated, err := prodSrv.SaveProduct(ctx, ports_dtos.SaveProductInputs{ID: product.ID, SKU: &newSKU})
+	require.NoError(t, err)
+	assert.Equal(t, product.Slug, updated.Slug)
+
+	_, err = prodSrv.GetProductBySKU(ctx, oldSKU)
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+	bySKU, err = prodSrv.GetProductBySKU(ctx, newSKU)
+	require.NoError(t, err)
+	assert.Equal(t, product.ID, bySKU.ID)
+
+	// a second product can't take the SKU
+	other := testhelpers.NewDomainProduct("Ipad Mini", savedCateg.ID)
+	other.SKU = newSKU
+	_, err = prodSrv.SaveProduct(ctx, other.ToInputs())
+	assert.ErrorIs(t, err, shared.ErrConflictingData)
+
+	require.NoError(t, prodSrv.DeleteProduct(ctx, product.ID))
+	_, err = prodSrv.GetProductBySlug(ctx, "ipad-14-pro")
+	assert.ErrorIs(t, err, domain.ErrProductNotFound)
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+)
+
+// default amount of products returned by top products report
+const defaultTopProductsLimit = 10
+
+type ReportService struct {
+	repo ports.ReportRepository
+}
+
+func NewReportService(repo ports.ReportRepository) ports.ReportService {
+	return &ReportService{repo: repo}
+}
+
+// helper func
+func validateReportFilters(filters ports_dtos.ReportFilters) error {
+	if !filters.From.Before(filters.To) {
+		return domain.ErrInvalidReportRange
+	}
+	return nil
+}
+
+// Revenue implements ports.ReportService.
+func (rs *ReportService) Revenue(ctx context.Context, filters ports_dtos.ReportFilters, period domain.ReportPeriod) ([]domain.RevenueBucket, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+	if !period.IsValid() {
+		return nil, domain.ErrInvalidReportPeriod
+	}
+
+	orders, err := rs.repo.ListPaidOrders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.BuildRevenueBuckets(orders, period), nil
+}
+
+// FeesByPayMethod implements ports.ReportService.
+func (rs *ReportService) FeesByPayMethod(ctx context.Context, filters ports_dtos.ReportFilters) ([]domain.FeesByPayMethod, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+
+	orders, err := rs.repo.ListPaidOrders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	return domain.BuildFeesByPayMethod(orders), nil
+}
+
+// AverageOrderValue implements ports.ReportService.
+func (rs *ReportService) AverageOrderValue(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.AverageOrderValue, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+
+	orders, err := rs.repo.ListPaidOrders(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	aov := domain.CalcAverageOrderValue(orders)
+	return &aov, nil
+}
+
+// TopProducts implements ports.ReportService.
+func (rs *ReportService) TopProducts(ctx context.Context, filters ports_dtos.ReportFilters, rankBy domain.TopProductsRanking, limit int) ([]domain.TopProduct, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+	if rankBy != domain.ByUnits && rankBy != domain.ByRevenue {
+		return nil, domain.ErrInvalidReportRanking
+	}
+	if limit <= 0 {
+		limit = defaultTopProductsLimit
+	}
+
+	return rs.repo.TopProducts(ctx, filters, rankBy, limit)
+}
+
+// Conversion implements ports.ReportService.
+func (rs *ReportService) Conversion(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.Conversion, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+
+	counts, err := rs.repo.CountOrdersByStatus(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion := domain.CalcConversion(counts)
+	return &conversion, nil
+}
+
+// Chargebacks implements ports.ReportService.
+func (rs *ReportService) Chargebacks(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.ChargebackSummary, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+
+	disputes, err := rs.repo.ListDisputes(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := domain.BuildChargebackSummary(disputes)
+	return &summary, nil
+}
+
+// CartRecovery implements ports.ReportService.
+func (rs *ReportService) CartRecovery(ctx context.Context, filters ports_dtos.ReportFilters) (*domain.CartRecoverySummary, error) {
+	if err := validateReportFilters(filters); err != nil {
+		return nil, err
+	}
+
+	recoveries, err := rs.repo.ListCartRecoveries(ctx, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := domain.BuildCartRecoverySummary(recoveries)
+	return &summary, nil
+}
@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ReportServices(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	userRepo := repository.NewUserRepo(tx)
+	categRepo := repository.NewCategoryRepo(tx)
+	prodRepo := repository.NewProductRepo(tx)
+	opRepo := repository.NewOrderProductRepo(tx)
+	opSrv := services.NewOrderProductService(opRepo)
+	orderRepo := repository.NewOrderRepo(opSrv, tx)
+	reportSrv := services.NewReportService(repository.NewReportRepo(tx))
+
+	newUser, err := userRepo.SaveUser(ctx, testhelpers.NewDomainUser("John", "john@report.test"))
+	require.NoError(t, err)
+
+	newCateg, err := categRepo.SaveCategory(ctx, testhelpers.NewDomainCategory("Notebooks"))
+	require.NoError(t, err)
+
+	newProd, err := prodRepo.SaveProduct(ctx, testhelpers.NewDomainProduct("Macbook Air", newCateg.ID))
+	require.NoError(t, err)
+
+	// two paid orders in different days and one pending order
+	day := time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)
+	card := "visa"
+	for i, total := range []float64{100, 300} {
+		paidAt := day.AddDate(0, 0, i)
+		fee := total * 0.1
+		net := total - fee
+
+		o := testhelpers.NewDomainOrder(newUser.ID)
+		o.Paid = true
+		o.PaidAt = &paidAt
+		o.Total = total
+		o.Fee = &fee
+		o.NetReceivedAmount = &net
+		o.PayMethod = &card
+		o.CreatedAt = paidAt
+
+		savedOrder, err := orderRepo.SaveOrder(ctx, o)
+		require.NoError(t, err)
+
+		// ordered with a discount of 1 on a unit price of 12, the product costs 10 now
+		_, err = opSrv.AddProductToOrder(ctx, savedOrder.ID, newProd.ID, nil, int16(i+1), 12, 1)
+		require.NoError(t, err)
+	}
+
+	pending := testhelpers.NewDomainOrder(newUser.ID)
+	pending.PayStatus = domain.Pending
+	pending.CreatedAt = day
+	_, err = orderRepo.SaveOrder(ctx, pending)
+	require.NoError(t, err)
+
+	filters := ports_dtos.ReportFilters{From: day.AddDate(0, 0, -1), To: day.AddDate(0, 0, 5)}
+
+	// revenue by day
+	buckets, err := reportSrv.Revenue(ctx, filters, domain.Daily)
+	require.NoError(t, err)
+	require.Len(t, buckets, 2)
+	assert.Equal(t, 100.0, buckets[0].Gross)
+	assert.Equal(t, 90.0, buckets[0].Net)
+	assert.Equal(t, 30.0, buckets[1].Fees)
+
+	// revenue by month groups both orders
+	buckets, err = reportSrv.Revenue(ctx, filters, domain.Monthly)
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, int64(2), buckets[0].Orders)
+
+	// fees by pay method
+	fees, err := reportSrv.FeesByPayMethod(ctx, filters)
+	require.NoError(t, err)
+	require.Len(t, fees, 1)
+	assert.Equal(t, card, fees[0].PayMethod)
+	assert.Equal(t, 40.0, fees[0].Fees)
+
+	// average order value
+	aov, err := reportSrv.AverageOrderValue(ctx, filters)
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, aov.Average)
+
+	// top products
+	top, err := reportSrv.TopProducts(ctx, filters, domain.ByUnits, 5)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, newProd.ID, top[0].ProductID)
+	assert.Equal(t, int64(3), top[0].Units)
+
+	// the revenue uses the prices when the products were ordered
+	top, err = reportSrv.TopProducts(ctx, filters, domain.ByRevenue, 5)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, 33.0, top[0].Revenue)
+
+	// conversion
+	conversion, err := reportSrv.Conversion(ctx, filters)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), conversion.Created)
+	assert.Equal(t, int64(2), conversion.Approved)
+
+	// invalid filters
+	_, err = reportSrv.Revenue(ctx, filters, domain.ReportPeriod("year"))
+	assert.ErrorIs(t, err, domain.ErrInvalidReportPeriod)
+
+	_, err = reportSrv.Conversion(ctx, ports_dtos.ReportFilters{From: filters.To, To: filters.From})
+	assert.ErrorIs(t, err, domain.ErrInvalidReportRange)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"log/slog"
+
+	"github.com/google/uuid"
+)
+
+type StockAlertService struct {
+	repo        ports.StockAlertRepository
+	productRepo ports.ProductRepository
+	userRepo    ports.UserRepository
+	notifier    ports.Notifier
+}
+
+func NewStockAlertService(repo ports.StockAlertRepository, productRepo ports.ProductRepository, userRepo ports.UserRepository, notifier ports.Notifier) ports.StockAlertService {
+	return &StockAlertService{
+		repo:        repo,
+		productRepo: productRepo,
+		userRepo:    userRepo,
+		notifier:    notifier,
+	}
+}
+
+// Subscribe implements ports.StockAlertService.
+func (ss *StockAlertService) Subscribe(ctx context.Context, productID uuid.UUID, userID uuid.UUID) (*domain.StockAlertSubscription, error) {
+	product, err := ss.productRepo.GetProductById(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Stock > 0 {
+		return nil, domain.ErrStockAlertProductInStock
+	}
+
+	return ss.repo.Subscribe(ctx, &domain.StockAlertSubscription{ProductID: productID, UserID: userID})
+}
+
+// Unsubscribe implements ports.StockAlertService.
+func (ss *StockAlertService) Unsubscribe(ctx context.Context, productID uuid.UUID, userID uuid.UUID) error {
+	return ss.repo.Unsubscribe(ctx, productID, userID)
+}
+
+// CheckStock implements ports.StockAlertService.
+// The low stock alert is sent once while the stock is low. Subscriptions are only taken while the product is out of
+// stock, so a product with stock and subscriptions went from 0 to positive. Archived products aren't alerted
+func (ss *StockAlertService) CheckStock(ctx context.Context, productID uuid.UUID) error {
+	product, err := ss.productRepo.GetProductById(ctx, productID)
+	if err == domain.ErrProductNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if product.IsLowStock() {
+		if err := ss.alertLowStock(ctx, product); err != nil {
+			return err
+		}
+	} else if product.LowStockAlertedAt != nil {
+		// the stock recovered, the next fall is alerted again
+		if err := ss.repo.ClearLowStock(ctx, product.ID); err != nil {
+			return err
+		}
+	}
+
+	if product.Stock > 0 {
+		return ss.alertBackInStock(ctx, product)
+	}
+	return nil
+}
+
+// CheckAllStock implements ports.StockAlertService.
+// Every product is checked even if one of them fails, the first error is returned
+func (ss *StockAlertService) CheckAllStock(ctx context.Context) error {
+	ids, err := ss.repo.ListProductsToCheck(ctx)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, id := range ids {
+		if err := ss.CheckStock(ctx, id); err != nil {
+			slog.Error("error checking stock alerts", "product_id", id, "error", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
+// helper func, notifies the sellers and admins
+func (ss *StockAlertService) alertLowStock(ctx context.Context, product *domain.Product) error {
+	marked, err := ss.repo.MarkLowStock(ctx, product.ID)
+	if err != nil || !marked {
+		return err
+	}
+
+	recipients, err := ss.userRepo.ListUsersByRole(ctx, domain.Admin, domain.Seller)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"product_id": product.ID,
+		"name":       product.Name,
+		"sku":        product.SKU,
+		"stock":      product.Stock,
+		"threshold":  product.LowStockThreshold,
+	}
+	for _, user := range recipients {
+		err := ss.notifier.Notify(ctx, domain.NewNotification(domain.NotificationLowStock, user, data))
+		if err != nil {
+			slog.Error("error sending low stock alert", "product_id", product.ID, "user_id", user.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// helper func, notifies the subscribed customers. The subscription is claimed before the notification so concurrent
+// checks send it once
+func (ss *StockAlertService) alertBackInStock(ctx context.Context, product *domain.Product) error {
+	subscriptions, err := ss.repo.ListSubscriptions(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	data := map[string]any{
+		"product_id": product.ID,
+		"name":       product.Name,
+		"slug":       product.Slug,
+		"stock":      product.Stock,
+	}
+	for _, subscription := range subscriptions {
+		claimed, err := ss.repo.ClaimSubscription(ctx, subscription.ID)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			continue
+		}
+
+		user, err := ss.userRepo.GetUserByID(ctx, subscription.UserID)
+		if err != nil {
+			slog.Warn("skipping back in stock alert of unknown user", "product_id", product.ID, "user_id", subscription.UserID, "error", err)
+			continue
+		}
+
+		err = ss.notifier.Notify(ctx, domain.NewNotification(domain.NotificationBackInStock, user, data))
+		if err != nil {
+			slog.Error("error sending back in stock alert", "product_id", product.ID, "user_id", user.ID, "error", err)
+		}
+	}
+	return nil
+}
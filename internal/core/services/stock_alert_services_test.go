@@ -0,0 +1,132 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_StockAlertService_Alerts(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	notifier := &mocks.MockNotifier{}
+	userRepo := repository.NewUserRepo(tx)
+	prodRepo := repository.NewProductRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, userRepo, notifier)
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), repository.NewWarehouseRepo(tx), prodRepo, stockAlertSrv, redis)
+
+	newUser := func(name string, role domain.UserRole) *domain.User {
+		user := testhelpers.NewDomainUser(name, name+"-"+uuid.NewString()[:8]+"@test.com")
+		user.Role = role
+		saved, err := userRepo.SaveUser(ctx, user)
+		require.NoError(t, err)
+		return saved
+	}
+	newUser("admin", domain.Admin)
+	newUser("seller", domain.Seller)
+	customer := newUser("customer", domain.Client)
+
+	category, err := categSrv.SaveCategory(ctx, 0, "Consoles", nil)
+	require.NoError(t, err)
+
+	threshold := int64(10)
+	inputs := testhelpers.NewDomainProduct("Steam Deck", category.ID).ToInputs()
+	inputs.LowStockThreshold = &threshold
+	product, err := prodSrv.SaveProduct(ctx, inputs)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), product.LowStockThreshold)
+
+	adjust := func(quantity int64) {
+		_, err := inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{
+			Type: string(domain.StockAdjustment), Quantity: quantity, Reason: "count",
+		})
+		require.NoError(t, err)
+	}
+
+	t.Run("alerts sellers and admins once when the stock falls to the threshold", func(t *testing.T) {
+		adjust(-85)
+		assert.Empty(t, notifier.Sent(domain.NotificationLowStock))
+
+		adjust(-6)
+		adjust(-1)
+
+		sent := notifier.Sent(domain.NotificationLowStock)
+		require.Len(t, sent, 2)
+		for _, n := range sent {
+			assert.NotEqual(t, customer.ID, n.UserID)
+			assert.Equal(t, int64(9), n.Data["stock"])
+		}
+	})
+
+	t.Run("alerts the subscribed customers when the product is back in stock", func(t *testing.T) {
+		_, err := stockAlertSrv.Subscribe(ctx, product.ID, customer.ID)
+		assert.ErrorIs(t, err, domain.ErrStockAlertProductInStock)
+
+		adjust(-8)
+
+		subscription, err := stockAlertSrv.Subscribe(ctx, product.ID, customer.ID)
+		require.NoError(t, err)
+		again, err := stockAlertSrv.Subscribe(ctx, product.ID, customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, subscription.ID, again.ID)
+
+		_, err = inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{
+			Type: string(domain.StockReceipt), Quantity: 20, Reason: "supplier delivery",
+		})
+		require.NoError(t, err)
+
+		sent := notifier.Sent(domain.NotificationBackInStock)
+		require.Len(t, sent, 1)
+		assert.Equal(t, customer.ID, sent[0].UserID)
+		assert.Equal(t, customer.Email, sent[0].To)
+
+		// the alert is sent once, the subscription is removed
+		err = stockAlertSrv.Unsubscribe(ctx, product.ID, customer.ID)
+		assert.ErrorIs(t, err, domain.ErrStockAlertNotFound)
+
+		// the stock recovered over the threshold, the next fall is alerted again
+		adjust(-15)
+		assert.Len(t, notifier.Sent(domain.NotificationLowStock), 4)
+	})
+
+	t.Run("sends the pending alerts of the stock changed outside the inventory", func(t *testing.T) {
+		adjust(-5)
+		_, err := stockAlertSrv.Subscribe(ctx, product.ID, customer.ID)
+		require.NoError(t, err)
+
+		before, err := prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		movement, err := domain.NewStockMovement(product.ID, nil, nil, domain.StockReceipt, 30-before.Stock, "supplier delivery", nil)
+		require.NoError(t, err)
+		_, err = repository.NewInventoryRepo(tx).SaveMovement(ctx, movement)
+		require.NoError(t, err)
+		assert.Len(t, notifier.Sent(domain.NotificationBackInStock), 1)
+
+		require.NoError(t, stockAlertSrv.CheckAllStock(ctx))
+		assert.Len(t, notifier.Sent(domain.NotificationBackInStock), 2)
+
+		current, err := prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Nil(t, current.LowStockAlertedAt)
+
+		// nothing is pending anymore
+		require.NoError(t, stockAlertSrv.CheckAllStock(ctx))
+		assert.Len(t, notifier.Sent(domain.NotificationBackInStock), 2)
+		assert.Len(t, notifier.Sent(domain.NotificationLowStock), 4)
+	})
+}
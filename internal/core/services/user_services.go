@@ -14,16 +14,18 @@ import (
 )
 
 type UserService struct {
-	repo   ports.UserRepository
-	cache  ports.CacheRepository
-	hasher domain.PasswordHasher
+	repo     ports.UserRepository
+	cache    ports.CacheRepository
+	hasher   domain.PasswordHasher
+	notifier ports.Notifier
 }
 
-func NewUserService(repo ports.UserRepository, cache ports.CacheRepository, hasher domain.PasswordHasher) ports.UserService {
+func NewUserService(repo ports.UserRepository, cache ports.CacheRepository, hasher domain.PasswordHasher, notifier ports.Notifier) ports.UserService {
 	return &UserService{
-		repo:   repo,
-		cache:  cache,
-		hasher: hasher,
+		repo:     repo,
+		cache:    cache,
+		hasher:   hasher,
+		notifier: notifier,
 	}
 }
 
@@ -38,6 +40,7 @@ func (us *UserService) SaveUser(ctx context.Context, inputs domain.SaveUserInput
 			Email:    inputs.Email,
 			Password: inputs.Password,
 			Role:     inputs.Role,
+			Locale:   inputs.Locale,
 		}
 
 		// find user before update
@@ -70,6 +73,7 @@ func (us *UserService) SaveUser(ctx context.Context, inputs domain.SaveUserInput
 			Email:    inputs.Email,
 			Password: inputs.Password,
 			Role:     inputs.Role,
+			Locale:   inputs.Locale,
 		}
 		existingUser.UpdateUser(inputs, us.hasher)
 		user = existingUser
@@ -106,6 +110,14 @@ func (us *UserService) SaveUser(ctx context.Context, inputs domain.SaveUserInput
 		slog.Warn("error invalidating list of all users", "error", err)
 	}
 
+	// welcome the new users, a failure doesn't undo the registration
+	if inputs.ID == uuid.Nil {
+		err = us.notifier.Notify(ctx, domain.NewNotification(domain.NotificationWelcome, result, nil))
+		if err != nil {
+			slog.Error("error sending welcome notification", "user_id", result.ID, "error", err)
+		}
+	}
+
 	return result, nil
 }
 
@@ -237,3 +249,18 @@ func (us *UserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// RestoreUser implements ports.UserService.
+func (us *UserService) RestoreUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	err := us.repo.RestoreUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = us.cache.Delete(ctx, cachekeys.AllUsers())
+	if err != nil {
+		slog.Warn("error invalidating list of all users", "error", err)
+	}
+
+	return us.GetUserByID(ctx, id)
+}
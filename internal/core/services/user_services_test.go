@@ -26,7 +26,7 @@ func newUserServices(t *testing.T) ports.UserService {
 	hasher := &security.Hasher{}
 
 	repo := repository.NewUserRepo(tx)
-	srv := services.NewUserService(repo, redis, hasher)
+	srv := services.NewUserService(repo, redis, hasher, &mocks.MockNotifier{})
 
 	return srv
 }
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+
+	"github.com/google/uuid"
+)
+
+type WarehouseService struct {
+	repo ports.WarehouseRepository
+}
+
+func NewWarehouseService(repo ports.WarehouseRepository) ports.WarehouseService {
+	return &WarehouseService{
+		repo: repo,
+	}
+}
+
+// SaveWarehouse implements ports.WarehouseService.
+func (ws *WarehouseService) SaveWarehouse(ctx context.Context, inputs ports_dtos.SaveWarehouseInputs) (*domain.Warehouse, error) {
+	// if inputs.ID doesn't exist create a new warehouse
+	if inputs.ID == uuid.Nil {
+		warehouse, err := domain.NewWarehouse(inputs)
+		if err != nil {
+			return nil, err
+		}
+		return ws.repo.SaveWarehouse(ctx, warehouse)
+	}
+
+	warehouse, err := ws.repo.GetWarehouseById(ctx, inputs.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := warehouse.Update(inputs); err != nil {
+		return nil, err
+	}
+	return ws.repo.SaveWarehouse(ctx, warehouse)
+}
+
+// GetWarehouseById implements ports.WarehouseService.
+func (ws *WarehouseService) GetWarehouseById(ctx context.Context, id uuid.UUID) (*domain.Warehouse, error) {
+	return ws.repo.GetWarehouseById(ctx, id)
+}
+
+// ListWarehouses implements ports.WarehouseService.
+func (ws *WarehouseService) ListWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	return ws.repo.ListWarehouses(ctx)
+}
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/shared"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/core/ports/ports_dtos"
+	"go-ecommerce/internal/core/services"
+	testhelpers "go-ecommerce/internal/test_helpers"
+	"go-ecommerce/internal/test_helpers/mocks"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WarehouseService_Allocation(t *testing.T) {
+	ctx := context.Background()
+	db := testhelpers.NewSQLiteTestDB(t)
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+
+	redis := mocks.NewMockRedis()
+	prodRepo := repository.NewProductRepo(tx)
+	warehouseRepo := repository.NewWarehouseRepo(tx)
+	prodSrv := services.NewProductService(prodRepo, redis)
+	categSrv := services.NewCategoryService(repository.NewCategoryRepo(tx), redis)
+	warehouseSrv := services.NewWarehouseService(warehouseRepo)
+	stockAlertSrv := services.NewStockAlertService(repository.NewStockAlertRepo(tx), prodRepo, repository.NewUserRepo(tx), &mocks.MockNotifier{})
+	inventorySrv := services.NewInventoryService(repository.NewInventoryRepo(tx), warehouseRepo, prodRepo, stockAlertSrv, redis)
+
+	category, err := categSrv.SaveCategory(ctx, 0, "Consoles", nil)
+	require.NoError(t, err)
+
+	// the 100 units of the new product go to the default warehouse, it has no location
+	product, err := prodSrv.SaveProduct(ctx, testhelpers.NewDomainProduct("Nintendo Switch", category.ID).ToInputs())
+	require.NoError(t, err)
+
+	newWarehouse := func(code string, latitude, longitude float64, units int64) *domain.Warehouse {
+		name := code + " warehouse"
+		warehouse, err := warehouseSrv.SaveWarehouse(ctx, ports_dtos.SaveWarehouseInputs{Code: &code, Name: &name, Latitude: &latitude, Longitude: &longitude})
+		require.NoError(t, err)
+
+		_, err = inventorySrv.RecordMovement(ctx, product.ID, ports_dtos.StockMovementInputs{
+			WarehouseID: &warehouse.ID, Type: string(domain.StockReceipt), Quantity: units, Reason: "supplier delivery",
+		})
+		require.NoError(t, err)
+		return warehouse
+	}
+	north := newWarehouse("north", 43.26, -2.93, 10)
+	south := newWarehouse("south", 36.72, -4.42, 30)
+	nearSouth := &domain.GeoPoint{Latitude: 37.39, Longitude: -5.98}
+	nearNorth := &domain.GeoPoint{Latitude: 43.36, Longitude: -5.85}
+
+	t.Run("saves the warehouses", func(t *testing.T) {
+		assert.Equal(t, "NORTH", north.Code)
+		assert.True(t, north.Active)
+		assert.False(t, north.IsDefault)
+
+		code, name := "north", "Another north"
+		_, err := warehouseSrv.SaveWarehouse(ctx, ports_dtos.SaveWarehouseInputs{Code: &code, Name: &name})
+		assert.ErrorIs(t, err, shared.ErrConflictingData)
+
+		latitude := 91.0
+		_, err = warehouseSrv.SaveWarehouse(ctx, ports_dtos.SaveWarehouseInputs{ID: north.ID, Latitude: &latitude, Longitude: north.Longitude})
+		assert.ErrorIs(t, err, domain.ErrWarehouseInvalidLocation)
+
+		_, err = warehouseSrv.GetWarehouseById(ctx, uuid.New())
+		assert.ErrorIs(t, err, domain.ErrWarehouseNotFound)
+	})
+
+	t.Run("aggregates the stock of the warehouses", func(t *testing.T) {
+		current, err := prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(140), current.Stock)
+
+		report, err := inventorySrv.StockAt(ctx, product.ID, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, int64(140), report.Stock)
+
+		byWarehouse := map[uuid.UUID]int64{}
+		for _, level := range report.Warehouses {
+			byWarehouse[*level.WarehouseID] += level.Stock
+		}
+		assert.Equal(t, int64(10), byWarehouse[north.ID])
+		assert.Equal(t, int64(30), byWarehouse[south.ID])
+	})
+
+	t.Run("allocates the order to one warehouse by the rule", func(t *testing.T) {
+		items := []domain.CartItem{{ProductID: product.ID, Quantity: 20}}
+
+		closest, err := inventorySrv.ReserveItems(ctx, items, domain.AllocateClosest, nearSouth)
+		require.NoError(t, err)
+		require.Len(t, closest, 1)
+		assert.Equal(t, south.ID, closest[0].WarehouseID)
+		assert.Equal(t, domain.AllocationReserved, closest[0].Status)
+
+		// the default warehouse has the most units
+		mostStock, err := inventorySrv.ReserveItems(ctx, items, domain.AllocateMostStock, nil)
+		require.NoError(t, err)
+		require.Len(t, mostStock, 1)
+		assert.NotEqual(t, north.ID, mostStock[0].WarehouseID)
+		assert.NotEqual(t, south.ID, mostStock[0].WarehouseID)
+
+		require.NoError(t, inventorySrv.ReleaseAllocations(ctx, append(closest, mostStock...)))
+
+		_, err = inventorySrv.ReserveItems(ctx, items, domain.AllocateClosest, nil)
+		assert.ErrorIs(t, err, domain.ErrAllocationLocationIsRequire)
+
+		_, err = inventorySrv.ReserveItems(ctx, items, "cheapest", nil)
+		assert.ErrorIs(t, err, domain.ErrInvalidAllocationRule)
+	})
+
+	t.Run("splits the order when no warehouse fills it", func(t *testing.T) {
+		allocations, err := inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: product.ID, Quantity: 130}}, domain.AllocateClosest, nearNorth)
+		require.NoError(t, err)
+		require.Len(t, allocations, 3)
+		assert.Equal(t, north.ID, allocations[0].WarehouseID)
+		assert.Equal(t, int64(10), allocations[0].Quantity)
+		assert.Equal(t, south.ID, allocations[1].WarehouseID)
+		assert.Equal(t, int64(30), allocations[1].Quantity)
+		assert.Equal(t, int64(90), allocations[2].Quantity)
+
+		_, err = inventorySrv.ReserveItems(ctx, []domain.CartItem{{ProductID: product.ID, Quantity: 11}}, "", nil)
+		assert.ErrorIs(t, err, domain.ErrProductInsufficientStock)
+
+		// the order is paid, its allocations are sold and can't be released anymore
+		order := &domain.Order{ID: uuid.New(), Items: []domain.OrderProduct{{ProductID: product.ID, Quantity: 130}}}
+		require.NoError(t, inventorySrv.AssignOrder(ctx, allocations, order.ID))
+		require.NoError(t, inventorySrv.RecordSale(ctx, order))
+		require.NoError(t, inventorySrv.ReleaseOrder(ctx, order))
+
+		sold, err := inventorySrv.ListOrderAllocations(ctx, order.ID)
+		require.NoError(t, err)
+		require.Len(t, sold, 3)
+		for _, allocation := range sold {
+			assert.Equal(t, domain.AllocationSold, allocation.Status)
+		}
+
+		current, err := prodRepo.GetProductById(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10), current.Stock)
+
+		discrepancies, err := inventorySrv.AuditStock(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, discrepancies)
+	})
+
+	t.Run("moves the default to another warehouse", func(t *testing.T) {
+		isDefault := true
+		updated, err := warehouseSrv.SaveWarehouse(ctx, ports_dtos.SaveWarehouseInputs{ID: north.ID, IsDefault: &isDefault})
+		require.NoError(t, err)
+		assert.True(t, updated.IsDefault)
+
+		warehouses, err := warehouseSrv.ListWarehouses(ctx)
+		require.NoError(t, err)
+		defaults := 0
+		for _, w := range warehouses {
+			if w.IsDefault {
+				defaults++
+			}
+		}
+		assert.Equal(t, 1, defaults)
+
+		notDefault, inactive := false, false
+		_, err = warehouseSrv.SaveWarehouse(ctx, ports_dtos.SaveWarehouseInputs{ID: north.ID, IsDefault: &notDefault})
+		assert.ErrorIs(t, err, domain.ErrWarehouseDefaultIsRequire)
+
+		_, err = warehouseSrv.SaveWarehouse(ctx, ports_dtos.SaveWarehouseInputs{ID: north.ID, Active: &inactive})
+		assert.ErrorIs(t, err, domain.ErrWarehouseDefaultInactive)
+	})
+}
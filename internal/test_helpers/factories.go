@@ -30,7 +30,7 @@ func NewDomainProduct(name string, categoryId uint64) *domain.Product {
 	return &domain.Product{
 		Name:       name,
 		CategoryID: categoryId,
-		SKU:        "product-test",
+		SKU:        "product-test-" + uuid.NewString()[:8], // SKUs are unique
 		Stock:      100,
 		Price:      10,
 		Image:      "product-image-test",
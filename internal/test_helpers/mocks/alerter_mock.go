@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"sync"
+)
+
+type Alert struct {
+	Subject string
+	Attrs   map[string]any
+}
+
+// MockAlerter keeps the raised alerts in memory
+type MockAlerter struct {
+	mu     sync.Mutex
+	Alerts []Alert
+}
+
+// Alert implements ports.Alerter.
+func (m *MockAlerter) Alert(ctx context.Context, subject string, attrs map[string]any) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Alerts = append(m.Alerts, Alert{Subject: subject, Attrs: attrs})
+	return nil
+}
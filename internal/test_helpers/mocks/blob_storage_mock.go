@@ -0,0 +1,43 @@
+package mocks
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// MockBlobStorage keeps the stored files in memory
+type MockBlobStorage struct {
+	mu    sync.Mutex
+	Blobs map[string][]byte
+}
+
+func NewMockBlobStorage() *MockBlobStorage {
+	return &MockBlobStorage{Blobs: map[string][]byte{}}
+}
+
+// Put implements ports.BlobStorage.
+func (m *MockBlobStorage) Put(ctx context.Context, key string, body io.Reader, contentType string) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Blobs[key] = data
+	return m.URL(key), nil
+}
+
+// Delete implements ports.BlobStorage.
+func (m *MockBlobStorage) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.Blobs, key)
+	return nil
+}
+
+// URL implements ports.BlobStorage.
+func (m *MockBlobStorage) URL(key string) string {
+	return "https://cdn.test/" + key
+}
@@ -0,0 +1,48 @@
+package mocks
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"sync"
+)
+
+// MockNotifier keeps the sent notifications in memory, Err fails the notifications while it's set
+type MockNotifier struct {
+	mu            sync.Mutex
+	Notifications []domain.Notification
+	Err           error
+}
+
+// Notify implements ports.Notifier.
+func (m *MockNotifier) Notify(ctx context.Context, notification domain.Notification) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	m.Notifications = append(m.Notifications, notification)
+	return nil
+}
+
+// Fail sets the error returned by the next notifications, nil sends them again
+func (m *MockNotifier) Fail(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.Err = err
+}
+
+// Sent returns the notifications of the kind
+func (m *MockNotifier) Sent(kind domain.NotificationKind) []domain.Notification {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sent []domain.Notification
+	for _, n := range m.Notifications {
+		if n.Kind == kind {
+			sent = append(sent, n)
+		}
+	}
+	return sent
+}
@@ -0,0 +1,75 @@
+package mocks
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/mercadopago/mp_dtos"
+	"go-ecommerce/internal/core/domain"
+)
+
+type MockPaymentProvider struct {
+	VerifyPaymentFunc  func(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error)
+	CancelPaymentFunc  func(ctx context.Context, paymentId string) error
+	CapturePaymentFunc func(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error)
+	GetChargebackFunc  func(ctx context.Context, chargebackId string) (*mp_dtos.MpChargeback, error)
+	GetPaymentFunc     func(ctx context.Context, paymentId string) (*mp_dtos.MpSimplifiedPayment, error)
+	SearchPaymentsFunc func(ctx context.Context, externalReference string) ([]*mp_dtos.MpSimplifiedPayment, error)
+}
+
+// GeneratePreference implements ports.PaymentProvider.
+func (m *MockPaymentProvider) GeneratePreference(ctx context.Context, order *domain.Order, items []mp_dtos.MpItem, user *domain.User) *mp_dtos.MpPreferenceRequest {
+	return &mp_dtos.MpPreferenceRequest{ExternalReference: order.ID.String(), Items: items}
+}
+
+// GenerateNewPayment implements ports.PaymentProvider.
+func (m *MockPaymentProvider) GenerateNewPayment(ctx context.Context, preference *mp_dtos.MpPreferenceRequest) (*string, error) {
+	url := "https://payment.test/" + preference.ExternalReference
+	return &url, nil
+}
+
+// VerifyPayment implements ports.PaymentProvider.
+func (m *MockPaymentProvider) VerifyPayment(ctx context.Context, id, topic *string) (*mp_dtos.MpSimplifiedPayment, error) {
+	if m.VerifyPaymentFunc == nil {
+		panic("unimplemented")
+	}
+	return m.VerifyPaymentFunc(ctx, id, topic)
+}
+
+// CancelPayment implements ports.PaymentProvider.
+func (m *MockPaymentProvider) CancelPayment(ctx context.Context, paymentId string) error {
+	if m.CancelPaymentFunc == nil {
+		panic("unimplemented")
+	}
+	return m.CancelPaymentFunc(ctx, paymentId)
+}
+
+// CapturePayment implements ports.PaymentProvider.
+func (m *MockPaymentProvider) CapturePayment(ctx context.Context, paymentId string, amount float64) (*mp_dtos.MpSimplifiedPayment, error) {
+	if m.CapturePaymentFunc == nil {
+		panic("unimplemented")
+	}
+	return m.CapturePaymentFunc(ctx, paymentId, amount)
+}
+
+// GetChargeback implements ports.PaymentProvider.
+func (m *MockPaymentProvider) GetChargeback(ctx context.Context, chargebackId string) (*mp_dtos.MpChargeback, error) {
+	if m.GetChargebackFunc == nil {
+		panic("unimplemented")
+	}
+	return m.GetChargebackFunc(ctx, chargebackId)
+}
+
+// GetPayment implements ports.PaymentProvider.
+func (m *MockPaymentProvider) GetPayment(ctx context.Context, paymentId string) (*mp_dtos.MpSimplifiedPayment, error) {
+	if m.GetPaymentFunc == nil {
+		panic("unimplemented")
+	}
+	return m.GetPaymentFunc(ctx, paymentId)
+}
+
+// SearchPayments implements ports.PaymentProvider.
+func (m *MockPaymentProvider) SearchPayments(ctx context.Context, externalReference string) ([]*mp_dtos.MpSimplifiedPayment, error) {
+	if m.SearchPaymentsFunc == nil {
+		panic("unimplemented")
+	}
+	return m.SearchPaymentsFunc(ctx, externalReference)
+}
@@ -74,6 +74,51 @@ func (m *MockRedis) DeleteByPrefix(ctx context.Context, prefix string) error {
 	return nil
 }
 
+func (m *MockRedis) Keys(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return nil, errors.New("redis connection is closed")
+	}
+
+	var keys []string
+	for k := range m.store {
+		if len(k) >= len(prefix) && k[:len(prefix)] == prefix {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+// Update holds the lock while fn runs, so the updates of a key are serialized like the transactions of redis.
+// fn must not use the mock
+func (m *MockRedis) Update(ctx context.Context, key string, ttl time.Duration, fn func(current []byte) ([]byte, error)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.closed {
+		return errors.New("redis connection is closed")
+	}
+
+	var current []byte
+	if v, ok := m.store[key]; ok {
+		current = []byte(v)
+	}
+
+	next, err := fn(current)
+	if err != nil {
+		return err
+	}
+
+	if next == nil {
+		delete(m.store, key)
+	} else {
+		m.store[key] = string(next)
+	}
+	return nil
+}
+
 func (m *MockRedis) Close() error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -35,3 +35,8 @@ func (m *MockUserService) ListUsers(ctx context.Context, skip uint64, limit uint
 func (m *MockUserService) DeleteUser(ctx context.Context, id uuid.UUID) error {
 	panic("unimplemented")
 }
+
+// RestoreUser implements ports.UserService.
+func (m *MockUserService) RestoreUser(ctx context.Context, id uuid.UUID) (*domain.User, error) {
+	panic("unimplemented")
+}
@@ -1,9 +1,11 @@
 package testhelpers
 
 import (
+	"fmt"
 	"go-ecommerce/internal/adapters/storage/database/postgres/models"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -13,8 +15,27 @@ import (
 // sqlite allows create a database in memory
 func NewSQLiteTestDB(t *testing.T) *gorm.DB {
 	t.Helper()
-	dsn := "file::memory:?cache=shared"
-	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	return openSQLiteTestDB(t, "file::memory:?cache=shared")
+}
+
+// NewIsolatedSQLiteTestDB creates a database in memory only for the test, it's used by the tests that commit, e.g.
+// concurrent requests can't share the transaction of a test. Its connections are serialized, sqlite allows only one
+// writer at a time
+func NewIsolatedSQLiteTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db := openSQLiteTestDB(t, fmt.Sprintf("file:%s?mode=memory&cache=shared", uuid.NewString()))
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+// helper func
+func openSQLiteTestDB(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{TranslateError: true})
 	require.NoError(t, err)
 
 	// foreign keys on
@@ -24,9 +45,30 @@ func NewSQLiteTestDB(t *testing.T) *gorm.DB {
 	require.NoError(t, db.AutoMigrate(
 		&models.UserModel{},
 		&models.ProductModel{},
+		&models.VariantModel{},
+		&models.ProductImageModel{},
+		&models.ProductPriceHistoryModel{},
+		&models.PriceScheduleModel{},
+		&models.WarehouseModel{},
+		&models.WarehouseStockModel{},
+		&models.StockAllocationModel{},
+		&models.StockMovementModel{},
+		&models.StockAlertSubscriptionModel{},
+		&models.NotificationModel{},
+		&models.CartRecoveryModel{},
+		&models.CartModel{},
+		&models.CartItemModel{},
 		&models.CategoryModel{},
 		&models.OrderModel{},
 		&models.OrderProductModel{},
+		&models.PaymentAttemptModel{},
+		&models.DisputeModel{},
+		&models.DisputeEvidenceModel{},
 	))
+
+	// the stock without a warehouse goes to the default one
+	require.NoError(t, db.Where(models.WarehouseModel{Code: "MAIN"}).
+		Attrs(models.WarehouseModel{Name: "Main warehouse", IsDefault: true, Active: true}).
+		FirstOrCreate(&models.WarehouseModel{}).Error)
 	return db
 }
@@ -8,6 +8,7 @@ import (
 	"go-ecommerce/internal/adapters/api/http/handlers"
 	"go-ecommerce/internal/adapters/api/http/routes"
 	"go-ecommerce/internal/adapters/security"
+	"go-ecommerce/internal/test_helpers/mocks"
 	"go-ecommerce/internal/test_helpers/test_containers"
 
 	cachekeys "go-ecommerce/internal/adapters/storage/cache/cache_keys"
@@ -44,8 +45,8 @@ func Test_UserE2E(t *testing.T) {
 
 	// dependency injection
 	repo := repository.NewUserRepo(tx)
-	srv := services.NewUserService(repo, redisCont.Client, hasher)
-	handler := handlers.NewUserHandler(srv)
+	srv := services.NewUserService(repo, redisCont.Client, hasher, &mocks.MockNotifier{})
+	handler := handlers.NewUserHandler(srv, nil)
 
 	r := chi.NewRouter()
 	routes.LoadUserRoutes(r, handler)
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"context"
+	"go-ecommerce/internal/adapters/storage/database/postgres/models"
+	"go-ecommerce/internal/adapters/storage/database/postgres/repository"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/test_helpers/test_containers"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CartRepo_With_Postgres_Container(t *testing.T) {
+	ctx := context.Background()
+
+	// init postgres container
+	cont, err := test_containers.NewPostgresContainerDB(t)
+	require.NoError(t, err)
+	defer cont.Container.Terminate(ctx)
+
+	require.NoError(t, cont.DB.AutoMigrate(&models.CartModel{}, &models.CartItemModel{}))
+
+	// the changes commit, each one in its own transaction
+	repo := repository.NewCartRepo(cont.DB)
+	userId := uuid.New()
+	productId := uuid.New()
+
+	// concurrent changes of the same cart wait for the lock of the row, no one is lost
+	const requests = 10
+
+	var wg sync.WaitGroup
+	errs := make(chan error, requests)
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := repo.UpdateCart(ctx, userId, func(cart *domain.Cart) error {
+				err := cart.AddItem(productId, nil, 1, 10, 100)
+				cart.Touch(time.Now())
+				return err
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	cart, err := repo.GetCart(ctx, userId)
+	require.NoError(t, err)
+	assert.Equal(t, int16(requests), cart.QuantityOf(productId, nil))
+
+	carts, err := repo.ListCarts(ctx)
+	require.NoError(t, err)
+	require.Len(t, carts, 1)
+	assert.Equal(t, userId, carts[0].UserID)
+
+	// a cart left without items is deleted
+	_, err = repo.UpdateCart(ctx, userId, func(cart *domain.Cart) error { return cart.Clear() })
+	require.NoError(t, err)
+
+	carts, err = repo.ListCarts(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, carts)
+}
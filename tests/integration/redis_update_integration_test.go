@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"context"
+	"go-ecommerce/internal/core/domain"
+	"go-ecommerce/internal/test_helpers/test_containers"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RedisUpdate_With_Redis_Container(t *testing.T) {
+	ctx := context.Background()
+
+	// init redis container
+	cont, err := test_containers.NewRedisContainer(t)
+	require.NoError(t, err)
+	defer cont.Container.Terminate(ctx)
+
+	increment := func(current []byte) ([]byte, error) {
+		n := 0
+		if len(current) > 0 {
+			n, _ = strconv.Atoi(string(current))
+		}
+		return []byte(strconv.Itoa(n + 1)), nil
+	}
+
+	// concurrent increments of the same key, no applied increment is lost
+	const requests = 5
+	const increments = 5
+
+	var wg sync.WaitGroup
+	errs := make(chan error, requests*increments)
+	for range requests {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range increments {
+				errs <- cont.Client.Update(ctx, "counter", time.Minute, increment)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	// an update that lost every retry is rejected, it isn't applied
+	applied := 0
+	for err := range errs {
+		if err != nil {
+			require.ErrorIs(t, err, domain.ErrConcurrentUpdate)
+			continue
+		}
+		applied++
+	}
+
+	value, err := cont.Client.Get(ctx, "counter")
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(applied), string(value))
+
+	// a nil value deletes the key
+	err = cont.Client.Update(ctx, "counter", time.Minute, func(current []byte) ([]byte, error) { return nil, nil })
+	require.NoError(t, err)
+
+	_, err = cont.Client.Get(ctx, "counter")
+	assert.Error(t, err)
+}